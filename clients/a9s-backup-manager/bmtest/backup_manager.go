@@ -0,0 +1,162 @@
+/*
+Copyright 2024 Klutch Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package bmtest provides an in-process stand-in for an a9s Backup Manager.
+// Unlike the reaction based fake client, it serves real HTTP, so the client
+// and the controllers built on top of it can be exercised end-to-end,
+// including authentication, request encoding, the /instances/{id}/backups,
+// /restores and /config endpoints and the mapping of error responses,
+// without an a9s deployment.
+package bmtest
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"time"
+
+	bkpmgr "github.com/anynines/klutchio/clients/a9s-backup-manager"
+)
+
+// Options configures a BackupManager.
+type Options struct {
+	// Username and Password, if set, are the basic auth credentials the
+	// backup manager requires on every request.
+	Username string
+	Password string
+	// PollsPerState is the number of times a backup or restore is reported
+	// as queued and then as running before it is done or failed. With the
+	// default of 0, backups and restores are done when they are first
+	// reported.
+	PollsPerState int
+	// InstanceConfig is the backup config of instances added with
+	// AddInstance. If nil, DefaultInstanceConfig is used.
+	InstanceConfig *InstanceConfig
+	// ListOptions makes the BackupManager apply the status,
+	// triggered_after, triggered_before, limit and continue query
+	// parameters of GetBackups and GetRestores and report them as applied
+	// in the X-List-Options header. Otherwise it ignores them, like backup
+	// managers that do not support list options, and leaves them to the
+	// client.
+	ListOptions bool
+	// Now returns the current time. It is used for the timestamps of
+	// backups and restores and for the retention of backups. If nil,
+	// time.Now is used.
+	Now func() time.Time
+}
+
+// BackupManager is an in-process a9s Backup Manager backed by an
+// httptest.Server. It keeps instances, backups and restores in memory and is
+// safe for concurrent use.
+type BackupManager struct {
+	// URL is the base URL of the backup manager, suitable for
+	// bkpmgr.ClientConfiguration.URL.
+	URL string
+
+	server  *httptest.Server
+	options Options
+
+	mu          sync.Mutex
+	instances   map[string]*Instance
+	backups     map[int]*Backup
+	restores    map[int]*Restore
+	faults      []*Fault
+	requests    []Request
+	sequence    int
+	failBackup  bool
+	failRestore bool
+}
+
+// Request is a record of a request the BackupManager received.
+type Request struct {
+	Route  Route
+	Method string
+	Path   string
+	Header http.Header
+}
+
+// NewBackupManager starts a BackupManager configured by the given Options.
+// Callers must call Close when they are done with it.
+func NewBackupManager(options Options) *BackupManager {
+	if options.Now == nil {
+		options.Now = time.Now
+	}
+	m := &BackupManager{
+		options:   options,
+		instances: map[string]*Instance{},
+		backups:   map[int]*Backup{},
+		restores:  map[int]*Restore{},
+	}
+
+	m.server = httptest.NewServer(m.handler())
+	m.URL = m.server.URL
+
+	return m
+}
+
+// Close shuts down the BackupManager and blocks until all outstanding
+// requests have completed.
+func (m *BackupManager) Close() {
+	m.server.Close()
+}
+
+// ClientConfiguration returns the default client configuration pointed at
+// the BackupManager, including its basic auth credentials if it requires
+// them.
+func (m *BackupManager) ClientConfiguration() *bkpmgr.ClientConfiguration {
+	config := bkpmgr.DefaultClientConfiguration()
+	config.Name = "bmtest"
+	config.URL = m.URL
+	if m.options.Username != "" || m.options.Password != "" {
+		config.AuthConfig = &bkpmgr.AuthConfig{
+			BasicAuthConfig: &bkpmgr.BasicAuthConfig{
+				Username: m.options.Username,
+				Password: m.options.Password,
+			},
+		}
+	}
+	return config
+}
+
+// Requests returns all requests the BackupManager received so far, in
+// order.
+func (m *BackupManager) Requests() []Request {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return append([]Request(nil), m.requests...)
+}
+
+func (m *BackupManager) authorized(r *http.Request) bool {
+	if m.options.Username == "" && m.options.Password == "" {
+		return true
+	}
+
+	username, password, ok := r.BasicAuth()
+	if !ok {
+		return false
+	}
+
+	usernameMatches := subtle.ConstantTimeCompare([]byte(username), []byte(m.options.Username)) == 1
+	passwordMatches := subtle.ConstantTimeCompare([]byte(password), []byte(m.options.Password)) == 1
+	return usernameMatches && passwordMatches
+}
+
+func (m *BackupManager) nextID() int {
+	m.sequence++
+	return m.sequence
+}
@@ -0,0 +1,384 @@
+/*
+Copyright 2024 Klutch Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package bmtest
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"k8s.io/utils/ptr"
+
+	bkpmgr "github.com/anynines/klutchio/clients/a9s-backup-manager"
+)
+
+const testInstanceID = "5b4c1e2a-8d2f-4a4f-9a4e-0b6f2b9c7d11"
+
+func newTestClient(t *testing.T, m *BackupManager) bkpmgr.Client {
+	t.Helper()
+
+	config := m.ClientConfiguration()
+	config.RetryPolicy = nil
+	klient, err := bkpmgr.NewClient(config)
+	if err != nil {
+		t.Fatalf("cannot create client: %v", err)
+	}
+	return klient
+}
+
+func createBackup(ctx context.Context, t *testing.T, klient bkpmgr.Client) string {
+	t.Helper()
+
+	response, err := klient.CreateBackup(ctx, &bkpmgr.CreateBackupRequest{InstanceID: testInstanceID})
+	if err != nil {
+		t.Fatalf("CreateBackup: unexpected error: %v", err)
+	}
+	return strconv.Itoa(*response.BackupID)
+}
+
+func getBackupStatus(ctx context.Context, t *testing.T, klient bkpmgr.Client, backupID string) string {
+	t.Helper()
+
+	backup, err := klient.GetBackup(ctx, &bkpmgr.GetBackupRequest{InstanceID: testInstanceID, BackupID: backupID})
+	if err != nil {
+		t.Fatalf("GetBackup: unexpected error: %v", err)
+	}
+	return string(backup.Status)
+}
+
+func TestBackupAndRestoreLifecycle(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	m := NewBackupManager(Options{
+		Username:      "admin",
+		Password:      "secret",
+		PollsPerState: 1,
+		Now:           func() time.Time { return now },
+	})
+	defer m.Close()
+	m.AddInstance(testInstanceID)
+	klient := newTestClient(t, m)
+
+	backupID := createBackup(ctx, t, klient)
+
+	var statuses []string
+	for range 3 {
+		statuses = append(statuses, getBackupStatus(ctx, t, klient, backupID))
+	}
+	if diff := cmp.Diff([]string{StatusQueued, StatusRunning, StatusDone}, statuses); diff != "" {
+		t.Errorf("GetBackup: -want statuses, +got statuses:\n%s", diff)
+	}
+
+	backups, err := klient.GetBackups(ctx, &bkpmgr.GetBackupsRequest{InstanceID: testInstanceID})
+	if err != nil {
+		t.Fatalf("GetBackups: unexpected error: %v", err)
+	}
+	id, _ := strconv.Atoi(backupID)
+	backup, _ := m.Backup(id)
+	want := []bkpmgr.GetBackupResponse{{
+		BackupID:     ptr.To(id),
+		Size:         len(backup.Content),
+		Status:       bkpmgr.BackupStatusDone,
+		TriggeredAt:  now,
+		FinishedAt:   now,
+		Downloadable: true,
+	}}
+	if diff := cmp.Diff(want, backups.Backups); diff != "" {
+		t.Errorf("GetBackups: -want, +got:\n%s", diff)
+	}
+
+	download, err := klient.DownloadBackup(ctx, testInstanceID, backupID)
+	if err != nil {
+		t.Fatalf("DownloadBackup: unexpected error: %v", err)
+	}
+	content, err := io.ReadAll(download)
+	_ = download.Close()
+	if err != nil {
+		t.Fatalf("DownloadBackup: cannot read backup: %v", err)
+	}
+	if diff := cmp.Diff(backup.Content, content); diff != "" {
+		t.Errorf("DownloadBackup: -want, +got:\n%s", diff)
+	}
+
+	restore, err := klient.CreateRestore(ctx, &bkpmgr.CreateRestoreRequest{InstanceID: testInstanceID, BackupID: backupID})
+	if err != nil {
+		t.Fatalf("CreateRestore: unexpected error: %v", err)
+	}
+	restoreID := strconv.Itoa(*restore.RestoreID)
+
+	// The backup cannot be deleted and no other restore can be started
+	// while it is restored.
+	_, err = klient.DeleteBackup(ctx, &bkpmgr.DeleteBackupRequest{InstanceID: testInstanceID, BackupID: ptr.To(id)})
+	if !errors.As(err, &bkpmgr.BackupLockedError{}) {
+		t.Errorf("DeleteBackup while restoring: expected BackupLockedError, got %v", err)
+	}
+	_, err = klient.CreateRestore(ctx, &bkpmgr.CreateRestoreRequest{InstanceID: testInstanceID, BackupID: backupID})
+	if !errors.As(err, &bkpmgr.RestoreInProgress{}) {
+		t.Errorf("CreateRestore while restoring: expected RestoreInProgress, got %v", err)
+	}
+
+	statuses = nil
+	for range 3 {
+		restore, err := klient.GetRestore(ctx, &bkpmgr.GetRestoreRequest{InstanceID: testInstanceID, RestoreID: restoreID})
+		if err != nil {
+			t.Fatalf("GetRestore: unexpected error: %v", err)
+		}
+		statuses = append(statuses, string(restore.Status))
+	}
+	if diff := cmp.Diff([]string{StatusQueued, StatusRunning, StatusDone}, statuses); diff != "" {
+		t.Errorf("GetRestore: -want statuses, +got statuses:\n%s", diff)
+	}
+
+	restores, err := klient.GetRestores(ctx, &bkpmgr.GetRestoresRequest{InstanceID: testInstanceID})
+	if err != nil {
+		t.Fatalf("GetRestores: unexpected error: %v", err)
+	}
+	if len(restores.Restores) != 1 || *restores.Restores[0].BackupID != id {
+		t.Errorf("GetRestores: unexpected restores %+v", restores.Restores)
+	}
+
+	if _, err := klient.DeleteBackup(ctx, &bkpmgr.DeleteBackupRequest{InstanceID: testInstanceID, BackupID: ptr.To(id)}); err != nil {
+		t.Fatalf("DeleteBackup: unexpected error: %v", err)
+	}
+	if status := getBackupStatus(ctx, t, klient, backupID); status != StatusDeleted {
+		t.Errorf("GetBackup after DeleteBackup: expected status %q, got %q", StatusDeleted, status)
+	}
+	_, err = klient.DeleteBackup(ctx, &bkpmgr.DeleteBackupRequest{InstanceID: testInstanceID, BackupID: ptr.To(id)})
+	if !errors.As(err, &bkpmgr.BackupNotFoundError{}) {
+		t.Errorf("DeleteBackup of a deleted backup: expected BackupNotFoundError, got %v", err)
+	}
+}
+
+func TestErrors(t *testing.T) {
+	ctx := context.Background()
+
+	cases := map[string]struct {
+		setup func(m *BackupManager, klient bkpmgr.Client) error
+		want  func(err error) bool
+	}{
+		"UnknownInstance": {
+			setup: func(_ *BackupManager, klient bkpmgr.Client) error {
+				_, err := klient.CreateBackup(ctx, &bkpmgr.CreateBackupRequest{InstanceID: "unknown"})
+				return err
+			},
+			want: func(err error) bool { return errors.As(err, &bkpmgr.InstanceNotFoundError{}) },
+		},
+		"UnknownBackup": {
+			setup: func(_ *BackupManager, klient bkpmgr.Client) error {
+				_, err := klient.GetBackup(ctx, &bkpmgr.GetBackupRequest{InstanceID: testInstanceID, BackupID: "42"})
+				return err
+			},
+			want: func(err error) bool {
+				httpErr, ok := bkpmgr.IsHTTPError(err)
+				return ok && httpErr.StatusCode == http.StatusNotFound
+			},
+		},
+		"RestoreOfRunningBackup": {
+			setup: func(m *BackupManager, klient bkpmgr.Client) error {
+				response, err := klient.CreateBackup(ctx, &bkpmgr.CreateBackupRequest{InstanceID: testInstanceID})
+				if err != nil {
+					return err
+				}
+				_, err = klient.CreateRestore(ctx, &bkpmgr.CreateRestoreRequest{InstanceID: testInstanceID, BackupID: strconv.Itoa(*response.BackupID)})
+				return err
+			},
+			want: func(err error) bool { return errors.As(err, &bkpmgr.BackupNonRestorableState{}) },
+		},
+		"RestoreOfUnknownBackup": {
+			setup: func(_ *BackupManager, klient bkpmgr.Client) error {
+				_, err := klient.CreateRestore(ctx, &bkpmgr.CreateRestoreRequest{InstanceID: testInstanceID, BackupID: "42"})
+				return err
+			},
+			want: func(err error) bool { return errors.As(err, &bkpmgr.BackupNotFound{}) },
+		},
+		"ShortEncryptionKey": {
+			setup: func(_ *BackupManager, klient bkpmgr.Client) error {
+				_, err := klient.UpdateBackupConfig(ctx, &bkpmgr.UpdateBackupConfigRequest{InstanceID: testInstanceID, EncryptionKey: ptr.To("short")})
+				return err
+			},
+			want: func(err error) bool {
+				httpErr, ok := bkpmgr.IsHTTPError(err)
+				return ok && httpErr.StatusCode == http.StatusBadRequest
+			},
+		},
+		"BackupFileDeletionFailed": {
+			setup: func(m *BackupManager, klient bkpmgr.Client) error {
+				m.InjectFault(BackupFileDeletionFailed())
+				_, err := klient.DeleteBackup(ctx, &bkpmgr.DeleteBackupRequest{InstanceID: testInstanceID, BackupID: ptr.To(1)})
+				return err
+			},
+			want: func(err error) bool { return errors.As(err, &bkpmgr.BackupFileDeletionFailed{}) },
+		},
+		"WrongCredentials": {
+			setup: func(m *BackupManager, _ bkpmgr.Client) error {
+				config := m.ClientConfiguration()
+				config.AuthConfig.BasicAuthConfig.Password = "wrong"
+				klient, err := bkpmgr.NewClient(config)
+				if err != nil {
+					return err
+				}
+				_, err = klient.GetBackups(ctx, &bkpmgr.GetBackupsRequest{InstanceID: testInstanceID})
+				return err
+			},
+			want: func(err error) bool {
+				httpErr, ok := bkpmgr.IsHTTPError(err)
+				return ok && httpErr.StatusCode == http.StatusUnauthorized
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			m := NewBackupManager(Options{Username: "admin", Password: "secret", PollsPerState: 1})
+			defer m.Close()
+			m.AddInstance(testInstanceID)
+
+			err := tc.setup(m, newTestClient(t, m))
+			if !tc.want(err) {
+				t.Errorf("unexpected error: %v", err)
+			}
+		})
+	}
+}
+
+func TestFailedBackup(t *testing.T) {
+	ctx := context.Background()
+	m := NewBackupManager(Options{})
+	defer m.Close()
+	m.AddInstance(testInstanceID)
+	klient := newTestClient(t, m)
+
+	m.FailNextBackup()
+	failed := createBackup(ctx, t, klient)
+	done := createBackup(ctx, t, klient)
+
+	if status := getBackupStatus(ctx, t, klient, failed); status != StatusFailed {
+		t.Errorf("expected the first backup to fail, got status %q", status)
+	}
+	if status := getBackupStatus(ctx, t, klient, done); status != StatusDone {
+		t.Errorf("expected the second backup to be done, got status %q", status)
+	}
+}
+
+func TestRetention(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	m := NewBackupManager(Options{
+		InstanceConfig: &InstanceConfig{MinBackupCount: 2, RetentionTime: 7},
+		Now:            func() time.Time { return now },
+	})
+	defer m.Close()
+	m.AddInstance(testInstanceID)
+	klient := newTestClient(t, m)
+
+	// Three backups are taken a week apart, so that only the newest two
+	// are kept once the third is done.
+	var ids []string
+	for range 3 {
+		id := createBackup(ctx, t, klient)
+		getBackupStatus(ctx, t, klient, id)
+		ids = append(ids, id)
+		now = now.AddDate(0, 0, 8)
+	}
+
+	var statuses []string
+	for _, id := range ids {
+		statuses = append(statuses, getBackupStatus(ctx, t, klient, id))
+	}
+	if diff := cmp.Diff([]string{StatusDeleted, StatusDone, StatusDone}, statuses); diff != "" {
+		t.Errorf("-want statuses, +got statuses:\n%s", diff)
+	}
+}
+
+func TestInstanceConfig(t *testing.T) {
+	ctx := context.Background()
+	m := NewBackupManager(Options{})
+	defer m.Close()
+	m.AddInstance(testInstanceID)
+	klient := newTestClient(t, m)
+
+	if _, err := klient.UpdateBackupConfig(ctx, &bkpmgr.UpdateBackupConfigRequest{
+		InstanceID:            testInstanceID,
+		EncryptionKey:         ptr.To("long enough"),
+		ExcludeFromAutoBackup: ptr.To(true),
+	}); err != nil {
+		t.Fatalf("UpdateBackupConfig: unexpected error: %v", err)
+	}
+
+	config, err := klient.GetInstanceConfig(ctx, &bkpmgr.GetInstanceConfigRequest{InstanceID: testInstanceID})
+	if err != nil {
+		t.Fatalf("GetInstanceConfig: unexpected error: %v", err)
+	}
+	want := &bkpmgr.GetInstanceConfigResponse{
+		MinBackupCount:         ptr.To(3),
+		RetentionTime:          ptr.To(7),
+		MinEncryptionKeyLength: ptr.To(8),
+		ExcludeFromAutoBackup:  ptr.To(true),
+	}
+	if diff := cmp.Diff(want, config); diff != "" {
+		t.Errorf("GetInstanceConfig: -want, +got:\n%s", diff)
+	}
+
+	instance, _ := m.Instance(testInstanceID)
+	if instance.EncryptionKey != "long enough" {
+		t.Errorf("expected the encryption key to be stored, got %q", instance.EncryptionKey)
+	}
+}
+
+func TestFaults(t *testing.T) {
+	ctx := context.Background()
+	m := NewBackupManager(Options{})
+	defer m.Close()
+	m.AddInstance(testInstanceID)
+	klient := newTestClient(t, m)
+
+	m.InjectFault(&Fault{Route: RouteGetBackups, StatusCode: http.StatusServiceUnavailable, Times: 1})
+	m.InjectFault(Latency(RouteGetInstanceConfig, time.Second))
+
+	_, err := klient.GetBackups(ctx, &bkpmgr.GetBackupsRequest{InstanceID: testInstanceID})
+	if httpErr, ok := bkpmgr.IsHTTPError(err); !ok || httpErr.StatusCode != http.StatusServiceUnavailable {
+		t.Errorf("GetBackups: expected the injected fault, got %v", err)
+	}
+	if _, err := klient.GetBackups(ctx, &bkpmgr.GetBackupsRequest{InstanceID: testInstanceID}); err != nil {
+		t.Errorf("GetBackups: expected the fault to be used up, got %v", err)
+	}
+
+	timeoutCtx, cancel := context.WithTimeout(ctx, 50*time.Millisecond)
+	defer cancel()
+	if _, err := klient.GetInstanceConfig(timeoutCtx, &bkpmgr.GetInstanceConfigRequest{InstanceID: testInstanceID}); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("GetInstanceConfig: expected the deadline to be exceeded, got %v", err)
+	}
+
+	m.ClearFaults()
+	if _, err := klient.GetInstanceConfig(ctx, &bkpmgr.GetInstanceConfigRequest{InstanceID: testInstanceID}); err != nil {
+		t.Errorf("GetInstanceConfig: expected the fault to be cleared, got %v", err)
+	}
+
+	var routes []Route
+	for _, request := range m.Requests() {
+		routes = append(routes, request.Route)
+	}
+	want := []Route{RouteGetBackups, RouteGetBackups, RouteGetInstanceConfig, RouteGetInstanceConfig}
+	if diff := cmp.Diff(want, routes); diff != "" {
+		t.Errorf("Requests(): -want routes, +got routes:\n%s", diff)
+	}
+}
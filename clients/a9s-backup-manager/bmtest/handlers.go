@@ -0,0 +1,418 @@
+/*
+Copyright 2024 Klutch Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package bmtest
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	bkpmgr "github.com/anynines/klutchio/clients/a9s-backup-manager"
+)
+
+const (
+	// instanceNotFound is the error the backup manager returns for requests
+	// about instances it does not know.
+	instanceNotFound            = "InstanceNotFound"
+	instanceNotFoundDescription = "Instance not found"
+
+	varInstanceID = "instance_id"
+	varBackupID   = "backup_id"
+	varRestoreID  = "restore_id"
+
+	instancePath = "/instances/{" + varInstanceID + "}"
+	backupPath   = instancePath + "/backups/{" + varBackupID + "}"
+	restorePath  = instancePath + "/restores/{" + varRestoreID + "}"
+)
+
+type createBackupResponseBody struct {
+	ID      int    `json:"id"`
+	Message string `json:"message"`
+}
+
+type backupResponseBody struct {
+	ID           int     `json:"id"`
+	Size         int     `json:"size"`
+	Status       string  `json:"status"`
+	TriggeredAt  *string `json:"triggered_at"`
+	FinishedAt   *string `json:"finished_at"`
+	Downloadable bool    `json:"downloadable"`
+}
+
+type createRestoreResponseBody struct {
+	ID int `json:"id"`
+}
+
+type restoreResponseBody struct {
+	ID          int     `json:"id"`
+	BackupID    int     `json:"backup_id"`
+	Status      string  `json:"status"`
+	TriggeredAt *string `json:"triggered_at"`
+	FinishedAt  *string `json:"finished_at"`
+}
+
+type updateBackupConfigRequestBody struct {
+	EncryptionKey            *string `json:"encryption_key"`
+	ExcludeFromAutoBackup    *bool   `json:"exclude_from_auto_backup"`
+	CredentialsUpdatedByUser *bool   `json:"credentials_updated_by_user"`
+}
+
+type messageResponseBody struct {
+	Message string `json:"message"`
+}
+
+type errorResponseBody struct {
+	Error       string `json:"error,omitempty"`
+	Description string `json:"description,omitempty"`
+}
+
+func (m *BackupManager) handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.Handle("POST "+instancePath+"/backups", m.route(RouteCreateBackup, m.createBackup))
+	mux.Handle("GET "+instancePath+"/backups", m.route(RouteGetBackups, m.getBackups))
+	mux.Handle("GET "+backupPath, m.route(RouteGetBackup, m.getBackup))
+	mux.Handle("DELETE "+backupPath, m.route(RouteDeleteBackup, m.deleteBackup))
+	mux.Handle("GET "+backupPath+"/download", m.route(RouteDownloadBackup, m.downloadBackup))
+	mux.Handle("POST "+backupPath+"/restore", m.route(RouteCreateRestore, m.createRestore))
+	mux.Handle("GET "+instancePath+"/restores", m.route(RouteGetRestores, m.getRestores))
+	mux.Handle("GET "+restorePath, m.route(RouteGetRestore, m.getRestore))
+	mux.Handle("GET "+instancePath+"/config", m.route(RouteGetInstanceConfig, m.getInstanceConfig))
+	mux.Handle("PUT "+instancePath, m.route(RouteUpdateBackupConfig, m.updateBackupConfig))
+	return mux
+}
+
+// route records the request, applies injected faults, checks the
+// credentials and then calls handle with m.mu held.
+func (m *BackupManager) route(route Route, handle http.HandlerFunc) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		m.mu.Lock()
+		m.requests = append(m.requests, Request{
+			Route:  route,
+			Method: r.Method,
+			Path:   r.URL.Path,
+			Header: r.Header.Clone(),
+		})
+		fault := m.takeFault(route)
+		m.mu.Unlock()
+
+		if fault != nil && applyFault(w, r, fault) {
+			return
+		}
+
+		if !m.authorized(r) {
+			writeError(w, http.StatusUnauthorized, "", "invalid credentials")
+			return
+		}
+
+		m.mu.Lock()
+		defer m.mu.Unlock()
+
+		handle(w, r)
+	})
+}
+
+func (m *BackupManager) createBackup(w http.ResponseWriter, r *http.Request) {
+	instance, ok := m.existingInstance(w, r)
+	if !ok {
+		return
+	}
+
+	backup := &Backup{
+		ID:          m.nextID(),
+		InstanceID:  instance.ID,
+		Status:      StatusQueued,
+		TriggeredAt: m.options.Now(),
+		job:         m.newJob(m.failBackup),
+	}
+	m.failBackup = false
+	m.backups[backup.ID] = backup
+
+	writeJSON(w, http.StatusCreated, createBackupResponseBody{
+		ID:      backup.ID,
+		Message: "job to backup is queued",
+	})
+}
+
+func (m *BackupManager) getBackups(w http.ResponseWriter, r *http.Request) {
+	instance, ok := m.existingInstance(w, r)
+	if !ok {
+		return
+	}
+
+	query, err := m.listQuery(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "", err.Error())
+		return
+	}
+
+	backups := m.backupsOf(instance.ID)
+	for _, backup := range backups {
+		m.observeBackup(backup)
+	}
+	backups, next := listPage(backups, query, func(b *Backup) (int, string, time.Time) {
+		return b.ID, b.Status, b.TriggeredAt
+	})
+
+	response := []backupResponseBody{}
+	for _, backup := range backups {
+		response = append(response, backup.response())
+	}
+	query.writeHeader(w, next)
+	writeJSON(w, http.StatusOK, response)
+}
+
+func (m *BackupManager) getBackup(w http.ResponseWriter, r *http.Request) {
+	backup, ok := m.existingBackup(w, r)
+	if !ok {
+		return
+	}
+
+	m.observeBackup(backup)
+	writeJSON(w, http.StatusOK, backup.response())
+}
+
+func (m *BackupManager) deleteBackup(w http.ResponseWriter, r *http.Request) {
+	backup, ok := m.existingBackup(w, r)
+	if !ok {
+		return
+	}
+	if backup.Status == StatusDeleted {
+		writeError(w, http.StatusNotFound, "", "backup not found")
+		return
+	}
+	if m.restoring(backup.ID) {
+		writeError(w, http.StatusLocked, "", "backup is currently being restored")
+		return
+	}
+
+	m.removeBackup(backup)
+	writeJSON(w, http.StatusOK, messageResponseBody{Message: "backup deleted"})
+}
+
+func (m *BackupManager) downloadBackup(w http.ResponseWriter, r *http.Request) {
+	backup, ok := m.existingBackup(w, r)
+	if !ok {
+		return
+	}
+	if backup.Status == StatusDeleted {
+		writeError(w, http.StatusNotFound, "", "backup not found")
+		return
+	}
+	if !backup.Downloadable {
+		writeError(w, http.StatusUnprocessableEntity, "", "backup is not downloadable")
+		return
+	}
+
+	checksum := sha256.Sum256(backup.Content)
+	w.Header().Set("Content-Type", "application/octet-stream")
+	w.Header().Set("Etag", fmt.Sprintf(`"bmtest-%d"`, backup.ID))
+	w.Header().Set("Repr-Digest", "sha-256=:"+base64.StdEncoding.EncodeToString(checksum[:])+":")
+	// ServeContent answers range requests, so that clients can resume
+	// downloads.
+	http.ServeContent(w, r, "", backup.FinishedAt, bytes.NewReader(backup.Content))
+}
+
+func (m *BackupManager) createRestore(w http.ResponseWriter, r *http.Request) {
+	backup, ok := m.existingBackup(w, r)
+	if !ok {
+		return
+	}
+	switch {
+	case backup.Status == StatusDeleted:
+		writeError(w, http.StatusNotFound, "", "backup not found")
+		return
+	case backup.Status != StatusDone:
+		writeError(w, http.StatusUnprocessableEntity, "", "backup is in a non-restorable state")
+		return
+	}
+	for _, restore := range m.restoresOf(backup.InstanceID) {
+		if restore.inProgress() {
+			writeError(w, http.StatusConflict, "", "restore already in progress")
+			return
+		}
+	}
+
+	restore := &Restore{
+		ID:          m.nextID(),
+		InstanceID:  backup.InstanceID,
+		BackupID:    backup.ID,
+		Status:      StatusQueued,
+		TriggeredAt: m.options.Now(),
+		job:         m.newJob(m.failRestore),
+	}
+	m.failRestore = false
+	m.restores[restore.ID] = restore
+
+	writeJSON(w, http.StatusAccepted, createRestoreResponseBody{ID: restore.ID})
+}
+
+func (m *BackupManager) getRestores(w http.ResponseWriter, r *http.Request) {
+	instance, ok := m.existingInstance(w, r)
+	if !ok {
+		return
+	}
+
+	query, err := m.listQuery(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "", err.Error())
+		return
+	}
+
+	restores := m.restoresOf(instance.ID)
+	for _, restore := range restores {
+		m.observeRestore(restore)
+	}
+	restores, next := listPage(restores, query, func(r *Restore) (int, string, time.Time) {
+		return r.ID, r.Status, r.TriggeredAt
+	})
+
+	response := []restoreResponseBody{}
+	for _, restore := range restores {
+		response = append(response, restore.response())
+	}
+	query.writeHeader(w, next)
+	writeJSON(w, http.StatusOK, response)
+}
+
+func (m *BackupManager) getRestore(w http.ResponseWriter, r *http.Request) {
+	instance, ok := m.existingInstance(w, r)
+	if !ok {
+		return
+	}
+
+	id, err := strconv.Atoi(r.PathValue(varRestoreID))
+	restore, found := m.restores[id]
+	if err != nil || !found || restore.InstanceID != instance.ID {
+		writeError(w, http.StatusNotFound, "", "restore not found")
+		return
+	}
+
+	m.observeRestore(restore)
+	writeJSON(w, http.StatusOK, restore.response())
+}
+
+func (m *BackupManager) getInstanceConfig(w http.ResponseWriter, r *http.Request) {
+	instance, ok := m.existingInstance(w, r)
+	if !ok {
+		return
+	}
+
+	config := instance.Config
+	response := bkpmgr.GetInstanceConfigResponse{
+		MinBackupCount:         &config.MinBackupCount,
+		RetentionTime:          &config.RetentionTime,
+		MinEncryptionKeyLength: &config.MinEncryptionKeyLength,
+		ExcludeFromAutoBackup:  &config.ExcludeFromAutoBackup,
+	}
+	if config.BackupType != "" {
+		response.BackupType = &config.BackupType
+	}
+	writeJSON(w, http.StatusOK, response)
+}
+
+func (m *BackupManager) updateBackupConfig(w http.ResponseWriter, r *http.Request) {
+	body := &updateBackupConfigRequestBody{}
+	if err := json.NewDecoder(r.Body).Decode(body); err != nil {
+		writeError(w, http.StatusBadRequest, "", "malformed request body: "+err.Error())
+		return
+	}
+
+	instance, ok := m.existingInstance(w, r)
+	if !ok {
+		return
+	}
+	if body.EncryptionKey != nil && len(*body.EncryptionKey) < instance.Config.MinEncryptionKeyLength {
+		writeError(w, http.StatusBadRequest, "", fmt.Sprintf("encryption key must be at least %d characters long", instance.Config.MinEncryptionKeyLength))
+		return
+	}
+
+	if body.EncryptionKey != nil {
+		instance.EncryptionKey = *body.EncryptionKey
+	}
+	if body.ExcludeFromAutoBackup != nil {
+		instance.Config.ExcludeFromAutoBackup = *body.ExcludeFromAutoBackup
+	}
+	if body.CredentialsUpdatedByUser != nil {
+		instance.CredentialsUpdatedByUser = *body.CredentialsUpdatedByUser
+	}
+	writeJSON(w, http.StatusOK, messageResponseBody{Message: "instance updated"})
+}
+
+// existingInstance looks up the instance a request is for. If it does not
+// exist the request is answered with 404 Not Found.
+func (m *BackupManager) existingInstance(w http.ResponseWriter, r *http.Request) (*Instance, bool) {
+	instance, ok := m.instances[r.PathValue(varInstanceID)]
+	if !ok {
+		writeError(w, http.StatusNotFound, instanceNotFound, instanceNotFoundDescription)
+		return nil, false
+	}
+	return instance, true
+}
+
+// existingBackup looks up the backup a request is for. If it or its
+// instance does not exist the request is answered with 404 Not Found.
+// Deleted backups are returned.
+func (m *BackupManager) existingBackup(w http.ResponseWriter, r *http.Request) (*Backup, bool) {
+	instance, ok := m.existingInstance(w, r)
+	if !ok {
+		return nil, false
+	}
+
+	id, err := strconv.Atoi(r.PathValue(varBackupID))
+	backup, found := m.backups[id]
+	if err != nil || !found || backup.InstanceID != instance.ID {
+		writeError(w, http.StatusNotFound, "", "backup not found")
+		return nil, false
+	}
+	return backup, true
+}
+
+func (b *Backup) response() backupResponseBody {
+	return backupResponseBody{
+		ID:           b.ID,
+		Size:         b.Size,
+		Status:       b.Status,
+		TriggeredAt:  formatTime(b.TriggeredAt),
+		FinishedAt:   formatTime(b.FinishedAt),
+		Downloadable: b.Downloadable,
+	}
+}
+
+func (r *Restore) response() restoreResponseBody {
+	return restoreResponseBody{
+		ID:          r.ID,
+		BackupID:    r.BackupID,
+		Status:      r.Status,
+		TriggeredAt: formatTime(r.TriggeredAt),
+		FinishedAt:  formatTime(r.FinishedAt),
+	}
+}
+
+func writeJSON(w http.ResponseWriter, statusCode int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+	_ = json.NewEncoder(w).Encode(body)
+}
+
+func writeError(w http.ResponseWriter, statusCode int, errorMessage, description string) {
+	writeJSON(w, statusCode, errorResponseBody{Error: errorMessage, Description: description})
+}
@@ -0,0 +1,133 @@
+/*
+Copyright 2024 Klutch Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package bmtest
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// listOptionsApplied is the X-List-Options header of list responses if
+// Options.ListOptions is set.
+const listOptionsApplied = "status,triggered_after,triggered_before,limit"
+
+// listQuery is the list options of a GetBackups or GetRestores request. It
+// is the zero value if Options.ListOptions is not set.
+type listQuery struct {
+	applied         bool
+	status          []string
+	triggeredAfter  time.Time
+	triggeredBefore time.Time
+	limit           int
+	// after is the ID of the last item of the previous page. Continue
+	// tokens are IDs, as backups and restores are listed by ascending ID.
+	after int
+}
+
+// listQuery parses the list options of the request.
+func (m *BackupManager) listQuery(r *http.Request) (listQuery, error) {
+	if !m.options.ListOptions {
+		return listQuery{}, nil
+	}
+
+	values := r.URL.Query()
+	query := listQuery{applied: true}
+	if status := values.Get("status"); status != "" {
+		query.status = strings.Split(status, ",")
+	}
+
+	var err error
+	if query.triggeredAfter, err = parseListTime(values, "triggered_after"); err != nil {
+		return listQuery{}, err
+	}
+	if query.triggeredBefore, err = parseListTime(values, "triggered_before"); err != nil {
+		return listQuery{}, err
+	}
+	if query.limit, err = parseListInt(values, "limit"); err != nil {
+		return listQuery{}, err
+	}
+	if query.after, err = parseListInt(values, "continue"); err != nil {
+		return listQuery{}, err
+	}
+	return query, nil
+}
+
+func parseListTime(values url.Values, name string) (time.Time, error) {
+	value := values.Get(name)
+	if value == "" {
+		return time.Time{}, nil
+	}
+	t, err := time.Parse(time.RFC3339Nano, value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid %s %q", name, value)
+	}
+	return t, nil
+}
+
+func parseListInt(values url.Values, name string) (int, error) {
+	value := values.Get(name)
+	if value == "" {
+		return 0, nil
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("invalid %s %q", name, value)
+	}
+	return n, nil
+}
+
+// writeHeader reports the applied list options and the continue token of
+// the next page, if any, in the header of the response.
+func (q listQuery) writeHeader(w http.ResponseWriter, next string) {
+	if !q.applied {
+		return
+	}
+	w.Header().Set("X-List-Options", listOptionsApplied)
+	if next != "" {
+		w.Header().Set("X-Continue", next)
+	}
+}
+
+// listPage returns the page of the items, sorted by ascending ID, that the
+// query asks for and the continue token of the next page.
+func listPage[T any](items []T, q listQuery, entry func(T) (int, string, time.Time)) ([]T, string) {
+	if !q.applied {
+		return items, ""
+	}
+
+	var page []T
+	for _, item := range items {
+		id, status, triggeredAt := entry(item)
+		switch {
+		case id <= q.after:
+		case len(q.status) > 0 && !slices.Contains(q.status, status):
+		case !q.triggeredAfter.IsZero() && !triggeredAt.After(q.triggeredAfter):
+		case !q.triggeredBefore.IsZero() && !triggeredAt.Before(q.triggeredBefore):
+		case q.limit > 0 && len(page) == q.limit:
+			last, _, _ := entry(page[len(page)-1])
+			return page, strconv.Itoa(last)
+		default:
+			page = append(page, item)
+		}
+	}
+	return page, ""
+}
@@ -0,0 +1,124 @@
+/*
+Copyright 2024 Klutch Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package bmtest
+
+import (
+	"context"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+
+	bkpmgr "github.com/anynines/klutchio/clients/a9s-backup-manager"
+)
+
+func TestListOptions(t *testing.T) {
+	cases := map[string]struct {
+		listOptions bool
+	}{
+		"applied by the backup manager": {listOptions: true},
+		"applied by the client":         {listOptions: false},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			start := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+			now := start
+			m := NewBackupManager(Options{
+				ListOptions: tc.listOptions,
+				Now:         func() time.Time { return now },
+			})
+			defer m.Close()
+			m.AddInstance(testInstanceID)
+			klient := newTestClient(t, m)
+
+			// Backups 1 to 5, one hour apart, of which backup 3 fails.
+			var backupIDs []string
+			for i := range 5 {
+				now = start.Add(time.Duration(i) * time.Hour)
+				if i == 2 {
+					m.FailNextBackup()
+				}
+				backupID := createBackup(ctx, t, klient)
+				getBackupStatus(ctx, t, klient, backupID)
+				backupIDs = append(backupIDs, backupID)
+			}
+
+			requests := len(m.Requests())
+			var got []int
+			for backup, err := range bkpmgr.Backups(ctx, klient, bkpmgr.GetBackupsRequest{
+				InstanceID:     testInstanceID,
+				Status:         []bkpmgr.BackupStatus{bkpmgr.BackupStatusDone},
+				TriggeredAfter: start,
+				Limit:          2,
+			}) {
+				if err != nil {
+					t.Fatalf("Backups: unexpected error: %v", err)
+				}
+				got = append(got, *backup.BackupID)
+			}
+			if diff := cmp.Diff([]int{2, 4, 5}, got); diff != "" {
+				t.Errorf("Backups: -want IDs, +got IDs:\n%s", diff)
+			}
+			if pages := len(m.Requests()) - requests; pages != 2 {
+				t.Errorf("Backups: want 2 pages, got %d", pages)
+			}
+
+			requests = len(m.Requests())
+			for range bkpmgr.Backups(ctx, klient, bkpmgr.GetBackupsRequest{InstanceID: testInstanceID, Limit: 1}) {
+				break
+			}
+			if pages := len(m.Requests()) - requests; pages != 1 {
+				t.Errorf("Backups: want 1 page after break, got %d", pages)
+			}
+
+			var restoreIDs []int
+			for i, backupID := range []string{backupIDs[1], backupIDs[3]} {
+				if i == 1 {
+					m.FailNextRestore()
+				}
+				response, err := klient.CreateRestore(ctx, &bkpmgr.CreateRestoreRequest{InstanceID: testInstanceID, BackupID: backupID})
+				if err != nil {
+					t.Fatalf("CreateRestore: unexpected error: %v", err)
+				}
+				// The restore is done or failed once it is observed, which
+				// unlocks the instance for the next one.
+				if _, err := klient.GetRestore(ctx, &bkpmgr.GetRestoreRequest{InstanceID: testInstanceID, RestoreID: strconv.Itoa(*response.RestoreID)}); err != nil {
+					t.Fatalf("GetRestore: unexpected error: %v", err)
+				}
+				restoreIDs = append(restoreIDs, *response.RestoreID)
+			}
+
+			got = nil
+			for restore, err := range bkpmgr.Restores(ctx, klient, bkpmgr.GetRestoresRequest{
+				InstanceID: testInstanceID,
+				Status:     []bkpmgr.RestoreStatus{bkpmgr.RestoreStatusFailed},
+				Limit:      1,
+			}) {
+				if err != nil {
+					t.Fatalf("Restores: unexpected error: %v", err)
+				}
+				got = append(got, *restore.RestoreID)
+			}
+			if diff := cmp.Diff(restoreIDs[1:], got); diff != "" {
+				t.Errorf("Restores: -want IDs, +got IDs:\n%s", diff)
+			}
+		})
+	}
+}
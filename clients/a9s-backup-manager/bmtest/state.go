@@ -0,0 +1,341 @@
+/*
+Copyright 2024 Klutch Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package bmtest
+
+import (
+	"fmt"
+	"slices"
+	"time"
+
+	bkpmgr "github.com/anynines/klutchio/clients/a9s-backup-manager"
+)
+
+// These are the states the BackupManager reports for backups and restores.
+// Only backups are deleted.
+const (
+	StatusQueued  = string(bkpmgr.BackupStatusQueued)
+	StatusRunning = string(bkpmgr.BackupStatusRunning)
+	StatusDone    = string(bkpmgr.BackupStatusDone)
+	StatusFailed  = string(bkpmgr.BackupStatusFailed)
+	StatusDeleted = string(bkpmgr.BackupStatusDeleted)
+)
+
+// InstanceConfig is the backup config of an instance.
+type InstanceConfig struct {
+	// MinBackupCount is the number of done backups that are kept
+	// regardless of their age.
+	MinBackupCount int
+	// RetentionTime is the number of days after which backups beyond
+	// MinBackupCount are deleted.
+	RetentionTime int
+	// MinEncryptionKeyLength is the minimum length of the encryption key
+	// of the instance.
+	MinEncryptionKeyLength int
+	// ExcludeFromAutoBackup excludes the instance from scheduled backups.
+	ExcludeFromAutoBackup bool
+	// BackupType is the type of the backups, e.g. "postgresql_wal". It is
+	// reported as null if empty.
+	BackupType string
+}
+
+// DefaultInstanceConfig returns the backup config of instances if
+// Options.InstanceConfig is nil.
+func DefaultInstanceConfig() InstanceConfig {
+	return InstanceConfig{
+		MinBackupCount:         3,
+		RetentionTime:          7,
+		MinEncryptionKeyLength: 8,
+	}
+}
+
+// Instance is a data service instance known to the BackupManager.
+type Instance struct {
+	ID     string
+	Config InstanceConfig
+	// EncryptionKey and CredentialsUpdatedByUser are set by
+	// UpdateBackupConfig requests.
+	EncryptionKey            string
+	CredentialsUpdatedByUser bool
+}
+
+// Backup is a backup known to the BackupManager.
+type Backup struct {
+	ID           int
+	InstanceID   string
+	Status       string
+	Size         int
+	TriggeredAt  time.Time
+	FinishedAt   time.Time
+	Downloadable bool
+	// Content is the file of the backup. It is set once the backup is
+	// done and removed when the backup is deleted.
+	Content []byte
+
+	job
+}
+
+// Restore is a restore known to the BackupManager.
+type Restore struct {
+	ID          int
+	InstanceID  string
+	BackupID    int
+	Status      string
+	TriggeredAt time.Time
+	FinishedAt  time.Time
+
+	job
+}
+
+// job tracks the progress of a backup or restore. A job is reported as
+// queued and then as running for pollsLeft more observations each before
+// it is done, or failed if failed is set.
+type job struct {
+	pollsLeft int
+	failed    bool
+}
+
+// AddInstance makes the instance with the given ID known to the
+// BackupManager. Requests about other instances are answered with 404 Not
+// Found, as the backup manager only knows instances provisioned by its
+// service broker.
+func (m *BackupManager) AddInstance(id string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	config := DefaultInstanceConfig()
+	if m.options.InstanceConfig != nil {
+		config = *m.options.InstanceConfig
+	}
+	if _, ok := m.instances[id]; !ok {
+		m.instances[id] = &Instance{ID: id, Config: config}
+	}
+}
+
+// SetInstanceConfig replaces the backup config of the instance with the
+// given ID. It returns false if the instance does not exist.
+func (m *BackupManager) SetInstanceConfig(id string, config InstanceConfig) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	instance, ok := m.instances[id]
+	if ok {
+		instance.Config = config
+	}
+	return ok
+}
+
+// Instance returns a copy of the instance with the given ID.
+func (m *BackupManager) Instance(id string) (Instance, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	instance, ok := m.instances[id]
+	if !ok {
+		return Instance{}, false
+	}
+	return *instance, true
+}
+
+// Backup returns a copy of the backup with the given ID, without observing
+// it.
+func (m *BackupManager) Backup(id int) (Backup, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	backup, ok := m.backups[id]
+	if !ok {
+		return Backup{}, false
+	}
+	result := *backup
+	result.Content = slices.Clone(backup.Content)
+	return result, true
+}
+
+// Restore returns a copy of the restore with the given ID, without
+// observing it.
+func (m *BackupManager) Restore(id int) (Restore, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	restore, ok := m.restores[id]
+	if !ok {
+		return Restore{}, false
+	}
+	return *restore, true
+}
+
+// FailNextBackup makes the next backup the BackupManager creates end in the
+// failed state.
+func (m *BackupManager) FailNextBackup() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.failBackup = true
+}
+
+// FailNextRestore makes the next restore the BackupManager creates end in
+// the failed state.
+func (m *BackupManager) FailNextRestore() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.failRestore = true
+}
+
+// newJob returns the job of a backup or restore that is created now and
+// fails if fail is set. Callers must hold m.mu.
+func (m *BackupManager) newJob(fail bool) job {
+	return job{pollsLeft: m.options.PollsPerState, failed: fail}
+}
+
+// advance moves the job in the given status on by one observation and
+// returns its new status. Callers must hold m.mu.
+func (j *job) advance(status string, pollsPerState int) string {
+	for status == StatusQueued || status == StatusRunning {
+		if j.pollsLeft > 0 {
+			j.pollsLeft--
+			return status
+		}
+
+		switch {
+		case status == StatusQueued:
+			status = StatusRunning
+			j.pollsLeft = pollsPerState
+		case j.failed:
+			status = StatusFailed
+		default:
+			status = StatusDone
+		}
+	}
+	return status
+}
+
+// observeBackup advances the backup by one observation. When it is done,
+// its file is created and the retention of its instance is applied. Callers
+// must hold m.mu.
+func (m *BackupManager) observeBackup(backup *Backup) {
+	status := backup.advance(backup.Status, m.options.PollsPerState)
+	if status == backup.Status {
+		return
+	}
+
+	backup.Status = status
+	switch status {
+	case StatusDone:
+		backup.FinishedAt = m.options.Now()
+		backup.Downloadable = true
+		backup.Content = []byte(fmt.Sprintf("bmtest backup %d of instance %s\n", backup.ID, backup.InstanceID))
+		backup.Size = len(backup.Content)
+		m.applyRetention(backup.InstanceID)
+	case StatusFailed:
+		backup.FinishedAt = m.options.Now()
+	}
+}
+
+// observeRestore advances the restore by one observation. Callers must hold
+// m.mu.
+func (m *BackupManager) observeRestore(restore *Restore) {
+	status := restore.advance(restore.Status, m.options.PollsPerState)
+	if status != restore.Status && (status == StatusDone || status == StatusFailed) {
+		restore.FinishedAt = m.options.Now()
+	}
+	restore.Status = status
+}
+
+// applyRetention deletes the done backups of the instance beyond the
+// newest MinBackupCount that are older than the RetentionTime. Backups that
+// are being restored are kept. Callers must hold m.mu.
+func (m *BackupManager) applyRetention(instanceID string) {
+	instance, ok := m.instances[instanceID]
+	if !ok {
+		return
+	}
+
+	var done []*Backup
+	for _, backup := range m.backupsOf(instanceID) {
+		if backup.Status == StatusDone {
+			done = append(done, backup)
+		}
+	}
+	// Newest first.
+	slices.Reverse(done)
+
+	cutoff := m.options.Now().AddDate(0, 0, -instance.Config.RetentionTime)
+	for i, backup := range done {
+		if i < instance.Config.MinBackupCount || !backup.TriggeredAt.Before(cutoff) || m.restoring(backup.ID) {
+			continue
+		}
+		m.removeBackup(backup)
+	}
+}
+
+// removeBackup marks the backup as deleted and removes its file. Callers
+// must hold m.mu.
+func (m *BackupManager) removeBackup(backup *Backup) {
+	backup.Status = StatusDeleted
+	backup.Downloadable = false
+	backup.Content = nil
+}
+
+// restoring returns whether a restore of the backup with the given ID is
+// queued or running. Callers must hold m.mu.
+func (m *BackupManager) restoring(backupID int) bool {
+	for _, restore := range m.restores {
+		if restore.BackupID == backupID && restore.inProgress() {
+			return true
+		}
+	}
+	return false
+}
+
+// backupsOf returns the backups of the instance, oldest first. Callers must
+// hold m.mu.
+func (m *BackupManager) backupsOf(instanceID string) []*Backup {
+	var backups []*Backup
+	for _, backup := range m.backups {
+		if backup.InstanceID == instanceID {
+			backups = append(backups, backup)
+		}
+	}
+	slices.SortFunc(backups, func(a, b *Backup) int { return a.ID - b.ID })
+	return backups
+}
+
+// restoresOf returns the restores of the instance, oldest first. Callers
+// must hold m.mu.
+func (m *BackupManager) restoresOf(instanceID string) []*Restore {
+	var restores []*Restore
+	for _, restore := range m.restores {
+		if restore.InstanceID == instanceID {
+			restores = append(restores, restore)
+		}
+	}
+	slices.SortFunc(restores, func(a, b *Restore) int { return a.ID - b.ID })
+	return restores
+}
+
+func (r *Restore) inProgress() bool {
+	return r.Status == StatusQueued || r.Status == StatusRunning
+}
+
+func formatTime(t time.Time) *string {
+	if t.IsZero() {
+		return nil
+	}
+	formatted := t.UTC().Format("2006-01-02T15:04:05.000Z07:00")
+	return &formatted
+}
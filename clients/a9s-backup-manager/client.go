@@ -27,9 +27,12 @@ import (
 	"io"
 	"net"
 	"net/http"
+	"slices"
 	"strings"
 	"time"
 
+	"github.com/go-logr/logr"
+	"go.opentelemetry.io/otel/trace"
 	"k8s.io/klog/v2"
 )
 
@@ -42,6 +45,7 @@ const (
 	restoreURLFmt         = "%s/instances/%s/restores/%s"
 	createRestoreURLFmt   = "%s/instances/%s/backups/%s/restore"
 	deleteBackupURLFmt    = "%s/instances/%s/backups/%d"
+	downloadBackupURLFmt  = "%s/instances/%s/backups/%s/download"
 )
 
 // NewClient is a CreateFunc for creating a new functional Client and
@@ -59,6 +63,7 @@ func NewClient(config *ClientConfiguration) (Client, error) {
 			KeepAlive: 30 * time.Second,
 			DualStack: true,
 		}).DialContext,
+		ResponseHeaderTimeout: time.Duration(config.TimeoutSeconds) * time.Second,
 		MaxIdleConns:          100,
 		IdleConnTimeout:       90 * time.Second,
 		ExpectContinueTimeout: 1 * time.Second,
@@ -81,15 +86,46 @@ func NewClient(config *ClientConfiguration) (Client, error) {
 
 	transport.TLSClientConfig = tlsConfig
 	httpClient.Transport = transport
+	if config.Metrics != nil {
+		httpClient.Transport = config.Metrics.InstrumentedTransport(transport, config.ProviderConfig)
+	}
 
 	c := &client{
 		Name:       config.Name,
 		URL:        strings.TrimRight(config.URL, "/"),
 		Verbose:    config.Verbose,
 		httpClient: httpClient,
+		// Downloads are bound by their context only, but must still be
+		// answered in time.
+		downloadHTTPClient: &http.Client{Transport: httpClient.Transport},
+		tracerProvider:     config.TracerProvider,
+		logger:             config.Logger,
+		interceptors:       config.Interceptors,
+		retryPolicy:        config.RetryPolicy,
+	}
+	if c.logger.GetSink() == nil {
+		c.logger = klog.Background()
 	}
+	c.logger = c.logger.WithValues("backupManager", config.Name)
 	c.doRequestFunc = c.doRequest
 
+	c.endpoints = []string{c.URL}
+	for _, failoverURL := range config.FailoverURLs {
+		failoverURL = normalizeEndpoint(failoverURL)
+		if failoverURL != "" && !slices.Contains(c.endpoints, failoverURL) {
+			c.endpoints = append(c.endpoints, failoverURL)
+		}
+	}
+	c.endpointHealth = config.EndpointHealth
+	if c.endpointHealth == nil {
+		c.endpointHealth = NewEndpointHealth(defaultEndpointCooldown)
+	}
+	if config.RateLimiter != nil {
+		// The rate limiter is the outermost interceptor, so that it also
+		// respects 429 responses of interceptors.
+		c.interceptors = append([]Interceptor{config.RateLimiter.intercept}, c.interceptors...)
+	}
+
 	if config.AuthConfig != nil {
 		if config.AuthConfig.BasicAuthConfig == nil {
 			return nil, errors.New("non-nil AuthConfig cannot be empty")
@@ -112,8 +148,15 @@ type client struct {
 	AuthConfig *AuthConfig
 	Verbose    bool
 
-	httpClient    *http.Client
-	doRequestFunc doRequestFunc
+	httpClient         *http.Client
+	downloadHTTPClient *http.Client
+	doRequestFunc      doRequestFunc
+	tracerProvider     trace.TracerProvider
+	logger             logr.Logger
+	endpoints          []string
+	endpointHealth     *EndpointHealth
+	interceptors       []Interceptor
+	retryPolicy        *RetryPolicy
 }
 
 var _ Client = &client{}
@@ -131,12 +174,21 @@ const (
 // prepareAndDo prepares a request for the given method, URL, and
 // message body, and executes the request, returning an http.Response or an
 // error.  Errors returned from this function represent http-layer errors and
-// not errors in the Backup Manager API.
-func (c *client) prepareAndDo(method, url string, params map[string]string, body interface{}) (*http.Response, error) {
+// not errors in the Backup Manager API.  The request is bound to ctx, which
+// carries the span and operation of the client method that sends it.
+func (c *client) prepareAndDo(ctx context.Context, method, url string, params map[string]string, body interface{}) (*http.Response, error) {
+	return c.prepareAndDoWithHeader(ctx, method, url, nil, params, body)
+}
+
+// prepareAndDoWithHeader is prepareAndDo for requests that carry the given
+// additional headers.
+func (c *client) prepareAndDoWithHeader(ctx context.Context, method, url string, header http.Header, params map[string]string, body interface{}) (*http.Response, error) {
 	var bodyReader io.Reader
+	var bodyBytes []byte
 
 	if body != nil {
-		bodyBytes, err := json.Marshal(body)
+		var err error
+		bodyBytes, err = json.Marshal(body)
 		if err != nil {
 			return nil, err
 		}
@@ -144,11 +196,15 @@ func (c *client) prepareAndDo(method, url string, params map[string]string, body
 		bodyReader = bytes.NewReader(bodyBytes)
 	}
 
-	request, err := http.NewRequestWithContext(context.Background(), method, url, bodyReader)
+	request, err := http.NewRequestWithContext(ctx, method, url, bodyReader)
 	if err != nil {
 		return nil, err
 	}
 
+	for key, values := range header {
+		request.Header[key] = values
+	}
+
 	if bodyReader != nil {
 		request.Header.Set(contentType, jsonType)
 	}
@@ -170,14 +226,45 @@ func (c *client) prepareAndDo(method, url string, params map[string]string, body
 		request.URL.RawQuery = q.Encode()
 	}
 
+	traceRequest(request)
+
 	if c.Verbose {
-		klog.Infof("backup-manager: doing request to %q", url)
+		c.logger.Info("Sending request",
+			"method", method,
+			"url", request.URL.String(),
+			"header", redactHeader(request.Header),
+			"body", redactBody(bodyBytes))
+	}
+
+	return c.send(request)
+}
+
+// send executes the request, retrying it according to the retry policy of
+// the client.
+func (c *client) send(request *http.Request) (*http.Response, error) {
+	if c.retryPolicy != nil {
+		return c.sendWithRetries(request)
 	}
+	return c.sendOnce(request)
+}
 
-	return c.doRequestFunc(request)
+// sendOnce executes the request and records its outcome on the span of the
+// request context.
+func (c *client) sendOnce(request *http.Request) (*http.Response, error) {
+	if len(c.endpoints) > 1 {
+		return c.sendWithFailover(request)
+	}
+	response, err := c.do(request)
+	recordResponse(request, response, err)
+	return response, err
 }
 
 func (c *client) doRequest(request *http.Request) (*http.Response, error) {
+	// The timeout of the HTTP client includes reading the response body,
+	// which would cut off the download of large backups.
+	if c.downloadHTTPClient != nil && invocationFromContext(request.Context()).Operation == operationDownloadBackup {
+		return c.downloadHTTPClient.Do(request)
+	}
 	return c.httpClient.Do(request)
 }
 
@@ -190,7 +277,10 @@ func (c *client) unmarshalResponse(response *http.Response, obj interface{}) err
 	}
 
 	if c.Verbose {
-		klog.Infof("backup-manager: response body: %v, type: %T", string(body), obj)
+		c.logger.Info("Received response",
+			"status", response.StatusCode,
+			"body", redactBody(body),
+			"type", fmt.Sprintf("%T", obj))
 	}
 
 	err = json.Unmarshal(body, obj)
@@ -204,7 +294,7 @@ func (c *client) unmarshalResponse(response *http.Response, obj interface{}) err
 // handleFailureResponse returns an HTTPStatusCodeError for the given
 // response.
 func (c *client) handleFailureResponse(response *http.Response) error {
-	klog.Info("handling failure responses")
+	c.logger.V(1).Info("Handling failure response", "status", response.StatusCode)
 
 	httpErr := HTTPStatusCodeError{
 		StatusCode: response.StatusCode,
@@ -242,7 +332,7 @@ func drainReader(reader io.Reader) error {
 // CheckAvailability verifies that the backup manager is reachable and responding.
 // The endpoint parameter allows customization of which endpoint to check (e.g., "/instances").
 // If the endpoint is empty, a default endpoint will be used.
-func (c *client) CheckAvailability(endpoint string) error {
+func (c *client) CheckAvailability(ctx context.Context, endpoint string) error {
 	// Use provided endpoint or default to /instances
 	if endpoint == "" {
 		endpoint = "/instances"
@@ -250,7 +340,10 @@ func (c *client) CheckAvailability(endpoint string) error {
 
 	url := fmt.Sprintf("%s%s", c.URL, endpoint)
 
-	request, err := http.NewRequest(http.MethodGet, url, nil)
+	ctx, span := c.startSpan(ctx, operationCheckAvailability, nil)
+	defer span.End()
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
 	if err != nil {
 		return fmt.Errorf("creating request: %w", err)
 	}
@@ -262,7 +355,9 @@ func (c *client) CheckAvailability(endpoint string) error {
 		)
 	}
 
-	response, err := c.httpClient.Do(request)
+	traceRequest(request)
+
+	response, err := c.send(request)
 	if err != nil {
 		return fmt.Errorf("health check request failed: %w", err)
 	}
@@ -17,18 +17,22 @@ limitations under the License.
 package backupmanager
 
 import (
+	"context"
 	"fmt"
 	"net/http"
 )
 
-func (c *client) CreateBackup(r *CreateBackupRequest) (*CreateBackupResponse, error) {
+func (c *client) CreateBackup(ctx context.Context, r *CreateBackupRequest) (*CreateBackupResponse, error) {
 	if err := validateCreateBackupRequest(r); err != nil {
 		return nil, err
 	}
 
 	fullURL := fmt.Sprintf(instanceBackupURLFmt, c.URL, r.InstanceID)
 
-	response, err := c.prepareAndDo(http.MethodPost, fullURL, nil, nil)
+	ctx, span := c.startSpan(ctx, operationCreateBackup, r, attributeInstanceID.String(r.InstanceID))
+	defer span.End()
+
+	response, err := c.prepareAndDo(ctx, http.MethodPost, fullURL, nil, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -17,6 +17,7 @@ limitations under the License.
 package backupmanager
 
 import (
+	"context"
 	"fmt"
 	"net/http"
 	"testing"
@@ -123,7 +124,7 @@ func TestCreateBackup(t *testing.T) {
 
 		klient := newTestClient(t, tc.name, tc.httpChecks, tc.httpReaction)
 
-		response, err := klient.CreateBackup(tc.request)
+		response, err := klient.CreateBackup(context.Background(), tc.request)
 
 		doResponseChecks(t, tc.name, response, err, tc.expectedResponse, tc.expectedErrMessage, tc.expectedErr)
 	}
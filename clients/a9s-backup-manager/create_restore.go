@@ -17,19 +17,23 @@ limitations under the License.
 package backupmanager
 
 import (
+	"context"
 	"fmt"
 	"net/http"
 	"strconv"
 )
 
-func (c *client) CreateRestore(r *CreateRestoreRequest) (*CreateRestoreResponse, error) {
+func (c *client) CreateRestore(ctx context.Context, r *CreateRestoreRequest) (*CreateRestoreResponse, error) {
 	if err := validateCreateRestoreRequest(r); err != nil {
 		return nil, err
 	}
 
 	fullURL := fmt.Sprintf(createRestoreURLFmt, c.URL, r.InstanceID, r.BackupID)
 
-	response, err := c.prepareAndDo(http.MethodPost, fullURL, nil, nil)
+	ctx, span := c.startSpan(ctx, operationCreateRestore, r, attributeInstanceID.String(r.InstanceID), attributeBackupID.String(r.BackupID))
+	defer span.End()
+
+	response, err := c.prepareAndDo(ctx, http.MethodPost, fullURL, nil, nil)
 	if err != nil {
 		return nil, err
 	}
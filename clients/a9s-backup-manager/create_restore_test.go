@@ -17,6 +17,7 @@ limitations under the License.
 package backupmanager
 
 import (
+	"context"
 	"fmt"
 	"net/http"
 	"testing"
@@ -164,7 +165,7 @@ func TestCreateRestore(t *testing.T) {
 
 		klient := newTestClient(t, tc.name, tc.httpChecks, tc.httpReaction)
 
-		response, err := klient.CreateRestore(tc.request)
+		response, err := klient.CreateRestore(context.Background(), tc.request)
 
 		doResponseChecks(t, tc.name, response, err, tc.expectedResponse, tc.expectedErrMessage, tc.expectedErr)
 	}
@@ -17,17 +17,22 @@ limitations under the License.
 package backupmanager
 
 import (
+	"context"
 	"fmt"
 	"net/http"
+	"strconv"
 )
 
-func (c *client) DeleteBackup(r *DeleteBackupRequest) (*DeleteBackupResponse, error) {
+func (c *client) DeleteBackup(ctx context.Context, r *DeleteBackupRequest) (*DeleteBackupResponse, error) {
 	if err := validateDeleteBackupRequest(r); err != nil {
 		return nil, err
 	}
 	fullURL := fmt.Sprintf(deleteBackupURLFmt, c.URL, r.InstanceID, *r.BackupID)
 
-	response, err := c.prepareAndDo(http.MethodDelete, fullURL, nil, nil)
+	ctx, span := c.startSpan(ctx, operationDeleteBackup, r, attributeInstanceID.String(r.InstanceID), attributeBackupID.String(strconv.Itoa(*r.BackupID)))
+	defer span.End()
+
+	response, err := c.prepareAndDo(ctx, http.MethodDelete, fullURL, nil, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -17,6 +17,7 @@ limitations under the License.
 package backupmanager
 
 import (
+	"context"
 	"fmt"
 	"net/http"
 	"strconv"
@@ -162,7 +163,7 @@ func TestDeleteBackup(t *testing.T) {
 
 			klient := newTestClient(t, name, tc.httpChecks, tc.httpReaction)
 			var response *DeleteBackupResponse
-			response, err := klient.DeleteBackup(tc.request)
+			response, err := klient.DeleteBackup(context.Background(), tc.request)
 			doResponseChecks(t, name, response, err, tc.expectedResponse, tc.expectedErrMessage, tc.expectedErr)
 
 		})
@@ -179,7 +180,7 @@ func TestDeleteBackup(t *testing.T) {
 
 		klient := newTestClient(t, name, tc.httpChecks, tc.httpReaction)
 		var response *DeleteBackupResponse
-		response, err := klient.DeleteBackup(tc.request)
+		response, err := klient.DeleteBackup(context.Background(), tc.request)
 		doResponseChecks(t, name, response, err, tc.expectedResponse, tc.expectedErrMessage, tc.expectedErr)
 	}
 }
@@ -0,0 +1,343 @@
+/*
+Copyright 2024 Klutch Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package backupmanager
+
+import (
+	"bytes"
+	"context"
+	"crypto/md5"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// maxDownloadResumes is how often in a row the download of a backup is
+// resumed without receiving any data before it fails.
+const maxDownloadResumes = 5
+
+// digestAlgorithms are the algorithms of the checksums the client verifies
+// downloaded backups with, by their names in Repr-Digest and Digest headers,
+// strongest first.
+var digestAlgorithms = []struct {
+	name string
+	new  func() hash.Hash
+}{
+	{name: "sha-512", new: sha512.New},
+	{name: "sha-256", new: sha256.New},
+	{name: "md5", new: md5.New},
+}
+
+func (c *client) DownloadBackup(ctx context.Context, instanceID, backupID string) (io.ReadCloser, error) {
+	r := &GetBackupRequest{InstanceID: instanceID, BackupID: backupID}
+	if err := validateGetBackupRequest(r); err != nil {
+		return nil, err
+	}
+
+	ctx, span := c.startSpan(ctx, operationDownloadBackup, r, attributeInstanceID.String(instanceID), attributeBackupID.String(backupID))
+
+	download := &backupDownload{
+		client: c,
+		ctx:    ctx,
+		span:   span,
+		url:    fmt.Sprintf(downloadBackupURLFmt, c.URL, instanceID, backupID),
+		size:   -1,
+	}
+	if err := download.open(); err != nil {
+		span.End()
+		return nil, err
+	}
+
+	return download, nil
+}
+
+// backupDownload reads the file of a backup from the backup manager. If the
+// connection breaks, it requests the rest of the file with a range request.
+// Once the file is read, it is verified against the checksum the backup
+// manager sent along with it.
+type backupDownload struct {
+	client *client
+	ctx    context.Context
+	span   trace.Span
+	url    string
+
+	// validator is the ETag or Last-Modified date of the file, which ensures
+	// that resumed downloads continue the same file.
+	validator string
+	// size is the size of the file, or -1 if it is unknown.
+	size   int64
+	offset int64
+
+	algorithm string
+	checksum  []byte
+	hash      hash.Hash
+
+	body    io.ReadCloser
+	resumes int
+	cause   error
+	err     error
+	endSpan sync.Once
+}
+
+// open requests the file from the current offset on.
+func (d *backupDownload) open() error {
+	var header http.Header
+	if d.offset > 0 {
+		header = http.Header{"Range": {fmt.Sprintf("bytes=%d-", d.offset)}}
+		if d.validator != "" {
+			header.Set("If-Range", d.validator)
+		}
+	}
+
+	response, err := d.client.prepareAndDoWithHeader(d.ctx, http.MethodGet, d.url, header, nil, nil)
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case response.StatusCode == http.StatusOK && d.offset == 0:
+		d.validator = rangeValidator(response.Header)
+		d.size = response.ContentLength
+		d.algorithm, d.checksum = responseChecksum(response.Header)
+		for _, algorithm := range digestAlgorithms {
+			if algorithm.name == d.algorithm {
+				d.hash = algorithm.new()
+			}
+		}
+	case response.StatusCode == http.StatusOK:
+		// The backup manager sent the whole file again, either because it
+		// does not support range requests or because the file changed.
+		if d.validator != "" && rangeValidator(response.Header) != d.validator {
+			response.Body.Close()
+			return errors.New("backup changed while it was downloaded")
+		}
+		if _, err := io.CopyN(io.Discard, response.Body, d.offset); err != nil {
+			response.Body.Close()
+			return fmt.Errorf("skipping the downloaded part of the backup: %w", err)
+		}
+	case response.StatusCode == http.StatusPartialContent:
+		if start, ok := contentRangeStart(response.Header.Get("Content-Range")); !ok || start != d.offset {
+			response.Body.Close()
+			return fmt.Errorf("backup manager resumed the download at %q instead of byte %d", response.Header.Get("Content-Range"), d.offset)
+		}
+	default:
+		defer func() {
+			_ = drainReader(response.Body)
+			response.Body.Close()
+		}()
+
+		if response.StatusCode == http.StatusNotFound {
+			return BackupNotFoundError{Reason: d.client.handleFailureResponse(response)}
+		}
+		return d.client.handleFailureResponse(response)
+	}
+
+	d.body = response.Body
+	return nil
+}
+
+// Read reads the file of the backup. It returns a ChecksumMismatchError
+// instead of io.EOF if the file does not match its checksum.
+func (d *backupDownload) Read(p []byte) (int, error) {
+	for {
+		if d.err != nil {
+			return 0, d.err
+		}
+		if d.body == nil {
+			if err := d.resume(); err != nil {
+				d.fail(err)
+				continue
+			}
+		}
+
+		n, err := d.body.Read(p)
+		d.offset += int64(n)
+		if d.hash != nil {
+			d.hash.Write(p[:n])
+		}
+		if n > 0 {
+			d.resumes = 0
+		}
+		if err == io.EOF && d.size >= 0 && d.offset < d.size {
+			err = io.ErrUnexpectedEOF
+		}
+
+		switch {
+		case err == nil:
+			return n, nil
+		case err == io.EOF:
+			d.err = d.verify()
+			d.end()
+			return n, d.err
+		case d.ctx.Err() != nil:
+			d.fail(d.ctx.Err())
+			return n, d.err
+		}
+
+		// The connection broke, the rest of the file is requested on the
+		// next read.
+		d.body.Close()
+		d.body = nil
+		d.cause = err
+		if n > 0 {
+			return n, nil
+		}
+	}
+}
+
+// resume requests the rest of the file after the connection broke, waiting
+// according to the retry policy of the client first.
+func (d *backupDownload) resume() error {
+	if d.resumes == maxDownloadResumes {
+		return fmt.Errorf("downloading backup: %w", d.cause)
+	}
+	d.resumes++
+
+	var delay time.Duration
+	if d.client.retryPolicy != nil {
+		delay = d.client.retryPolicy.delay(d.resumes-1, nil)
+	}
+	d.client.logger.V(1).Info("Resuming backup download", "offset", d.offset, "error", d.cause.Error(), "delay", delay.String())
+
+	timer := time.NewTimer(delay)
+	select {
+	case <-d.ctx.Done():
+		timer.Stop()
+		return d.ctx.Err()
+	case <-timer.C:
+	}
+
+	return d.open()
+}
+
+// verify returns io.EOF if the file matches its checksum, or if the backup
+// manager did not send one.
+func (d *backupDownload) verify() error {
+	if d.hash == nil {
+		return io.EOF
+	}
+	if actual := d.hash.Sum(nil); !bytes.Equal(actual, d.checksum) {
+		return ChecksumMismatchError{
+			Algorithm: d.algorithm,
+			Expected:  base64.StdEncoding.EncodeToString(d.checksum),
+			Actual:    base64.StdEncoding.EncodeToString(actual),
+		}
+	}
+	return io.EOF
+}
+
+// fail makes every further read return err.
+func (d *backupDownload) fail(err error) {
+	d.err = err
+	if d.body != nil {
+		d.body.Close()
+		d.body = nil
+	}
+	d.span.RecordError(err)
+	d.span.SetStatus(codes.Error, err.Error())
+	d.end()
+}
+
+func (d *backupDownload) end() {
+	d.endSpan.Do(func() { d.span.End() })
+}
+
+// Close closes the connection to the backup manager without reading the rest
+// of the file.
+func (d *backupDownload) Close() error {
+	var err error
+	if d.body != nil {
+		err = d.body.Close()
+		d.body = nil
+	}
+	if d.err == nil {
+		d.err = errors.New("backup download is closed")
+	}
+	d.end()
+	return err
+}
+
+// rangeValidator returns the strong ETag or the Last-Modified date of a file,
+// which resumed downloads send in the If-Range header, or an empty string if
+// the file has neither.
+func rangeValidator(header http.Header) string {
+	if etag := header.Get("ETag"); etag != "" && !strings.HasPrefix(etag, "W/") {
+		return etag
+	}
+	return header.Get("Last-Modified")
+}
+
+// responseChecksum returns the strongest checksum of the file sent in the
+// Repr-Digest, Digest or Content-MD5 header, or an empty algorithm if there
+// is none.
+func responseChecksum(header http.Header) (string, []byte) {
+	checksums := map[string][]byte{}
+	for _, key := range []string{"Repr-Digest", "Digest"} {
+		for _, field := range strings.Split(header.Get(key), ",") {
+			algorithm, value, ok := strings.Cut(strings.TrimSpace(field), "=")
+			if !ok {
+				continue
+			}
+			// Repr-Digest encloses the checksum in colons.
+			checksum, err := base64.StdEncoding.DecodeString(strings.Trim(value, ":"))
+			if err != nil {
+				continue
+			}
+			if _, ok := checksums[strings.ToLower(algorithm)]; !ok {
+				checksums[strings.ToLower(algorithm)] = checksum
+			}
+		}
+	}
+	if value := header.Get("Content-MD5"); value != "" {
+		if checksum, err := base64.StdEncoding.DecodeString(value); err == nil {
+			checksums["md5"] = checksum
+		}
+	}
+
+	for _, algorithm := range digestAlgorithms {
+		if checksum, ok := checksums[algorithm.name]; ok {
+			return algorithm.name, checksum
+		}
+	}
+	return "", nil
+}
+
+// contentRangeStart returns the first byte of a Content-Range header like
+// "bytes 100-199/200".
+func contentRangeStart(value string) (int64, bool) {
+	byteRange, ok := strings.CutPrefix(value, "bytes ")
+	if !ok {
+		return 0, false
+	}
+	first, _, ok := strings.Cut(byteRange, "-")
+	if !ok {
+		return 0, false
+	}
+	start, err := strconv.ParseInt(first, 10, 64)
+	return start, err == nil
+}
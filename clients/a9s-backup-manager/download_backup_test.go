@@ -0,0 +1,295 @@
+/*
+Copyright 2024 Klutch Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package backupmanager
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+const backupContent = "the quick brown fox jumps over the lazy dog"
+
+func TestDownloadBackup(t *testing.T) {
+	checksum := sha256.Sum256([]byte(backupContent))
+
+	cases := map[string]struct {
+		server *backupServer
+		// wantRanges are the Range headers of the requests.
+		wantRanges []string
+		wantErr    error
+	}{
+		"Download": {
+			server:     &backupServer{},
+			wantRanges: []string{""},
+		},
+		"VerifiedChecksum": {
+			server: &backupServer{header: http.Header{
+				"Repr-Digest": {"sha-256=:" + base64.StdEncoding.EncodeToString(checksum[:]) + ":"},
+			}},
+			wantRanges: []string{""},
+		},
+		"ChecksumMismatch": {
+			server: &backupServer{header: http.Header{
+				"Digest": {"SHA-256=" + base64.StdEncoding.EncodeToString(make([]byte, sha256.Size))},
+			}},
+			wantRanges: []string{""},
+			wantErr:    ChecksumMismatchError{},
+		},
+		"ResumedAfterBrokenConnection": {
+			server: &backupServer{
+				header:     http.Header{"Etag": {`"v1"`}},
+				breakAfter: []int{10, 5},
+			},
+			wantRanges: []string{"", "bytes=10-", "bytes=15-"},
+		},
+		"ResumedAfterPrematureEnd": {
+			server:     &backupServer{endAfter: []int{10}},
+			wantRanges: []string{"", "bytes=10-"},
+		},
+		"ResumedWithoutRangeSupport": {
+			server: &backupServer{
+				header:      http.Header{"Etag": {`"v1"`}},
+				breakAfter:  []int{10},
+				ignoreRange: true,
+			},
+			wantRanges: []string{"", "bytes=10-"},
+		},
+		"ResumedChecksum": {
+			server: &backupServer{
+				header: http.Header{
+					"Repr-Digest": {"sha-256=:" + base64.StdEncoding.EncodeToString(checksum[:]) + ":"},
+				},
+				breakAfter: []int{10},
+			},
+			wantRanges: []string{"", "bytes=10-"},
+		},
+		"ChangedWhileDownloading": {
+			server: &backupServer{
+				header:      http.Header{"Etag": {`"v1"`}},
+				breakAfter:  []int{10},
+				ignoreRange: true,
+				changed:     true,
+			},
+			wantRanges: []string{"", "bytes=10-"},
+			wantErr:    errors.New("backup changed while it was downloaded"),
+		},
+		"ResumesLimited": {
+			server:     &backupServer{breakAfter: []int{10, 0, 0, 0, 0, 0, 0}},
+			wantRanges: []string{"", "bytes=10-", "bytes=10-", "bytes=10-", "bytes=10-", "bytes=10-"},
+			wantErr:    errBrokenConnection,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			klient := newTestClient(t, name, httpChecks{}, httpReaction{})
+			klient.doRequestFunc = tc.server.do
+
+			reader, err := klient.DownloadBackup(context.Background(), "instance-id", "1")
+			if err != nil {
+				t.Fatalf("DownloadBackup(...): unexpected error: %v", err)
+			}
+			got, err := io.ReadAll(reader)
+			if closeErr := reader.Close(); closeErr != nil {
+				t.Errorf("Close(): unexpected error: %v", closeErr)
+			}
+
+			switch want := tc.wantErr.(type) {
+			case nil:
+				if err != nil {
+					t.Errorf("unexpected error: %v", err)
+				}
+				if string(got) != backupContent {
+					t.Errorf("want %q, got %q", backupContent, got)
+				}
+			case ChecksumMismatchError:
+				if !errors.As(err, &want) || want.Algorithm != "sha-256" {
+					t.Errorf("want checksum mismatch, got %v", err)
+				}
+			default:
+				if err == nil || !strings.Contains(err.Error(), want.Error()) {
+					t.Errorf("want error %q, got %v", want, err)
+				}
+			}
+
+			var ranges []string
+			for _, request := range tc.server.requests {
+				ranges = append(ranges, request.Header.Get("Range"))
+				if request.Header.Get("Range") != "" && tc.server.header.Get("ETag") != request.Header.Get("If-Range") {
+					t.Errorf("want If-Range %q, got %q", tc.server.header.Get("ETag"), request.Header.Get("If-Range"))
+				}
+			}
+			if fmt.Sprint(ranges) != fmt.Sprint(tc.wantRanges) {
+				t.Errorf("want requests with ranges %q, got %q", tc.wantRanges, ranges)
+			}
+		})
+	}
+}
+
+func TestDownloadBackupErrors(t *testing.T) {
+	cases := map[string]struct {
+		backupID string
+		status   int
+		wantErr  func(error) bool
+	}{
+		"InvalidBackupID": {
+			backupID: "latest",
+			wantErr:  func(err error) bool { return err.Error() == "backupID must be a numerical value" },
+		},
+		"NotFound": {
+			backupID: "1",
+			status:   http.StatusNotFound,
+			wantErr:  func(err error) bool { return errors.As(err, &BackupNotFoundError{}) },
+		},
+		"ServerError": {
+			backupID: "1",
+			status:   http.StatusInternalServerError,
+			wantErr: func(err error) bool {
+				httpErr, ok := IsHTTPError(err)
+				return ok && httpErr.StatusCode == http.StatusInternalServerError
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			klient := newTestClient(t, name, httpChecks{URL: "/instances/instance-id/backups/1/download"}, httpReaction{
+				status: tc.status,
+				body:   `{"error":"oops"}`,
+			})
+
+			reader, err := klient.DownloadBackup(context.Background(), "instance-id", tc.backupID)
+			if reader != nil {
+				t.Error("want no reader on error")
+			}
+			if err == nil || !tc.wantErr(err) {
+				t.Errorf("unexpected error %v", err)
+			}
+		})
+	}
+}
+
+func TestResponseChecksum(t *testing.T) {
+	cases := map[string]struct {
+		header        http.Header
+		wantAlgorithm string
+		wantChecksum  string
+	}{
+		"None": {
+			header: http.Header{},
+		},
+		"ReprDigest": {
+			header:        http.Header{"Repr-Digest": {"sha-256=:YWJj:, unknown=:ZGVm:"}},
+			wantAlgorithm: "sha-256",
+			wantChecksum:  "abc",
+		},
+		"StrongestAlgorithm": {
+			header:        http.Header{"Digest": {"MD5=YWJj,SHA-512=ZGVm"}},
+			wantAlgorithm: "sha-512",
+			wantChecksum:  "def",
+		},
+		"ReprDigestPreferred": {
+			header:        http.Header{"Repr-Digest": {"sha-256=:YWJj:"}, "Digest": {"SHA-256=ZGVm"}},
+			wantAlgorithm: "sha-256",
+			wantChecksum:  "abc",
+		},
+		"ContentMD5": {
+			header:        http.Header{"Content-Md5": {"YWJj"}},
+			wantAlgorithm: "md5",
+			wantChecksum:  "abc",
+		},
+		"InvalidEncoding": {
+			header: http.Header{"Repr-Digest": {"sha-256=:not base64:"}},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			algorithm, checksum := responseChecksum(tc.header)
+			if algorithm != tc.wantAlgorithm || string(checksum) != tc.wantChecksum {
+				t.Errorf("want %s checksum %q, got %s checksum %q", tc.wantAlgorithm, tc.wantChecksum, algorithm, checksum)
+			}
+		})
+	}
+}
+
+var errBrokenConnection = errors.New("connection reset by peer")
+
+// backupServer serves backupContent like a backup manager that supports
+// range requests.
+type backupServer struct {
+	// header is sent with every response.
+	header http.Header
+	// breakAfter is the number of bytes after which the connection of the
+	// nth response breaks.
+	breakAfter []int
+	// endAfter is the number of bytes after which the nth response ends
+	// prematurely.
+	endAfter []int
+	// ignoreRange makes the server answer range requests with the whole
+	// file.
+	ignoreRange bool
+	// changed makes the server answer further requests with another ETag.
+	changed bool
+
+	requests []*http.Request
+}
+
+func (s *backupServer) do(request *http.Request) (*http.Response, error) {
+	n := len(s.requests)
+	s.requests = append(s.requests, request)
+
+	response := &http.Response{StatusCode: http.StatusOK, Header: http.Header{}}
+	for key, values := range s.header {
+		response.Header[key] = values
+	}
+	if s.changed && n > 0 {
+		response.Header.Set("ETag", `"v2"`)
+	}
+
+	content := []byte(backupContent)
+	var start int
+	if _, err := fmt.Sscanf(request.Header.Get("Range"), "bytes=%d-", &start); err == nil && !s.ignoreRange {
+		response.StatusCode = http.StatusPartialContent
+		response.Header.Set("Content-Range", fmt.Sprintf("bytes %d-%d/%d", start, len(content)-1, len(content)))
+		content = content[start:]
+	}
+	response.ContentLength = int64(len(content))
+
+	var body io.Reader = bytes.NewReader(content)
+	if n < len(s.breakAfter) {
+		body = io.MultiReader(bytes.NewReader(content[:s.breakAfter[n]]), failingReader{})
+	} else if n < len(s.endAfter) {
+		body = bytes.NewReader(content[:s.endAfter[n]])
+	}
+	response.Body = io.NopCloser(body)
+	return response, nil
+}
+
+type failingReader struct{}
+
+func (failingReader) Read([]byte) (int, error) {
+	return 0, errBrokenConnection
+}
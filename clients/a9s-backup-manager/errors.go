@@ -205,3 +205,23 @@ func (e RestoreInProgress) Error() string {
 		e.reason,
 	)
 }
+
+// ChecksumMismatchError is an error type signifying that a downloaded backup
+// file does not match the checksum the backup manager sent along with it.
+type ChecksumMismatchError struct {
+	// Algorithm is the name of the checksum algorithm, e.g. "sha-256".
+	Algorithm string
+	// Expected is the base64-encoded checksum sent by the backup manager.
+	Expected string
+	// Actual is the base64-encoded checksum of the downloaded file.
+	Actual string
+}
+
+func (e ChecksumMismatchError) Error() string {
+	return fmt.Sprintf(
+		"backup file does not match its %s checksum: expected %s, got %s",
+		e.Algorithm,
+		e.Expected,
+		e.Actual,
+	)
+}
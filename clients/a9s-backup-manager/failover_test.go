@@ -0,0 +1,114 @@
+/*
+Copyright 2024 Klutch Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package backupmanager
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestFailover(t *testing.T) {
+	refused := &net.OpError{Op: "dial", Net: "tcp", Err: errors.New("connection refused")}
+
+	cases := map[string]struct {
+		// status or error of the first endpoint. The second endpoint answers
+		// 200 OK.
+		status    int
+		err       error
+		request   func(c *client) error
+		wantHosts []string
+		wantErr   bool
+	}{
+		"get fails over if the endpoint is unreachable": {
+			err:       refused,
+			request:   getBackup,
+			wantHosts: []string{"a.example.com", "b.example.com"},
+		},
+		"get fails over if the endpoint is unavailable": {
+			status:    http.StatusServiceUnavailable,
+			request:   getBackup,
+			wantHosts: []string{"a.example.com", "b.example.com"},
+		},
+		"create fails over if the endpoint is unreachable": {
+			err:       refused,
+			request:   createBackup,
+			wantHosts: []string{"a.example.com", "b.example.com"},
+		},
+		"create does not fail over if the endpoint is unavailable": {
+			status:    http.StatusBadGateway,
+			request:   createBackup,
+			wantHosts: []string{"a.example.com"},
+			wantErr:   true,
+		},
+		"availability check fails over": {
+			err:       refused,
+			request:   func(c *client) error { return c.CheckAvailability(context.Background(), "") },
+			wantHosts: []string{"a.example.com", "b.example.com"},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			klient := newTestClient(t, name, httpChecks{}, httpReaction{})
+			klient.URL = "https://a.example.com"
+			klient.endpoints = []string{"https://a.example.com", "https://b.example.com"}
+			klient.endpointHealth = NewEndpointHealth(time.Minute)
+
+			var hosts []string
+			klient.doRequestFunc = func(request *http.Request) (*http.Response, error) {
+				hosts = append(hosts, request.URL.Host)
+				if request.URL.Host == "a.example.com" {
+					if tc.err != nil {
+						return nil, tc.err
+					}
+					return &http.Response{StatusCode: tc.status, Body: io.NopCloser(strings.NewReader("{}"))}, nil
+				}
+				status := http.StatusOK
+				if request.Method == http.MethodPost {
+					status = http.StatusCreated
+				}
+				return &http.Response{StatusCode: status, Body: io.NopCloser(strings.NewReader(`{"id":1}`))}, nil
+			}
+
+			err := tc.request(klient)
+			if (err != nil) != tc.wantErr {
+				t.Errorf("want error %t, got %v", tc.wantErr, err)
+			}
+			if diff := cmp.Diff(tc.wantHosts, hosts); diff != "" {
+				t.Errorf("requested hosts: -want, +got:\n%s", diff)
+			}
+		})
+	}
+}
+
+func getBackup(c *client) error {
+	_, err := c.GetBackup(context.Background(), &GetBackupRequest{InstanceID: "instance-id", BackupID: "1"})
+	return err
+}
+
+func createBackup(c *client) error {
+	_, err := c.CreateBackup(context.Background(), &CreateBackupRequest{InstanceID: "instance-id"})
+	return err
+}
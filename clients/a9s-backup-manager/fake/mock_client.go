@@ -17,6 +17,9 @@ limitations under the License.
 package fake
 
 import (
+	"bytes"
+	"context"
+	"io"
 	"sync"
 
 	backupmanager "github.com/anynines/klutchio/clients/a9s-backup-manager"
@@ -51,6 +54,7 @@ func NewFakeClient(config *FakeClientConfiguration) *Client {
 		CreateBackupReaction:       config.CreateBackupReaction,
 		CreateRestoreReaction:      config.CreateRestoreReaction,
 		DeleteBackupReaction:       config.DeleteBackupReaction,
+		DownloadBackupReaction:     config.DownloadBackupReaction,
 		GetBackupReaction:          config.GetBackupReaction,
 		GetBackupsReaction:         config.GetBackupsReaction,
 		GetInstanceConfigReaction:  config.GetInstanceConfigReaction,
@@ -65,6 +69,7 @@ type FakeClientConfiguration struct {
 	CreateBackupReaction       CreateBackupReaction
 	CreateRestoreReaction      CreateRestoreReaction
 	DeleteBackupReaction       DeleteBackupReaction
+	DownloadBackupReaction     DownloadBackupReaction
 	GetBackupReaction          GetBackupReaction
 	GetBackupsReaction         GetBackupsReaction
 	GetInstanceConfigReaction  GetInstanceConfigReaction
@@ -88,6 +93,7 @@ const (
 	CreateBackup       ActionType = "CreateBackup"
 	CreateRestore      ActionType = "CreateRestore"
 	DeleteBackup       ActionType = "DeleteBackup"
+	DownloadBackup     ActionType = "DownloadBackup"
 	GetBackup          ActionType = "GetBackup"
 	GetBackups         ActionType = "GetBackups"
 	GetInstanceConfig  ActionType = "GetInstanceConfig"
@@ -104,6 +110,7 @@ type Client struct {
 	CreateBackupReaction       CreateBackupReaction
 	CreateRestoreReaction      CreateRestoreReaction
 	DeleteBackupReaction       DeleteBackupReaction
+	DownloadBackupReaction     DownloadBackupReaction
 	GetBackupReaction          GetBackupReaction
 	GetBackupsReaction         GetBackupsReaction
 	GetInstanceConfigReaction  GetInstanceConfigReaction
@@ -141,7 +148,7 @@ func (r *CreateBackupReaction) React(req *backupmanager.CreateBackupRequest) (*b
 }
 
 // CreateBackup implements the Client.CreateBackup method for the FakeClient.
-func (c *Client) CreateBackup(r *backupmanager.CreateBackupRequest) (*backupmanager.CreateBackupResponse, error) {
+func (c *Client) CreateBackup(_ context.Context, r *backupmanager.CreateBackupRequest) (*backupmanager.CreateBackupResponse, error) {
 	c.Mutex.Lock()
 	defer c.Mutex.Unlock()
 
@@ -170,7 +177,7 @@ func (r *CreateRestoreReaction) React(req *backupmanager.CreateRestoreRequest) (
 // to be created and returns information about the restore or an error.
 // CreateRestore does a POST on the restore managers endpoint for the
 // requested instance ID and backup ID (/instance/{instance-id}/backups/{backup-id}/restore)
-func (c *Client) CreateRestore(r *backupmanager.CreateRestoreRequest) (*backupmanager.CreateRestoreResponse, error) {
+func (c *Client) CreateRestore(_ context.Context, r *backupmanager.CreateRestoreRequest) (*backupmanager.CreateRestoreResponse, error) {
 	c.Mutex.Lock()
 	defer c.Mutex.Unlock()
 
@@ -199,7 +206,7 @@ func (r *GetBackupReaction) React(req *backupmanager.GetBackupRequest) (*backupm
 // instance from the backup manager or returns an error. GetBackup does a
 // GET on the backup managers endpoint for the requested instance ID and
 // backup ID (/instances/{instance-id}/backups/{backup-id}).
-func (c *Client) GetBackup(r *backupmanager.GetBackupRequest) (*backupmanager.GetBackupResponse, error) {
+func (c *Client) GetBackup(_ context.Context, r *backupmanager.GetBackupRequest) (*backupmanager.GetBackupResponse, error) {
 	c.Mutex.Lock()
 	defer c.Mutex.Unlock()
 
@@ -227,7 +234,7 @@ func (r *GetBackupsReaction) React() (*backupmanager.GetBackupsResponse, error)
 // specific instance from the backup manager or returns an error.
 // GetBackups does a GET on the backup managers endpoint for the requested
 // instance ID (/instances/{instance-id}/backups).
-func (c *Client) GetBackups(r *backupmanager.GetBackupsRequest) (*backupmanager.GetBackupsResponse, error) {
+func (c *Client) GetBackups(_ context.Context, r *backupmanager.GetBackupsRequest) (*backupmanager.GetBackupsResponse, error) {
 	c.Mutex.Lock()
 	defer c.Mutex.Unlock()
 
@@ -255,7 +262,7 @@ func (r *GetInstanceConfigReaction) React() (*backupmanager.GetInstanceConfigRes
 // data service instance from the backup manager or returns an error.
 // GetInstanceConfig does a GET on the backup manager's endpoint for
 // the requested instance ID (/instances/{instance-id}/config).
-func (c *Client) GetInstanceConfig(r *backupmanager.GetInstanceConfigRequest) (*backupmanager.GetInstanceConfigResponse, error) {
+func (c *Client) GetInstanceConfig(_ context.Context, r *backupmanager.GetInstanceConfigRequest) (*backupmanager.GetInstanceConfigResponse, error) {
 	c.Mutex.Lock()
 	defer c.Mutex.Unlock()
 
@@ -284,7 +291,7 @@ func (r *UpdateBackupConfigReaction) React(req *backupmanager.UpdateBackupConfig
 // is updated and returns information about the update or an error.
 // UpdateBackupConfig does a PUT on the backup managers endpoint for the
 // requested instance ID (/instances/{instance-id}).
-func (c *Client) UpdateBackupConfig(r *backupmanager.UpdateBackupConfigRequest) (*backupmanager.UpdateBackupConfigResponse, error) {
+func (c *Client) UpdateBackupConfig(_ context.Context, r *backupmanager.UpdateBackupConfigRequest) (*backupmanager.UpdateBackupConfigResponse, error) {
 	c.Mutex.Lock()
 	defer c.Mutex.Unlock()
 
@@ -313,7 +320,7 @@ func (r *GetRestoreReaction) React() (*backupmanager.GetRestoreResponse, error)
 // error. GetRestore does a GET on the backup managers endpoint for the
 // requested instance ID and restore ID
 // (/instances/{instance-id}/restores/{restore-id}).
-func (c *Client) GetRestore(r *backupmanager.GetRestoreRequest) (*backupmanager.GetRestoreResponse, error) {
+func (c *Client) GetRestore(_ context.Context, r *backupmanager.GetRestoreRequest) (*backupmanager.GetRestoreResponse, error) {
 	c.Mutex.Lock()
 	defer c.Mutex.Unlock()
 
@@ -341,7 +348,7 @@ func (r *GetRestoresReaction) React() (*backupmanager.GetRestoresResponse, error
 // for a specific instance from the backup manager or returns an error.
 // GetRestores does a GET on the backup managers endpoint for the requested
 // instance ID (/instances/{instance-id}/restores).
-func (c *Client) GetRestores(r *backupmanager.GetRestoresRequest) (*backupmanager.GetRestoresResponse, error) {
+func (c *Client) GetRestores(_ context.Context, r *backupmanager.GetRestoresRequest) (*backupmanager.GetRestoresResponse, error) {
 	c.Mutex.Lock()
 	defer c.Mutex.Unlock()
 
@@ -370,10 +377,10 @@ func (r *DeleteBackupReaction) React(req *backupmanager.DeleteBackupRequest) (*b
 // and returns a confirmation of the deletion or an error.
 // This includes the metadata of the backup in the backup manager as
 // well as the actual file containing the backup.
-// DeleteBackup does a POST on the backup managers endpoint for the
+// DeleteBackup does a DELETE on the backup managers endpoint for the
 // requested instance ID and the requested backup id
 // (/instances/{instance-id}/backups/{backup-id})
-func (c *Client) DeleteBackup(r *backupmanager.DeleteBackupRequest) (*backupmanager.DeleteBackupResponse, error) {
+func (c *Client) DeleteBackup(_ context.Context, r *backupmanager.DeleteBackupRequest) (*backupmanager.DeleteBackupResponse, error) {
 	c.Mutex.Lock()
 	defer c.Mutex.Unlock()
 
@@ -386,8 +393,42 @@ func (c *Client) DeleteBackup(r *backupmanager.DeleteBackupRequest) (*backupmana
 	return nil, UnexpectedActionError()
 }
 
+// DownloadBackupReaction defines the reaction to DownloadBackup requests.
+// Response is the content of the backup file.
+type DownloadBackupReaction struct {
+	Request  *backupmanager.GetBackupRequest
+	Response []byte
+	Error    error
+}
+
+func (r *DownloadBackupReaction) React(req *backupmanager.GetBackupRequest) (io.ReadCloser, error) {
+	r.Request = req
+	if r.Error != nil {
+		return nil, r.Error
+	}
+	return io.NopCloser(bytes.NewReader(r.Response)), nil
+}
+
+// DownloadBackup streams the file of a backup of a specific instance from
+// the backup manager or returns an error. DownloadBackup does a GET on the
+// backup managers endpoint for the requested instance ID and backup ID
+// (/instances/{instance-id}/backups/{backup-id}/download).
+func (c *Client) DownloadBackup(_ context.Context, instanceID, backupID string) (io.ReadCloser, error) {
+	c.Mutex.Lock()
+	defer c.Mutex.Unlock()
+
+	r := &backupmanager.GetBackupRequest{InstanceID: instanceID, BackupID: backupID}
+	c.actions = append(c.actions, Action{Type: DownloadBackup, Request: r})
+
+	if c.DownloadBackupReaction.Response != nil || c.DownloadBackupReaction.Error != nil {
+		return c.DownloadBackupReaction.React(r)
+	}
+
+	return nil, UnexpectedActionError()
+}
+
 // CheckAvailability mocks the CheckAvailability method
-func (c *Client) CheckAvailability(string) error {
+func (c *Client) CheckAvailability(context.Context, string) error {
 	return nil
 }
 
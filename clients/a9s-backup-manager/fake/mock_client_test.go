@@ -17,7 +17,10 @@ limitations under the License.
 package fake_test
 
 import (
+	"context"
+	"io"
 	"testing"
+	"time"
 
 	backupmanager "github.com/anynines/klutchio/clients/a9s-backup-manager"
 	"github.com/anynines/klutchio/clients/a9s-backup-manager/fake"
@@ -97,7 +100,7 @@ func TestCreateBackup(t *testing.T) {
 			t.Parallel()
 
 			c := fake.NewFakeClient(tt.args.cfg)
-			got, err := c.CreateBackup(tt.args.r)
+			got, err := c.CreateBackup(context.Background(), tt.args.r)
 			if diff := cmp.Diff(tt.error, err, EquateHTTPSErrors()); diff != "" {
 				t.Errorf("FakeClient.CreateBackup() -want error, +got error:\n%s", diff)
 				return
@@ -179,7 +182,7 @@ func TestCreateRestore(t *testing.T) {
 			t.Parallel()
 
 			c := fake.NewFakeClient(tt.args.cfg)
-			got, err := c.CreateRestore(tt.args.r)
+			got, err := c.CreateRestore(context.Background(), tt.args.r)
 			if diff := cmp.Diff(tt.error, err, EquateHTTPSErrors()); diff != "" {
 				t.Errorf("FakeClient.CreateRestore() -want error, +got error:\n%s", diff)
 				return
@@ -211,8 +214,8 @@ func TestGetBackup(t *testing.T) {
 							BackupID:     pointer.Int(1),
 							Size:         10,
 							Status:       "done",
-							TriggeredAt:  "2023-04-11T08:52:48.209Z",
-							FinishedAt:   "2023-04-11T08:53:16.411Z",
+							TriggeredAt:  time.Date(2023, 4, 11, 8, 52, 48, 209*int(time.Millisecond), time.UTC),
+							FinishedAt:   time.Date(2023, 4, 11, 8, 53, 16, 411*int(time.Millisecond), time.UTC),
 							Downloadable: false,
 						},
 					},
@@ -226,8 +229,8 @@ func TestGetBackup(t *testing.T) {
 				BackupID:     pointer.Int(1),
 				Size:         10,
 				Status:       "done",
-				TriggeredAt:  "2023-04-11T08:52:48.209Z",
-				FinishedAt:   "2023-04-11T08:53:16.411Z",
+				TriggeredAt:  time.Date(2023, 4, 11, 8, 52, 48, 209*int(time.Millisecond), time.UTC),
+				FinishedAt:   time.Date(2023, 4, 11, 8, 53, 16, 411*int(time.Millisecond), time.UTC),
 				Downloadable: false,
 			},
 		},
@@ -274,7 +277,7 @@ func TestGetBackup(t *testing.T) {
 			t.Parallel()
 
 			c := fake.NewFakeClient(tt.args.cfg)
-			got, err := c.GetBackup(tt.args.r)
+			got, err := c.GetBackup(context.Background(), tt.args.r)
 			if diff := cmp.Diff(tt.error, err, EquateHTTPSErrors()); diff != "" {
 				t.Errorf("FakeClient.GetBackup() -want error, +got error:\n%s", diff)
 				return
@@ -308,8 +311,8 @@ func TestGetBackups(t *testing.T) {
 									BackupID:     pointer.Int(1),
 									Size:         10,
 									Status:       "done",
-									TriggeredAt:  "2023-04-11T08:52:48.209Z",
-									FinishedAt:   "2023-04-11T08:53:16.411Z",
+									TriggeredAt:  time.Date(2023, 4, 11, 8, 52, 48, 209*int(time.Millisecond), time.UTC),
+									FinishedAt:   time.Date(2023, 4, 11, 8, 53, 16, 411*int(time.Millisecond), time.UTC),
 									Downloadable: false,
 								},
 							},
@@ -326,8 +329,8 @@ func TestGetBackups(t *testing.T) {
 						BackupID:     pointer.Int(1),
 						Size:         10,
 						Status:       "done",
-						TriggeredAt:  "2023-04-11T08:52:48.209Z",
-						FinishedAt:   "2023-04-11T08:53:16.411Z",
+						TriggeredAt:  time.Date(2023, 4, 11, 8, 52, 48, 209*int(time.Millisecond), time.UTC),
+						FinishedAt:   time.Date(2023, 4, 11, 8, 53, 16, 411*int(time.Millisecond), time.UTC),
 						Downloadable: false,
 					},
 				},
@@ -374,7 +377,7 @@ func TestGetBackups(t *testing.T) {
 			t.Parallel()
 
 			c := fake.NewFakeClient(tt.args.cfg)
-			got, err := c.GetBackups(tt.args.r)
+			got, err := c.GetBackups(context.Background(), tt.args.r)
 			if diff := cmp.Diff(tt.error, err, EquateHTTPSErrors()); diff != "" {
 				t.Errorf("FakeClient.GetBackups() -want error, +got error:\n%s", diff)
 				return
@@ -464,7 +467,7 @@ func TestGetInstanceConfig(t *testing.T) {
 			t.Parallel()
 
 			c := fake.NewFakeClient(tt.args.cfg)
-			got, err := c.GetInstanceConfig(tt.args.r)
+			got, err := c.GetInstanceConfig(context.Background(), tt.args.r)
 			if diff := cmp.Diff(tt.error, err, EquateHTTPSErrors()); diff != "" {
 				t.Errorf("FakeClient.GetInstanceConfig() -want error, +got error:\n%s", diff)
 				return
@@ -549,7 +552,7 @@ func TestUpdateBackupConfig(t *testing.T) {
 			t.Parallel()
 
 			c := fake.NewFakeClient(tt.args.cfg)
-			got, err := c.UpdateBackupConfig(tt.args.r)
+			got, err := c.UpdateBackupConfig(context.Background(), tt.args.r)
 			if diff := cmp.Diff(tt.error, err, EquateHTTPSErrors()); diff != "" {
 				t.Errorf("FakeClient.UpdateBackupConfig() -want error, +got error:\n%s", diff)
 				return
@@ -581,8 +584,8 @@ func TestGetRestore(t *testing.T) {
 							RestoreID:   pointer.Int(1),
 							BackupID:    pointer.Int(1),
 							Status:      "done",
-							TriggeredAt: "2023-04-11T08:52:48.209Z",
-							FinishedAt:  "2023-04-11T08:53:16.411Z",
+							TriggeredAt: time.Date(2023, 4, 11, 8, 52, 48, 209*int(time.Millisecond), time.UTC),
+							FinishedAt:  time.Date(2023, 4, 11, 8, 53, 16, 411*int(time.Millisecond), time.UTC),
 						},
 					},
 				},
@@ -595,8 +598,8 @@ func TestGetRestore(t *testing.T) {
 				RestoreID:   pointer.Int(1),
 				BackupID:    pointer.Int(1),
 				Status:      "done",
-				TriggeredAt: "2023-04-11T08:52:48.209Z",
-				FinishedAt:  "2023-04-11T08:53:16.411Z",
+				TriggeredAt: time.Date(2023, 4, 11, 8, 52, 48, 209*int(time.Millisecond), time.UTC),
+				FinishedAt:  time.Date(2023, 4, 11, 8, 53, 16, 411*int(time.Millisecond), time.UTC),
 			},
 		},
 		{
@@ -642,7 +645,7 @@ func TestGetRestore(t *testing.T) {
 			t.Parallel()
 
 			c := fake.NewFakeClient(tt.args.cfg)
-			got, err := c.GetRestore(tt.args.r)
+			got, err := c.GetRestore(context.Background(), tt.args.r)
 			if diff := cmp.Diff(tt.error, err, EquateHTTPSErrors()); diff != "" {
 				t.Errorf("FakeClient.GetRestore() -want error, +got error:\n%s", diff)
 				return
@@ -676,8 +679,8 @@ func TestGetRestores(t *testing.T) {
 									RestoreID:   pointer.Int(1),
 									BackupID:    pointer.Int(1),
 									Status:      "done",
-									TriggeredAt: "2023-04-11T08:52:48.209Z",
-									FinishedAt:  "2023-04-11T08:53:16.411Z",
+									TriggeredAt: time.Date(2023, 4, 11, 8, 52, 48, 209*int(time.Millisecond), time.UTC),
+									FinishedAt:  time.Date(2023, 4, 11, 8, 53, 16, 411*int(time.Millisecond), time.UTC),
 								},
 							},
 						},
@@ -693,8 +696,8 @@ func TestGetRestores(t *testing.T) {
 						RestoreID:   pointer.Int(1),
 						BackupID:    pointer.Int(1),
 						Status:      "done",
-						TriggeredAt: "2023-04-11T08:52:48.209Z",
-						FinishedAt:  "2023-04-11T08:53:16.411Z",
+						TriggeredAt: time.Date(2023, 4, 11, 8, 52, 48, 209*int(time.Millisecond), time.UTC),
+						FinishedAt:  time.Date(2023, 4, 11, 8, 53, 16, 411*int(time.Millisecond), time.UTC),
 					},
 				},
 			},
@@ -739,7 +742,7 @@ func TestGetRestores(t *testing.T) {
 			t.Parallel()
 
 			c := fake.NewFakeClient(tt.args.cfg)
-			got, err := c.GetRestores(tt.args.r)
+			got, err := c.GetRestores(context.Background(), tt.args.r)
 			if diff := cmp.Diff(tt.error, err, EquateHTTPSErrors()); diff != "" {
 				t.Errorf("FakeClient.GetRestores() -want error, +got error:\n%s", diff)
 				return
@@ -824,7 +827,7 @@ func TestDeleteBackup(t *testing.T) {
 			t.Parallel()
 
 			c := fake.NewFakeClient(tt.args.cfg)
-			got, err := c.DeleteBackup(tt.args.r)
+			got, err := c.DeleteBackup(context.Background(), tt.args.r)
 			if diff := cmp.Diff(tt.error, err, EquateHTTPSErrors()); diff != "" {
 				t.Errorf("FakeClient.DeleteBackup() -want error, +got error:\n%s", diff)
 				return
@@ -836,6 +839,71 @@ func TestDeleteBackup(t *testing.T) {
 	}
 }
 
+func TestDownloadBackup(t *testing.T) {
+	tests := []struct {
+		name  string
+		cfg   *fake.FakeClientConfiguration
+		want  []byte
+		error error
+	}{
+		{
+			name: "successBackupDownloaded",
+			cfg: &fake.FakeClientConfiguration{
+				DownloadBackupReaction: fake.DownloadBackupReaction{
+					Response: []byte("backup"),
+				},
+			},
+			want: []byte("backup"),
+		},
+		{
+			name: "errorBackupNotFound",
+			cfg: &fake.FakeClientConfiguration{
+				DownloadBackupReaction: fake.DownloadBackupReaction{
+					Error: backupmanager.HTTPStatusCodeError{
+						StatusCode:   404,
+						ErrorMessage: pointer.String("NotFound"),
+					},
+				},
+			},
+			error: backupmanager.HTTPStatusCodeError{
+				StatusCode:   404,
+				ErrorMessage: pointer.String("NotFound"),
+			},
+		},
+		{
+			name:  "errorUnexpectedAction",
+			error: fake.UnexpectedActionError(),
+		},
+	}
+	for _, tt := range tests {
+
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+
+			t.Parallel()
+
+			c := fake.NewFakeClient(tt.cfg)
+			reader, err := c.DownloadBackup(context.Background(), "0b0001f9-38a2-4248-9ef8-5cbf27d78e8b", "1")
+			if diff := cmp.Diff(tt.error, err, EquateHTTPSErrors()); diff != "" {
+				t.Errorf("FakeClient.DownloadBackup() -want error, +got error:\n%s", diff)
+				return
+			}
+			if err != nil {
+				return
+			}
+			defer reader.Close()
+			got, err := io.ReadAll(reader)
+			if err != nil {
+				t.Fatalf("FakeClient.DownloadBackup(): unexpected read error: %v", err)
+			}
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("FakeClient.DownloadBackup() -want, +got:\n%s", diff)
+			}
+		})
+	}
+}
+
 func TestActions(t *testing.T) {
 
 	cfg := &fake.FakeClientConfiguration{}
@@ -905,35 +973,35 @@ func TestActions(t *testing.T) {
 
 	c := fake.NewFakeClient(cfg)
 
-	c.CreateBackup(&backupmanager.CreateBackupRequest{
+	c.CreateBackup(context.Background(), &backupmanager.CreateBackupRequest{
 		InstanceID: "0b0001f9-38a2-4248-9ef8-5cbf27d78e8b",
 	})
-	c.CreateRestore(&backupmanager.CreateRestoreRequest{
+	c.CreateRestore(context.Background(), &backupmanager.CreateRestoreRequest{
 		InstanceID: "0b0001f9-38a2-4248-9ef8-5cbf27d78e8b",
 		BackupID:   "761e6d41-49c2-4f80-805f-7a03de9fe798",
 	})
-	c.DeleteBackup(&backupmanager.DeleteBackupRequest{
+	c.DeleteBackup(context.Background(), &backupmanager.DeleteBackupRequest{
 		InstanceID: "0b0001f9-38a2-4248-9ef8-5cbf27d78e8b",
 		BackupID:   pointer.Int(1),
 	})
-	c.GetBackup(&backupmanager.GetBackupRequest{
+	c.GetBackup(context.Background(), &backupmanager.GetBackupRequest{
 		InstanceID: "0b0001f9-38a2-4248-9ef8-5cbf27d78e8b",
 		BackupID:   "761e6d41-49c2-4f80-805f-7a03de9fe798",
 	})
-	c.GetBackups(&backupmanager.GetBackupsRequest{
+	c.GetBackups(context.Background(), &backupmanager.GetBackupsRequest{
 		InstanceID: "0b0001f9-38a2-4248-9ef8-5cbf27d78e8b",
 	})
-	c.GetInstanceConfig(&backupmanager.GetInstanceConfigRequest{
+	c.GetInstanceConfig(context.Background(), &backupmanager.GetInstanceConfigRequest{
 		InstanceID: "0b0001f9-38a2-4248-9ef8-5cbf27d78e8b",
 	})
-	c.GetRestore(&backupmanager.GetRestoreRequest{
+	c.GetRestore(context.Background(), &backupmanager.GetRestoreRequest{
 		InstanceID: "0b0001f9-38a2-4248-9ef8-5cbf27d78e8b",
 		RestoreID:  "21284603-daf0-4ca4-8f2c-cad385ac1740",
 	})
-	c.GetRestores(&backupmanager.GetRestoresRequest{
+	c.GetRestores(context.Background(), &backupmanager.GetRestoresRequest{
 		InstanceID: "0b0001f9-38a2-4248-9ef8-5cbf27d78e8b",
 	})
-	c.UpdateBackupConfig(&backupmanager.UpdateBackupConfigRequest{
+	c.UpdateBackupConfig(context.Background(), &backupmanager.UpdateBackupConfigRequest{
 		InstanceID:               "0b0001f9-38a2-4248-9ef8-5cbf27d78e8b",
 		EncryptionKey:            pointer.String("test"),
 		ExcludeFromAutoBackup:    pointer.Bool(true),
@@ -17,19 +17,23 @@ limitations under the License.
 package backupmanager
 
 import (
+	"context"
 	"fmt"
 	"net/http"
 	"strconv"
 )
 
-func (c *client) GetBackup(r *GetBackupRequest) (*GetBackupResponse, error) {
+func (c *client) GetBackup(ctx context.Context, r *GetBackupRequest) (*GetBackupResponse, error) {
 	if err := validateGetBackupRequest(r); err != nil {
 		return nil, err
 	}
 
 	fullURL := fmt.Sprintf(backupURLFmt, c.URL, r.InstanceID, r.BackupID)
 
-	response, err := c.prepareAndDo(http.MethodGet, fullURL, nil, nil)
+	ctx, span := c.startSpan(ctx, operationGetBackup, r, attributeInstanceID.String(r.InstanceID), attributeBackupID.String(r.BackupID))
+	defer span.End()
+
+	response, err := c.prepareAndDo(ctx, http.MethodGet, fullURL, nil, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -17,9 +17,11 @@ limitations under the License.
 package backupmanager
 
 import (
+	"context"
 	"fmt"
 	"net/http"
 	"testing"
+	"time"
 
 	"k8s.io/utils/pointer"
 )
@@ -40,6 +42,21 @@ const successGetBackupRequestResponseBody = `{
 	"downloadable": false
   }`
 
+const runningGetBackupRequestResponseBody = `{
+	"id": 6,
+	"size": 0,
+	"status": "running",
+	"triggered_at": "2023-04-11T08:52:48.209Z",
+	"finished_at": null,
+	"downloadable": false
+  }`
+
+const malformedTimestampGetBackupRequestResponseBody = `{
+	"id": 6,
+	"status": "running",
+	"triggered_at": "yesterday"
+  }`
+
 const instanceNotFoundGetBackupResponseBody = `{
 	"error": "NotFound",
 	"description": "The instance test-instance was not found."
@@ -86,11 +103,31 @@ func TestGetBackup(t *testing.T) {
 				BackupID:     pointer.Int(5),
 				Size:         1184,
 				Status:       "done",
-				TriggeredAt:  "2023-04-11T08:52:48.209Z",
-				FinishedAt:   "2023-04-11T08:53:16.411Z",
+				TriggeredAt:  time.Date(2023, 4, 11, 8, 52, 48, 209*int(time.Millisecond), time.UTC),
+				FinishedAt:   time.Date(2023, 4, 11, 8, 53, 16, 411*int(time.Millisecond), time.UTC),
 				Downloadable: false,
 			},
 		},
+		{
+			name: "success while running",
+			httpReaction: httpReaction{
+				status: http.StatusOK,
+				body:   runningGetBackupRequestResponseBody,
+			},
+			expectedResponse: &GetBackupResponse{
+				BackupID:    pointer.Int(6),
+				Status:      BackupStatusRunning,
+				TriggeredAt: time.Date(2023, 4, 11, 8, 52, 48, 209*int(time.Millisecond), time.UTC),
+			},
+		},
+		{
+			name: "200 with malformed timestamp",
+			httpReaction: httpReaction{
+				status: http.StatusOK,
+				body:   malformedTimestampGetBackupRequestResponseBody,
+			},
+			expectedErrMessage: `Status: 200; ErrorMessage: <nil>; Description: <nil>; ResponseError: cannot parse triggered_at: parsing time "yesterday" as "2006-01-02T15:04:05.999999999Z07:00": cannot parse "yesterday" as "2006"`,
+		},
 		{
 			name: "instance not found",
 			request: &GetBackupRequest{
@@ -159,7 +196,7 @@ func TestGetBackup(t *testing.T) {
 
 		klient := newTestClient(t, tc.name, tc.httpChecks, tc.httpReaction)
 
-		response, err := klient.GetBackup(tc.request)
+		response, err := klient.GetBackup(context.Background(), tc.request)
 		doResponseChecks(t, tc.name, response, err, tc.expectedResponse, tc.expectedErrMessage, tc.expectedErr)
 	}
 }
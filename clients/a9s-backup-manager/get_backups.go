@@ -17,18 +17,23 @@ limitations under the License.
 package backupmanager
 
 import (
+	"context"
 	"fmt"
 	"net/http"
 )
 
-func (c *client) GetBackups(r *GetBackupsRequest) (*GetBackupsResponse, error) {
+func (c *client) GetBackups(ctx context.Context, r *GetBackupsRequest) (*GetBackupsResponse, error) {
 	if err := validateGetBackupsRequest(r); err != nil {
 		return nil, err
 	}
 
 	fullURL := fmt.Sprintf(instanceBackupURLFmt, c.URL, r.InstanceID)
 
-	response, err := c.prepareAndDo(http.MethodGet, fullURL, nil, nil)
+	ctx, span := c.startSpan(ctx, operationGetBackups, r, attributeInstanceID.String(r.InstanceID))
+	defer span.End()
+
+	options := r.listOptions()
+	response, err := c.prepareAndDo(ctx, http.MethodGet, fullURL, options.params(), nil)
 	if err != nil {
 		return nil, err
 	}
@@ -45,7 +50,12 @@ func (c *client) GetBackups(r *GetBackupsRequest) (*GetBackupsResponse, error) {
 			return nil, HTTPStatusCodeError{StatusCode: response.StatusCode, ResponseError: err}
 		}
 
-		return &GetBackupsResponse{Backups: *userResponse}, nil
+		page, next, err := applyListOptions(*userResponse, backupListEntry, options, response.Header)
+		if err != nil {
+			return nil, err
+		}
+
+		return &GetBackupsResponse{Backups: page, Continue: next}, nil
 	default:
 		return nil, c.handleFailureResponse(response)
 	}
@@ -56,5 +66,5 @@ func validateGetBackupsRequest(request *GetBackupsRequest) error {
 		return required("instanceID")
 	}
 
-	return nil
+	return request.listOptions().validate()
 }
@@ -17,10 +17,14 @@ limitations under the License.
 package backupmanager
 
 import (
+	"context"
+	"encoding/json"
 	"fmt"
 	"net/http"
 	"testing"
+	"time"
 
+	"github.com/google/go-cmp/cmp"
 	"k8s.io/utils/pointer"
 )
 
@@ -83,21 +87,212 @@ func TestGetBackups(t *testing.T) {
 						BackupID:     pointer.Int(1),
 						Size:         1184,
 						Status:       "done",
-						TriggeredAt:  "2023-04-11T08:52:48.209Z",
-						FinishedAt:   "2023-04-11T08:53:16.411Z",
+						TriggeredAt:  time.Date(2023, 4, 11, 8, 52, 48, 209*int(time.Millisecond), time.UTC),
+						FinishedAt:   time.Date(2023, 4, 11, 8, 53, 16, 411*int(time.Millisecond), time.UTC),
 						Downloadable: false,
 					},
 					{
 						BackupID:     pointer.Int(2),
 						Size:         1234,
 						Status:       "failed",
-						TriggeredAt:  "2023-04-12T08:52:51.209Z",
-						FinishedAt:   "2023-04-12T08:53:53.411Z",
+						TriggeredAt:  time.Date(2023, 4, 12, 8, 52, 51, 209*int(time.Millisecond), time.UTC),
+						FinishedAt:   time.Date(2023, 4, 12, 8, 53, 53, 411*int(time.Millisecond), time.UTC),
 						Downloadable: true,
 					},
 				},
 			},
 		},
+		{
+			name: "list options applied by the backup manager",
+			request: &GetBackupsRequest{
+				InstanceID:     "test-instance-id",
+				Status:         []BackupStatus{BackupStatusDone, BackupStatusFailed},
+				TriggeredAfter: time.Date(2023, 4, 1, 0, 0, 0, 0, time.FixedZone("CEST", 2*60*60)),
+				Limit:          2,
+				Continue:       "opaque",
+			},
+			httpChecks: httpChecks{
+				params: map[string]string{
+					"status":          "done,failed",
+					"triggered_after": "2023-03-31T22:00:00Z",
+					"limit":           "2",
+					"continue":        "opaque",
+				},
+			},
+			httpReaction: httpReaction{
+				status: http.StatusOK,
+				body:   successGetBackupsRequestResponseBody,
+				header: http.Header{
+					"X-List-Options": {"status, triggered_after, limit"},
+					"X-Continue":     {"next"},
+				},
+			},
+			expectedResponse: &GetBackupsResponse{
+				Backups: []GetBackupResponse{
+					{
+						BackupID:     pointer.Int(1),
+						Size:         1184,
+						Status:       "done",
+						TriggeredAt:  time.Date(2023, 4, 11, 8, 52, 48, 209*int(time.Millisecond), time.UTC),
+						FinishedAt:   time.Date(2023, 4, 11, 8, 53, 16, 411*int(time.Millisecond), time.UTC),
+						Downloadable: false,
+					},
+					{
+						BackupID:     pointer.Int(2),
+						Size:         1234,
+						Status:       "failed",
+						TriggeredAt:  time.Date(2023, 4, 12, 8, 52, 51, 209*int(time.Millisecond), time.UTC),
+						FinishedAt:   time.Date(2023, 4, 12, 8, 53, 53, 411*int(time.Millisecond), time.UTC),
+						Downloadable: true,
+					},
+				},
+				Continue: "next",
+			},
+		},
+		{
+			name: "status filtered by the client",
+			request: &GetBackupsRequest{
+				InstanceID: "test-instance-id",
+				Status:     []BackupStatus{BackupStatusFailed},
+			},
+			httpReaction: httpReaction{
+				status: http.StatusOK,
+				body:   successGetBackupsRequestResponseBody,
+			},
+			expectedResponse: &GetBackupsResponse{
+				Backups: []GetBackupResponse{
+					{
+						BackupID:     pointer.Int(2),
+						Size:         1234,
+						Status:       "failed",
+						TriggeredAt:  time.Date(2023, 4, 12, 8, 52, 51, 209*int(time.Millisecond), time.UTC),
+						FinishedAt:   time.Date(2023, 4, 12, 8, 53, 53, 411*int(time.Millisecond), time.UTC),
+						Downloadable: true,
+					},
+				},
+			},
+		},
+		{
+			name: "triggered before filtered by the client",
+			request: &GetBackupsRequest{
+				InstanceID:      "test-instance-id",
+				TriggeredBefore: time.Date(2023, 4, 12, 8, 52, 51, 209*int(time.Millisecond), time.UTC),
+			},
+			httpReaction: httpReaction{
+				status: http.StatusOK,
+				body:   successGetBackupsRequestResponseBody,
+				header: http.Header{"X-List-Options": {"status"}},
+			},
+			expectedResponse: &GetBackupsResponse{
+				Backups: []GetBackupResponse{
+					{
+						BackupID:     pointer.Int(1),
+						Size:         1184,
+						Status:       "done",
+						TriggeredAt:  time.Date(2023, 4, 11, 8, 52, 48, 209*int(time.Millisecond), time.UTC),
+						FinishedAt:   time.Date(2023, 4, 11, 8, 53, 16, 411*int(time.Millisecond), time.UTC),
+						Downloadable: false,
+					},
+				},
+			},
+		},
+		{
+			name: "first page paginated by the client",
+			request: &GetBackupsRequest{
+				InstanceID: "test-instance-id",
+				Limit:      1,
+			},
+			httpReaction: httpReaction{
+				status: http.StatusOK,
+				body:   successGetBackupsRequestResponseBody,
+			},
+			expectedResponse: &GetBackupsResponse{
+				Backups: []GetBackupResponse{
+					{
+						BackupID:     pointer.Int(1),
+						Size:         1184,
+						Status:       "done",
+						TriggeredAt:  time.Date(2023, 4, 11, 8, 52, 48, 209*int(time.Millisecond), time.UTC),
+						FinishedAt:   time.Date(2023, 4, 11, 8, 53, 16, 411*int(time.Millisecond), time.UTC),
+						Downloadable: false,
+					},
+				},
+				Continue: "1",
+			},
+		},
+		{
+			name: "last page paginated by the client",
+			request: &GetBackupsRequest{
+				InstanceID: "test-instance-id",
+				Limit:      1,
+				Continue:   "1",
+			},
+			httpReaction: httpReaction{
+				status: http.StatusOK,
+				body:   successGetBackupsRequestResponseBody,
+			},
+			expectedResponse: &GetBackupsResponse{
+				Backups: []GetBackupResponse{
+					{
+						BackupID:     pointer.Int(2),
+						Size:         1234,
+						Status:       "failed",
+						TriggeredAt:  time.Date(2023, 4, 12, 8, 52, 51, 209*int(time.Millisecond), time.UTC),
+						FinishedAt:   time.Date(2023, 4, 12, 8, 53, 53, 411*int(time.Millisecond), time.UTC),
+						Downloadable: true,
+					},
+				},
+			},
+		},
+		{
+			name: "continue token after the last backup",
+			request: &GetBackupsRequest{
+				InstanceID: "test-instance-id",
+				Continue:   "3",
+			},
+			httpReaction: httpReaction{
+				status: http.StatusOK,
+				body:   successGetBackupsRequestResponseBody,
+			},
+			expectedResponse: &GetBackupsResponse{Backups: []GetBackupResponse{}},
+		},
+		{
+			name: "continue token of a deleted backup",
+			request: &GetBackupsRequest{
+				InstanceID: "test-instance-id",
+				Limit:      1,
+				Continue:   "0",
+			},
+			httpReaction: httpReaction{
+				status: http.StatusOK,
+				body:   successGetBackupsRequestResponseBody,
+			},
+			expectedResponse: &GetBackupsResponse{
+				Backups: []GetBackupResponse{
+					{
+						BackupID:     pointer.Int(1),
+						Size:         1184,
+						Status:       "done",
+						TriggeredAt:  time.Date(2023, 4, 11, 8, 52, 48, 209*int(time.Millisecond), time.UTC),
+						FinishedAt:   time.Date(2023, 4, 11, 8, 53, 16, 411*int(time.Millisecond), time.UTC),
+						Downloadable: false,
+					},
+				},
+				Continue: "1",
+			},
+		},
+		{
+			name: "malformed continue token",
+			request: &GetBackupsRequest{
+				InstanceID: "test-instance-id",
+				Continue:   "opaque",
+			},
+			httpReaction: httpReaction{
+				status: http.StatusOK,
+				body:   successGetBackupsRequestResponseBody,
+			},
+			expectedErrMessage: `continue token "opaque" is malformed`,
+		},
 		{
 			name: "instance not found",
 			request: &GetBackupsRequest{
@@ -164,12 +359,50 @@ func TestGetBackups(t *testing.T) {
 
 		klient := newTestClient(t, tc.name, tc.httpChecks, tc.httpReaction)
 
-		response, err := klient.GetBackups(tc.request)
+		response, err := klient.GetBackups(context.Background(), tc.request)
 
 		doResponseChecks(t, tc.name, response, err, tc.expectedResponse, tc.expectedErrMessage, tc.expectedErr)
 	}
 }
 
+func TestBackupsDeletedBetweenPages(t *testing.T) {
+	// The backup manager lists the backups newest first and does not apply
+	// list options, so that the client pages through them by ID.
+	backups := []int{3, 2, 1}
+	klient := &client{
+		Name: "test client",
+		URL:  "https://example.com",
+		doRequestFunc: func(request *http.Request) (*http.Response, error) {
+			body := []map[string]interface{}{}
+			for _, id := range backups {
+				body = append(body, map[string]interface{}{"id": id, "status": "done"})
+			}
+			encoded, err := json.Marshal(body)
+			if err != nil {
+				return nil, err
+			}
+			return &http.Response{StatusCode: http.StatusOK, Body: closer(string(encoded))}, nil
+		},
+	}
+
+	var got []int
+	for backup, err := range Backups(context.Background(), klient, GetBackupsRequest{InstanceID: "test-instance-id", Limit: 1}) {
+		if err != nil {
+			t.Fatalf("Backups: unexpected error: %v", err)
+		}
+		got = append(got, *backup.BackupID)
+		if *backup.BackupID == 1 {
+			// Backup 1 is deleted after the first page, whose continue
+			// token is its ID.
+			backups = []int{3, 2}
+		}
+	}
+
+	if diff := cmp.Diff([]int{1, 2, 3}, got); diff != "" {
+		t.Errorf("Backups: -want IDs, +got IDs:\n%s", diff)
+	}
+}
+
 func TestValidateGetBackups(t *testing.T) {
 	cases := []struct {
 		name    string
@@ -190,6 +423,15 @@ func TestValidateGetBackups(t *testing.T) {
 			}(),
 			valid: false,
 		},
+		{
+			name: "negative limit",
+			request: func() *GetBackupsRequest {
+				r := defaultGetBackupsRequest()
+				r.Limit = -1
+				return r
+			}(),
+			valid: false,
+		},
 	}
 
 	for _, tc := range cases {
@@ -17,18 +17,22 @@ limitations under the License.
 package backupmanager
 
 import (
+	"context"
 	"fmt"
 	"net/http"
 )
 
-func (c *client) GetInstanceConfig(r *GetInstanceConfigRequest) (*GetInstanceConfigResponse, error) {
+func (c *client) GetInstanceConfig(ctx context.Context, r *GetInstanceConfigRequest) (*GetInstanceConfigResponse, error) {
 	if err := validateGetInstanceConfigRequest(r); err != nil {
 		return nil, err
 	}
 
 	fullURL := fmt.Sprintf(instanceConfigURLFmt, c.URL, r.InstanceID)
 
-	response, err := c.prepareAndDo(http.MethodGet, fullURL, nil, nil)
+	ctx, span := c.startSpan(ctx, operationGetInstanceConfig, r, attributeInstanceID.String(r.InstanceID))
+	defer span.End()
+
+	response, err := c.prepareAndDo(ctx, http.MethodGet, fullURL, nil, nil)
 	if err != nil {
 		return nil, err
 	}
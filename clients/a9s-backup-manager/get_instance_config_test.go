@@ -17,6 +17,7 @@ limitations under the License.
 package backupmanager
 
 import (
+	"context"
 	"fmt"
 	"net/http"
 	"testing"
@@ -129,7 +130,7 @@ func TestGetInstanceConfig(t *testing.T) {
 
 		klient := newTestClient(t, tc.name, tc.httpChecks, tc.httpReaction)
 
-		response, err := klient.GetInstanceConfig(tc.request)
+		response, err := klient.GetInstanceConfig(context.Background(), tc.request)
 
 		doResponseChecks(t, tc.name, response, err, tc.expectedResponse, tc.expectedErrMessage, tc.expectedErr)
 	}
@@ -17,19 +17,23 @@ limitations under the License.
 package backupmanager
 
 import (
+	"context"
 	"fmt"
 	"net/http"
 	"strconv"
 )
 
-func (c *client) GetRestore(r *GetRestoreRequest) (*GetRestoreResponse, error) {
+func (c *client) GetRestore(ctx context.Context, r *GetRestoreRequest) (*GetRestoreResponse, error) {
 	if err := validateGetRestoreRequest(r); err != nil {
 		return nil, err
 	}
 
 	fullURL := fmt.Sprintf(restoreURLFmt, c.URL, r.InstanceID, r.RestoreID)
 
-	response, err := c.prepareAndDo(http.MethodGet, fullURL, nil, nil)
+	ctx, span := c.startSpan(ctx, operationGetRestore, r, attributeInstanceID.String(r.InstanceID), attributeRestoreID.String(r.RestoreID))
+	defer span.End()
+
+	response, err := c.prepareAndDo(ctx, http.MethodGet, fullURL, nil, nil)
 	if err != nil {
 		return nil, err
 	}
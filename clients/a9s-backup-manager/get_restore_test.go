@@ -17,9 +17,11 @@ limitations under the License.
 package backupmanager
 
 import (
+	"context"
 	"fmt"
 	"net/http"
 	"testing"
+	"time"
 
 	"k8s.io/utils/pointer"
 )
@@ -85,8 +87,8 @@ func TestGetRestore(t *testing.T) {
 				RestoreID:   pointer.Int(1),
 				BackupID:    pointer.Int(10),
 				Status:      "done",
-				TriggeredAt: "2023-04-11T08:52:48.209Z",
-				FinishedAt:  "2023-04-11T08:53:16.411Z",
+				TriggeredAt: time.Date(2023, 4, 11, 8, 52, 48, 209*int(time.Millisecond), time.UTC),
+				FinishedAt:  time.Date(2023, 4, 11, 8, 53, 16, 411*int(time.Millisecond), time.UTC),
 			},
 		},
 		{
@@ -157,7 +159,7 @@ func TestGetRestore(t *testing.T) {
 
 		klient := newTestClient(t, tc.name, tc.httpChecks, tc.httpReaction)
 
-		response, err := klient.GetRestore(tc.request)
+		response, err := klient.GetRestore(context.Background(), tc.request)
 		doResponseChecks(t, tc.name, response, err, tc.expectedResponse, tc.expectedErrMessage, tc.expectedErr)
 	}
 }
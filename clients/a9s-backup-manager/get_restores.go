@@ -17,18 +17,23 @@ limitations under the License.
 package backupmanager
 
 import (
+	"context"
 	"fmt"
 	"net/http"
 )
 
-func (c *client) GetRestores(r *GetRestoresRequest) (*GetRestoresResponse, error) {
+func (c *client) GetRestores(ctx context.Context, r *GetRestoresRequest) (*GetRestoresResponse, error) {
 	if err := validateGetRestoresRequest(r); err != nil {
 		return nil, err
 	}
 
 	fullURL := fmt.Sprintf(instanceRestoreURLFmt, c.URL, r.InstanceID)
 
-	response, err := c.prepareAndDo(http.MethodGet, fullURL, nil, nil)
+	ctx, span := c.startSpan(ctx, operationGetRestores, r, attributeInstanceID.String(r.InstanceID))
+	defer span.End()
+
+	options := r.listOptions()
+	response, err := c.prepareAndDo(ctx, http.MethodGet, fullURL, options.params(), nil)
 	if err != nil {
 		return nil, err
 	}
@@ -45,7 +50,12 @@ func (c *client) GetRestores(r *GetRestoresRequest) (*GetRestoresResponse, error
 			return nil, HTTPStatusCodeError{StatusCode: response.StatusCode, ResponseError: err}
 		}
 
-		return &GetRestoresResponse{Restores: *userResponse}, nil
+		page, next, err := applyListOptions(*userResponse, restoreListEntry, options, response.Header)
+		if err != nil {
+			return nil, err
+		}
+
+		return &GetRestoresResponse{Restores: page, Continue: next}, nil
 	default:
 		return nil, c.handleFailureResponse(response)
 	}
@@ -56,5 +66,5 @@ func validateGetRestoresRequest(request *GetRestoresRequest) error {
 		return required("instanceID")
 	}
 
-	return nil
+	return request.listOptions().validate()
 }
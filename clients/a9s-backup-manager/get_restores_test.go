@@ -17,9 +17,11 @@ limitations under the License.
 package backupmanager
 
 import (
+	"context"
 	"fmt"
 	"net/http"
 	"testing"
+	"time"
 
 	"k8s.io/utils/pointer"
 )
@@ -86,19 +88,87 @@ func TestGetRestores(t *testing.T) {
 						RestoreID:   pointer.Int(1),
 						BackupID:    pointer.Int(1),
 						Status:      "done",
-						TriggeredAt: "2023-04-11T08:52:48.209Z",
-						FinishedAt:  "2023-04-11T08:53:16.411Z",
+						TriggeredAt: time.Date(2023, 4, 11, 8, 52, 48, 209*int(time.Millisecond), time.UTC),
+						FinishedAt:  time.Date(2023, 4, 11, 8, 53, 16, 411*int(time.Millisecond), time.UTC),
 					},
 					{
 						RestoreID:   pointer.Int(2),
 						BackupID:    pointer.Int(3),
 						Status:      "failed",
-						TriggeredAt: "2023-04-12T08:52:51.209Z",
-						FinishedAt:  "2023-04-12T08:53:53.411Z",
+						TriggeredAt: time.Date(2023, 4, 12, 8, 52, 51, 209*int(time.Millisecond), time.UTC),
+						FinishedAt:  time.Date(2023, 4, 12, 8, 53, 53, 411*int(time.Millisecond), time.UTC),
 					},
 				},
 			},
 		},
+		{
+			name: "list options applied by the backup manager",
+			request: &GetRestoresRequest{
+				InstanceID:      "test-instance-id",
+				Status:          []RestoreStatus{RestoreStatusDone, RestoreStatusFailed},
+				TriggeredBefore: time.Date(2023, 5, 1, 0, 0, 0, 0, time.UTC),
+				Limit:           2,
+			},
+			httpChecks: httpChecks{
+				params: map[string]string{
+					"status":           "done,failed",
+					"triggered_before": "2023-05-01T00:00:00Z",
+					"limit":            "2",
+				},
+			},
+			httpReaction: httpReaction{
+				status: http.StatusOK,
+				body:   successGetRestoresRequestResponseBody,
+				header: http.Header{
+					"X-List-Options": {"status,triggered_before,limit"},
+					"X-Continue":     {"next"},
+				},
+			},
+			expectedResponse: &GetRestoresResponse{
+				Restores: []GetRestoreResponse{
+					{
+						RestoreID:   pointer.Int(1),
+						BackupID:    pointer.Int(1),
+						Status:      "done",
+						TriggeredAt: time.Date(2023, 4, 11, 8, 52, 48, 209*int(time.Millisecond), time.UTC),
+						FinishedAt:  time.Date(2023, 4, 11, 8, 53, 16, 411*int(time.Millisecond), time.UTC),
+					},
+					{
+						RestoreID:   pointer.Int(2),
+						BackupID:    pointer.Int(3),
+						Status:      "failed",
+						TriggeredAt: time.Date(2023, 4, 12, 8, 52, 51, 209*int(time.Millisecond), time.UTC),
+						FinishedAt:  time.Date(2023, 4, 12, 8, 53, 53, 411*int(time.Millisecond), time.UTC),
+					},
+				},
+				Continue: "next",
+			},
+		},
+		{
+			name: "filtered and paginated by the client",
+			request: &GetRestoresRequest{
+				InstanceID:     "test-instance-id",
+				Status:         []RestoreStatus{RestoreStatusDone, RestoreStatusFailed},
+				TriggeredAfter: time.Date(2023, 4, 11, 0, 0, 0, 0, time.UTC),
+				Limit:          1,
+			},
+			httpReaction: httpReaction{
+				status: http.StatusOK,
+				body:   successGetRestoresRequestResponseBody,
+			},
+			expectedResponse: &GetRestoresResponse{
+				Restores: []GetRestoreResponse{
+					{
+						RestoreID:   pointer.Int(1),
+						BackupID:    pointer.Int(1),
+						Status:      "done",
+						TriggeredAt: time.Date(2023, 4, 11, 8, 52, 48, 209*int(time.Millisecond), time.UTC),
+						FinishedAt:  time.Date(2023, 4, 11, 8, 53, 16, 411*int(time.Millisecond), time.UTC),
+					},
+				},
+				Continue: "1",
+			},
+		},
 		{
 			name: "instance not found",
 			request: &GetRestoresRequest{
@@ -165,7 +235,7 @@ func TestGetRestores(t *testing.T) {
 
 		klient := newTestClient(t, tc.name, tc.httpChecks, tc.httpReaction)
 
-		response, err := klient.GetRestores(tc.request)
+		response, err := klient.GetRestores(context.Background(), tc.request)
 
 		doResponseChecks(t, tc.name, response, err, tc.expectedResponse, tc.expectedErrMessage, tc.expectedErr)
 	}
@@ -0,0 +1,103 @@
+/*
+Copyright 2024 Klutch Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package backupmanager
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestInterceptors(t *testing.T) {
+	errInjected := errors.New("injected fault")
+	getBackupRequest := &GetBackupRequest{InstanceID: "instance-id", BackupID: "1"}
+
+	cases := map[string]struct {
+		interceptors    []Interceptor
+		request         func(c *client) error
+		wantInvocations []Invocation
+		wantSent        bool
+		wantErr         error
+	}{
+		"interceptors are called with the invocation": {
+			request: func(c *client) error {
+				_, err := c.GetBackup(context.Background(), getBackupRequest)
+				return err
+			},
+			wantInvocations: []Invocation{
+				{Operation: "get_backup", Request: getBackupRequest},
+				{Operation: "get_backup", Request: getBackupRequest},
+			},
+			wantSent: true,
+		},
+		"availability checks are intercepted": {
+			request: func(c *client) error { return c.CheckAvailability(context.Background(), "") },
+			wantInvocations: []Invocation{
+				{Operation: "check_availability"},
+				{Operation: "check_availability"},
+			},
+			wantSent: true,
+		},
+		"interceptors may answer requests": {
+			interceptors: []Interceptor{func(Invocation, *http.Request, Invoker) (*http.Response, error) {
+				return nil, errInjected
+			}},
+			request: func(c *client) error {
+				_, err := c.GetBackup(context.Background(), getBackupRequest)
+				return err
+			},
+			wantInvocations: []Invocation{{Operation: "get_backup", Request: getBackupRequest}},
+			wantErr:         errInjected,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			klient := newTestClient(t, name, httpChecks{}, httpReaction{})
+
+			var invocations []Invocation
+			record := func(invocation Invocation, request *http.Request, next Invoker) (*http.Response, error) {
+				invocations = append(invocations, invocation)
+				return next(request)
+			}
+			klient.interceptors = append([]Interceptor{record}, tc.interceptors...)
+			klient.interceptors = append(klient.interceptors, record)
+
+			sent := false
+			klient.doRequestFunc = func(request *http.Request) (*http.Response, error) {
+				sent = true
+				return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(strings.NewReader(`{"id":1}`))}, nil
+			}
+
+			err := tc.request(klient)
+			if !errors.Is(err, tc.wantErr) {
+				t.Errorf("want error %v, got %v", tc.wantErr, err)
+			}
+			if diff := cmp.Diff(tc.wantInvocations, invocations); diff != "" {
+				t.Errorf("invocations: -want, +got:\n%s", diff)
+			}
+			if sent != tc.wantSent {
+				t.Errorf("want request sent %t, got %t", tc.wantSent, sent)
+			}
+		})
+	}
+}
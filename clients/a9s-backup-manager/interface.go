@@ -16,6 +16,14 @@ limitations under the License.
 
 package backupmanager
 
+import (
+	"context"
+	"io"
+
+	"github.com/go-logr/logr"
+	"go.opentelemetry.io/otel/trace"
+)
+
 // AuthConfig is a union-type representing the possible auth configurations a
 // client may use to authenticate to the backup manager. Currently, only basic auth is
 // supported.
@@ -43,8 +51,13 @@ type ClientConfiguration struct {
 	// TimeoutSeconds is the length of the timeout of any request to the
 	// backup manager, in seconds.
 	TimeoutSeconds int
-	// Verbose is whether the client will log to klog.
+	// Verbose is whether the client logs the requests it sends and the
+	// responses it receives. Authorization headers, encryption keys and
+	// credentials are redacted.
 	Verbose bool
+	// Logger receives the structured log messages of the client. If it is the
+	// zero Logger, klog is used.
+	Logger logr.Logger
 	// InsecureSkipVerify skips certificate verification for TLS connections.
 	// Useful for self-signed certificates. Should only be used in development.
 	InsecureSkipVerify bool
@@ -54,13 +67,45 @@ type ClientConfiguration struct {
 	// OverrideServerName overrides the server name used for certificate verification.
 	// This is useful when the certificate is issued for a different name than the URL hostname.
 	OverrideServerName string
+	// Metrics, if set, records the metrics of every request the client sends
+	// to the backup manager.
+	Metrics *Metrics
+	// ProviderConfig is the name of the ProviderConfig the client is created
+	// for. It is only used to label metrics.
+	ProviderConfig string
+	// TracerProvider creates the spans of requests to the backup manager. The
+	// global TracerProvider is used if it is nil.
+	TracerProvider trace.TracerProvider
+	// FailoverURLs are the URLs of further endpoints of the backup manager,
+	// in order of preference after URL. Requests that cannot be delivered to
+	// an endpoint, or that are idempotent and fail in transit or are answered
+	// with 502, 503 or 504, are sent to the next endpoint.
+	FailoverURLs []string
+	// EndpointHealth records the endpoints that failed, so that requests are
+	// sent to healthy endpoints first. It can be shared by clients. If it is
+	// nil, the client records the health of its endpoints by itself.
+	EndpointHealth *EndpointHealth
+	// Interceptors intercept every request the client sends to the backup manager,
+	// in order: the first interceptor is the outermost and the last one
+	// calls the transport.
+	Interceptors []Interceptor
+	// RateLimiter, if set, limits the rate and concurrency of the requests
+	// the client sends to the backup manager and retries requests that are answered
+	// with 429 Too Many Requests. It can be shared by clients.
+	RateLimiter *RateLimiter
+	// RetryPolicy, if set, retries requests that failed transiently and that
+	// are safe to send again. Retries stop when the context of the request
+	// is done.
+	RetryPolicy *RetryPolicy
 }
 
 // DefaultClientConfiguration returns a default ClientConfiguration:
 //   - 60 second timeout
+//   - the DefaultRetryPolicy
 func DefaultClientConfiguration() *ClientConfiguration {
 	return &ClientConfiguration{
 		TimeoutSeconds: 60,
+		RetryPolicy:    DefaultRetryPolicy(),
 	}
 }
 
@@ -68,69 +113,93 @@ func DefaultClientConfiguration() *ClientConfiguration {
 //
 // 1. Create a new backup of a data service instance with the CreateBackup method
 // 2. Update the backup config of a data service instance with UpdateBackupConfig method
+//
+// Every method takes a context.Context as its first argument. The context
+// governs the lifetime of the underlying HTTP requests, including retries:
+// when it is cancelled or its deadline expires, the request to the backup
+// manager is aborted and the method returns the context's error. The timeout
+// from ClientConfiguration.TimeoutSeconds still applies to every single
+// request in addition.
 type Client interface {
 	// CreateBackup requests that a new backup of a data service be
 	// created and returns information about the backup or an error.
 	// CreateBackup does a POST on the backup managers endpoint for the
 	// requested instance ID (/instances/{instance-id}/backups).
-	CreateBackup(r *CreateBackupRequest) (*CreateBackupResponse, error)
+	CreateBackup(ctx context.Context, r *CreateBackupRequest) (*CreateBackupResponse, error)
 
 	// CreateRestore requests that a new restore of a backup of a data service
 	// to be created and returns information about the restore or an error.
 	// CreateRestore does a POST on the restore managers endpoint for the
 	// requested instance ID and backup ID (/instance/{instance-id}/backups/{backup-id}/restore)
-	CreateRestore(r *CreateRestoreRequest) (*CreateRestoreResponse, error)
+	CreateRestore(ctx context.Context, r *CreateRestoreRequest) (*CreateRestoreResponse, error)
 
 	// GetBackup retrieves information about a specific backup for a specific
 	// instance from the backup manager or returns an error. GetBackup does a
 	// GET on the backup managers endpoint for the requested instance ID and
 	// backup ID (/instances/{instance-id}/backups/{backup-id}).
-	GetBackup(r *GetBackupRequest) (*GetBackupResponse, error)
+	GetBackup(ctx context.Context, r *GetBackupRequest) (*GetBackupResponse, error)
 
 	// GetBackups retrieves information about all existing backups for a
 	// specific instance from the backup manager or returns an error.
 	// GetBackups does a GET on the backup managers endpoint for the requested
-	// instance ID (/instances/{instance-id}/backups).
-	GetBackups(r *GetBackupsRequest) (*GetBackupsResponse, error)
+	// instance ID (/instances/{instance-id}/backups). The list options of the
+	// request are applied by the client if the backup manager does not
+	// support them; Backups iterates over all pages.
+	GetBackups(ctx context.Context, r *GetBackupsRequest) (*GetBackupsResponse, error)
 
 	// GetInstanceConfig retrieves the configuration of a specific
 	// data service instance from the backup manager or returns an error.
 	// GetInstanceConfig does a GET on the backup manager's endpoint for
 	// the requested instance ID (/instances/{instance-id}/config).
-	GetInstanceConfig(r *GetInstanceConfigRequest) (*GetInstanceConfigResponse, error)
+	GetInstanceConfig(ctx context.Context, r *GetInstanceConfigRequest) (*GetInstanceConfigResponse, error)
 
 	// UpdateBackupConfig requests that the backup config of a data service instance
 	// is updated and returns information about the update or an error.
 	// UpdateBackupConfig does a PUT on the backup managers endpoint for the
 	// requested instance ID (/instances/{instance-id}).
-	UpdateBackupConfig(r *UpdateBackupConfigRequest) (*UpdateBackupConfigResponse, error)
+	UpdateBackupConfig(ctx context.Context, r *UpdateBackupConfigRequest) (*UpdateBackupConfigResponse, error)
 
 	// GetRestore retrieves information about a specific restore that has been
 	// performed on a specific instance from the backup manager or returns an
 	// error. GetRestore does a GET on the backup managers endpoint for the
 	// requested instance ID and restore ID
 	// (/instances/{instance-id}/restores/{restore-id}).
-	GetRestore(r *GetRestoreRequest) (*GetRestoreResponse, error)
+	GetRestore(ctx context.Context, r *GetRestoreRequest) (*GetRestoreResponse, error)
 
 	// GetRestores retrieves information about all previously performed restores
 	// for a specific instance from the backup manager or returns an error.
 	// GetRestores does a GET on the backup managers endpoint for the requested
-	// instance ID (/instances/{instance-id}/restores).
-	GetRestores(r *GetRestoresRequest) (*GetRestoresResponse, error)
+	// instance ID (/instances/{instance-id}/restores). The list options of the
+	// request are applied by the client if the backup manager does not
+	// support them; Restores iterates over all pages.
+	GetRestores(ctx context.Context, r *GetRestoresRequest) (*GetRestoresResponse, error)
 
 	// DeleteBackup requests that a backup of a data service be deleted
 	// and returns a confirmation of the deletion or an error.
 	// This includes the metadata of the backup in the backup manager as
 	// well as the actual file containing the backup.
-	// DeleteBackup does a POST on the backup managers endpoint for the
+	// DeleteBackup does a DELETE on the backup managers endpoint for the
 	// requested instance ID and the requested backup id
 	// (/instances/{instance-id}/backups/{backup-id})
-	DeleteBackup(r *DeleteBackupRequest) (*DeleteBackupResponse, error)
-
+	DeleteBackup(ctx context.Context, r *DeleteBackupRequest) (*DeleteBackupResponse, error)
+
+	// DownloadBackup streams the file of a backup of a specific instance from
+	// the backup manager or returns an error. DownloadBackup does a GET on the
+	// backup managers endpoint for the requested instance ID and backup ID
+	// (/instances/{instance-id}/backups/{backup-id}/download). If the
+	// connection breaks, the download is resumed with a range request. If
+	// the backup manager sends a checksum of the file in the Repr-Digest,
+	// Digest or Content-MD5 header, reading the end of the file returns a
+	// ChecksumMismatchError instead of io.EOF if the file does not match it.
+	// The caller must close the returned reader. Unlike the other methods,
+	// the download is not bound by ClientConfiguration.TimeoutSeconds, only by
+	// ctx. With a RateLimiter that limits MaxInFlight, the download holds one
+	// of the in-flight slots until the reader is closed.
+	DownloadBackup(ctx context.Context, instanceID, backupID string) (io.ReadCloser, error)
 	// CheckAvailability verifies that the backup manager is reachable and responding.
 	// The endpoint parameter allows customization of which endpoint to check (e.g., "/instances").
 	// If the endpoint is empty, a default endpoint will be used.
-	CheckAvailability(endpoint string) error
+	CheckAvailability(ctx context.Context, endpoint string) error
 }
 
 // CreateFunc allows control over which implementation of a Client is
@@ -0,0 +1,290 @@
+/*
+Copyright 2024 Klutch Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package backupmanager
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"iter"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// These are the query parameters of the list options of GetBackups and
+// GetRestores.
+const (
+	paramStatus          = "status"
+	paramTriggeredAfter  = "triggered_after"
+	paramTriggeredBefore = "triggered_before"
+	paramLimit           = "limit"
+	paramContinue        = "continue"
+)
+
+const (
+	// headerListOptions is set by backup managers that support list options
+	// to the comma separated query parameters they applied to a list
+	// response. The client applies the list options the backup manager did
+	// not apply itself, which makes list options work with backup managers
+	// that do not support them, at the cost of fetching every backup or
+	// restore of the instance on each call. "limit" covers "continue".
+	headerListOptions = "X-List-Options"
+	// headerContinue is the token of the next page of a list response whose
+	// limit the backup manager applied. It is not set on the last page.
+	headerContinue = "X-Continue"
+)
+
+// listOptions are the list options of a GetBackupsRequest or
+// GetRestoresRequest.
+type listOptions struct {
+	status          []string
+	triggeredAfter  time.Time
+	triggeredBefore time.Time
+	limit           int
+	continueToken   string
+}
+
+// listEntry is what the list options apply to of a backup or restore.
+type listEntry struct {
+	id          *int
+	status      string
+	triggeredAt time.Time
+}
+
+func (r *GetBackupsRequest) listOptions() listOptions {
+	status := make([]string, 0, len(r.Status))
+	for _, s := range r.Status {
+		status = append(status, string(s))
+	}
+	return listOptions{
+		status:          status,
+		triggeredAfter:  r.TriggeredAfter,
+		triggeredBefore: r.TriggeredBefore,
+		limit:           r.Limit,
+		continueToken:   r.Continue,
+	}
+}
+
+func (r *GetRestoresRequest) listOptions() listOptions {
+	status := make([]string, 0, len(r.Status))
+	for _, s := range r.Status {
+		status = append(status, string(s))
+	}
+	return listOptions{
+		status:          status,
+		triggeredAfter:  r.TriggeredAfter,
+		triggeredBefore: r.TriggeredBefore,
+		limit:           r.Limit,
+		continueToken:   r.Continue,
+	}
+}
+
+func backupListEntry(b GetBackupResponse) listEntry {
+	return listEntry{id: b.BackupID, status: string(b.Status), triggeredAt: b.TriggeredAt}
+}
+
+func restoreListEntry(r GetRestoreResponse) listEntry {
+	return listEntry{id: r.RestoreID, status: string(r.Status), triggeredAt: r.TriggeredAt}
+}
+
+func (o listOptions) validate() error {
+	if o.limit < 0 {
+		return fmt.Errorf("limit must not be negative")
+	}
+	return nil
+}
+
+// params returns the query parameters of the list options, or nil if none
+// are set.
+func (o listOptions) params() map[string]string {
+	params := map[string]string{}
+	if len(o.status) > 0 {
+		params[paramStatus] = strings.Join(o.status, ",")
+	}
+	if !o.triggeredAfter.IsZero() {
+		params[paramTriggeredAfter] = o.triggeredAfter.UTC().Format(time.RFC3339Nano)
+	}
+	if !o.triggeredBefore.IsZero() {
+		params[paramTriggeredBefore] = o.triggeredBefore.UTC().Format(time.RFC3339Nano)
+	}
+	if o.limit > 0 {
+		params[paramLimit] = strconv.Itoa(o.limit)
+	}
+	if o.continueToken != "" {
+		params[paramContinue] = o.continueToken
+	}
+
+	if len(params) == 0 {
+		return nil
+	}
+	return params
+}
+
+// matches returns whether the entry passes the filters of the list options
+// that are not in applied.
+func (o listOptions) matches(e listEntry, applied map[string]bool) bool {
+	switch {
+	case !applied[paramStatus] && len(o.status) > 0 && !slices.Contains(o.status, e.status):
+		return false
+	case !applied[paramTriggeredAfter] && !o.triggeredAfter.IsZero() && !e.triggeredAt.After(o.triggeredAfter):
+		return false
+	case !applied[paramTriggeredBefore] && !o.triggeredBefore.IsZero() && !e.triggeredAt.Before(o.triggeredBefore):
+		return false
+	default:
+		return true
+	}
+}
+
+// applyListOptions applies the list options the backup manager did not
+// apply, according to the header of its response, to the items of the
+// response. It returns the items of the requested page and the continue
+// token of the next page.
+//
+// If the client applies the limit, the items are ordered by ID and the
+// continue token is the ID of the last item of the page. The next page
+// starts at the first item with a greater ID, so that items deleted between
+// two pages do not break the pagination.
+func applyListOptions[T any](items []T, entry func(T) listEntry, o listOptions, header http.Header) ([]T, string, error) {
+	applied := map[string]bool{}
+	for _, name := range strings.Split(header.Get(headerListOptions), ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			applied[name] = true
+		}
+	}
+
+	if applied[paramLimit] {
+		page := slices.DeleteFunc(items, func(item T) bool { return !o.matches(entry(item), applied) })
+		return page, header.Get(headerContinue), nil
+	}
+
+	if o.limit > 0 || o.continueToken != "" {
+		slices.SortStableFunc(items, func(a, b T) int {
+			return compareIDs(entry(a).id, entry(b).id)
+		})
+	}
+
+	start := 0
+	if o.continueToken != "" {
+		id, err := parseContinueToken(o.continueToken)
+		if err != nil {
+			return nil, "", err
+		}
+		start = slices.IndexFunc(items, func(item T) bool {
+			e := entry(item)
+			return e.id != nil && *e.id > id
+		})
+		if start == -1 {
+			start = len(items)
+		}
+	}
+
+	page := []T{}
+	for _, item := range items[start:] {
+		e := entry(item)
+		if !o.matches(e, applied) {
+			continue
+		}
+		if o.limit > 0 && len(page) == o.limit {
+			last := entry(page[len(page)-1])
+			if last.id == nil {
+				return nil, "", fmt.Errorf("cannot continue after an item without ID")
+			}
+			return page, strconv.Itoa(*last.id), nil
+		}
+		page = append(page, item)
+	}
+	return page, "", nil
+}
+
+// compareIDs orders items by ID, with items without ID last.
+func compareIDs(a, b *int) int {
+	switch {
+	case a == nil && b == nil:
+		return 0
+	case a == nil:
+		return 1
+	case b == nil:
+		return -1
+	default:
+		return cmp.Compare(*a, *b)
+	}
+}
+
+func parseContinueToken(token string) (int, error) {
+	id, err := strconv.Atoi(token)
+	if err != nil {
+		return 0, fmt.Errorf("continue token %q is malformed", token)
+	}
+	return id, nil
+}
+
+// Backups returns an iterator over the backups of the instance that match
+// the list options of r. It fetches the backups a page of r.Limit backups at
+// a time, starting at the page of r.Continue, and ends after the first
+// error.
+func Backups(ctx context.Context, c Client, r GetBackupsRequest) iter.Seq2[GetBackupResponse, error] {
+	return func(yield func(GetBackupResponse, error) bool) {
+		for {
+			response, err := c.GetBackups(ctx, &r)
+			if err != nil {
+				yield(GetBackupResponse{}, err)
+				return
+			}
+
+			for _, backup := range response.Backups {
+				if !yield(backup, nil) {
+					return
+				}
+			}
+
+			if response.Continue == "" {
+				return
+			}
+			r.Continue = response.Continue
+		}
+	}
+}
+
+// Restores returns an iterator over the restores of the instance that match
+// the list options of r. It fetches the restores a page of r.Limit restores
+// at a time, starting at the page of r.Continue, and ends after the first
+// error.
+func Restores(ctx context.Context, c Client, r GetRestoresRequest) iter.Seq2[GetRestoreResponse, error] {
+	return func(yield func(GetRestoreResponse, error) bool) {
+		for {
+			response, err := c.GetRestores(ctx, &r)
+			if err != nil {
+				yield(GetRestoreResponse{}, err)
+				return
+			}
+
+			for _, restore := range response.Restores {
+				if !yield(restore, nil) {
+					return
+				}
+			}
+
+			if response.Continue == "" {
+				return
+			}
+			r.Continue = response.Continue
+		}
+	}
+}
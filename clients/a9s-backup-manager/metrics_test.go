@@ -0,0 +1,104 @@
+/*
+Copyright 2024 Klutch Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package backupmanager
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestInstrumentedTransport(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/instances/available/backups":
+			w.Header().Set("Content-Type", "application/json")
+			w.Write([]byte(`[]`))
+		case "/instances/missing/backups":
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{}`))
+		default:
+			w.WriteHeader(http.StatusInternalServerError)
+			w.Write([]byte(`{}`))
+		}
+	}))
+	defer server.Close()
+
+	metrics := NewMetrics()
+	config := DefaultClientConfiguration()
+	config.URL = server.URL
+	config.Metrics = metrics
+	config.ProviderConfig = "default"
+	klient, err := NewClient(config)
+	if err != nil {
+		t.Fatalf("cannot create client: %v", err)
+	}
+
+	if _, err := klient.GetBackups(context.Background(), &GetBackupsRequest{InstanceID: "available"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	_, _ = klient.GetBackups(context.Background(), &GetBackupsRequest{InstanceID: "missing"})
+	_, _ = klient.CreateBackup(context.Background(), &CreateBackupRequest{InstanceID: "broken"})
+
+	expected := `
+# HELP a9s_backup_manager_client_request_errors_total Number of failed requests to backup managers, by ProviderConfig, operation and error class.
+# TYPE a9s_backup_manager_client_request_errors_total counter
+a9s_backup_manager_client_request_errors_total{class="client_error",operation="get_backups",provider_config="default"} 1
+a9s_backup_manager_client_request_errors_total{class="server_error",operation="create_backup",provider_config="default"} 1
+# HELP a9s_backup_manager_client_requests_total Number of requests to backup managers, by ProviderConfig, operation and HTTP status code.
+# TYPE a9s_backup_manager_client_requests_total counter
+a9s_backup_manager_client_requests_total{code="200",operation="get_backups",provider_config="default"} 1
+a9s_backup_manager_client_requests_total{code="404",operation="get_backups",provider_config="default"} 1
+a9s_backup_manager_client_requests_total{code="500",operation="create_backup",provider_config="default"} 1
+`
+	if err := testutil.CollectAndCompare(metrics, strings.NewReader(expected),
+		"a9s_backup_manager_client_requests_total", "a9s_backup_manager_client_request_errors_total"); err != nil {
+		t.Error(err)
+	}
+	if count := testutil.CollectAndCount(metrics, "a9s_backup_manager_client_request_duration_seconds"); count != 2 {
+		t.Errorf("expected latencies of 2 operations, got %d", count)
+	}
+}
+
+func TestErrorClass(t *testing.T) {
+	cases := []struct {
+		name     string
+		response *http.Response
+		err      error
+		expected string
+	}{
+		{name: "success", response: &http.Response{StatusCode: http.StatusOK}},
+		{name: "not found", response: &http.Response{StatusCode: http.StatusNotFound}, expected: errorClassClientError},
+		{name: "bad gateway", response: &http.Response{StatusCode: http.StatusBadGateway}, expected: errorClassServerError},
+		{name: "canceled", err: context.Canceled, expected: errorClassCanceled},
+		{name: "deadline exceeded", err: context.DeadlineExceeded, expected: errorClassTimeout},
+		{name: "connection refused", err: errors.New("connection refused"), expected: errorClassNetwork},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if class := errorClass(tc.response, tc.err); class != tc.expected {
+				t.Errorf("expected error class %q, got %q", tc.expected, class)
+			}
+		})
+	}
+}
@@ -0,0 +1,118 @@
+/*
+Copyright 2024 Klutch Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package backupmanager
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRateLimiter(t *testing.T) {
+	cases := map[string]struct {
+		// tooManyRequests is the number of requests answered with 429.
+		tooManyRequests int
+		request         func(c *client) error
+		wantRequests    int
+		wantErr         bool
+	}{
+		"get is retried": {
+			tooManyRequests: 1,
+			request:         getBackup,
+			wantRequests:    2,
+		},
+		"create is retried": {
+			tooManyRequests: 1,
+			request:         createBackup,
+			wantRequests:    2,
+		},
+		"retries are limited": {
+			tooManyRequests: 10,
+			request:         getBackup,
+			wantRequests:    maxRateLimitRetries + 1,
+			wantErr:         true,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			klient := newTestClient(t, name, httpChecks{}, httpReaction{})
+			klient.interceptors = []Interceptor{NewRateLimiter(RateLimit{MaxInFlight: 1}).intercept}
+
+			requests := 0
+			klient.doRequestFunc = func(request *http.Request) (*http.Response, error) {
+				requests++
+				if requests <= tc.tooManyRequests {
+					return &http.Response{
+						StatusCode: http.StatusTooManyRequests,
+						Header:     http.Header{"Retry-After": []string{"0"}},
+						Body:       io.NopCloser(strings.NewReader("{}")),
+					}, nil
+				}
+				status := http.StatusOK
+				if request.Method == http.MethodPost {
+					status = http.StatusCreated
+				}
+				return &http.Response{StatusCode: status, Body: io.NopCloser(strings.NewReader(`{"id":1}`))}, nil
+			}
+
+			err := tc.request(klient)
+			if (err != nil) != tc.wantErr {
+				t.Errorf("want error %t, got %v", tc.wantErr, err)
+			}
+			if requests != tc.wantRequests {
+				t.Errorf("want %d requests, got %d", tc.wantRequests, requests)
+			}
+		})
+	}
+}
+
+func TestRateLimiterMaxInFlight(t *testing.T) {
+	limiter := NewRateLimiter(RateLimit{MaxInFlight: 1})
+	next := func(request *http.Request) (*http.Response, error) {
+		return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(strings.NewReader(""))}, nil
+	}
+	newRequest := func(ctx context.Context) *http.Request {
+		request, err := http.NewRequestWithContext(ctx, http.MethodGet, "https://example.com/instances", nil)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		return request
+	}
+
+	first, err := limiter.intercept(Invocation{}, newRequest(context.Background()), next)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, err := limiter.intercept(Invocation{}, newRequest(ctx), next); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("want queued request to be canceled, got %v", err)
+	}
+
+	first.Body.Close()
+	second, err := limiter.intercept(Invocation{}, newRequest(context.Background()), next)
+	if err != nil {
+		t.Fatalf("unexpected error after the first request completed: %v", err)
+	}
+	second.Body.Close()
+}
@@ -0,0 +1,111 @@
+/*
+Copyright 2024 Klutch Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package backupmanager
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+)
+
+// redacted replaces every credential the client logs.
+const redacted = "REDACTED"
+
+// redactedHeaders are the headers whose values are redacted.
+var redactedHeaders = []string{"Authorization", "Proxy-Authorization", "Cookie", "Set-Cookie"}
+
+// redactedFields are the fields of JSON bodies whose values are redacted.
+var redactedFields = map[string]bool{
+	"encryption_key": true,
+	"password":       true,
+	"credentials":    true,
+}
+
+// redactHeader returns a copy of header with the values of redactedHeaders
+// replaced.
+func redactHeader(header http.Header) http.Header {
+	if len(header) == 0 {
+		return nil
+	}
+	result := header.Clone()
+	for _, name := range redactedHeaders {
+		if values := result.Values(name); len(values) > 0 {
+			result.Set(name, redacted)
+		}
+	}
+	return result
+}
+
+// redactBody returns a JSON body with the values of redactedFields replaced.
+// Bodies that are not JSON are returned as they are.
+func redactBody(body []byte) string {
+	if len(body) == 0 {
+		return ""
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.UseNumber()
+	var value interface{}
+	if err := decoder.Decode(&value); err != nil {
+		return string(body)
+	}
+	result, err := json.Marshal(redactFields(value))
+	if err != nil {
+		return string(body)
+	}
+	return string(result)
+}
+
+// redactFields redacts the values of redactedFields in a decoded JSON value.
+func redactFields(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, field := range v {
+			if redactedFields[key] {
+				v[key] = redactAll(field)
+			} else {
+				v[key] = redactFields(field)
+			}
+		}
+	case []interface{}:
+		for i := range v {
+			v[i] = redactFields(v[i])
+		}
+	}
+	return value
+}
+
+// redactAll redacts every value of a decoded JSON value, keeping the structure
+// of objects and arrays.
+func redactAll(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key := range v {
+			v[key] = redactAll(v[key])
+		}
+		return v
+	case []interface{}:
+		for i := range v {
+			v[i] = redactAll(v[i])
+		}
+		return v
+	case nil:
+		return nil
+	default:
+		return redacted
+	}
+}
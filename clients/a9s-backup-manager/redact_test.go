@@ -0,0 +1,89 @@
+/*
+Copyright 2024 Klutch Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package backupmanager
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/go-logr/logr/funcr"
+	"k8s.io/utils/pointer"
+)
+
+func TestRedactBody(t *testing.T) {
+	cases := []struct {
+		name     string
+		body     string
+		expected string
+	}{
+		{
+			name:     "encryption key",
+			body:     `{"encryption_key":"s3cr3t","exclude_from_auto_backup":true}`,
+			expected: `{"encryption_key":"REDACTED","exclude_from_auto_backup":true}`,
+		},
+		{
+			name:     "nested credentials",
+			body:     `[{"id":1,"credentials":{"username":"admin","password":"hunter2"}}]`,
+			expected: `[{"credentials":{"password":"REDACTED","username":"REDACTED"},"id":1}]`,
+		},
+		{
+			name:     "not JSON",
+			body:     "Bad Gateway",
+			expected: "Bad Gateway",
+		},
+	}
+
+	for _, tc := range cases {
+		if got := redactBody([]byte(tc.body)); got != tc.expected {
+			t.Errorf("%s: expected %s, got %s", tc.name, tc.expected, got)
+		}
+	}
+}
+
+func TestVerboseLogsAreRedacted(t *testing.T) {
+	var logs strings.Builder
+	klient := newTestClient(t, "verbose", httpChecks{
+		body: `{"encryption_key":"s3cr3t"}`,
+	}, httpReaction{
+		status: http.StatusCreated,
+		body:   `{"message": "instance updated"}`,
+	})
+	klient.AuthConfig = &AuthConfig{BasicAuthConfig: &BasicAuthConfig{Username: "admin", Password: "hunter2"}}
+	klient.logger = funcr.New(func(prefix, args string) {
+		logs.WriteString(args + "\n")
+	}, funcr.Options{})
+
+	if _, err := klient.UpdateBackupConfig(context.Background(), &UpdateBackupConfigRequest{
+		InstanceID:    "test-instance-id",
+		EncryptionKey: pointer.String("s3cr3t"),
+	}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	for _, expected := range []string{`"msg"="Sending request"`, `"msg"="Received response"`, "instance updated"} {
+		if !strings.Contains(logs.String(), expected) {
+			t.Errorf("expected logs to contain %s, got:\n%s", expected, logs.String())
+		}
+	}
+	for _, secret := range []string{"s3cr3t", "YWRtaW46aHVudGVyMg=="} {
+		if strings.Contains(logs.String(), secret) {
+			t.Errorf("expected logs not to contain %q, got:\n%s", secret, logs.String())
+		}
+	}
+}
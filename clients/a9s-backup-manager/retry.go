@@ -0,0 +1,99 @@
+/*
+Copyright 2024 Klutch Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package backupmanager
+
+import (
+	"net/http"
+	"time"
+)
+
+// RetryPolicy configures how a client retries requests that failed
+// transiently. Only requests it is safe to send again are retried: requests
+// that were not delivered to the backup manager, and idempotent requests, i.e.
+// every request but those that create backups and restores, that fail in
+// transit or are answered with 502, 503 or 504.
+type RetryPolicy struct {
+	// MaxRetries is the maximum number of times a request is retried.
+	MaxRetries int
+	// InitialInterval is the delay before the first retry. It doubles with
+	// every further retry, up to MaxInterval. A Retry-After header of the
+	// backup manager takes precedence.
+	InitialInterval time.Duration
+	// MaxInterval caps the delay between two attempts.
+	MaxInterval time.Duration
+}
+
+// DefaultRetryPolicy returns the default RetryPolicy:
+//
+//   - up to 3 retries
+//   - 500 millisecond initial interval, doubling up to 5 seconds
+func DefaultRetryPolicy() *RetryPolicy {
+	return &RetryPolicy{
+		MaxRetries:      3,
+		InitialInterval: 500 * time.Millisecond,
+		MaxInterval:     5 * time.Second,
+	}
+}
+
+// delay returns how long to wait before the given retry, counted from zero,
+// of a request that was answered with response.
+func (p *RetryPolicy) delay(retry int, response *http.Response) time.Duration {
+	if response != nil && response.Header.Get("Retry-After") != "" {
+		return min(retryAfter(response.Header), p.MaxInterval)
+	}
+
+	delay := p.InitialInterval << retry
+	if delay < p.InitialInterval || delay > p.MaxInterval {
+		delay = p.MaxInterval
+	}
+	return delay
+}
+
+// sendWithRetries sends the request and retries it according to the retry
+// policy of the client until it succeeds, it cannot be retried or the context
+// of the request is done.
+func (c *client) sendWithRetries(request *http.Request) (*http.Response, error) {
+	for retry := 0; ; retry++ {
+		response, err := c.sendOnce(request)
+		// Requests are retried on the same conditions they fail over to the
+		// next endpoint on.
+		if retry >= c.retryPolicy.MaxRetries || !shouldFailOver(request, response, err) {
+			return response, err
+		}
+
+		delay := c.retryPolicy.delay(retry, response)
+		if err == nil {
+			_ = drainReader(response.Body)
+			response.Body.Close()
+			c.logger.V(1).Info("Retrying request", "method", request.Method, "status", response.StatusCode, "delay", delay.String())
+		} else {
+			c.logger.V(1).Info("Retrying request", "method", request.Method, "error", err.Error(), "delay", delay.String())
+		}
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-request.Context().Done():
+			timer.Stop()
+			return nil, request.Context().Err()
+		case <-timer.C:
+		}
+
+		if request, err = replay(request); err != nil {
+			return nil, err
+		}
+	}
+}
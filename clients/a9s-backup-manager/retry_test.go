@@ -0,0 +1,191 @@
+/*
+Copyright 2024 Klutch Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package backupmanager
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRetries(t *testing.T) {
+	refused := &net.OpError{Op: "dial", Net: "tcp", Err: errors.New("connection refused")}
+	reset := errors.New("connection reset by peer")
+
+	cases := map[string]struct {
+		// failures is the number of requests that fail with status or err.
+		failures     int
+		status       int
+		err          error
+		request      func(c *client) error
+		wantRequests int
+		wantErr      bool
+	}{
+		"get is retried if the backup manager is unavailable": {
+			failures:     2,
+			status:       http.StatusServiceUnavailable,
+			request:      getBackup,
+			wantRequests: 3,
+		},
+		"get is retried if the connection is reset": {
+			failures:     1,
+			err:          reset,
+			request:      getBackup,
+			wantRequests: 2,
+		},
+		"update is retried if the backup manager is unavailable": {
+			failures:     1,
+			status:       http.StatusGatewayTimeout,
+			request:      updateBackupConfig,
+			wantRequests: 2,
+		},
+		"create is retried if the backup manager is unreachable": {
+			failures:     1,
+			err:          refused,
+			request:      createBackup,
+			wantRequests: 2,
+		},
+		"create is not retried if the backup manager is unavailable": {
+			failures:     1,
+			status:       http.StatusBadGateway,
+			request:      createBackup,
+			wantRequests: 1,
+			wantErr:      true,
+		},
+		"create is not retried if the connection is reset": {
+			failures:     1,
+			err:          reset,
+			request:      createBackup,
+			wantRequests: 1,
+			wantErr:      true,
+		},
+		"client errors are not retried": {
+			failures:     1,
+			status:       http.StatusNotFound,
+			request:      getBackup,
+			wantRequests: 1,
+			wantErr:      true,
+		},
+		"retries are limited": {
+			failures:     10,
+			status:       http.StatusServiceUnavailable,
+			request:      getBackup,
+			wantRequests: 3,
+			wantErr:      true,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			klient := newTestClient(t, name, httpChecks{}, httpReaction{})
+			klient.retryPolicy = &RetryPolicy{MaxRetries: 2, InitialInterval: time.Millisecond, MaxInterval: time.Millisecond}
+
+			requests := 0
+			klient.doRequestFunc = func(request *http.Request) (*http.Response, error) {
+				requests++
+				if request.Body != nil {
+					if body, _ := io.ReadAll(request.Body); !strings.Contains(string(body), "exclude_from_auto_backup") {
+						t.Errorf("unexpected request body %q", body)
+					}
+				}
+				if requests <= tc.failures {
+					if tc.err != nil {
+						return nil, tc.err
+					}
+					return &http.Response{StatusCode: tc.status, Body: io.NopCloser(strings.NewReader("{}"))}, nil
+				}
+				status := http.StatusOK
+				if request.Method == http.MethodPost {
+					status = http.StatusCreated
+				}
+				return &http.Response{StatusCode: status, Body: io.NopCloser(strings.NewReader(`{"id":1}`))}, nil
+			}
+
+			err := tc.request(klient)
+			if (err != nil) != tc.wantErr {
+				t.Errorf("want error %t, got %v", tc.wantErr, err)
+			}
+			if requests != tc.wantRequests {
+				t.Errorf("want %d requests, got %d", tc.wantRequests, requests)
+			}
+		})
+	}
+}
+
+func TestRetriesStopWhenContextIsDone(t *testing.T) {
+	klient := newTestClient(t, "context", httpChecks{}, httpReaction{})
+	klient.retryPolicy = &RetryPolicy{MaxRetries: 10, InitialInterval: time.Hour, MaxInterval: time.Hour}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	requests := 0
+	klient.doRequestFunc = func(request *http.Request) (*http.Response, error) {
+		requests++
+		return &http.Response{StatusCode: http.StatusServiceUnavailable, Body: io.NopCloser(strings.NewReader("{}"))}, nil
+	}
+
+	_, err := klient.GetBackup(ctx, &GetBackupRequest{InstanceID: "instance-id", BackupID: "1"})
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("want the wait for the retry to end with the context, got %v", err)
+	}
+	if requests != 1 {
+		t.Errorf("want 1 request, got %d", requests)
+	}
+}
+
+func TestRetryPolicyDelay(t *testing.T) {
+	policy := &RetryPolicy{InitialInterval: time.Second, MaxInterval: 5 * time.Second}
+
+	cases := map[string]struct {
+		retry      int
+		retryAfter string
+		want       time.Duration
+	}{
+		"FirstRetry":       {retry: 0, want: time.Second},
+		"Doubles":          {retry: 2, want: 4 * time.Second},
+		"Capped":           {retry: 3, want: 5 * time.Second},
+		"Overflow":         {retry: 100, want: 5 * time.Second},
+		"RetryAfter":       {retry: 0, retryAfter: "2", want: 2 * time.Second},
+		"RetryAfterCapped": {retry: 0, retryAfter: "60", want: 5 * time.Second},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			response := &http.Response{Header: http.Header{}}
+			if tc.retryAfter != "" {
+				response.Header.Set("Retry-After", tc.retryAfter)
+			}
+			if got := policy.delay(tc.retry, response); got != tc.want {
+				t.Errorf("delay(%d): want %v, got %v", tc.retry, tc.want, got)
+			}
+		})
+	}
+}
+
+func updateBackupConfig(c *client) error {
+	excluded := true
+	_, err := c.UpdateBackupConfig(context.Background(), &UpdateBackupConfigRequest{
+		InstanceID:            "instance-id",
+		ExcludeFromAutoBackup: &excluded,
+	})
+	return err
+}
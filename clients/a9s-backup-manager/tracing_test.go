@@ -0,0 +1,96 @@
+/*
+Copyright 2024 Klutch Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package backupmanager
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestTracing(t *testing.T) {
+	defer otel.SetTextMapPropagator(otel.GetTextMapPropagator())
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+
+	var traceparent string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		traceparent = r.Header.Get("traceparent")
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"id":1,"status":"done"}`))
+	}))
+	defer server.Close()
+
+	recorder := tracetest.NewSpanRecorder()
+
+	config := DefaultClientConfiguration()
+	config.URL = server.URL
+	config.TracerProvider = sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	klient, err := NewClient(config)
+	if err != nil {
+		t.Fatalf("cannot create client: %v", err)
+	}
+
+	// The span of the request must be a child of the span of the caller,
+	// such as the span of a reconcile.
+	ctx, parent := config.TracerProvider.Tracer("test").Start(context.Background(), "reconcile")
+	if _, err := klient.GetBackup(ctx, &GetBackupRequest{InstanceID: "instance-1", BackupID: "1"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	parent.End()
+
+	spans := recorder.Ended()
+	if len(spans) != 2 {
+		t.Fatalf("expected 2 spans, got %d", len(spans))
+	}
+	span := spans[0]
+
+	if span.Parent().SpanID() != parent.SpanContext().SpanID() {
+		t.Errorf("expected span to be a child of the caller's span %s, got parent %s", parent.SpanContext().SpanID(), span.Parent().SpanID())
+	}
+
+	if span.Name() != "backup_manager.get_backup" {
+		t.Errorf("expected span name %q, got %q", "backup_manager.get_backup", span.Name())
+	}
+	if span.Status().Code == codes.Error {
+		t.Errorf("expected span not to be marked as failed")
+	}
+
+	attributes := attribute.NewSet(span.Attributes()...)
+	for key, expected := range map[attribute.Key]attribute.Value{
+		attributeOperation:          attribute.StringValue("get_backup"),
+		attributeInstanceID:         attribute.StringValue("instance-1"),
+		attributeBackupID:           attribute.StringValue("1"),
+		"http.response.status_code": attribute.IntValue(http.StatusOK),
+	} {
+		if value, ok := attributes.Value(key); !ok || value != expected {
+			t.Errorf("expected attribute %s=%v, got %v", key, expected.Emit(), value.Emit())
+		}
+	}
+
+	expectedTraceparent := "00-" + span.SpanContext().TraceID().String() + "-" + span.SpanContext().SpanID().String() + "-01"
+	if traceparent != expectedTraceparent {
+		t.Errorf("expected traceparent header %q, got %q", expectedTraceparent, traceparent)
+	}
+}
@@ -16,6 +16,76 @@ limitations under the License.
 
 package backupmanager
 
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// BackupStatus is the status of a backup reported by the backup manager.
+type BackupStatus string
+
+// These are the statuses the backup manager reports for backups.
+const (
+	// BackupStatusQueued is the status of a backup that is queued for
+	// execution but has not begun yet.
+	BackupStatusQueued BackupStatus = "queued"
+	// BackupStatusRunning is the status of a backup that is being executed.
+	BackupStatusRunning BackupStatus = "running"
+	// BackupStatusDone is the status of a backup that has been executed
+	// successfully.
+	BackupStatusDone BackupStatus = "done"
+	// BackupStatusFailed is the status of a backup whose execution was not
+	// successful.
+	BackupStatusFailed BackupStatus = "failed"
+	// BackupStatusDeleted is the status of a backup whose file has been
+	// deleted. The metadata of the backup is kept by the backup manager.
+	BackupStatusDeleted BackupStatus = "deleted"
+)
+
+// IsTerminal returns whether the backup manager has finished working on the
+// backup, that is whether the backup is neither queued nor running. It is
+// false for unknown statuses.
+func (s BackupStatus) IsTerminal() bool {
+	switch s {
+	case BackupStatusDone, BackupStatusFailed, BackupStatusDeleted:
+		return true
+	}
+	return false
+}
+
+// RestoreStatus is the status of a restore reported by the backup manager.
+type RestoreStatus string
+
+// These are the statuses the backup manager reports for restores.
+const (
+	// RestoreStatusQueued is the status of a restore that is queued for
+	// execution but has not begun yet.
+	RestoreStatusQueued RestoreStatus = "queued"
+	// RestoreStatusRunning is the status of a restore that is being
+	// executed.
+	RestoreStatusRunning RestoreStatus = "running"
+	// RestoreStatusDone is the status of a restore that has been executed
+	// successfully.
+	RestoreStatusDone RestoreStatus = "done"
+	// RestoreStatusFailed is the status of a restore whose execution was not
+	// successful.
+	RestoreStatusFailed RestoreStatus = "failed"
+	// RestoreStatusDeleted is the status of a restore that has been deleted.
+	RestoreStatusDeleted RestoreStatus = "deleted"
+)
+
+// IsTerminal returns whether the backup manager has finished working on the
+// restore, that is whether the restore is neither queued nor running. It is
+// false for unknown statuses.
+func (s RestoreStatus) IsTerminal() bool {
+	switch s {
+	case RestoreStatusDone, RestoreStatusFailed, RestoreStatusDeleted:
+		return true
+	}
+	return false
+}
+
 // CreateBackupRequest represents a request to create a new backup for a
 // data service instance.
 type CreateBackupRequest struct {
@@ -37,10 +107,28 @@ type GetBackupsRequest struct {
 	// InstanceID is the ID of the data service instance from which the
 	// backups should be fetched.
 	InstanceID string `json:"instance_id"`
+	// Status, if set, restricts the backups to those in one of the given
+	// statuses.
+	Status []BackupStatus `json:"status,omitempty"`
+	// TriggeredAfter and TriggeredBefore, if set, restrict the backups to
+	// those triggered strictly after or before the given time.
+	TriggeredAfter  time.Time `json:"triggered_after,omitzero"`
+	TriggeredBefore time.Time `json:"triggered_before,omitzero"`
+	// Limit, if positive, is the maximum number of backups returned. The
+	// remaining backups are fetched by passing the Continue token of the
+	// response in the next request.
+	Limit int `json:"limit,omitempty"`
+	// Continue is the token of the page to fetch, as returned in
+	// GetBackupsResponse.Continue. The other fields must be the same as in
+	// the request that returned the token.
+	Continue string `json:"continue,omitempty"`
 }
 
 type GetBackupsResponse struct {
 	Backups []GetBackupResponse
+	// Continue is the token that fetches the next page of backups. It is
+	// empty on the last page.
+	Continue string
 }
 
 type GetBackupRequest struct {
@@ -53,12 +141,36 @@ type GetBackupRequest struct {
 }
 
 type GetBackupResponse struct {
-	BackupID     *int   `json:"id"`
-	Size         int    `json:"size"`
-	Status       string `json:"status"`
-	TriggeredAt  string `json:"triggered_at"`
-	FinishedAt   string `json:"finished_at"`
-	Downloadable bool   `json:"downloadable"`
+	BackupID *int         `json:"id"`
+	Size     int          `json:"size"`
+	Status   BackupStatus `json:"status"`
+	// TriggeredAt is the time the backup was triggered.
+	TriggeredAt time.Time `json:"triggered_at"`
+	// FinishedAt is the time the backup was done or failed. It is zero
+	// while the backup is queued or running.
+	FinishedAt   time.Time `json:"finished_at"`
+	Downloadable bool      `json:"downloadable"`
+}
+
+// UnmarshalJSON decodes a backup, accepting null and empty timestamps for
+// backups that have not finished yet.
+func (r *GetBackupResponse) UnmarshalJSON(data []byte) error {
+	type plain GetBackupResponse
+	body := struct {
+		*plain
+		TriggeredAt *string `json:"triggered_at"`
+		FinishedAt  *string `json:"finished_at"`
+	}{plain: (*plain)(r)}
+	if err := json.Unmarshal(data, &body); err != nil {
+		return err
+	}
+
+	var err error
+	if r.TriggeredAt, err = parseTimestamp("triggered_at", body.TriggeredAt); err != nil {
+		return err
+	}
+	r.FinishedAt, err = parseTimestamp("finished_at", body.FinishedAt)
+	return err
 }
 
 // GetInstanceConfigRequest represents a request to retrieve the config of
@@ -144,21 +256,63 @@ type GetRestoreRequest struct {
 }
 
 type GetRestoreResponse struct {
-	RestoreID   *int   `json:"id"`
-	BackupID    *int   `json:"backup_id"`
-	Status      string `json:"status"`
-	TriggeredAt string `json:"triggered_at"`
-	FinishedAt  string `json:"finished_at"`
+	RestoreID *int          `json:"id"`
+	BackupID  *int          `json:"backup_id"`
+	Status    RestoreStatus `json:"status"`
+	// TriggeredAt is the time the restore was triggered.
+	TriggeredAt time.Time `json:"triggered_at"`
+	// FinishedAt is the time the restore was done or failed. It is zero
+	// while the restore is queued or running.
+	FinishedAt time.Time `json:"finished_at"`
+}
+
+// UnmarshalJSON decodes a restore, accepting null and empty timestamps for
+// restores that have not finished yet.
+func (r *GetRestoreResponse) UnmarshalJSON(data []byte) error {
+	type plain GetRestoreResponse
+	body := struct {
+		*plain
+		TriggeredAt *string `json:"triggered_at"`
+		FinishedAt  *string `json:"finished_at"`
+	}{plain: (*plain)(r)}
+	if err := json.Unmarshal(data, &body); err != nil {
+		return err
+	}
+
+	var err error
+	if r.TriggeredAt, err = parseTimestamp("triggered_at", body.TriggeredAt); err != nil {
+		return err
+	}
+	r.FinishedAt, err = parseTimestamp("finished_at", body.FinishedAt)
+	return err
 }
 
 type GetRestoresRequest struct {
 	// InstanceID is the ID of the data service instance from which the
 	// restore should be fetched.
 	InstanceID string `json:"instance_id"`
+	// Status, if set, restricts the restores to those in one of the given
+	// statuses.
+	Status []RestoreStatus `json:"status,omitempty"`
+	// TriggeredAfter and TriggeredBefore, if set, restrict the restores to
+	// those triggered strictly after or before the given time.
+	TriggeredAfter  time.Time `json:"triggered_after,omitzero"`
+	TriggeredBefore time.Time `json:"triggered_before,omitzero"`
+	// Limit, if positive, is the maximum number of restores returned. The
+	// remaining restores are fetched by passing the Continue token of the
+	// response in the next request.
+	Limit int `json:"limit,omitempty"`
+	// Continue is the token of the page to fetch, as returned in
+	// GetRestoresResponse.Continue. The other fields must be the same as in
+	// the request that returned the token.
+	Continue string `json:"continue,omitempty"`
 }
 
 type GetRestoresResponse struct {
 	Restores []GetRestoreResponse
+	// Continue is the token that fetches the next page of restores. It is
+	// empty on the last page.
+	Continue string
 }
 
 // DeleteBackupRequest represents a request to delete a given backup of a
@@ -177,3 +331,16 @@ type DeleteBackupResponse struct {
 	// manager api.
 	Message *string `json:"message,omitempty"`
 }
+
+// parseTimestamp parses an RFC 3339 timestamp of the backup manager, e.g.
+// "2023-04-11T08:52:48.209Z". Missing and empty timestamps are zero.
+func parseTimestamp(field string, value *string) (time.Time, error) {
+	if value == nil || *value == "" {
+		return time.Time{}, nil
+	}
+	t, err := time.Parse(time.RFC3339Nano, *value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("cannot parse %s: %w", field, err)
+	}
+	return t, nil
+}
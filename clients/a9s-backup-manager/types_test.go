@@ -0,0 +1,53 @@
+/*
+Copyright 2024 Klutch Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package backupmanager
+
+import "testing"
+
+func TestBackupStatusIsTerminal(t *testing.T) {
+	cases := map[BackupStatus]bool{
+		BackupStatusQueued:  false,
+		BackupStatusRunning: false,
+		BackupStatusDone:    true,
+		BackupStatusFailed:  true,
+		BackupStatusDeleted: true,
+		"unknown":           false,
+	}
+
+	for status, want := range cases {
+		if got := status.IsTerminal(); got != want {
+			t.Errorf("BackupStatus(%q).IsTerminal(): expected %v, got %v", status, want, got)
+		}
+	}
+}
+
+func TestRestoreStatusIsTerminal(t *testing.T) {
+	cases := map[RestoreStatus]bool{
+		RestoreStatusQueued:  false,
+		RestoreStatusRunning: false,
+		RestoreStatusDone:    true,
+		RestoreStatusFailed:  true,
+		RestoreStatusDeleted: true,
+		"unknown":            false,
+	}
+
+	for status, want := range cases {
+		if got := status.IsTerminal(); got != want {
+			t.Errorf("RestoreStatus(%q).IsTerminal(): expected %v, got %v", status, want, got)
+		}
+	}
+}
@@ -17,11 +17,12 @@ limitations under the License.
 package backupmanager
 
 import (
+	"context"
 	"fmt"
 	"net/http"
 )
 
-func (c *client) UpdateBackupConfig(r *UpdateBackupConfigRequest) (*UpdateBackupConfigResponse, error) {
+func (c *client) UpdateBackupConfig(ctx context.Context, r *UpdateBackupConfigRequest) (*UpdateBackupConfigResponse, error) {
 	if err := validateUpdateBackupConfigRequest(r); err != nil {
 		return nil, err
 	}
@@ -34,7 +35,10 @@ func (c *client) UpdateBackupConfig(r *UpdateBackupConfigRequest) (*UpdateBackup
 		CredentialsUpdatedByUser: r.CredentialsUpdatedByUser,
 	}
 
-	response, err := c.prepareAndDo(http.MethodPut, fullURL, nil, requestBody)
+	ctx, span := c.startSpan(ctx, operationUpdateBackupConfig, r, attributeInstanceID.String(r.InstanceID))
+	defer span.End()
+
+	response, err := c.prepareAndDo(ctx, http.MethodPut, fullURL, nil, requestBody)
 	if err != nil {
 		return nil, err
 	}
@@ -17,7 +17,12 @@ limitations under the License.
 package v2
 
 import (
+	"context"
+	"fmt"
+	"io"
 	"net/http"
+	"net/http/httptest"
+	"reflect"
 	"strings"
 	"testing"
 )
@@ -45,7 +50,7 @@ func TestBasicAuth(t *testing.T) {
 			BasicAuthConfig: tc.BasicAuthConfig,
 		}
 		client.doRequestFunc = addBasicAuthCheck(t, tc.name, tc.BasicAuthConfig, client.doRequestFunc)
-		_, _ = client.prepareAndDo(http.MethodGet, client.URL, nil, nil, nil)
+		_, _ = client.prepareAndDo(context.Background(), http.MethodGet, client.URL, nil, nil, nil)
 	}
 }
 
@@ -71,7 +76,7 @@ func TestBearerAuth(t *testing.T) {
 			BearerConfig: tc.BearerConfig,
 		}
 		client.doRequestFunc = addBearerAuthCheck(t, tc.name, tc.BearerConfig, client.doRequestFunc)
-		_, _ = client.prepareAndDo(http.MethodGet, client.URL, nil, nil, nil)
+		_, _ = client.prepareAndDo(context.Background(), http.MethodGet, client.URL, nil, nil, nil)
 	}
 }
 
@@ -130,3 +135,250 @@ func parseBearerToken(auth string) (token string, ok bool) {
 	}
 	return auth[len(prefix):], true
 }
+
+// newTokenServer starts an OAuth2 token endpoint that hands out the tokens
+// "token-1", "token-2", ... valid for the given number of seconds.
+func newTokenServer(t *testing.T, expiresIn int) (*httptest.Server, *int) {
+	issued := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseForm(); err != nil {
+			t.Errorf("cannot parse token request: %v", err)
+		}
+		if grantType := r.PostForm.Get("grant_type"); grantType != "client_credentials" {
+			t.Errorf("unexpected grant type %q", grantType)
+		}
+		if scope := r.PostForm.Get("scope"); scope != "brokers.read brokers.write" {
+			t.Errorf("unexpected scope %q", scope)
+		}
+		if id, secret, _ := r.BasicAuth(); id != "klutch" || secret != "SuchSecret" {
+			t.Errorf("unexpected client credentials %q:%q", id, secret)
+		}
+
+		issued++
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintf(w, `{"access_token":"token-%d","token_type":"bearer","expires_in":%d}`, issued, expiresIn)
+	}))
+	t.Cleanup(server.Close)
+	return server, &issued
+}
+
+func newOAuth2TestClient(t *testing.T, tokenURL string, brokerStatus func(token string) int) (*client, *[]string) {
+	klient := newTestClient(t, "oauth2", Version2_11(), false, httpChecks{}, httpReaction{})
+	klient.AuthConfig = &AuthConfig{
+		OAuth2Config: &OAuth2Config{
+			TokenURL:     tokenURL,
+			ClientID:     "klutch",
+			ClientSecret: "SuchSecret",
+			Scopes:       []string{"brokers.read", "brokers.write"},
+		},
+	}
+	klient.httpClient = http.DefaultClient
+	var err error
+	klient.tokenSource, err = newTokenSource(klient.AuthConfig.OAuth2Config)
+	if err != nil {
+		t.Fatalf("cannot create token source: %v", err)
+	}
+
+	var seen []string
+	klient.doRequestFunc = func(request *http.Request) (*http.Response, error) {
+		token, ok := parseBearerToken(request.Header.Get("Authorization"))
+		if !ok {
+			t.Errorf("expected bearer auth in request but none found")
+			return nil, errWalkingGhost
+		}
+		body, _ := io.ReadAll(request.Body)
+		seen = append(seen, token+" "+string(body))
+		return &http.Response{StatusCode: brokerStatus(token), Body: closer("{}")}, nil
+	}
+	return klient, &seen
+}
+
+func TestOAuth2Auth(t *testing.T) {
+	accept := func(string) int { return http.StatusOK }
+	revokedFirstToken := func(token string) int {
+		if token == "token-1" {
+			return http.StatusUnauthorized
+		}
+		return http.StatusOK
+	}
+	reject := func(string) int { return http.StatusUnauthorized }
+
+	cases := []struct {
+		name           string
+		expiresIn      int
+		brokerStatus   func(token string) int
+		requests       int
+		expectedStatus int
+		expectedSeen   []string
+		expectedIssued int
+	}{
+		{
+			name:           "token is reused until it expires",
+			expiresIn:      3600,
+			brokerStatus:   accept,
+			requests:       2,
+			expectedStatus: http.StatusOK,
+			expectedSeen:   []string{`token-1 {"n":0}`, `token-1 {"n":1}`},
+			expectedIssued: 1,
+		},
+		{
+			name:           "token is refreshed before it expires",
+			expiresIn:      10,
+			brokerStatus:   accept,
+			requests:       2,
+			expectedStatus: http.StatusOK,
+			expectedSeen:   []string{`token-1 {"n":0}`, `token-2 {"n":1}`},
+			expectedIssued: 2,
+		},
+		{
+			name:           "retried once with a new token on 401",
+			expiresIn:      3600,
+			brokerStatus:   revokedFirstToken,
+			requests:       2,
+			expectedStatus: http.StatusOK,
+			expectedSeen:   []string{`token-1 {"n":0}`, `token-2 {"n":0}`, `token-2 {"n":1}`},
+			expectedIssued: 2,
+		},
+		{
+			name:           "401 is returned after the retry",
+			expiresIn:      3600,
+			brokerStatus:   reject,
+			requests:       1,
+			expectedStatus: http.StatusUnauthorized,
+			expectedSeen:   []string{`token-1 {"n":0}`, `token-2 {"n":0}`},
+			expectedIssued: 2,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			tokenServer, issued := newTokenServer(t, tc.expiresIn)
+			klient, seen := newOAuth2TestClient(t, tokenServer.URL, tc.brokerStatus)
+
+			for i := 0; i < tc.requests; i++ {
+				response, err := klient.prepareAndDo(context.Background(), http.MethodPut, klient.URL, nil, map[string]int{"n": i}, nil)
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				if response.StatusCode != tc.expectedStatus {
+					t.Errorf("expected status %d, got %d", tc.expectedStatus, response.StatusCode)
+				}
+			}
+
+			if !reflect.DeepEqual(tc.expectedSeen, *seen) {
+				t.Errorf("expected broker requests %q, got %q", tc.expectedSeen, *seen)
+			}
+			if *issued != tc.expectedIssued {
+				t.Errorf("expected %d issued tokens, got %d", tc.expectedIssued, *issued)
+			}
+		})
+	}
+}
+
+func TestOAuth2TokenError(t *testing.T) {
+	tokenServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		fmt.Fprint(w, `{"error":"invalid_client"}`)
+	}))
+	defer tokenServer.Close()
+
+	klient, seen := newOAuth2TestClient(t, tokenServer.URL, func(string) int { return http.StatusOK })
+	_, err := klient.prepareAndDo(context.Background(), http.MethodGet, klient.URL, nil, nil, nil)
+	if err == nil || !strings.Contains(err.Error(), "invalid_client") {
+		t.Errorf("expected token error, got %v", err)
+	}
+	if len(*seen) != 0 {
+		t.Errorf("expected no request to the broker, got %q", *seen)
+	}
+}
+
+func TestTokenCache(t *testing.T) {
+	issued := 0
+	tokenServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		issued++
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintf(w, `{"access_token":"token-%d","token_type":"bearer","expires_in":3600}`, issued)
+	}))
+	defer tokenServer.Close()
+	cache := NewTokenCache()
+	newCachedClient := func(clientSecret string) *client {
+		config := DefaultClientConfiguration()
+		config.URL = "https://example.com"
+		config.TokenCache = cache
+		config.AuthConfig = &AuthConfig{OAuth2Config: &OAuth2Config{
+			TokenURL:     tokenServer.URL,
+			ClientID:     "klutch",
+			ClientSecret: clientSecret,
+			Scopes:       []string{"brokers.read", "brokers.write"},
+		}}
+		klient, err := NewClient(config)
+		if err != nil {
+			t.Fatalf("cannot create client: %v", err)
+		}
+		return klient.(*client)
+	}
+
+	cases := []struct {
+		name           string
+		clientSecret   string
+		expectedIssued int
+	}{
+		{name: "first client fetches a token", clientSecret: "SuchSecret", expectedIssued: 1},
+		{name: "second client reuses the token", clientSecret: "SuchSecret", expectedIssued: 1},
+		{name: "rotated secret drops the token", clientSecret: "RotatedSecret", expectedIssued: 2},
+	}
+
+	for _, tc := range cases {
+		klient := newCachedClient(tc.clientSecret)
+		if _, err := klient.tokenSource.Token(context.Background(), klient.httpClient); err != nil {
+			t.Fatalf("%v: unexpected error: %v", tc.name, err)
+		}
+		if issued != tc.expectedIssued {
+			t.Errorf("%v: expected %d issued tokens, got %d", tc.name, tc.expectedIssued, issued)
+		}
+	}
+}
+
+func TestNewClientAuthConfigValidation(t *testing.T) {
+	cases := []struct {
+		name       string
+		authConfig *AuthConfig
+		valid      bool
+	}{
+		{
+			name:       "empty",
+			authConfig: &AuthConfig{},
+		},
+		{
+			name: "basic auth and OAuth2",
+			authConfig: &AuthConfig{
+				BasicAuthConfig: &BasicAuthConfig{Username: "CoolUser", Password: "HardPassword"},
+				OAuth2Config:    &OAuth2Config{TokenURL: "https://uaa.example.com/oauth/token", ClientID: "klutch"},
+			},
+		},
+		{
+			name:       "OAuth2 without token URL",
+			authConfig: &AuthConfig{OAuth2Config: &OAuth2Config{ClientID: "klutch"}},
+		},
+		{
+			name:       "OAuth2",
+			authConfig: &AuthConfig{OAuth2Config: &OAuth2Config{TokenURL: "https://uaa.example.com/oauth/token", ClientID: "klutch"}},
+			valid:      true,
+		},
+	}
+
+	for _, tc := range cases {
+		config := DefaultClientConfiguration()
+		config.URL = "https://example.com"
+		config.AuthConfig = tc.authConfig
+
+		_, err := NewClient(config)
+		if tc.valid && err != nil {
+			t.Errorf("%v: unexpected error: %v", tc.name, err)
+		}
+		if !tc.valid && err == nil {
+			t.Errorf("%v: expected an error", tc.name)
+		}
+	}
+}
@@ -17,10 +17,9 @@ limitations under the License.
 package v2
 
 import (
+	"context"
 	"fmt"
 	"net/http"
-
-	"k8s.io/klog/v2"
 )
 
 // internal message body types
@@ -31,6 +30,8 @@ type bindRequestBody struct {
 	Parameters   map[string]interface{} `json:"parameters,omitempty"`
 	BindResource map[string]interface{} `json:"bind_resource,omitempty"`
 	Context      map[string]interface{} `json:"context,omitempty"`
+
+	PredecessorBindingID *string `json:"predecessor_binding_id,omitempty"`
 }
 
 type bindSuccessResponseBody struct {
@@ -46,7 +47,7 @@ const (
 	bindResourceRouteKey   = "route"
 )
 
-func (c *client) Bind(r *BindRequest) (*BindResponse, error) {
+func (c *client) Bind(ctx context.Context, r *BindRequest) (*BindResponse, error) {
 	if r.AcceptsIncomplete {
 		if err := c.validateClientVersionIsAtLeast(Version2_14()); err != nil {
 			return nil, AsyncBindingOperationsNotAllowedError{
@@ -55,6 +56,14 @@ func (c *client) Bind(r *BindRequest) (*BindResponse, error) {
 		}
 	}
 
+	if r.PredecessorBindingID != nil {
+		if err := c.validateClientVersionIsAtLeast(Version2_17()); err != nil {
+			return nil, BindingRotationNotAllowedError{
+				reason: err.Error(),
+			}
+		}
+	}
+
 	if err := validateBindRequest(r); err != nil {
 		return nil, err
 	}
@@ -72,10 +81,14 @@ func (c *client) Bind(r *BindRequest) (*BindResponse, error) {
 		Parameters: r.Parameters,
 	}
 
-	if c.APIVersion.AtLeast(Version2_13()) {
+	if c.Version().AtLeast(Version2_13()) {
 		requestBody.Context = r.Context
 	}
 
+	if c.Version().AtLeast(Version2_17()) {
+		requestBody.PredecessorBindingID = r.PredecessorBindingID
+	}
+
 	if r.BindResource != nil {
 		requestBody.BindResource = map[string]interface{}{}
 		if r.BindResource.AppGUID != nil {
@@ -86,7 +99,10 @@ func (c *client) Bind(r *BindRequest) (*BindResponse, error) {
 		}
 	}
 
-	response, err := c.prepareAndDo(http.MethodPut, fullURL, params, requestBody, r.OriginatingIdentity)
+	ctx, span := c.startSpan(ctx, operationBind, r, attributeInstanceID.String(r.InstanceID), attributeBindingID.String(r.BindingID))
+	defer span.End()
+
+	response, err := c.prepareAndDo(ctx, http.MethodPut, fullURL, params, requestBody, r.OriginatingIdentity)
 	if err != nil {
 		return nil, err
 	}
@@ -106,6 +122,9 @@ func (c *client) Bind(r *BindRequest) (*BindResponse, error) {
 		if !c.EnableAlphaFeatures {
 			userResponse.Endpoints = nil
 		}
+		if err := c.validateResponse(ctx, bindViolations(userResponse)); err != nil {
+			return nil, err
+		}
 
 		return userResponse, nil
 	case http.StatusAccepted:
@@ -134,10 +153,13 @@ func (c *client) Bind(r *BindRequest) (*BindResponse, error) {
 		}
 		if response.StatusCode == http.StatusAccepted {
 			if c.Verbose {
-				klog.Infof("broker %q: received asynchronous response", c.Name)
+				c.logger.Info("Received asynchronous response")
 			}
 			userResponse.Async = true
 		}
+		if err := c.validateResponse(ctx, bindViolations(userResponse)); err != nil {
+			return nil, err
+		}
 
 		return userResponse, nil
 	default:
@@ -0,0 +1,193 @@
+/*
+Copyright 2024 Klutch Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v2
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"reflect"
+	"sync"
+	"time"
+
+	"golang.org/x/sync/singleflight"
+)
+
+const (
+	etagHeader            = "ETag"
+	lastModifiedHeader    = "Last-Modified"
+	ifNoneMatchHeader     = "If-None-Match"
+	ifModifiedSinceHeader = "If-Modified-Since"
+
+	// catalogFetchTimeout is how long a CatalogCache waits for a broker to
+	// send its catalog.
+	catalogFetchTimeout = time.Minute
+)
+
+// CatalogChangeFunc is called with the URL of a broker and its new catalog
+// when a CatalogCache fetched a catalog that differs from the one it cached
+// before.
+type CatalogChangeFunc func(brokerURL string, catalog *CatalogResponse)
+
+// CatalogCache caches the catalogs of brokers and can be shared by any number
+// of clients, e.g. by every client a controller creates for the same broker.
+// Catalogs are cached per broker URL, credentials, API version and alpha
+// setting of a client.
+//
+// Once a cached catalog is older than the freshness of the cache, it is
+// requested again with the If-None-Match and If-Modified-Since headers of the
+// ETag and Last-Modified headers the broker sent with it, so that the broker
+// can answer 304 Not Modified instead of sending the whole catalog again.
+// Concurrent requests for the same catalog are sent to the broker only once.
+//
+// The cached catalogs are shared between clients and must not be modified.
+type CatalogCache struct {
+	freshness    time.Duration
+	fetchTimeout time.Duration
+	now          func() time.Time
+	group        singleflight.Group
+
+	mu       sync.Mutex
+	entries  map[string]*catalogCacheEntry
+	onChange []CatalogChangeFunc
+}
+
+// catalogCacheEntry is a catalog and the validators the broker sent with it.
+type catalogCacheEntry struct {
+	catalog      *CatalogResponse
+	etag         string
+	lastModified string
+	fetched      time.Time
+}
+
+// NewCatalogCache returns a CatalogCache that requests catalogs from the
+// broker again once they are older than freshness.
+func NewCatalogCache(freshness time.Duration) *CatalogCache {
+	return &CatalogCache{
+		freshness:    freshness,
+		fetchTimeout: catalogFetchTimeout,
+		now:          time.Now,
+		entries:      map[string]*catalogCacheEntry{},
+	}
+}
+
+// OnChange registers fn to be called whenever a catalog fetched from a broker
+// differs from the catalog cached for it before. It is not called for the
+// first catalog of a broker.
+func (c *CatalogCache) OnChange(fn CatalogChangeFunc) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.onChange = append(c.onChange, fn)
+}
+
+// get returns the cached catalog for key if it is fresh. Otherwise it fetches
+// the catalog with fetch, which is passed the stale entry, if any, so that it
+// can send a conditional request. Concurrent calls for the same key share one
+// call of fetch. The call keeps the values of the context of the first caller,
+// but is not canceled with it; it times out after the fetch timeout of the
+// cache instead. Every caller stops waiting when its own context is done.
+func (c *CatalogCache) get(ctx context.Context, key, brokerURL string, fetch func(ctx context.Context, cached *catalogCacheEntry) (*catalogCacheEntry, error)) (*CatalogResponse, error) {
+	c.mu.Lock()
+	cached := c.entries[key]
+	c.mu.Unlock()
+	if cached != nil && c.now().Sub(cached.fetched) < c.freshness {
+		return cached.catalog, nil
+	}
+
+	result := c.group.DoChan(key, func() (interface{}, error) {
+		fetchCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), c.fetchTimeout)
+		defer cancel()
+
+		entry, err := fetch(fetchCtx, cached)
+		if err != nil {
+			return nil, err
+		}
+		c.set(key, brokerURL, entry)
+		return entry.catalog, nil
+	})
+
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case r := <-result:
+		if r.Err != nil {
+			return nil, r.Err
+		}
+		return r.Val.(*CatalogResponse), nil
+	}
+}
+
+// set caches entry for key and notifies the change handlers if it replaces a
+// different catalog.
+func (c *CatalogCache) set(key, brokerURL string, entry *catalogCacheEntry) {
+	entry.fetched = c.now()
+
+	c.mu.Lock()
+	previous := c.entries[key]
+	c.entries[key] = entry
+	handlers := c.onChange
+	c.mu.Unlock()
+
+	if previous == nil || previous.catalog == entry.catalog || reflect.DeepEqual(previous.catalog, entry.catalog) {
+		return
+	}
+	for _, handler := range handlers {
+		handler(brokerURL, entry.catalog)
+	}
+}
+
+// catalogCacheKey identifies the catalog of the client in a CatalogCache.
+// Credentials are hashed, so that they are not kept in memory any longer than
+// the client keeps them.
+func (c *client) catalogCacheKey() string {
+	hash := sha256.New()
+	write := func(values ...string) {
+		for _, value := range values {
+			hash.Write([]byte(value))
+			hash.Write([]byte{0})
+		}
+	}
+	write(c.URL, c.Version().HeaderValue())
+	if c.EnableAlphaFeatures {
+		write("alpha")
+	}
+	if c.AuthConfig != nil {
+		switch {
+		case c.AuthConfig.BasicAuthConfig != nil:
+			write("basic", c.AuthConfig.BasicAuthConfig.Username, c.AuthConfig.BasicAuthConfig.Password)
+		case c.AuthConfig.BearerConfig != nil:
+			write("bearer", c.AuthConfig.BearerConfig.Token)
+		case c.AuthConfig.OAuth2Config != nil:
+			write("oauth2", c.AuthConfig.OAuth2Config.TokenURL, c.AuthConfig.OAuth2Config.ClientID, c.AuthConfig.OAuth2Config.ClientSecret)
+		}
+	}
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// requestHeaderContextKey is the context key of additional headers of a
+// request, such as the validators of a conditional request.
+type requestHeaderContextKey struct{}
+
+func withRequestHeader(ctx context.Context, header http.Header) context.Context {
+	return context.WithValue(ctx, requestHeaderContextKey{}, header)
+}
+
+func requestHeader(ctx context.Context) http.Header {
+	header, _ := ctx.Value(requestHeaderContextKey{}).(http.Header)
+	return header
+}
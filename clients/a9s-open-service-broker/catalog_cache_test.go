@@ -0,0 +1,255 @@
+/*
+Copyright 2024 Klutch Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v2
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+// catalogBroker serves body as catalog with the given validator header and
+// answers 304 Not Modified to requests that carry the matching conditional
+// header. It records the conditional header of every request.
+type catalogBroker struct {
+	mu          sync.Mutex
+	body        string
+	validator   string // ETag or Last-Modified
+	conditional string // If-None-Match or If-Modified-Since
+	value       string
+	requests    []string
+}
+
+func (b *catalogBroker) do(request *http.Request) (*http.Response, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	received := request.Header.Get(b.conditional)
+	b.requests = append(b.requests, received)
+	header := http.Header{}
+	header.Set(b.validator, b.value)
+	if received != "" && received == b.value {
+		return &http.Response{StatusCode: http.StatusNotModified, Header: header, Body: io.NopCloser(strings.NewReader(""))}, nil
+	}
+	return &http.Response{StatusCode: http.StatusOK, Header: header, Body: io.NopCloser(strings.NewReader(b.body))}, nil
+}
+
+func (b *catalogBroker) change(body, value string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.body, b.value = body, value
+}
+
+func TestCatalogCacheConditionalRequests(t *testing.T) {
+	cases := []struct {
+		name        string
+		validator   string
+		conditional string
+		values      []string
+	}{
+		{
+			name:        "etag",
+			validator:   etagHeader,
+			conditional: ifNoneMatchHeader,
+			values:      []string{`"v1"`, `"v2"`},
+		},
+		{
+			name:        "last modified",
+			validator:   lastModifiedHeader,
+			conditional: ifModifiedSinceHeader,
+			values:      []string{"Wed, 14 Oct 2026 07:28:00 GMT", "Thu, 15 Oct 2026 07:28:00 GMT"},
+		},
+	}
+
+	for _, tc := range cases {
+		now := time.Now()
+		catalogCache := NewCatalogCache(time.Minute)
+		catalogCache.now = func() time.Time { return now }
+		var changes []*CatalogResponse
+		catalogCache.OnChange(func(brokerURL string, catalog *CatalogResponse) {
+			if brokerURL != "https://example.com" {
+				t.Errorf("%s: unexpected broker URL %q", tc.name, brokerURL)
+			}
+			changes = append(changes, catalog)
+		})
+
+		broker := &catalogBroker{body: okCatalogBytes, validator: tc.validator, conditional: tc.conditional, value: tc.values[0]}
+		klient := newTestClient(t, tc.name, Version2_11(), false, httpChecks{}, httpReaction{})
+		klient.catalogCache = catalogCache
+		klient.doRequestFunc = broker.do
+
+		getCatalog := func(expected *CatalogResponse) {
+			t.Helper()
+			catalog, err := klient.GetCatalog(context.Background())
+			if err != nil {
+				t.Fatalf("%s: unexpected error: %v", tc.name, err)
+			}
+			if diff := cmp.Diff(expected, catalog); diff != "" {
+				t.Errorf("%s: -want, +got:\n%s", tc.name, diff)
+			}
+		}
+
+		getCatalog(okCatalogResponse())
+		getCatalog(okCatalogResponse())
+		if diff := cmp.Diff([]string{""}, broker.requests); diff != "" {
+			t.Errorf("%s: expected one unconditional request, -want, +got:\n%s", tc.name, diff)
+		}
+
+		// The stale catalog is requested again and not modified.
+		now = now.Add(2 * time.Minute)
+		getCatalog(okCatalogResponse())
+		getCatalog(okCatalogResponse())
+		if diff := cmp.Diff([]string{"", tc.values[0]}, broker.requests); diff != "" {
+			t.Errorf("%s: expected a conditional request, -want, +got:\n%s", tc.name, diff)
+		}
+
+		// The modified catalog replaces the cached one.
+		now = now.Add(2 * time.Minute)
+		broker.change(okCatalog2Bytes, tc.values[1])
+		getCatalog(okCatalog2Response())
+		if diff := cmp.Diff([]string{"", tc.values[0], tc.values[0]}, broker.requests); diff != "" {
+			t.Errorf("%s: expected a conditional request, -want, +got:\n%s", tc.name, diff)
+		}
+		if diff := cmp.Diff([]*CatalogResponse{okCatalog2Response()}, changes); diff != "" {
+			t.Errorf("%s: expected one change notification, -want, +got:\n%s", tc.name, diff)
+		}
+	}
+}
+
+func TestCatalogCacheSharedBetweenClients(t *testing.T) {
+	catalogCache := NewCatalogCache(time.Minute)
+
+	var requests atomic.Int32
+	release := make(chan struct{})
+	newClient := func(password string) *client {
+		klient := newTestClient(t, "shared", Version2_11(), false, httpChecks{}, httpReaction{})
+		klient.AuthConfig = &AuthConfig{BasicAuthConfig: &BasicAuthConfig{Username: "admin", Password: password}}
+		klient.catalogCache = catalogCache
+		klient.doRequestFunc = func(request *http.Request) (*http.Response, error) {
+			requests.Add(1)
+			<-release
+			return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(strings.NewReader(okCatalogBytes))}, nil
+		}
+		return klient
+	}
+
+	// Concurrent requests of clients with the same configuration are sent
+	// to the broker once.
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := newClient("hunter2").GetCatalog(context.Background()); err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+		}()
+	}
+	for requests.Load() == 0 {
+		time.Sleep(time.Millisecond)
+	}
+	time.Sleep(10 * time.Millisecond)
+	close(release)
+	wg.Wait()
+	if n := requests.Load(); n != 1 {
+		t.Errorf("expected 1 request, got %d", n)
+	}
+
+	if _, err := newClient("hunter2").GetCatalog(context.Background()); err != nil || requests.Load() != 1 {
+		t.Errorf("expected the catalog to be served from the cache, got %d requests (error %v)", requests.Load(), err)
+	}
+
+	// Clients with other credentials do not share the catalog.
+	if _, err := newClient("other").GetCatalog(context.Background()); err != nil || requests.Load() != 2 {
+		t.Errorf("expected the catalog to be requested, got %d requests (error %v)", requests.Load(), err)
+	}
+}
+
+func TestCatalogCacheFetchIsDetachedFromCallers(t *testing.T) {
+	catalogCache := NewCatalogCache(time.Minute)
+
+	requested := make(chan struct{})
+	release := make(chan struct{})
+	var requests atomic.Int32
+	var canceled atomic.Bool
+	klient := newTestClient(t, "detached", Version2_11(), false, httpChecks{}, httpReaction{})
+	klient.catalogCache = catalogCache
+	klient.doRequestFunc = func(request *http.Request) (*http.Response, error) {
+		requests.Add(1)
+		close(requested)
+		<-release
+		canceled.Store(request.Context().Err() != nil)
+		return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(strings.NewReader(okCatalogBytes))}, nil
+	}
+
+	// The first caller gives up while the catalog is fetched.
+	ctx, cancel := context.WithCancel(context.Background())
+	first := make(chan error)
+	go func() {
+		_, err := klient.GetCatalog(ctx)
+		first <- err
+	}()
+	<-requested
+
+	second := make(chan error)
+	go func() {
+		_, err := klient.GetCatalog(context.Background())
+		second <- err
+	}()
+
+	cancel()
+	if err := <-first; err != context.Canceled {
+		t.Errorf("expected the canceled caller to return %v, got %v", context.Canceled, err)
+	}
+
+	// The other caller still receives the catalog of the same request.
+	close(release)
+	if err := <-second; err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if canceled.Load() {
+		t.Error("expected the request to outlive the canceled caller")
+	}
+	if n := requests.Load(); n != 1 {
+		t.Errorf("expected 1 request, got %d", n)
+	}
+}
+
+func TestCatalogCacheFetchTimeout(t *testing.T) {
+	catalogCache := NewCatalogCache(time.Minute)
+	catalogCache.fetchTimeout = 10 * time.Millisecond
+
+	klient := newTestClient(t, "timeout", Version2_11(), false, httpChecks{}, httpReaction{})
+	klient.catalogCache = catalogCache
+	klient.doRequestFunc = func(request *http.Request) (*http.Response, error) {
+		<-request.Context().Done()
+		return nil, request.Context().Err()
+	}
+
+	_, err := klient.GetCatalog(context.Background())
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected %v, got %v", context.DeadlineExceeded, err)
+	}
+}
@@ -17,16 +17,20 @@ limitations under the License.
 package v2
 
 import (
+	"context"
 	"fmt"
 	"net/http"
 	"strings"
 )
 
-func (c *client) CheckAvailability(endpoint string) error {
+func (c *client) CheckAvailability(ctx context.Context, endpoint string) error {
 	endpointFmt := prepareEndpointFmtOrDefault(endpoint)
 
 	fullURL := fmt.Sprintf(endpointFmt, c.URL)
-	response, err := c.prepareAndDo(http.MethodHead, fullURL, nil, nil, nil)
+	ctx, span := c.startSpan(ctx, operationCheckAvailability, nil)
+	defer span.End()
+
+	response, err := c.prepareAndDo(ctx, http.MethodHead, fullURL, nil, nil, nil)
 
 	if err != nil {
 		return err
@@ -17,6 +17,7 @@ limitations under the License.
 package v2
 
 import (
+	"context"
 	"net/http"
 	"testing"
 
@@ -57,7 +58,7 @@ func TestCheckAvailability(t *testing.T) {
 
 		klient := newTestClient(t, tc.name, LatestAPIVersion(), false, httpChecks, tc.httpReaction)
 
-		err := klient.CheckAvailability("")
+		err := klient.CheckAvailability(context.Background(), "")
 
 		if err == nil && tc.expectedErrMessage != nil {
 			t.Fatalf("Expected check to fail with %v, but it did not", tc.expectedErrMessage)
@@ -109,7 +110,7 @@ func TestCheckAvailabilityEndpoint(t *testing.T) {
 	for _, tc := range cases {
 		klient := newTestClient(t, tc.name, LatestAPIVersion(), false, tc.httpChecks, tc.httpReaction)
 
-		err := klient.CheckAvailability(tc.endpoint)
+		err := klient.CheckAvailability(context.Background(), tc.endpoint)
 
 		if err == nil && tc.expectedErrMessage != nil {
 			t.Fatalf("Expected check to fail with %v, but it did not", tc.expectedErrMessage)
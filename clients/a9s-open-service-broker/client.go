@@ -18,6 +18,7 @@ package v2
 
 import (
 	"bytes"
+	"context"
 	"crypto/tls"
 	"crypto/x509"
 	"encoding/base64"
@@ -27,9 +28,15 @@ import (
 	"io"
 	"net"
 	"net/http"
+	"slices"
+	"strconv"
 	"strings"
+	"sync"
 	"time"
 
+	"github.com/go-logr/logr"
+	"go.opentelemetry.io/otel/trace"
+	"golang.org/x/oauth2"
 	"k8s.io/client-go/tools/cache"
 	"k8s.io/klog/v2"
 	"k8s.io/utils/pointer"
@@ -115,6 +122,12 @@ func NewClient(config *ClientConfiguration) (Client, error) {
 		return nil, errors.New("cannot specify root CAs and to skip TLS verification")
 	}
 	httpClient.Transport = transport
+	if config.WrapTransport != nil {
+		httpClient.Transport = config.WrapTransport(httpClient.Transport)
+	}
+	if config.Metrics != nil {
+		httpClient.Transport = config.Metrics.InstrumentedTransport(httpClient.Transport, config.ProviderConfig)
+	}
 
 	c := &client{
 		Name:                config.Name,
@@ -123,22 +136,73 @@ func NewClient(config *ClientConfiguration) (Client, error) {
 		EnableAlphaFeatures: config.EnableAlphaFeatures,
 		Verbose:             config.Verbose,
 		httpClient:          httpClient,
+		tracerProvider:      config.TracerProvider,
+		logger:              config.Logger,
+		interceptors:        config.Interceptors,
+		responseValidation:  config.ResponseValidation,
+	}
+	if c.logger.GetSink() == nil {
+		c.logger = klog.Background()
 	}
+	c.logger = c.logger.WithValues("broker", config.Name)
 	c.doRequestFunc = c.doRequest
 
+	c.endpoints = []string{c.URL}
+	for _, failoverURL := range config.FailoverURLs {
+		failoverURL = normalizeEndpoint(failoverURL)
+		if failoverURL != "" && !slices.Contains(c.endpoints, failoverURL) {
+			c.endpoints = append(c.endpoints, failoverURL)
+		}
+	}
+	c.endpointHealth = config.EndpointHealth
+	if c.endpointHealth == nil {
+		c.endpointHealth = NewEndpointHealth(defaultEndpointCooldown)
+	}
+	if config.RateLimiter != nil {
+		// The rate limiter is the outermost interceptor, so that it also
+		// respects 429 responses of interceptors.
+		c.interceptors = append([]Interceptor{config.RateLimiter.intercept}, c.interceptors...)
+	}
+
 	if config.AuthConfig != nil {
-		if config.AuthConfig.BasicAuthConfig == nil && config.AuthConfig.BearerConfig == nil {
+		configured := 0
+		for _, set := range []bool{
+			config.AuthConfig.BasicAuthConfig != nil,
+			config.AuthConfig.BearerConfig != nil,
+			config.AuthConfig.OAuth2Config != nil,
+		} {
+			if set {
+				configured++
+			}
+		}
+		if configured == 0 {
 			return nil, errors.New("non-nil AuthConfig cannot be empty")
 		}
-		if config.AuthConfig.BasicAuthConfig != nil && config.AuthConfig.BearerConfig != nil {
+		if configured > 1 {
 			return nil, errors.New("only one AuthConfig implementation must be set at a time")
 		}
 
+		if config.AuthConfig.OAuth2Config != nil {
+			var tokenSource *tokenSource
+			var err error
+			if config.TokenCache != nil {
+				tokenSource, err = config.TokenCache.tokenSource(config.AuthConfig.OAuth2Config)
+			} else {
+				tokenSource, err = newTokenSource(config.AuthConfig.OAuth2Config)
+			}
+			if err != nil {
+				return nil, err
+			}
+			c.tokenSource = tokenSource
+		}
+
 		c.AuthConfig = config.AuthConfig
 	}
 
 	// if the value of CacheFreshnessSeconds is explicitly set to 0 then the cache gets disabled.
-	if *config.CacheFreshnessSeconds == 0 {
+	if config.CatalogCache != nil {
+		c.catalogCache = config.CatalogCache
+	} else if *config.CacheFreshnessSeconds == 0 {
 		c.cache = nil
 	} else {
 		c.cache = cache.NewTTLStore(cacheKeyFunc, time.Duration(*config.CacheFreshnessSeconds)*time.Second)
@@ -170,9 +234,20 @@ type client struct {
 	EnableAlphaFeatures bool
 	Verbose             bool
 	cache               cache.Store
-
-	httpClient    *http.Client
-	doRequestFunc doRequestFunc
+	catalogCache        *CatalogCache
+	endpoints           []string
+	endpointHealth      *EndpointHealth
+	responseValidation  ResponseValidation
+
+	httpClient     *http.Client
+	doRequestFunc  doRequestFunc
+	tokenSource    *tokenSource
+	tracerProvider trace.TracerProvider
+	logger         logr.Logger
+	interceptors   []Interceptor
+
+	versionLock          sync.RWMutex
+	negotiatedAPIVersion *APIVersion
 }
 
 var _ Client = &client{}
@@ -196,12 +271,15 @@ const (
 // prepareAndDo prepares a request for the given method, URL, and
 // message body, and executes the request, returning an http.Response or an
 // error.  Errors returned from this function represent http-layer errors and
-// not errors in the Open Service Broker API.
-func (c *client) prepareAndDo(method, URL string, params map[string]string, body interface{}, originatingIdentity *OriginatingIdentity) (*http.Response, error) {
+// not errors in the Open Service Broker API.  The request is bound to ctx, so
+// cancelling ctx or exceeding its deadline aborts the request in flight.
+func (c *client) prepareAndDo(ctx context.Context, method, URL string, params map[string]string, body interface{}, originatingIdentity *OriginatingIdentity) (*http.Response, error) {
 	var bodyReader io.Reader
+	var bodyBytes []byte
 
 	if body != nil {
-		bodyBytes, err := json.Marshal(body)
+		var err error
+		bodyBytes, err = json.Marshal(body)
 		if err != nil {
 			return nil, err
 		}
@@ -209,27 +287,26 @@ func (c *client) prepareAndDo(method, URL string, params map[string]string, body
 		bodyReader = bytes.NewReader(bodyBytes)
 	}
 
-	request, err := http.NewRequest(method, URL, bodyReader)
+	request, err := http.NewRequestWithContext(ctx, method, URL, bodyReader)
 	if err != nil {
 		return nil, err
 	}
 
-	request.Header.Set(APIVersionHeader, c.APIVersion.HeaderValue())
+	for name, values := range requestHeader(ctx) {
+		request.Header[name] = values
+	}
+	version := c.requestAPIVersion(ctx)
+	request.Header.Set(APIVersionHeader, version.HeaderValue())
 	if bodyReader != nil {
 		request.Header.Set(contentType, jsonType)
 	}
 
-	if c.AuthConfig != nil {
-		if c.AuthConfig.BasicAuthConfig != nil {
-			basicAuth := c.AuthConfig.BasicAuthConfig
-			request.SetBasicAuth(basicAuth.Username, basicAuth.Password)
-		} else if c.AuthConfig.BearerConfig != nil {
-			bearer := c.AuthConfig.BearerConfig
-			request.Header.Set("Authorization", "Bearer "+bearer.Token)
-		}
+	token, err := c.setAuthorization(ctx, request)
+	if err != nil {
+		return nil, err
 	}
 
-	if c.APIVersion.AtLeast(Version2_13()) && originatingIdentity != nil {
+	if version.AtLeast(Version2_13()) && originatingIdentity != nil {
 		headerValue, err := buildOriginatingIdentityHeaderValue(originatingIdentity)
 		if err != nil {
 			return nil, err
@@ -245,11 +322,77 @@ func (c *client) prepareAndDo(method, URL string, params map[string]string, body
 		request.URL.RawQuery = q.Encode()
 	}
 
+	traceRequest(request)
+
 	if c.Verbose {
-		klog.Infof("broker %q: doing request to %q", c.Name, URL)
+		c.logger.Info("Sending request",
+			"method", method,
+			"url", request.URL.String(),
+			"header", RedactHeader(request.Header),
+			"body", RedactBody(jsonType, bodyBytes))
+	}
+
+	response, err := c.send(request)
+	if err != nil || token == nil || response.StatusCode != http.StatusUnauthorized {
+		return response, err
+	}
+
+	// The broker may have revoked the access token before it expired. Retry
+	// once with a new token.
+	_ = drainReader(response.Body)
+	response.Body.Close()
+	c.tokenSource.Invalidate(token)
+
+	retry := request.Clone(ctx)
+	if request.GetBody != nil {
+		if retry.Body, err = request.GetBody(); err != nil {
+			return nil, err
+		}
+	}
+	if _, err := c.setAuthorization(ctx, retry); err != nil {
+		return nil, err
+	}
+
+	return c.send(retry)
+}
+
+// send executes the request and records its outcome on the span of the
+// request context.
+func (c *client) send(request *http.Request) (*http.Response, error) {
+	if len(c.endpoints) > 1 {
+		return c.sendWithFailover(request)
+	}
+	response, err := c.do(request)
+	recordResponse(request, response, err)
+	return response, err
+}
+
+// setAuthorization sets the credentials configured in AuthConfig on the
+// request. If the client uses OAuth2 it returns the access token it used.
+func (c *client) setAuthorization(ctx context.Context, request *http.Request) (*oauth2.Token, error) {
+	if c.AuthConfig == nil {
+		return nil, nil
+	}
+
+	switch {
+	case c.AuthConfig.BasicAuthConfig != nil:
+		basicAuth := c.AuthConfig.BasicAuthConfig
+		request.SetBasicAuth(basicAuth.Username, basicAuth.Password)
+	case c.AuthConfig.BearerConfig != nil:
+		bearer := c.AuthConfig.BearerConfig
+		request.Header.Set("Authorization", "Bearer "+bearer.Token)
+	case c.tokenSource != nil:
+		// Tokens are fetched with the same transport, and therefore the same
+		// TLS configuration, as requests to the broker.
+		token, err := c.tokenSource.Token(ctx, c.httpClient)
+		if err != nil {
+			return nil, err
+		}
+		token.SetAuthHeader(request)
+		return token, nil
 	}
 
-	return c.doRequestFunc(request)
+	return nil, nil
 }
 
 func (c *client) doRequest(request *http.Request) (*http.Response, error) {
@@ -265,7 +408,10 @@ func (c *client) unmarshalResponse(response *http.Response, obj interface{}) err
 	}
 
 	if c.Verbose {
-		klog.Infof("broker %q: response body: %v, type: %T", c.Name, string(body), obj)
+		c.logger.Info("Received response",
+			"status", response.StatusCode,
+			"body", RedactBody(response.Header.Get(contentType), body),
+			"type", fmt.Sprintf("%T", obj))
 	}
 
 	err = json.Unmarshal(body, obj)
@@ -279,7 +425,7 @@ func (c *client) unmarshalResponse(response *http.Response, obj interface{}) err
 // handleFailureResponse returns an HTTPStatusCodeError for the given
 // response.
 func (c *client) handleFailureResponse(response *http.Response) error {
-	klog.Info("handling failure responses")
+	c.logger.V(1).Info("Handling failure response", "status", response.StatusCode)
 
 	httpErr := HTTPStatusCodeError{
 		StatusCode: response.StatusCode,
@@ -328,12 +474,12 @@ func isValidJSON(s string) error {
 // validateClientVersionIsAtLeast returns an error if client version is not at
 // least the specified version
 func (c *client) validateClientVersionIsAtLeast(version APIVersion) error {
-	if !c.APIVersion.AtLeast(version) {
+	if current := c.Version(); !current.AtLeast(version) {
 		return OperationNotAllowedError{
 			reason: fmt.Sprintf(
 				"must have API version >= %s. Current: %s",
 				version,
-				c.APIVersion.label,
+				current.label,
 			),
 		}
 	}
@@ -341,6 +487,20 @@ func (c *client) validateClientVersionIsAtLeast(version APIVersion) error {
 	return nil
 }
 
+// pollDelayFromHeader returns the delay a broker asked the client to wait
+// before polling again, as announced through the PollingDelayHeader of a last
+// operation response. Only the delay-seconds form of the header is
+// understood; nil is returned if the header is absent, malformed or not
+// positive.
+func pollDelayFromHeader(header http.Header) *time.Duration {
+	delay, err := strconv.Atoi(header.Get(PollingDelayHeader))
+	if err != nil || delay <= 0 {
+		return nil
+	}
+	duration := time.Duration(delay) * time.Second
+	return &duration
+}
+
 // drainReader reads and discards the remaining data in reader (for example
 // response body data) For HTTP this ensures that the http connection
 // could be reused for another request if the keepalive is enabled.
@@ -18,15 +18,18 @@ package v2
 
 import (
 	"bytes"
+	"context"
 	"crypto/rand"
 	"crypto/rsa"
 	"crypto/x509"
 	"crypto/x509/pkix"
 	"encoding/pem"
+	"errors"
 	"fmt"
 	"io"
 	"math/big"
 	"net/http"
+	"net/http/httptest"
 	"reflect"
 	"testing"
 	"time"
@@ -410,6 +413,39 @@ func TestCreateClient(t *testing.T) {
 	}
 }
 
+func TestPrepareAndDoHonoursContext(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-release:
+		case <-r.Context().Done():
+		}
+	}))
+	defer server.Close()
+	defer close(release)
+
+	c, err := NewClient(&ClientConfiguration{
+		URL:            server.URL,
+		APIVersion:     LatestAPIVersion(),
+		TimeoutSeconds: 30,
+	})
+	if err != nil {
+		t.Fatalf("NewClient(...): unexpected error: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	_, err = c.GetCatalog(ctx)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("GetCatalog(...): want %v, got %v", context.DeadlineExceeded, err)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("GetCatalog(...): request was not aborted by the context, took %v", elapsed)
+	}
+}
+
 // generateTestCACert creates a minimal self-signed CA certificate in PEM format for use in tests.
 func generateTestCACert(t *testing.T) []byte {
 	t.Helper()
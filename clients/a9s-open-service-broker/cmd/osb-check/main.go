@@ -0,0 +1,159 @@
+/*
+Copyright 2024 Klutch Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Command osb-check runs a provision, update, bind, unbind and deprovision
+// lifecycle against a broker and prints a conformance report. It exits with
+// status 1 if the broker does not conform and with status 2 if the check
+// could not be run.
+//
+// Check a broker with:
+//
+//	OSB_PASSWORD=... osb-check -url https://broker.example.com -username admin -service a9s-postgresql13
+//
+// or try the command against the in-process broker stand-in of the osbtest
+// package with:
+//
+//	osb-check -stand-in
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"time"
+
+	v2 "github.com/anynines/klutchio/clients/a9s-open-service-broker"
+	"github.com/anynines/klutchio/clients/a9s-open-service-broker/conformance"
+	"github.com/anynines/klutchio/clients/a9s-open-service-broker/osbtest"
+)
+
+func main() {
+	os.Exit(run())
+}
+
+func run() int {
+	var (
+		url          = flag.String("url", "", "The URL of the broker.")
+		username     = flag.String("username", os.Getenv("OSB_USERNAME"), "The basic auth username of the broker. Defaults to $OSB_USERNAME.")
+		password     = flag.String("password", "", "The basic auth password of the broker. Defaults to $OSB_PASSWORD.")
+		insecure     = flag.Bool("insecure", false, "Skip the verification of the TLS certificate of the broker.")
+		apiVersion   = flag.String("api-version", v2.LatestAPIVersion().HeaderValue(), "The highest API version to negotiate with the broker.")
+		service      = flag.String("service", "", "The name, or a prefix of the name, of the service to check. Defaults to the first bindable service.")
+		plan         = flag.String("plan", "", "The name of the plan to provision. Defaults to the first plan of the service.")
+		updatePlan   = flag.String("update-plan", "", "The name of the plan to update the instance to. Defaults to another plan if the service allows plan updates.")
+		parameters   = flag.String("parameters", "", "The parameters to provision the instance with, as JSON object.")
+		pollInterval = flag.Duration("poll-interval", 5*time.Second, "The initial interval between two polls of an asynchronous operation.")
+		timeout      = flag.Duration("timeout", 30*time.Minute, "The maximum duration of a single asynchronous operation.")
+		output       = flag.String("output", "text", "The format of the report, text or json.")
+		standIn      = flag.Bool("stand-in", false, "Check the in-process broker stand-in of the osbtest package instead of -url.")
+	)
+	flag.Parse()
+
+	if *password == "" {
+		*password = os.Getenv("OSB_PASSWORD")
+	}
+	if *output != "text" && *output != "json" {
+		return usageError("-output must be text or json, got %q", *output)
+	}
+
+	version, err := v2.ParseAPIVersion(*apiVersion)
+	if err != nil {
+		return usageError("invalid -api-version: %v", err)
+	}
+
+	options := conformance.Options{
+		Service:    *service,
+		Plan:       *plan,
+		UpdatePlan: *updatePlan,
+		Wait:       v2.DefaultWaitOptions(),
+	}
+	options.Wait.InitialInterval = *pollInterval
+	options.Wait.MaxDuration = *timeout
+	// Unbinding and deprovisioning are two operations.
+	options.CleanupTimeout = 2 * *timeout
+	if *parameters != "" {
+		if err := json.Unmarshal([]byte(*parameters), &options.Parameters); err != nil {
+			return usageError("invalid -parameters: %v", err)
+		}
+	}
+
+	var config *v2.ClientConfiguration
+	switch {
+	case *standIn:
+		broker := osbtest.NewBroker(osbtest.Options{
+			Async:          true,
+			PollsUntilDone: 1,
+			RetryAfter:     time.Second,
+		})
+		defer broker.Close()
+		config = broker.ClientConfiguration()
+	case *url != "":
+		config = v2.DefaultClientConfiguration()
+		config.Name = "osb-check"
+		config.URL = *url
+		config.Insecure = *insecure
+		if *username != "" || *password != "" {
+			config.AuthConfig = &v2.AuthConfig{
+				BasicAuthConfig: &v2.BasicAuthConfig{Username: *username, Password: *password},
+			}
+		}
+	default:
+		return usageError("either -url or -stand-in is required")
+	}
+	config.APIVersion = version
+	// Every response is validated against the specification.
+	config.ResponseValidation = v2.ResponseValidationStrict
+
+	client, err := v2.NewClient(config)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "cannot create client: %v\n", err)
+		return 2
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	report, err := conformance.Run(ctx, client, options)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "check interrupted: %v\n", err)
+	}
+
+	if *output == "json" {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		err = encoder.Encode(report)
+	} else {
+		err = report.WriteText(os.Stdout)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "cannot write report: %v\n", err)
+		return 2
+	}
+
+	if !report.Passed() {
+		return 1
+	}
+	return 0
+}
+
+func usageError(format string, args ...interface{}) int {
+	fmt.Fprintf(os.Stderr, format+"\n\n", args...)
+	flag.Usage()
+	return 2
+}
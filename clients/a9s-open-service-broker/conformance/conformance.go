@@ -0,0 +1,569 @@
+/*
+Copyright 2024 Klutch Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package conformance checks whether a broker behaves the way the client and
+// the provider built on top of it expect. It runs a scripted provision,
+// update, bind, unbind and deprovision lifecycle against the broker and
+// reports the outcome of every step, including the a9s specific quirks a new
+// data service may or may not have.
+package conformance
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/google/uuid"
+
+	v2 "github.com/anynines/klutchio/clients/a9s-open-service-broker"
+)
+
+// Options configures a conformance check.
+type Options struct {
+	// Service is the name, or a prefix of the name, of the service to check.
+	// a9s brokers append a unique suffix such as -ms-1687789907 to the names
+	// of their services. If empty, the first bindable service is checked.
+	Service string
+	// Plan is the name of the plan to provision. If empty, the first plan of
+	// the service is used.
+	Plan string
+	// UpdatePlan is the name of the plan the instance is updated to. If
+	// empty, the instance is updated to another plan of the service if the
+	// service allows plan updates, and with its parameters otherwise.
+	UpdatePlan string
+	// Parameters are the parameters the instance is provisioned with.
+	Parameters map[string]interface{}
+	// OrganizationGUID and SpaceGUID are sent with the provision request. If
+	// empty, "osb-check" is sent.
+	OrganizationGUID string
+	SpaceGUID        string
+	// Wait configures how asynchronous operations are polled. If nil,
+	// v2.DefaultWaitOptions is used.
+	Wait *v2.WaitOptions
+	// CleanupTimeout limits how long unbinding and deprovisioning may take.
+	// They run even if the context passed to Run is done, so that an
+	// interrupted check does not leave its instance behind. If 0,
+	// DefaultCleanupTimeout is used.
+	CleanupTimeout time.Duration
+}
+
+// DefaultCleanupTimeout is the default of Options.CleanupTimeout.
+const DefaultCleanupTimeout = 30 * time.Minute
+
+// Status is the outcome of a single check.
+type Status string
+
+// These are the outcomes of a check.
+const (
+	// StatusPassed means the broker behaved as expected.
+	StatusPassed Status = "PASS"
+	// StatusWarning means the broker deviates from the Open Service Broker
+	// API in a way the client copes with, e.g. a9s specific behavior.
+	StatusWarning Status = "WARN"
+	// StatusFailed means the broker deviates from the Open Service Broker
+	// API in a way the client or the provider cannot cope with.
+	StatusFailed Status = "FAIL"
+	// StatusSkipped means the check was not run, because the broker does not
+	// support it or a step it depends on failed.
+	StatusSkipped Status = "SKIP"
+)
+
+// Check is the outcome of a single step of a conformance check.
+type Check struct {
+	// Name identifies the step, e.g. "provision".
+	Name string `json:"name"`
+	// Status is the outcome of the step.
+	Status Status `json:"status"`
+	// Message explains the outcome, if there is anything to explain.
+	Message string `json:"message,omitempty"`
+	// Duration is how long the step took, including polling its
+	// asynchronous operation.
+	Duration time.Duration `json:"duration"`
+}
+
+// Report is the outcome of a conformance check.
+type Report struct {
+	// APIVersion is the API version the broker was checked with.
+	APIVersion string `json:"apiVersion"`
+	// Service and Plan are the names of the service and plan that were
+	// provisioned.
+	Service string `json:"service,omitempty"`
+	Plan    string `json:"plan,omitempty"`
+	// InstanceID and BindingID are the IDs of the service instance and
+	// binding the check created.
+	InstanceID string `json:"instanceID"`
+	BindingID  string `json:"bindingID"`
+	// LeakedInstanceID and LeakedBindingID are the IDs of the service
+	// instance and binding the check could not delete. They have to be
+	// deleted by hand.
+	LeakedInstanceID string `json:"leakedInstanceID,omitempty"`
+	LeakedBindingID  string `json:"leakedBindingID,omitempty"`
+	// Checks lists the outcome of every step, in order.
+	Checks []Check `json:"checks"`
+}
+
+// Passed returns whether no check of the report failed.
+func (r *Report) Passed() bool {
+	for _, check := range r.Checks {
+		if check.Status == StatusFailed {
+			return false
+		}
+	}
+	return true
+}
+
+// WriteText writes the report as a table, one check per line, followed by a
+// summary line.
+func (r *Report) WriteText(w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintf(tw, "Service:\t%s\n", r.Service)
+	fmt.Fprintf(tw, "Plan:\t%s\n", r.Plan)
+	fmt.Fprintf(tw, "API version:\t%s\n", r.APIVersion)
+	fmt.Fprintf(tw, "Instance ID:\t%s\n", r.InstanceID)
+	fmt.Fprintln(tw)
+	fmt.Fprintln(tw, "CHECK\tRESULT\tDURATION\tMESSAGE")
+	for _, check := range r.Checks {
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", check.Name, check.Status, check.Duration.Round(time.Millisecond), check.Message)
+	}
+	fmt.Fprintln(tw)
+	if r.LeakedBindingID != "" {
+		fmt.Fprintf(tw, "Leaked binding:\t%s\n", r.LeakedBindingID)
+	}
+	if r.LeakedInstanceID != "" {
+		fmt.Fprintf(tw, "Leaked instance:\t%s\n", r.LeakedInstanceID)
+	}
+	if r.Passed() {
+		fmt.Fprintln(tw, "The broker conforms.")
+	} else {
+		fmt.Fprintln(tw, "The broker does not conform.")
+	}
+	return tw.Flush()
+}
+
+// suffix matches the unique suffix a9s brokers append to service names.
+var suffix = regexp.MustCompile(`-ms-\d+$`)
+
+// checker runs the steps of a conformance check and records their outcome.
+type checker struct {
+	client  v2.Client
+	options Options
+	report  *Report
+
+	service      v2.Service
+	plan         v2.Plan
+	instanceDone bool
+	bindingDone  bool
+
+	// instanceOperation and bindingOperation are the asynchronous
+	// operations whose polling was interrupted because ctx was done.
+	instanceOperation *interruptedOperation
+	bindingOperation  *interruptedOperation
+}
+
+// interruptedOperation is an asynchronous operation that may still be in
+// progress on the broker.
+type interruptedOperation struct {
+	key *v2.OperationKey
+}
+
+// Run runs a conformance check against the broker the client talks to. The
+// client should validate responses strictly, see
+// v2.ClientConfiguration.ResponseValidation, so that every response is
+// checked against the specification as well.
+//
+// Run tries to unbind and deprovision what it created even if a step in
+// between fails or ctx is done, see Options.CleanupTimeout. What it cannot
+// delete is recorded in the report as leaked. Run returns an error only if
+// ctx is done; failing steps are recorded in the report.
+func Run(ctx context.Context, client v2.Client, options Options) (*Report, error) {
+	if options.OrganizationGUID == "" {
+		options.OrganizationGUID = "osb-check"
+	}
+	if options.SpaceGUID == "" {
+		options.SpaceGUID = "osb-check"
+	}
+	if options.CleanupTimeout == 0 {
+		options.CleanupTimeout = DefaultCleanupTimeout
+	}
+
+	c := &checker{
+		client:  client,
+		options: options,
+		report: &Report{
+			InstanceID: uuid.NewString(),
+			BindingID:  uuid.NewString(),
+		},
+	}
+
+	c.step(ctx, "negotiate API version", c.negotiate)
+	c.report.APIVersion = client.Version().HeaderValue()
+	if c.step(ctx, "catalog", c.catalog) {
+		c.step(ctx, "provision", c.provision)
+	} else {
+		c.skip("provision", "no service to provision")
+	}
+
+	if c.instanceDone {
+		c.step(ctx, "get instance", c.getInstance)
+		c.step(ctx, "update", c.update)
+		if c.service.Bindable {
+			c.step(ctx, "bind", c.bind)
+		} else {
+			c.skip("bind", "service is not bindable")
+		}
+
+		cleanupCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), c.options.CleanupTimeout)
+		defer cancel()
+		c.finishInterruptedOperations(cleanupCtx)
+		if c.bindingDone {
+			if !c.step(cleanupCtx, "unbind", c.unbind) {
+				c.report.LeakedBindingID = c.report.BindingID
+			}
+		} else {
+			c.skip("unbind", "no binding")
+		}
+		if c.step(cleanupCtx, "deprovision", c.deprovision) {
+			c.step(cleanupCtx, "deprovision again", c.deprovisionAgain)
+		} else {
+			c.report.LeakedInstanceID = c.report.InstanceID
+			c.skip("deprovision again", "deprovision failed")
+		}
+	} else {
+		for _, name := range []string{"get instance", "update", "bind", "unbind", "deprovision", "deprovision again"} {
+			c.skip(name, "no service instance")
+		}
+	}
+
+	return c.report, ctx.Err()
+}
+
+// step runs a step and records its outcome. A step returns its status and an
+// optional message, or an error if it failed. step returns whether the step
+// did not fail.
+func (c *checker) step(ctx context.Context, name string, run func(context.Context) (Status, string, error)) bool {
+	start := time.Now()
+	status, message, err := run(ctx)
+	if err != nil {
+		status, message = StatusFailed, err.Error()
+	}
+	c.report.Checks = append(c.report.Checks, Check{
+		Name:     name,
+		Status:   status,
+		Message:  message,
+		Duration: time.Since(start),
+	})
+	return status != StatusFailed
+}
+
+func (c *checker) skip(name, reason string) {
+	c.report.Checks = append(c.report.Checks, Check{Name: name, Status: StatusSkipped, Message: reason})
+}
+
+// negotiate checks that the broker accepts one of the API versions up to the
+// configured one. Every later step uses the negotiated version.
+func (c *checker) negotiate(ctx context.Context) (Status, string, error) {
+	version, err := c.client.NegotiateAPIVersion(ctx)
+	if err != nil {
+		return "", "", fmt.Errorf("cannot negotiate API version: %w", err)
+	}
+	return StatusPassed, fmt.Sprintf("broker supports API version %s", version.HeaderValue()), nil
+}
+
+func (c *checker) catalog(ctx context.Context) (Status, string, error) {
+	catalog, err := c.client.GetCatalog(ctx)
+	if err != nil {
+		return "", "", fmt.Errorf("cannot get catalog: %w", err)
+	}
+
+	found := false
+	for _, service := range catalog.Services {
+		if c.options.Service == "" && service.Bindable || c.options.Service != "" && strings.HasPrefix(service.Name, c.options.Service) {
+			c.service, found = service, true
+			break
+		}
+	}
+	if !found && c.options.Service == "" && len(catalog.Services) > 0 {
+		c.service, found = catalog.Services[0], true
+	}
+	if !found {
+		return "", "", fmt.Errorf("catalog has no service named %q", c.options.Service)
+	}
+	c.report.Service = c.service.Name
+
+	found = false
+	for _, plan := range c.service.Plans {
+		if c.options.Plan == "" || plan.Name == c.options.Plan {
+			c.plan, found = plan, true
+			break
+		}
+	}
+	if !found {
+		return "", "", fmt.Errorf("service %q has no plan named %q", c.service.Name, c.options.Plan)
+	}
+	c.report.Plan = c.plan.Name
+
+	if suffix.MatchString(c.service.Name) {
+		return StatusWarning, fmt.Sprintf("service name %q carries the broker specific suffix %q, services must be matched by prefix", c.service.Name, suffix.FindString(c.service.Name)), nil
+	}
+	return StatusPassed, "", nil
+}
+
+func (c *checker) provision(ctx context.Context) (Status, string, error) {
+	response, err := c.client.ProvisionInstance(ctx, &v2.ProvisionRequest{
+		InstanceID:        c.report.InstanceID,
+		AcceptsIncomplete: true,
+		ServiceID:         c.service.ID,
+		PlanID:            c.plan.ID,
+		OrganizationGUID:  c.options.OrganizationGUID,
+		SpaceGUID:         c.options.SpaceGUID,
+		Parameters:        c.options.Parameters,
+	})
+	if err != nil {
+		return "", "", fmt.Errorf("cannot provision instance: %w", err)
+	}
+	// The instance may exist even if the operation fails, so it is
+	// deprovisioned either way.
+	c.instanceDone = true
+
+	if response.Async {
+		if err := c.waitForOperation(ctx, response.OperationKey); err != nil {
+			return "", "", fmt.Errorf("provision operation: %w", err)
+		}
+		return asyncStatus(response.OperationKey)
+	}
+	return StatusPassed, "", nil
+}
+
+// getInstance checks the a9s specific /instances endpoint, which is not part
+// of the Open Service Broker API.
+func (c *checker) getInstance(ctx context.Context) (Status, string, error) {
+	instance, err := c.client.GetInstance(ctx, &v2.GetInstanceRequest{InstanceID: c.report.InstanceID})
+	if err != nil {
+		if httpErr, ok := v2.IsHTTPError(err); ok && httpErr.StatusCode == 404 {
+			return StatusWarning, "broker does not serve the a9s specific /instances endpoint", nil
+		}
+		return "", "", fmt.Errorf("cannot get instance: %w", err)
+	}
+	if instance.GUIDAtTenant != c.report.InstanceID {
+		return "", "", fmt.Errorf("instance has guid_at_tenant %q, want %q", instance.GUIDAtTenant, c.report.InstanceID)
+	}
+	if instance.PlanGUID != c.plan.ID {
+		return "", "", fmt.Errorf("instance has plan %q, want %q", instance.PlanGUID, c.plan.ID)
+	}
+	return StatusPassed, fmt.Sprintf("instance is in state %q", instance.State), nil
+}
+
+func (c *checker) update(ctx context.Context) (Status, string, error) {
+	request := &v2.UpdateInstanceRequest{
+		InstanceID:        c.report.InstanceID,
+		AcceptsIncomplete: true,
+		ServiceID:         c.service.ID,
+		Parameters:        c.options.Parameters,
+		PreviousValues:    &v2.PreviousValues{PlanID: c.plan.ID},
+	}
+
+	target := c.updatePlan()
+	if target != nil {
+		request.PlanID = &target.ID
+	}
+
+	response, err := c.client.UpdateInstance(ctx, request)
+	if err != nil {
+		return "", "", fmt.Errorf("cannot update instance: %w", err)
+	}
+	if response.Async {
+		if err := c.waitForOperation(ctx, response.OperationKey); err != nil {
+			return "", "", fmt.Errorf("update operation: %w", err)
+		}
+	}
+	if target != nil {
+		c.plan = *target
+		c.report.Plan = target.Name
+	}
+	if response.Async {
+		return asyncStatus(response.OperationKey)
+	}
+	return StatusPassed, "", nil
+}
+
+// updatePlan returns the plan the instance is updated to, or nil if it keeps
+// its plan.
+func (c *checker) updatePlan() *v2.Plan {
+	for i, plan := range c.service.Plans {
+		if c.options.UpdatePlan != "" && plan.Name == c.options.UpdatePlan {
+			return &c.service.Plans[i]
+		}
+	}
+	if c.options.UpdatePlan != "" || c.service.PlanUpdatable == nil || !*c.service.PlanUpdatable {
+		return nil
+	}
+	for i, plan := range c.service.Plans {
+		if plan.ID != c.plan.ID {
+			return &c.service.Plans[i]
+		}
+	}
+	return nil
+}
+
+func (c *checker) bind(ctx context.Context) (Status, string, error) {
+	async := c.client.Capabilities().AsyncBindings
+	response, err := c.client.Bind(ctx, &v2.BindRequest{
+		BindingID:         c.report.BindingID,
+		InstanceID:        c.report.InstanceID,
+		AcceptsIncomplete: async,
+		ServiceID:         c.service.ID,
+		PlanID:            c.plan.ID,
+	})
+	if err != nil {
+		return "", "", fmt.Errorf("cannot bind: %w", err)
+	}
+	c.bindingDone = true
+
+	credentials := response.Credentials
+	if response.Async {
+		if err := c.waitForBindingOperation(ctx, response.OperationKey); err != nil {
+			return "", "", fmt.Errorf("bind operation: %w", err)
+		}
+		if !c.client.Capabilities().GetBinding {
+			return StatusWarning, "cannot fetch the credentials of an asynchronous binding with this API version", nil
+		}
+		binding, err := c.client.GetBinding(ctx, &v2.GetBindingRequest{InstanceID: c.report.InstanceID, BindingID: c.report.BindingID})
+		if err != nil {
+			return "", "", fmt.Errorf("cannot get binding: %w", err)
+		}
+		credentials = binding.Credentials
+	}
+
+	if len(credentials) == 0 {
+		return StatusWarning, "binding has no credentials", nil
+	}
+	return StatusPassed, "", nil
+}
+
+func (c *checker) unbind(ctx context.Context) (Status, string, error) {
+	response, err := c.client.Unbind(ctx, &v2.UnbindRequest{
+		BindingID:         c.report.BindingID,
+		InstanceID:        c.report.InstanceID,
+		AcceptsIncomplete: c.client.Capabilities().AsyncBindings,
+		ServiceID:         c.service.ID,
+		PlanID:            c.plan.ID,
+	})
+	if err != nil {
+		return "", "", fmt.Errorf("cannot unbind: %w", err)
+	}
+	if response.Async {
+		if err := c.waitForBindingOperation(ctx, response.OperationKey); err != nil && !v2.IsGoneError(err) {
+			return "", "", fmt.Errorf("unbind operation: %w", err)
+		}
+		return asyncStatus(response.OperationKey)
+	}
+	return StatusPassed, "", nil
+}
+
+func (c *checker) deprovision(ctx context.Context) (Status, string, error) {
+	response, err := c.client.DeprovisionInstance(ctx, c.deprovisionRequest())
+	if err != nil {
+		return "", "", fmt.Errorf("cannot deprovision instance: %w", err)
+	}
+	if response.Async {
+		// Brokers may answer the last poll of a deprovision with HTTP GONE.
+		if err := c.waitForOperation(ctx, response.OperationKey); err != nil && !v2.IsGoneError(err) {
+			return "", "", fmt.Errorf("deprovision operation: %w", err)
+		}
+		return asyncStatus(response.OperationKey)
+	}
+	return StatusPassed, "", nil
+}
+
+// deprovisionAgain checks that the broker answers the deprovision request of
+// a deleted instance right away, with HTTP GONE or OK, which the provider
+// relies on to finish the deletion of a resource.
+func (c *checker) deprovisionAgain(ctx context.Context) (Status, string, error) {
+	response, err := c.client.DeprovisionInstance(ctx, c.deprovisionRequest())
+	if err != nil {
+		return "", "", fmt.Errorf("want HTTP GONE for the deprovision request of a deleted instance, got: %w", err)
+	}
+	if response.Async {
+		return "", "", errors.New("broker started another deprovision operation for a deleted instance, want HTTP GONE")
+	}
+	return StatusPassed, "", nil
+}
+
+func (c *checker) deprovisionRequest() *v2.DeprovisionRequest {
+	return &v2.DeprovisionRequest{
+		InstanceID:        c.report.InstanceID,
+		AcceptsIncomplete: true,
+		ServiceID:         c.service.ID,
+		PlanID:            c.plan.ID,
+	}
+}
+
+func (c *checker) waitForOperation(ctx context.Context, key *v2.OperationKey) error {
+	_, err := v2.WaitForOperation(ctx, c.client, &v2.LastOperationRequest{
+		InstanceID:   c.report.InstanceID,
+		ServiceID:    &c.service.ID,
+		PlanID:       &c.plan.ID,
+		OperationKey: key,
+	}, c.options.Wait)
+	if err != nil && ctx.Err() != nil {
+		c.instanceOperation = &interruptedOperation{key: key}
+	}
+	return err
+}
+
+func (c *checker) waitForBindingOperation(ctx context.Context, key *v2.OperationKey) error {
+	_, err := v2.WaitForBindingOperation(ctx, c.client, &v2.BindingLastOperationRequest{
+		InstanceID:   c.report.InstanceID,
+		BindingID:    c.report.BindingID,
+		ServiceID:    &c.service.ID,
+		PlanID:       &c.plan.ID,
+		OperationKey: key,
+	}, c.options.Wait)
+	if err != nil && ctx.Err() != nil {
+		c.bindingOperation = &interruptedOperation{key: key}
+	}
+	return err
+}
+
+// finishInterruptedOperations waits for the asynchronous operations whose
+// polling was interrupted, because brokers reject unbind and deprovision
+// requests while another operation of the resource is in progress. Their
+// outcome does not matter, the cleanup steps report whether they succeed.
+func (c *checker) finishInterruptedOperations(ctx context.Context) {
+	if op := c.bindingOperation; op != nil {
+		c.bindingOperation = nil
+		_ = c.waitForBindingOperation(ctx, op.key)
+	}
+	if op := c.instanceOperation; op != nil {
+		c.instanceOperation = nil
+		_ = c.waitForOperation(ctx, op.key)
+	}
+}
+
+// asyncStatus returns the outcome of a successful asynchronous operation. The
+// provider copes with brokers that omit the operation key, but cannot tell
+// concurrent operations of the same instance apart then.
+func asyncStatus(key *v2.OperationKey) (Status, string, error) {
+	if key == nil || *key == "" {
+		return StatusWarning, "asynchronous response without operation key", nil
+	}
+	return StatusPassed, "", nil
+}
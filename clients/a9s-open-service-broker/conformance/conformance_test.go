@@ -0,0 +1,276 @@
+/*
+Copyright 2024 Klutch Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package conformance
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+
+	v2 "github.com/anynines/klutchio/clients/a9s-open-service-broker"
+	"github.com/anynines/klutchio/clients/a9s-open-service-broker/osbtest"
+)
+
+func TestRun(t *testing.T) {
+	cases := map[string]struct {
+		options osbtest.Options
+		fault   *osbtest.Fault
+		want    map[string]Status
+		passed  bool
+	}{
+		"AsyncBroker": {
+			options: osbtest.Options{Async: true, PollsUntilDone: 1},
+			want: map[string]Status{
+				"negotiate API version": StatusPassed,
+				"catalog":               StatusPassed,
+				"provision":             StatusPassed,
+				"get instance":          StatusPassed,
+				"update":                StatusPassed,
+				"bind":                  StatusPassed,
+				"unbind":                StatusPassed,
+				"deprovision":           StatusPassed,
+				"deprovision again":     StatusPassed,
+			},
+			passed: true,
+		},
+		"SyncBroker": {
+			want: map[string]Status{
+				"negotiate API version": StatusPassed,
+				"catalog":               StatusPassed,
+				"provision":             StatusPassed,
+				"get instance":          StatusPassed,
+				"update":                StatusPassed,
+				"bind":                  StatusPassed,
+				"unbind":                StatusPassed,
+				"deprovision":           StatusPassed,
+				"deprovision again":     StatusPassed,
+			},
+			passed: true,
+		},
+		"WithoutInstancesEndpoint": {
+			fault: &osbtest.Fault{Route: osbtest.RouteGetInstance, StatusCode: http.StatusNotFound},
+			want: map[string]Status{
+				"negotiate API version": StatusPassed,
+				"catalog":               StatusPassed,
+				"provision":             StatusPassed,
+				"get instance":          StatusWarning,
+				"update":                StatusPassed,
+				"bind":                  StatusPassed,
+				"unbind":                StatusPassed,
+				"deprovision":           StatusPassed,
+				"deprovision again":     StatusPassed,
+			},
+			passed: true,
+		},
+		"ProvisionFails": {
+			options: osbtest.Options{Async: true},
+			fault:   osbtest.ServerError(osbtest.RouteProvision, http.StatusInternalServerError),
+			want: map[string]Status{
+				"negotiate API version": StatusPassed,
+				"catalog":               StatusPassed,
+				"provision":             StatusFailed,
+				"get instance":          StatusSkipped,
+				"update":                StatusSkipped,
+				"bind":                  StatusSkipped,
+				"unbind":                StatusSkipped,
+				"deprovision":           StatusSkipped,
+				"deprovision again":     StatusSkipped,
+			},
+		},
+		"BindFails": {
+			options: osbtest.Options{Async: true},
+			fault:   osbtest.ServerError(osbtest.RouteBind, http.StatusInternalServerError),
+			want: map[string]Status{
+				"negotiate API version": StatusPassed,
+				"catalog":               StatusPassed,
+				"provision":             StatusPassed,
+				"get instance":          StatusPassed,
+				"update":                StatusPassed,
+				"bind":                  StatusFailed,
+				"unbind":                StatusSkipped,
+				"deprovision":           StatusPassed,
+				"deprovision again":     StatusPassed,
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			broker := osbtest.NewBroker(tc.options)
+			defer broker.Close()
+			if tc.fault != nil {
+				broker.InjectFault(tc.fault)
+			}
+
+			report := runAgainst(t, broker, Options{})
+
+			got := map[string]Status{}
+			for _, check := range report.Checks {
+				got[check.Name] = check.Status
+			}
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("Run(...): -want, +got:\n%s", diff)
+			}
+			if report.Passed() != tc.passed {
+				t.Errorf("Passed(): want %t, got %t", tc.passed, report.Passed())
+			}
+		})
+	}
+}
+
+func TestRunWarnsAboutServiceNameSuffix(t *testing.T) {
+	catalog := osbtest.DefaultCatalog()
+	catalog.Services[0].Name = "a9s-postgresql13-ms-1687789907"
+	broker := osbtest.NewBroker(osbtest.Options{Catalog: catalog})
+	defer broker.Close()
+
+	report := runAgainst(t, broker, Options{Service: "a9s-postgresql13"})
+
+	if report.Service != catalog.Services[0].Name {
+		t.Errorf("want service %q to be checked, got %q", catalog.Services[0].Name, report.Service)
+	}
+	if check := report.Checks[1]; check.Name != "catalog" || check.Status != StatusWarning || !strings.Contains(check.Message, "-ms-1687789907") {
+		t.Errorf("unexpected catalog check %+v", check)
+	}
+	if !report.Passed() {
+		t.Error("want warnings not to fail the check")
+	}
+}
+
+func TestRunUnknownService(t *testing.T) {
+	broker := osbtest.NewBroker(osbtest.Options{})
+	defer broker.Close()
+
+	report := runAgainst(t, broker, Options{Service: "unknown"})
+
+	if report.Passed() {
+		t.Error("want check of an unknown service to fail")
+	}
+	// The catalog requested to negotiate the API version is cached.
+	if len(broker.Requests()) != 1 {
+		t.Errorf("want only the catalog to be requested, got %d requests", len(broker.Requests()))
+	}
+}
+
+// interruptingClient cancels the context of the check once the binding is
+// created, like a user pressing Ctrl-C.
+type interruptingClient struct {
+	v2.Client
+	cancel context.CancelFunc
+}
+
+func (c interruptingClient) Bind(ctx context.Context, r *v2.BindRequest) (*v2.BindResponse, error) {
+	defer c.cancel()
+	return c.Client.Bind(ctx, r)
+}
+
+func TestRunCleansUpWhenInterrupted(t *testing.T) {
+	broker := osbtest.NewBroker(osbtest.Options{Async: true, PollsUntilDone: 1})
+	defer broker.Close()
+	client, err := v2.NewClient(broker.ClientConfiguration())
+	if err != nil {
+		t.Fatalf("cannot create client: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	wait := &v2.WaitOptions{InitialInterval: time.Millisecond, MaxInterval: time.Millisecond, MaxDuration: 10 * time.Second}
+	report, err := Run(ctx, interruptingClient{Client: client, cancel: cancel}, Options{Wait: wait})
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("Run(...): want context.Canceled, got %v", err)
+	}
+
+	if _, ok := broker.Binding(report.InstanceID, report.BindingID); ok {
+		t.Error("want the binding to be deleted")
+	}
+	if instance, _ := broker.Instance(report.InstanceID); instance.State != osbtest.StateDeleted {
+		t.Errorf("want the instance to be deprovisioned, got state %q", instance.State)
+	}
+	if report.LeakedInstanceID != "" || report.LeakedBindingID != "" {
+		t.Errorf("want nothing to be leaked, got instance %q and binding %q", report.LeakedInstanceID, report.LeakedBindingID)
+	}
+}
+
+func TestRunReportsLeakedInstance(t *testing.T) {
+	broker := osbtest.NewBroker(osbtest.Options{})
+	defer broker.Close()
+	broker.InjectFault(osbtest.ServerError(osbtest.RouteDeprovision, http.StatusInternalServerError))
+
+	report := runAgainst(t, broker, Options{})
+
+	if report.LeakedInstanceID != report.InstanceID {
+		t.Errorf("want instance %q to be reported as leaked, got %q", report.InstanceID, report.LeakedInstanceID)
+	}
+	if report.LeakedBindingID != "" {
+		t.Errorf("want no binding to be reported as leaked, got %q", report.LeakedBindingID)
+	}
+
+	var buf bytes.Buffer
+	if err := report.WriteText(&buf); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := "Leaked instance:  " + report.InstanceID; !strings.Contains(buf.String(), want) {
+		t.Errorf("want report to contain %q, got:\n%s", want, buf.String())
+	}
+}
+
+func TestWriteText(t *testing.T) {
+	report := &Report{
+		APIVersion: "2.14",
+		Service:    "a9s-postgresql13",
+		Plan:       "postgresql-single-small",
+		InstanceID: "instance",
+		Checks: []Check{
+			{Name: "catalog", Status: StatusPassed, Duration: 12 * time.Millisecond},
+			{Name: "provision", Status: StatusFailed, Message: "oops"},
+		},
+	}
+
+	var buf bytes.Buffer
+	if err := report.WriteText(&buf); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, want := range []string{"Service:      a9s-postgresql13", "catalog    PASS    12ms", "provision  FAIL    0s        oops", "The broker does not conform."} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("want report to contain %q, got:\n%s", want, buf.String())
+		}
+	}
+}
+
+func runAgainst(t *testing.T, broker *osbtest.Broker, options Options) *Report {
+	t.Helper()
+
+	config := broker.ClientConfiguration()
+	config.ResponseValidation = v2.ResponseValidationStrict
+	client, err := v2.NewClient(config)
+	if err != nil {
+		t.Fatalf("cannot create client: %v", err)
+	}
+
+	options.Wait = &v2.WaitOptions{InitialInterval: time.Millisecond, MaxInterval: time.Millisecond, MaxDuration: 10 * time.Second}
+	report, err := Run(context.Background(), client, options)
+	if err != nil {
+		t.Fatalf("Run(...): unexpected error: %v", err)
+	}
+	return report
+}
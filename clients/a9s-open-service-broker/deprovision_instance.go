@@ -17,11 +17,12 @@ limitations under the License.
 package v2
 
 import (
+	"context"
 	"fmt"
 	"net/http"
 )
 
-func (c *client) DeprovisionInstance(r *DeprovisionRequest) (*DeprovisionResponse, error) {
+func (c *client) DeprovisionInstance(ctx context.Context, r *DeprovisionRequest) (*DeprovisionResponse, error) {
 	if err := validateDeprovisionRequest(r); err != nil {
 		return nil, err
 	}
@@ -36,7 +37,10 @@ func (c *client) DeprovisionInstance(r *DeprovisionRequest) (*DeprovisionRespons
 		params[AcceptsIncomplete] = "true"
 	}
 
-	response, err := c.prepareAndDo(http.MethodDelete, fullURL, params, nil, r.OriginatingIdentity)
+	ctx, span := c.startSpan(ctx, operationDeprovision, r, attributeInstanceID.String(r.InstanceID))
+	defer span.End()
+
+	response, err := c.prepareAndDo(ctx, http.MethodDelete, fullURL, params, nil, r.OriginatingIdentity)
 	if err != nil {
 		return nil, err
 	}
@@ -72,6 +76,9 @@ func (c *client) DeprovisionInstance(r *DeprovisionRequest) (*DeprovisionRespons
 			Async:        true,
 			OperationKey: opPtr,
 		}
+		if err := c.validateResponse(ctx, deprovisionViolations(userResponse)); err != nil {
+			return nil, err
+		}
 
 		return userResponse, nil
 	default:
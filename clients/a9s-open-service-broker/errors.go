@@ -19,6 +19,7 @@ package v2
 import (
 	"fmt"
 	"net/http"
+	"time"
 )
 
 // HTTPStatusCodeError is an error type that provides additional information
@@ -267,6 +268,23 @@ func IsAsyncBindingOperationsNotAllowedError(err error) bool {
 	return ok
 }
 
+// BindingRotationNotAllowedError is an error type signifying that bindings
+// cannot be created as the successor of another binding by this client.
+type BindingRotationNotAllowedError struct {
+	reason string
+}
+
+func (e BindingRotationNotAllowedError) Error() string {
+	return fmt.Sprintf("Binding rotation is not allowed: %s", e.reason)
+}
+
+// IsBindingRotationNotAllowedError returns whether the error represents
+// binding rotation not being allowed for this client.
+func IsBindingRotationNotAllowedError(err error) bool {
+	_, ok := err.(BindingRotationNotAllowedError)
+	return ok
+}
+
 // AvailabilityInvalidStatusError indicates that the availability check received an unexpected
 // HTTP status.
 type AvailabilityInvalidStatusError struct {
@@ -284,3 +302,34 @@ type OperationStateError struct {
 func (e OperationStateError) Error() string {
 	return fmt.Sprintf("Operation is in failed state %q", e.State)
 }
+
+// PollingTimeoutError is returned by WaitForOperation and
+// WaitForBindingOperation when an operation is still in progress after the
+// configured maximum polling duration.
+type PollingTimeoutError struct {
+	MaxDuration time.Duration
+}
+
+func (e PollingTimeoutError) Error() string {
+	return fmt.Sprintf("Operation still in progress after %v", e.MaxDuration)
+}
+
+// IsPollingTimeoutError returns whether the error represents an operation that
+// did not complete within the maximum polling duration.
+func IsPollingTimeoutError(err error) bool {
+	_, ok := err.(PollingTimeoutError)
+	return ok
+}
+
+// APIVersionNegotiationError is an error type signifying that the broker
+// rejected every API version up to MaxVersion.
+type APIVersionNegotiationError struct {
+	MaxVersion APIVersion
+}
+
+func (e APIVersionNegotiationError) Error() string {
+	return fmt.Sprintf(
+		"broker does not support any API version up to %s",
+		e.MaxVersion,
+	)
+}
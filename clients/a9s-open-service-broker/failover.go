@@ -0,0 +1,202 @@
+/*
+Copyright 2024 Klutch Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v2
+
+import (
+	"errors"
+	"net"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// defaultEndpointCooldown is how long a client that does not share an
+// EndpointHealth skips an endpoint that failed.
+const defaultEndpointCooldown = 30 * time.Second
+
+// EndpointHealth records the endpoints of brokers that failed, so that
+// requests are sent to healthy endpoints first. It can be shared by any number
+// of clients, e.g. by every client a controller creates for the same broker,
+// so that clients skip the endpoints other clients found to be down.
+//
+// An endpoint that failed is healthy again once the cooldown passed or it
+// answered a request, which is sent to it if no other endpoint is healthy.
+type EndpointHealth struct {
+	cooldown time.Duration
+	now      func() time.Time
+
+	mu       sync.Mutex
+	failures map[string]time.Time
+}
+
+// NewEndpointHealth returns an EndpointHealth that skips endpoints for
+// cooldown after they failed.
+func NewEndpointHealth(cooldown time.Duration) *EndpointHealth {
+	return &EndpointHealth{
+		cooldown: cooldown,
+		now:      time.Now,
+		failures: map[string]time.Time{},
+	}
+}
+
+// MarkFailed records that the endpoint at endpointURL failed.
+func (h *EndpointHealth) MarkFailed(endpointURL string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.failures[normalizeEndpoint(endpointURL)] = h.now()
+}
+
+// MarkHealthy records that the endpoint at endpointURL answered.
+func (h *EndpointHealth) MarkHealthy(endpointURL string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	delete(h.failures, normalizeEndpoint(endpointURL))
+}
+
+// Order returns the endpoints at endpointURLs in the order requests are sent
+// to them: the healthy endpoints in the given order, followed by the endpoints
+// that failed, the least recently failed first.
+func (h *EndpointHealth) Order(endpointURLs []string) []string {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	var healthy, failed []string
+	for _, endpoint := range endpointURLs {
+		endpoint = normalizeEndpoint(endpoint)
+		if failedAt, ok := h.failures[endpoint]; ok && h.now().Sub(failedAt) < h.cooldown {
+			failed = append(failed, endpoint)
+			continue
+		}
+		healthy = append(healthy, endpoint)
+	}
+	sort.SliceStable(failed, func(i, j int) bool {
+		return h.failures[failed[i]].Before(h.failures[failed[j]])
+	})
+	return append(healthy, failed...)
+}
+
+// Active returns the endpoint of endpointURLs requests are sent to first, or
+// an empty string if there is none.
+func (h *EndpointHealth) Active(endpointURLs []string) string {
+	if order := h.Order(endpointURLs); len(order) > 0 {
+		return order[0]
+	}
+	return ""
+}
+
+func normalizeEndpoint(endpointURL string) string {
+	return strings.TrimRight(endpointURL, "/")
+}
+
+// sendWithFailover sends the request to the endpoints of the client in the
+// order of their health until one of them answers. Requests fail over to the
+// next endpoint if they cannot be delivered, or, if they are idempotent, if
+// they fail in transit or are answered with 502, 503 or 504, which load
+// balancers answer for brokers that are down. Open Service Broker requests
+// are idempotent except for instance updates, since instances and bindings
+// are identified by the platform.
+func (c *client) sendWithFailover(request *http.Request) (*http.Response, error) {
+	path := strings.TrimPrefix(request.URL.String(), c.URL)
+	endpoints := c.endpointHealth.Order(c.endpoints)
+
+	var response *http.Response
+	var err error
+	for i, endpoint := range endpoints {
+		attempt := request
+		if endpoint != c.URL {
+			if attempt, err = retarget(request, endpoint+path); err != nil {
+				return nil, err
+			}
+		}
+
+		response, err = c.do(attempt)
+		recordResponse(attempt, response, err)
+		if !shouldFailOver(attempt, response, err) {
+			if err == nil {
+				c.endpointHealth.MarkHealthy(endpoint)
+			}
+			return response, err
+		}
+
+		c.endpointHealth.MarkFailed(endpoint)
+		if i == len(endpoints)-1 {
+			break
+		}
+		if err == nil {
+			_ = drainReader(response.Body)
+			response.Body.Close()
+			c.logger.V(1).Info("Failing over to the next endpoint", "endpoint", endpoint, "status", response.StatusCode)
+		} else {
+			c.logger.V(1).Info("Failing over to the next endpoint", "endpoint", endpoint, "error", err.Error())
+		}
+	}
+	return response, err
+}
+
+// retarget returns a copy of request that is sent to rawURL.
+func retarget(request *http.Request, rawURL string) (*http.Request, error) {
+	target, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, err
+	}
+
+	attempt, err := replay(request)
+	if err != nil {
+		return nil, err
+	}
+	attempt.URL = target
+	attempt.Host = ""
+	return attempt, nil
+}
+
+// shouldFailOver returns whether the request is sent to the next endpoint
+// after it failed with err or was answered with response.
+func shouldFailOver(request *http.Request, response *http.Response, err error) bool {
+	if request.Context().Err() != nil {
+		return false
+	}
+	if err != nil {
+		return isDialError(err) || isIdempotent(request.Method)
+	}
+	switch response.StatusCode {
+	case http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return isIdempotent(request.Method)
+	}
+	return false
+}
+
+// isDialError returns whether err is an error of connecting to an endpoint,
+// i.e. whether the request was not delivered.
+func isDialError(err error) bool {
+	var opErr *net.OpError
+	if errors.As(err, &opErr) && opErr.Op == "dial" {
+		return true
+	}
+	var dnsErr *net.DNSError
+	return errors.As(err, &dnsErr)
+}
+
+func isIdempotent(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodPut, http.MethodDelete, http.MethodOptions:
+		return true
+	}
+	return false
+}
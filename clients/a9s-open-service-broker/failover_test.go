@@ -0,0 +1,200 @@
+/*
+Copyright 2024 Klutch Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v2
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+var errConnectionRefused = &net.OpError{Op: "dial", Net: "tcp", Err: errors.New("connection refused")}
+
+// endpointReaction is how an endpoint answers in failover tests.
+type endpointReaction struct {
+	status int
+	err    error
+}
+
+func TestFailover(t *testing.T) {
+	cases := map[string]struct {
+		// reactions of the endpoints by host. Hosts without reaction answer
+		// 200 OK.
+		reactions map[string]endpointReaction
+		failed    []string
+		request   func(c *client) error
+		wantHosts []string
+		wantErr   bool
+		wantOrder []string
+	}{
+		"first endpoint answers": {
+			request:   getCatalog,
+			wantHosts: []string{"a.example.com"},
+			wantOrder: []string{"https://a.example.com", "https://b.example.com", "https://c.example.com"},
+		},
+		"unreachable endpoint fails over": {
+			reactions: map[string]endpointReaction{"a.example.com": {err: errConnectionRefused}},
+			request:   getCatalog,
+			wantHosts: []string{"a.example.com", "b.example.com"},
+			wantOrder: []string{"https://b.example.com", "https://c.example.com", "https://a.example.com"},
+		},
+		"unavailable endpoint fails over": {
+			reactions: map[string]endpointReaction{
+				"a.example.com": {status: http.StatusBadGateway},
+				"b.example.com": {status: http.StatusServiceUnavailable},
+			},
+			request:   getCatalog,
+			wantHosts: []string{"a.example.com", "b.example.com", "c.example.com"},
+			wantOrder: []string{"https://c.example.com", "https://a.example.com", "https://b.example.com"},
+		},
+		"failed endpoint is skipped": {
+			failed:    []string{"https://a.example.com"},
+			request:   getCatalog,
+			wantHosts: []string{"b.example.com"},
+			wantOrder: []string{"https://b.example.com", "https://c.example.com", "https://a.example.com"},
+		},
+		"failed endpoints are tried if no endpoint is healthy": {
+			failed:    []string{"https://a.example.com", "https://b.example.com", "https://c.example.com"},
+			request:   getCatalog,
+			wantHosts: []string{"a.example.com"},
+			wantOrder: []string{"https://a.example.com", "https://b.example.com", "https://c.example.com"},
+		},
+		"error of the last endpoint is returned": {
+			reactions: map[string]endpointReaction{
+				"a.example.com": {err: errConnectionRefused},
+				"b.example.com": {err: errConnectionRefused},
+				"c.example.com": {err: errConnectionRefused},
+			},
+			request:   getCatalog,
+			wantHosts: []string{"a.example.com", "b.example.com", "c.example.com"},
+			wantErr:   true,
+		},
+		"client errors do not fail over": {
+			reactions: map[string]endpointReaction{"a.example.com": {status: http.StatusBadRequest}},
+			request:   getCatalog,
+			wantHosts: []string{"a.example.com"},
+			wantErr:   true,
+		},
+		"update fails over if it cannot be delivered": {
+			reactions: map[string]endpointReaction{"a.example.com": {err: errConnectionRefused}},
+			request:   updateInstance,
+			wantHosts: []string{"a.example.com", "b.example.com"},
+		},
+		"update does not fail over if the endpoint is unavailable": {
+			reactions: map[string]endpointReaction{"a.example.com": {status: http.StatusBadGateway}},
+			request:   updateInstance,
+			wantHosts: []string{"a.example.com"},
+			wantErr:   true,
+		},
+		"update does not fail over if it fails in transit": {
+			reactions: map[string]endpointReaction{"a.example.com": {err: io.ErrUnexpectedEOF}},
+			request:   updateInstance,
+			wantHosts: []string{"a.example.com"},
+			wantErr:   true,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			health := NewEndpointHealth(time.Minute)
+			now := time.Now()
+			health.now = func() time.Time { return now }
+			for _, endpoint := range tc.failed {
+				health.MarkFailed(endpoint)
+				now = now.Add(time.Second)
+			}
+
+			klient := newTestClient(t, name, Version2_14(), false, httpChecks{}, httpReaction{})
+			klient.URL = "https://a.example.com"
+			klient.endpoints = []string{"https://a.example.com", "https://b.example.com", "https://c.example.com"}
+			klient.endpointHealth = health
+
+			var hosts []string
+			klient.doRequestFunc = func(request *http.Request) (*http.Response, error) {
+				now = now.Add(time.Second)
+				hosts = append(hosts, request.URL.Host)
+				if request.Body != nil {
+					if body, _ := io.ReadAll(request.Body); !strings.Contains(string(body), "plan-id") {
+						t.Errorf("unexpected request body %q", body)
+					}
+				}
+				reaction := tc.reactions[request.URL.Host]
+				if reaction.err != nil {
+					return nil, reaction.err
+				}
+				status := reaction.status
+				body := okCatalogBytes
+				if status == 0 {
+					status = http.StatusOK
+				}
+				if request.Method == http.MethodPatch {
+					body = "{}"
+				}
+				return &http.Response{StatusCode: status, Body: io.NopCloser(strings.NewReader(body))}, nil
+			}
+
+			err := tc.request(klient)
+			if (err != nil) != tc.wantErr {
+				t.Errorf("want error %t, got %v", tc.wantErr, err)
+			}
+			if diff := cmp.Diff(tc.wantHosts, hosts); diff != "" {
+				t.Errorf("requested hosts: -want, +got:\n%s", diff)
+			}
+			if tc.wantOrder != nil {
+				if diff := cmp.Diff(tc.wantOrder, health.Order(klient.endpoints)); diff != "" {
+					t.Errorf("endpoint order: -want, +got:\n%s", diff)
+				}
+			}
+		})
+	}
+}
+
+func getCatalog(c *client) error {
+	_, err := c.GetCatalog(context.Background())
+	return err
+}
+
+func updateInstance(c *client) error {
+	planID := "plan-id"
+	_, err := c.UpdateInstance(context.Background(), &UpdateInstanceRequest{
+		InstanceID: "instance-id",
+		ServiceID:  "service-id",
+		PlanID:     &planID,
+	})
+	return err
+}
+
+func TestNewClientFailoverURLs(t *testing.T) {
+	config := DefaultClientConfiguration()
+	config.URL = "https://a.example.com/"
+	config.FailoverURLs = []string{"https://b.example.com/", "", "https://a.example.com"}
+
+	klient, err := NewClient(config)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if diff := cmp.Diff([]string{"https://a.example.com", "https://b.example.com"}, klient.(*client).endpoints); diff != "" {
+		t.Errorf("endpoints: -want, +got:\n%s", diff)
+	}
+}
@@ -1,6 +1,7 @@
 package fake
 
 import (
+	"context"
 	"errors"
 	"net/http"
 	"sync"
@@ -44,6 +45,8 @@ func NewFakeClient(config FakeClientConfiguration) *FakeClient {
 		GetBindingReaction:               config.GetBindingReaction,
 		CheckAvailabilityReaction:        config.CheckAvailabilityReaction,
 		GetOperationReaction:             config.GetOperationReaction,
+		NegotiateAPIVersionReaction:      config.NegotiateAPIVersionReaction,
+		APIVersion:                       config.APIVersion,
 	}
 }
 
@@ -64,6 +67,10 @@ type FakeClientConfiguration struct {
 	GetBindingReaction               GetBindingReactionInterface
 	CheckAvailabilityReaction        CheckAvailabilityReactionInterface
 	GetOperationReaction             GetOperationReactionInterface
+	NegotiateAPIVersionReaction      NegotiateAPIVersionReactionInterface
+	// APIVersion is the API version returned by Version. If nil, the latest
+	// API version is returned.
+	APIVersion *v2.APIVersion
 }
 
 // Action is a record of a method call on the FakeClient.
@@ -92,6 +99,7 @@ const (
 	GetBinding               ActionType = "GetBinding"
 	CheckAvailability        ActionType = "CheckAvailability"
 	GetOperation             ActionType = "GetOperation"
+	NegotiateAPIVersion      ActionType = "NegotiateAPIVersion"
 )
 
 // FakeClient is a fake implementation of the v2.Client interface. It records
@@ -114,6 +122,10 @@ type FakeClient struct {
 	GetBindingReaction               GetBindingReactionInterface
 	CheckAvailabilityReaction        CheckAvailabilityReactionInterface
 	GetOperationReaction             GetOperationReactionInterface
+	NegotiateAPIVersionReaction      NegotiateAPIVersionReactionInterface
+	// APIVersion is the API version returned by Version. If nil, the latest
+	// API version is returned. A successful NegotiateAPIVersion sets it.
+	APIVersion *v2.APIVersion
 
 	sync.Mutex
 	actions []Action
@@ -131,7 +143,7 @@ func (c *FakeClient) Actions() []Action {
 }
 
 // GetCatalog implements the Client.GetCatalog method for the FakeClient.
-func (c *FakeClient) GetCatalog() (*v2.CatalogResponse, error) {
+func (c *FakeClient) GetCatalog(_ context.Context) (*v2.CatalogResponse, error) {
 	c.Mutex.Lock()
 	defer c.Mutex.Unlock()
 
@@ -146,7 +158,7 @@ func (c *FakeClient) GetCatalog() (*v2.CatalogResponse, error) {
 
 // ProvisionInstance implements the Client.ProvisionRequest method for the
 // FakeClient.
-func (c *FakeClient) ProvisionInstance(r *v2.ProvisionRequest) (*v2.ProvisionResponse, error) {
+func (c *FakeClient) ProvisionInstance(_ context.Context, r *v2.ProvisionRequest) (*v2.ProvisionResponse, error) {
 	c.Mutex.Lock()
 	defer c.Mutex.Unlock()
 
@@ -161,7 +173,7 @@ func (c *FakeClient) ProvisionInstance(r *v2.ProvisionRequest) (*v2.ProvisionRes
 
 // UpdateInstance implements the Client.UpdateInstance method for the
 // FakeClient.
-func (c *FakeClient) UpdateInstance(r *v2.UpdateInstanceRequest) (*v2.UpdateInstanceResponse, error) {
+func (c *FakeClient) UpdateInstance(_ context.Context, r *v2.UpdateInstanceRequest) (*v2.UpdateInstanceResponse, error) {
 	c.Mutex.Lock()
 	defer c.Mutex.Unlock()
 
@@ -176,7 +188,7 @@ func (c *FakeClient) UpdateInstance(r *v2.UpdateInstanceRequest) (*v2.UpdateInst
 
 // DeprovisionInstance implements the Client.DeprovisionInstance method on the
 // FakeClient.
-func (c *FakeClient) DeprovisionInstance(r *v2.DeprovisionRequest) (*v2.DeprovisionResponse, error) {
+func (c *FakeClient) DeprovisionInstance(_ context.Context, r *v2.DeprovisionRequest) (*v2.DeprovisionResponse, error) {
 	c.Mutex.Lock()
 	defer c.Mutex.Unlock()
 
@@ -190,7 +202,7 @@ func (c *FakeClient) DeprovisionInstance(r *v2.DeprovisionRequest) (*v2.Deprovis
 }
 
 // GetInstance implements the Client.GetInstance method for the FakeClient.
-func (c *FakeClient) GetInstance(*v2.GetInstanceRequest) (*v2.GetInstanceResponse, error) {
+func (c *FakeClient) GetInstance(_ context.Context, _ *v2.GetInstanceRequest) (*v2.GetInstanceResponse, error) {
 	c.Mutex.Lock()
 	defer c.Mutex.Unlock()
 
@@ -204,7 +216,7 @@ func (c *FakeClient) GetInstance(*v2.GetInstanceRequest) (*v2.GetInstanceRespons
 }
 
 // GetServiceInstance implements the Client.GetServiceInstance method for the FakeClient.
-func (c *FakeClient) GetServiceInstance(*v2.GetInstanceRequest) (*v2.GetServiceInstanceResponse, error) {
+func (c *FakeClient) GetServiceInstance(_ context.Context, _ *v2.GetInstanceRequest) (*v2.GetServiceInstanceResponse, error) {
 	c.Mutex.Lock()
 	defer c.Mutex.Unlock()
 
@@ -218,7 +230,7 @@ func (c *FakeClient) GetServiceInstance(*v2.GetInstanceRequest) (*v2.GetServiceI
 }
 
 // GetInstances implements the Client.GetInstances method for the FakeClient.
-func (c *FakeClient) GetInstances() (*v2.GetInstancesResponse, error) {
+func (c *FakeClient) GetInstances(_ context.Context) (*v2.GetInstancesResponse, error) {
 	c.Mutex.Lock()
 	defer c.Mutex.Unlock()
 
@@ -233,7 +245,7 @@ func (c *FakeClient) GetInstances() (*v2.GetInstancesResponse, error) {
 
 // PollLastOperation implements the Client.PollLastOperation method on the
 // FakeClient.
-func (c *FakeClient) PollLastOperation(r *v2.LastOperationRequest) (*v2.LastOperationResponse, error) {
+func (c *FakeClient) PollLastOperation(_ context.Context, r *v2.LastOperationRequest) (*v2.LastOperationResponse, error) {
 	c.Mutex.Lock()
 	defer c.Mutex.Unlock()
 
@@ -250,7 +262,7 @@ func (c *FakeClient) PollLastOperation(r *v2.LastOperationRequest) (*v2.LastOper
 
 // PollBindingLastOperation implements the Client.PollBindingLastOperation
 // method on the FakeClient.
-func (c *FakeClient) PollBindingLastOperation(r *v2.BindingLastOperationRequest) (*v2.LastOperationResponse, error) {
+func (c *FakeClient) PollBindingLastOperation(_ context.Context, r *v2.BindingLastOperationRequest) (*v2.LastOperationResponse, error) {
 	c.Mutex.Lock()
 	defer c.Mutex.Unlock()
 
@@ -264,7 +276,7 @@ func (c *FakeClient) PollBindingLastOperation(r *v2.BindingLastOperationRequest)
 }
 
 // Bind implements the Client.Bind method on the FakeClient.
-func (c *FakeClient) Bind(r *v2.BindRequest) (*v2.BindResponse, error) {
+func (c *FakeClient) Bind(_ context.Context, r *v2.BindRequest) (*v2.BindResponse, error) {
 	c.Mutex.Lock()
 	defer c.Mutex.Unlock()
 
@@ -278,7 +290,7 @@ func (c *FakeClient) Bind(r *v2.BindRequest) (*v2.BindResponse, error) {
 }
 
 // Unbind implements the Client.Unbind method on the FakeClient.
-func (c *FakeClient) Unbind(r *v2.UnbindRequest) (*v2.UnbindResponse, error) {
+func (c *FakeClient) Unbind(_ context.Context, r *v2.UnbindRequest) (*v2.UnbindResponse, error) {
 	c.Mutex.Lock()
 	defer c.Mutex.Unlock()
 
@@ -292,7 +304,7 @@ func (c *FakeClient) Unbind(r *v2.UnbindRequest) (*v2.UnbindResponse, error) {
 }
 
 // GetBinding implements the Client.GetBinding method for the FakeClient.
-func (c *FakeClient) GetBinding(*v2.GetBindingRequest) (*v2.GetBindingResponse, error) {
+func (c *FakeClient) GetBinding(_ context.Context, _ *v2.GetBindingRequest) (*v2.GetBindingResponse, error) {
 	c.Mutex.Lock()
 	defer c.Mutex.Unlock()
 
@@ -306,7 +318,7 @@ func (c *FakeClient) GetBinding(*v2.GetBindingRequest) (*v2.GetBindingResponse,
 }
 
 // CheckAvailability implements the Client.CheckAvailability method for the FakeClient.
-func (c *FakeClient) CheckAvailability(endpoint string) error {
+func (c *FakeClient) CheckAvailability(_ context.Context, endpoint string) error {
 	c.Mutex.Lock()
 	defer c.Mutex.Unlock()
 
@@ -319,7 +331,7 @@ func (c *FakeClient) CheckAvailability(endpoint string) error {
 	return UnexpectedActionError()
 }
 
-func (c *FakeClient) GetOperation(*v2.GetOperationRequest) (*v2.GetOperationResponse, error) {
+func (c *FakeClient) GetOperation(_ context.Context, _ *v2.GetOperationRequest) (*v2.GetOperationResponse, error) {
 	c.Mutex.Lock()
 	defer c.Mutex.Unlock()
 
@@ -332,6 +344,41 @@ func (c *FakeClient) GetOperation(*v2.GetOperationRequest) (*v2.GetOperationResp
 	return nil, UnexpectedActionError()
 }
 
+// NegotiateAPIVersion implements the Client.NegotiateAPIVersion method for
+// the FakeClient.
+func (c *FakeClient) NegotiateAPIVersion(_ context.Context) (v2.APIVersion, error) {
+	c.Mutex.Lock()
+	defer c.Mutex.Unlock()
+
+	c.actions = append(c.actions, Action{Type: NegotiateAPIVersion})
+
+	if c.NegotiateAPIVersionReaction != nil {
+		version, err := c.NegotiateAPIVersionReaction.React()
+		if err == nil {
+			c.APIVersion = &version
+		}
+		return version, err
+	}
+
+	return v2.APIVersion{}, UnexpectedActionError()
+}
+
+// Version implements the Client.Version method for the FakeClient.
+func (c *FakeClient) Version() v2.APIVersion {
+	c.Mutex.Lock()
+	defer c.Mutex.Unlock()
+
+	if c.APIVersion != nil {
+		return *c.APIVersion
+	}
+	return v2.LatestAPIVersion()
+}
+
+// Capabilities implements the Client.Capabilities method for the FakeClient.
+func (c *FakeClient) Capabilities() v2.Capabilities {
+	return c.Version().Capabilities()
+}
+
 // UnexpectedActionError returns an error message when an action is not found
 // in the FakeClient's action array.
 func UnexpectedActionError() error {
@@ -633,6 +680,22 @@ func (r *GetOperationReaction) React() (*v2.GetOperationResponse, error) {
 	return r.Response, r.Error
 }
 
+type NegotiateAPIVersionReactionInterface interface {
+	React() (v2.APIVersion, error)
+}
+
+type NegotiateAPIVersionReaction struct {
+	Response v2.APIVersion
+	Error    error
+}
+
+func (r *NegotiateAPIVersionReaction) React() (v2.APIVersion, error) {
+	if r == nil {
+		return v2.APIVersion{}, UnexpectedActionError()
+	}
+	return r.Response, r.Error
+}
+
 func strPtr(s string) *string {
 	return &s
 }
@@ -1,6 +1,7 @@
 package fake_test
 
 import (
+	"context"
 	"errors"
 	"reflect"
 	"testing"
@@ -109,7 +110,7 @@ func TestGetCatalog(t *testing.T) {
 			CatalogReaction: tc.reaction,
 		}
 
-		response, err := fakeClient.GetCatalog()
+		response, err := fakeClient.GetCatalog(context.Background())
 
 		if !reflect.DeepEqual(tc.response, response) {
 			t.Errorf("%v: unexpected response; expected %+v, got %+v", tc.name, tc.response, response)
@@ -200,7 +201,7 @@ func TestProvisionInstance(t *testing.T) {
 			ProvisionReaction: tc.reaction,
 		}
 
-		response, err := fakeClient.ProvisionInstance(provisionRequest())
+		response, err := fakeClient.ProvisionInstance(context.Background(), provisionRequest())
 
 		if !reflect.DeepEqual(tc.response, response) {
 			t.Errorf("%v: unexpected response; expected %+v, got %+v", tc.name, tc.response, response)
@@ -222,7 +223,7 @@ func TestProvisionInstance(t *testing.T) {
 
 func TestProvisionRequestRequiredFields(t *testing.T) {
 	fakeClient := &fake.FakeClient{ProvisionReaction: &fake.ProvisionReaction{}}
-	_, err := fakeClient.ProvisionInstance(&v2.ProvisionRequest{})
+	_, err := fakeClient.ProvisionInstance(context.Background(), &v2.ProvisionRequest{})
 	if err == nil {
 		t.Fatalf("request should have failed for missing required fields")
 	}
@@ -288,7 +289,7 @@ func TestUpdateInstance(t *testing.T) {
 			UpdateInstanceReaction: tc.reaction,
 		}
 
-		response, err := fakeClient.UpdateInstance(&v2.UpdateInstanceRequest{})
+		response, err := fakeClient.UpdateInstance(context.Background(), &v2.UpdateInstanceRequest{})
 
 		if !reflect.DeepEqual(tc.response, response) {
 			t.Errorf("%v: unexpected response; expected %+v, got %+v", tc.name, tc.response, response)
@@ -368,7 +369,7 @@ func TestDeprovisionInstance(t *testing.T) {
 			DeprovisionReaction: tc.reaction,
 		}
 
-		response, err := fakeClient.DeprovisionInstance(&v2.DeprovisionRequest{})
+		response, err := fakeClient.DeprovisionInstance(context.Background(), &v2.DeprovisionRequest{})
 
 		if !reflect.DeepEqual(tc.response, response) {
 			t.Errorf("%v: unexpected response; expected %+v, got %+v", tc.name, tc.response, response)
@@ -447,7 +448,7 @@ func TestGetInstance(t *testing.T) {
 			GetInstanceReaction: tc.reaction,
 		}
 
-		response, err := fakeClient.GetInstance(&v2.GetInstanceRequest{})
+		response, err := fakeClient.GetInstance(context.Background(), &v2.GetInstanceRequest{})
 
 		if !reflect.DeepEqual(tc.response, response) {
 			t.Errorf("%v: unexpected response; expected %+v, got %+v", tc.name, tc.response, response)
@@ -527,7 +528,7 @@ func TestPollLastOperation(t *testing.T) {
 			PollLastOperationReaction: tc.reaction,
 		}
 
-		response, err := fakeClient.PollLastOperation(&v2.LastOperationRequest{})
+		response, err := fakeClient.PollLastOperation(context.Background(), &v2.LastOperationRequest{})
 
 		if !reflect.DeepEqual(tc.response, response) {
 			t.Errorf("%v: unexpected response; expected %+v, got %+v", tc.name, tc.response, response)
@@ -615,8 +616,8 @@ func TestPollLastOperations(t *testing.T) {
 			PollLastOperationReactions: tc.reactions,
 		}
 
-		_, _ = fakeClient.DeprovisionInstance(&v2.DeprovisionRequest{})
-		response, err := fakeClient.PollLastOperation(&v2.LastOperationRequest{OperationKey: tc.operationKey})
+		_, _ = fakeClient.DeprovisionInstance(context.Background(), &v2.DeprovisionRequest{})
+		response, err := fakeClient.PollLastOperation(context.Background(), &v2.LastOperationRequest{OperationKey: tc.operationKey})
 
 		if !reflect.DeepEqual(tc.response, response) {
 			t.Errorf("%v: unexpected response; expected %+v, got %+v", tc.name, tc.response, response)
@@ -690,7 +691,7 @@ func TestPollBindingLastOperation(t *testing.T) {
 			PollBindingLastOperationReaction: tc.reaction,
 		}
 
-		response, err := fakeClient.PollBindingLastOperation(&v2.BindingLastOperationRequest{})
+		response, err := fakeClient.PollBindingLastOperation(context.Background(), &v2.BindingLastOperationRequest{})
 
 		if !reflect.DeepEqual(tc.response, response) {
 			t.Errorf("%v: unexpected response; expected %+v, got %+v", tc.name, tc.response, response)
@@ -772,7 +773,7 @@ func TestBind(t *testing.T) {
 			BindReaction: tc.reaction,
 		}
 
-		response, err := fakeClient.Bind(&v2.BindRequest{})
+		response, err := fakeClient.Bind(context.Background(), &v2.BindRequest{})
 
 		if !reflect.DeepEqual(tc.response, response) {
 			t.Errorf("%v: unexpected response; expected %+v, got %+v", tc.name, tc.response, response)
@@ -850,7 +851,7 @@ func TestUnbind(t *testing.T) {
 			UnbindReaction: tc.reaction,
 		}
 
-		response, err := fakeClient.Unbind(&v2.UnbindRequest{})
+		response, err := fakeClient.Unbind(context.Background(), &v2.UnbindRequest{})
 
 		if !reflect.DeepEqual(tc.response, response) {
 			t.Errorf("%v: unexpected response; expected %+v, got %+v", tc.name, tc.response, response)
@@ -932,7 +933,7 @@ func TestGetBinding(t *testing.T) {
 			GetBindingReaction: tc.reaction,
 		}
 
-		response, err := fakeClient.GetBinding(&v2.GetBindingRequest{})
+		response, err := fakeClient.GetBinding(context.Background(), &v2.GetBindingRequest{})
 
 		if !reflect.DeepEqual(tc.response, response) {
 			t.Errorf("%v: unexpected response; expected %+v, got %+v", tc.name, tc.response, response)
@@ -998,7 +999,7 @@ func TestGetOperation(t *testing.T) {
 			GetOperationReaction: tc.reaction,
 		}
 
-		response, err := fakeClient.GetOperation(&v2.GetOperationRequest{})
+		response, err := fakeClient.GetOperation(context.Background(), &v2.GetOperationRequest{})
 
 		if !reflect.DeepEqual(tc.response, response) {
 			t.Errorf("%v: unexpected response; expected %+v, got %+v", tc.name, tc.response, response)
@@ -1045,7 +1046,7 @@ func TestCheckAvailability(t *testing.T) {
 		fakeClient := &fake.FakeClient{
 			CheckAvailabilityReaction: tc.reaction,
 		}
-		err := fakeClient.CheckAvailability("")
+		err := fakeClient.CheckAvailability(context.Background(), "")
 
 		if !reflect.DeepEqual(tc.expectedErr, err) {
 			t.Errorf("%v: unexpected error; expected %+v, got %+v", tc.name, tc.expectedErr, err)
@@ -1163,8 +1164,8 @@ func TestNewFakeClient(t *testing.T) {
 	},
 	}
 
-	response, err := newfakeClient.Bind(&v2.BindRequest{})
-	response2, err2 := testfakeclient.Bind(&v2.BindRequest{})
+	response, err := newfakeClient.Bind(context.Background(), &v2.BindRequest{})
+	response2, err2 := testfakeclient.Bind(context.Background(), &v2.BindRequest{})
 
 	//for _, tc := range cases {
 	//		fakeClient := fake.NewFakeClient(tc.config)
@@ -0,0 +1,827 @@
+/*
+Copyright 2024 Klutch Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package fake
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"net/http"
+	"reflect"
+	"slices"
+	"sync"
+
+	v2 "github.com/anynines/klutchio/clients/a9s-open-service-broker"
+	"github.com/anynines/klutchio/clients/a9s-open-service-broker/osbtest"
+)
+
+// instanceNotFound is the error an a9s broker returns for requests about
+// service instances it does not know.
+const instanceNotFound = "InstanceNotFound"
+
+// StatefulClient is a fake implementation of the v2.Client interface that
+// keeps an in-memory model of the service instances, bindings and
+// asynchronous operations of a broker. Unlike FakeClient, it needs no
+// reactions: it answers every call like a broker that follows the Open
+// Service Broker API, e.g. with 409 Conflict for a provision request that
+// conflicts with an existing instance and with 410 Gone for deprovision
+// requests of deleted instances. Asynchronous operations advance on every
+// poll. StatefulClient is threadsafe.
+type StatefulClient struct {
+	// Async makes the client complete provision, update, deprovision, bind
+	// and unbind calls asynchronously if the request accepts incomplete
+	// operations. Instance calls that do not accept them fail with an
+	// AsyncRequired error, binding calls are completed synchronously.
+	Async bool
+	// PollsUntilDone is the number of times an asynchronous operation is
+	// reported as in progress before it completes.
+	PollsUntilDone int
+	// APIVersion is the API version returned by Version. If nil, the latest
+	// API version is returned.
+	APIVersion *v2.APIVersion
+
+	mu         sync.Mutex
+	catalog    *v2.CatalogResponse
+	instances  map[string]*Instance
+	bindings   map[bindingKey]*Binding
+	operations map[operationKey]*operation
+	failures   []*Failure
+	actions    []Action
+	sequence   int
+	failNext   bool
+}
+
+var _ v2.Client = &StatefulClient{}
+
+// NewStatefulClient returns a StatefulClient of a broker that serves the
+// given catalog. If catalog is nil, osbtest.DefaultCatalog is served.
+func NewStatefulClient(catalog *v2.CatalogResponse) *StatefulClient {
+	if catalog == nil {
+		catalog = osbtest.DefaultCatalog()
+	}
+	return &StatefulClient{
+		catalog:    catalog,
+		instances:  map[string]*Instance{},
+		bindings:   map[bindingKey]*Binding{},
+		operations: map[operationKey]*operation{},
+	}
+}
+
+// Instance is a service instance known to a StatefulClient.
+type Instance struct {
+	ID               string
+	ServiceID        string
+	PlanID           string
+	OrganizationGUID string
+	SpaceGUID        string
+	Parameters       map[string]interface{}
+	MaintenanceInfo  *v2.MaintenanceInfo
+	// State is the state the instance is reported in by GetInstance, one of
+	// the osbtest instance states.
+	State string
+
+	number        int
+	lastOperation string
+}
+
+// Binding is a service binding known to a StatefulClient.
+type Binding struct {
+	ID          string
+	InstanceID  string
+	Parameters  map[string]interface{}
+	Credentials map[string]interface{}
+	// Ready is false while an asynchronous bind is in progress.
+	Ready bool
+
+	number        int
+	lastOperation string
+}
+
+type bindingKey struct {
+	instanceID string
+	bindingID  string
+}
+
+type operationKey struct {
+	instanceID string
+	key        string
+}
+
+// operation is an asynchronous operation. It reports StateInProgress until
+// it has been polled pollsLeft more times, then calls complete or fail and
+// reports the final state.
+type operation struct {
+	state     v2.LastOperationState
+	pollsLeft int
+	failed    bool
+	complete  func()
+	fail      func()
+}
+
+// Failure is an error a StatefulClient returns instead of handling a call.
+type Failure struct {
+	// Action restricts the failure to calls of the given method. An empty
+	// Action matches every call.
+	Action ActionType
+	// Error is returned by the call.
+	Error error
+	// Times limits how many calls the failure applies to. A value of 0 means
+	// the failure applies until it is cleared.
+	Times int
+}
+
+// InjectFailure makes the client fail matching calls with the given Failure.
+// Failures apply in the order they were injected; only the first matching
+// failure applies to a call.
+func (c *StatefulClient) InjectFailure(f *Failure) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.failures = append(c.failures, f)
+}
+
+// ClearFailures removes all injected failures.
+func (c *StatefulClient) ClearFailures() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.failures = nil
+}
+
+// FailNextOperation makes the next asynchronous operation the client starts
+// end in the failed state.
+func (c *StatefulClient) FailNextOperation() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.failNext = true
+}
+
+// Actions returns the calls taken on the client, in order.
+func (c *StatefulClient) Actions() []Action {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return slices.Clone(c.actions)
+}
+
+// Instance returns a copy of the service instance with the given ID.
+func (c *StatefulClient) Instance(id string) (Instance, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	instance, ok := c.instances[id]
+	if !ok {
+		return Instance{}, false
+	}
+	result := *instance
+	result.Parameters = maps.Clone(instance.Parameters)
+	return result, true
+}
+
+// Binding returns a copy of the service binding with the given IDs.
+func (c *StatefulClient) Binding(instanceID, bindingID string) (Binding, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	binding, ok := c.bindings[bindingKey{instanceID, bindingID}]
+	if !ok {
+		return Binding{}, false
+	}
+	result := *binding
+	result.Parameters = maps.Clone(binding.Parameters)
+	result.Credentials = maps.Clone(binding.Credentials)
+	return result, true
+}
+
+// GetCatalog implements the Client.GetCatalog method for the StatefulClient.
+func (c *StatefulClient) GetCatalog(_ context.Context) (*v2.CatalogResponse, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if err := c.record(GetCatalog, nil); err != nil {
+		return nil, err
+	}
+	return c.catalog, nil
+}
+
+// ProvisionInstance implements the Client.ProvisionInstance method for the
+// StatefulClient.
+func (c *StatefulClient) ProvisionInstance(_ context.Context, r *v2.ProvisionRequest) (*v2.ProvisionResponse, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if err := c.record(ProvisionInstance, r); err != nil {
+		return nil, err
+	}
+	if !c.inCatalog(r.ServiceID, r.PlanID) {
+		return nil, statusError(http.StatusBadRequest, "", "unknown service or plan")
+	}
+	async, err := c.async(r.AcceptsIncomplete)
+	if err != nil {
+		return nil, err
+	}
+
+	if existing, ok := c.instances[r.InstanceID]; ok && existing.State != osbtest.StateDeleted {
+		identical := existing.ServiceID == r.ServiceID &&
+			existing.PlanID == r.PlanID &&
+			reflect.DeepEqual(existing.Parameters, mergeParameters(nil, r.Parameters))
+		switch {
+		case !identical:
+			return nil, statusError(http.StatusConflict, "", "instance already exists with different attributes")
+		case c.inProgress(existing.ID, existing.lastOperation):
+			return &v2.ProvisionResponse{Async: true, OperationKey: operationKeyPtr(existing.lastOperation)}, nil
+		default:
+			return &v2.ProvisionResponse{}, nil
+		}
+	}
+
+	instance := &Instance{
+		ID:               r.InstanceID,
+		ServiceID:        r.ServiceID,
+		PlanID:           r.PlanID,
+		OrganizationGUID: r.OrganizationGUID,
+		SpaceGUID:        r.SpaceGUID,
+		Parameters:       mergeParameters(nil, r.Parameters),
+		MaintenanceInfo:  r.MaintenanceInfo,
+		State:            osbtest.StateDeploying,
+		number:           c.nextID(),
+	}
+	c.instances[instance.ID] = instance
+
+	instance.lastOperation = c.startOperation(instance.ID, async, func() {
+		instance.State = osbtest.StateProvisioned
+	}, func() {
+		instance.State = osbtest.StateFailed
+	})
+	if async {
+		return &v2.ProvisionResponse{Async: true, OperationKey: operationKeyPtr(instance.lastOperation)}, nil
+	}
+	return &v2.ProvisionResponse{}, nil
+}
+
+// UpdateInstance implements the Client.UpdateInstance method for the
+// StatefulClient.
+func (c *StatefulClient) UpdateInstance(_ context.Context, r *v2.UpdateInstanceRequest) (*v2.UpdateInstanceResponse, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if err := c.record(UpdateInstance, r); err != nil {
+		return nil, err
+	}
+	instance, err := c.existingInstance(r.InstanceID, http.StatusNotFound)
+	if err != nil {
+		return nil, err
+	}
+
+	planID := instance.PlanID
+	if r.PlanID != nil {
+		planID = *r.PlanID
+	}
+	if r.ServiceID != instance.ServiceID || !c.inCatalog(r.ServiceID, planID) {
+		return nil, statusError(http.StatusBadRequest, "", "unknown service or plan")
+	}
+	async, err := c.async(r.AcceptsIncomplete)
+	if err != nil {
+		return nil, err
+	}
+
+	previousState := instance.State
+	instance.State = osbtest.StateDeploying
+	instance.lastOperation = c.startOperation(instance.ID, async, func() {
+		instance.PlanID = planID
+		instance.Parameters = mergeParameters(instance.Parameters, r.Parameters)
+		if r.MaintenanceInfo != nil {
+			instance.MaintenanceInfo = r.MaintenanceInfo
+		}
+		instance.State = osbtest.StateProvisioned
+	}, func() {
+		instance.State = previousState
+	})
+	if async {
+		return &v2.UpdateInstanceResponse{Async: true, OperationKey: operationKeyPtr(instance.lastOperation)}, nil
+	}
+	return &v2.UpdateInstanceResponse{}, nil
+}
+
+// DeprovisionInstance implements the Client.DeprovisionInstance method for
+// the StatefulClient.
+func (c *StatefulClient) DeprovisionInstance(_ context.Context, r *v2.DeprovisionRequest) (*v2.DeprovisionResponse, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if err := c.record(DeprovisionInstance, r); err != nil {
+		return nil, err
+	}
+	instance, err := c.existingInstance(r.InstanceID, http.StatusGone)
+	if err != nil {
+		return nil, err
+	}
+	async, err := c.async(r.AcceptsIncomplete)
+	if err != nil {
+		return nil, err
+	}
+
+	previousState := instance.State
+	instance.State = osbtest.StateDeleting
+	instance.lastOperation = c.startOperation(instance.ID, async, func() {
+		for key, binding := range c.bindings {
+			if binding.InstanceID == instance.ID {
+				delete(c.bindings, key)
+			}
+		}
+		instance.State = osbtest.StateDeleted
+	}, func() {
+		instance.State = previousState
+	})
+	if async {
+		return &v2.DeprovisionResponse{Async: true, OperationKey: operationKeyPtr(instance.lastOperation)}, nil
+	}
+	return &v2.DeprovisionResponse{}, nil
+}
+
+// GetInstance implements the Client.GetInstance method for the
+// StatefulClient. Deleted instances are reported in the deleted state, like
+// the a9s /instances endpoint does.
+func (c *StatefulClient) GetInstance(_ context.Context, r *v2.GetInstanceRequest) (*v2.GetInstanceResponse, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if err := c.record(GetInstance, r); err != nil {
+		return nil, err
+	}
+	instance, ok := c.instances[r.InstanceID]
+	if !ok {
+		return nil, statusError(http.StatusNotFound, instanceNotFound, "Instance not found")
+	}
+	response := c.getInstanceResponse(instance)
+	return &response, nil
+}
+
+// GetServiceInstance implements the Client.GetServiceInstance method for the
+// StatefulClient.
+func (c *StatefulClient) GetServiceInstance(_ context.Context, r *v2.GetInstanceRequest) (*v2.GetServiceInstanceResponse, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if err := c.record(GetServiceInstance, r); err != nil {
+		return nil, err
+	}
+	instance, ok := c.instances[r.InstanceID]
+	if !ok || instance.State == osbtest.StateDeleted {
+		return nil, statusError(http.StatusNotFound, instanceNotFound, "Instance not found")
+	}
+	return &v2.GetServiceInstanceResponse{
+		ID:              instance.ID,
+		PlanGUID:        instance.PlanID,
+		ServiceGUID:     instance.ServiceID,
+		Parameters:      maps.Clone(instance.Parameters),
+		MaintenanceInfo: instance.MaintenanceInfo,
+		Context: v2.Context{
+			OrganizationGUID: instance.OrganizationGUID,
+			SpaceGUID:        instance.SpaceGUID,
+		},
+	}, nil
+}
+
+// GetInstances implements the Client.GetInstances method for the
+// StatefulClient.
+func (c *StatefulClient) GetInstances(_ context.Context) (*v2.GetInstancesResponse, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if err := c.record(GetInstances, nil); err != nil {
+		return nil, err
+	}
+
+	instances := slices.Collect(maps.Values(c.instances))
+	slices.SortFunc(instances, func(a, b *Instance) int { return a.number - b.number })
+
+	response := &v2.GetInstancesResponse{
+		TotalResults: len(instances),
+		TotalPages:   1,
+		CurrentPage:  1,
+		Resources:    make([]v2.GetInstanceResponse, 0, len(instances)),
+	}
+	for _, instance := range instances {
+		response.Resources = append(response.Resources, c.getInstanceResponse(instance))
+	}
+	return response, nil
+}
+
+// PollLastOperation implements the Client.PollLastOperation method for the
+// StatefulClient. Every poll advances the operation.
+func (c *StatefulClient) PollLastOperation(_ context.Context, r *v2.LastOperationRequest) (*v2.LastOperationResponse, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if err := c.record(PollLastOperation, r); err != nil {
+		return nil, err
+	}
+
+	key := ""
+	if r.OperationKey != nil {
+		key = string(*r.OperationKey)
+	} else if instance, ok := c.instances[r.InstanceID]; ok {
+		key = instance.lastOperation
+	}
+	state, err := c.poll(r.InstanceID, key)
+	if err != nil {
+		return nil, err
+	}
+	return &v2.LastOperationResponse{State: state}, nil
+}
+
+// PollBindingLastOperation implements the Client.PollBindingLastOperation
+// method for the StatefulClient. Every poll advances the operation.
+func (c *StatefulClient) PollBindingLastOperation(_ context.Context, r *v2.BindingLastOperationRequest) (*v2.LastOperationResponse, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if err := c.record(PollBindingLastOperation, r); err != nil {
+		return nil, err
+	}
+
+	key := ""
+	if r.OperationKey != nil {
+		key = string(*r.OperationKey)
+	} else if binding, ok := c.bindings[bindingKey{r.InstanceID, r.BindingID}]; ok {
+		key = binding.lastOperation
+	}
+	state, err := c.poll(r.InstanceID, key)
+	if err != nil {
+		return nil, err
+	}
+	return &v2.LastOperationResponse{State: state}, nil
+}
+
+// GetOperation implements the Client.GetOperation method for the
+// StatefulClient. Every call advances the operation.
+func (c *StatefulClient) GetOperation(_ context.Context, r *v2.GetOperationRequest) (*v2.GetOperationResponse, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if err := c.record(GetOperation, r); err != nil {
+		return nil, err
+	}
+
+	key := string(r.OperationKey)
+	if instance, ok := c.instances[r.InstanceID]; ok && key == "" {
+		key = instance.lastOperation
+	}
+	state, err := c.poll(r.InstanceID, key)
+	if err != nil {
+		return nil, err
+	}
+	return &v2.GetOperationResponse{State: string(state)}, nil
+}
+
+// Bind implements the Client.Bind method for the StatefulClient.
+func (c *StatefulClient) Bind(_ context.Context, r *v2.BindRequest) (*v2.BindResponse, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if err := c.record(Bind, r); err != nil {
+		return nil, err
+	}
+	instance, err := c.existingInstance(r.InstanceID, http.StatusNotFound)
+	if err != nil {
+		return nil, err
+	}
+	if r.ServiceID != instance.ServiceID || r.PlanID != instance.PlanID {
+		return nil, statusError(http.StatusBadRequest, "", "service or plan does not match the instance")
+	}
+
+	key := bindingKey{r.InstanceID, r.BindingID}
+	if existing, ok := c.bindings[key]; ok {
+		switch {
+		case !reflect.DeepEqual(existing.Parameters, mergeParameters(nil, r.Parameters)):
+			return nil, statusError(http.StatusConflict, "", "binding already exists with different attributes")
+		case c.inProgress(key.instanceID, existing.lastOperation):
+			return &v2.BindResponse{Async: true, OperationKey: operationKeyPtr(existing.lastOperation)}, nil
+		default:
+			return &v2.BindResponse{Credentials: maps.Clone(existing.Credentials)}, nil
+		}
+	}
+
+	binding := &Binding{
+		ID:         r.BindingID,
+		InstanceID: r.InstanceID,
+		Parameters: mergeParameters(nil, r.Parameters),
+		Credentials: map[string]interface{}{
+			"host":     "fake.local",
+			"port":     5432,
+			"username": "user-" + r.BindingID,
+			"password": "password-" + r.BindingID,
+		},
+		number: c.nextID(),
+	}
+	c.bindings[key] = binding
+
+	async := c.Async && r.AcceptsIncomplete
+	binding.lastOperation = c.startOperation(key.instanceID, async, func() {
+		binding.Ready = true
+	}, func() {
+		delete(c.bindings, key)
+	})
+	if async {
+		return &v2.BindResponse{Async: true, OperationKey: operationKeyPtr(binding.lastOperation)}, nil
+	}
+	return &v2.BindResponse{Credentials: maps.Clone(binding.Credentials)}, nil
+}
+
+// Unbind implements the Client.Unbind method for the StatefulClient.
+func (c *StatefulClient) Unbind(_ context.Context, r *v2.UnbindRequest) (*v2.UnbindResponse, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if err := c.record(Unbind, r); err != nil {
+		return nil, err
+	}
+
+	key := bindingKey{r.InstanceID, r.BindingID}
+	binding, ok := c.bindings[key]
+	if !ok {
+		return nil, statusError(http.StatusGone, "", "")
+	}
+	if c.inProgress(key.instanceID, binding.lastOperation) {
+		return nil, ConcurrencyError()
+	}
+
+	async := c.Async && r.AcceptsIncomplete
+	binding.lastOperation = c.startOperation(key.instanceID, async, func() {
+		delete(c.bindings, key)
+	}, func() {})
+	if async {
+		return &v2.UnbindResponse{Async: true, OperationKey: operationKeyPtr(binding.lastOperation)}, nil
+	}
+	return &v2.UnbindResponse{}, nil
+}
+
+// GetBinding implements the Client.GetBinding method for the StatefulClient.
+func (c *StatefulClient) GetBinding(_ context.Context, r *v2.GetBindingRequest) (*v2.GetBindingResponse, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if err := c.record(GetBinding, r); err != nil {
+		return nil, err
+	}
+
+	binding, ok := c.bindings[bindingKey{r.InstanceID, r.BindingID}]
+	if !ok || !binding.Ready && c.inProgress(binding.InstanceID, binding.lastOperation) {
+		return nil, statusError(http.StatusNotFound, "", "binding not found")
+	}
+	return &v2.GetBindingResponse{
+		Credentials: maps.Clone(binding.Credentials),
+		Parameters:  maps.Clone(binding.Parameters),
+	}, nil
+}
+
+// CheckAvailability implements the Client.CheckAvailability method for the
+// StatefulClient.
+func (c *StatefulClient) CheckAvailability(_ context.Context, _ string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.record(CheckAvailability, nil)
+}
+
+// NegotiateAPIVersion implements the Client.NegotiateAPIVersion method for
+// the StatefulClient. It negotiates the API version returned by Version.
+func (c *StatefulClient) NegotiateAPIVersion(_ context.Context) (v2.APIVersion, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if err := c.record(NegotiateAPIVersion, nil); err != nil {
+		return v2.APIVersion{}, err
+	}
+	return c.version(), nil
+}
+
+// Version implements the Client.Version method for the StatefulClient.
+func (c *StatefulClient) Version() v2.APIVersion {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.version()
+}
+
+// Capabilities implements the Client.Capabilities method for the
+// StatefulClient.
+func (c *StatefulClient) Capabilities() v2.Capabilities {
+	return c.Version().Capabilities()
+}
+
+func (c *StatefulClient) version() v2.APIVersion {
+	if c.APIVersion != nil {
+		return *c.APIVersion
+	}
+	return v2.LatestAPIVersion()
+}
+
+// record records the call and returns the error of the first matching
+// failure, if any, using up one of its applications. Callers must hold c.mu.
+func (c *StatefulClient) record(action ActionType, request interface{}) error {
+	c.actions = append(c.actions, Action{Type: action, Request: request})
+
+	for i, f := range c.failures {
+		if f.Action != "" && f.Action != action {
+			continue
+		}
+		if f.Times > 0 {
+			f.Times--
+			if f.Times == 0 {
+				c.failures = append(c.failures[:i:i], c.failures[i+1:]...)
+			}
+		}
+		return f.Error
+	}
+	return nil
+}
+
+// startOperation runs complete right away if the call is handled
+// synchronously. Otherwise it registers an asynchronous operation for the
+// instance and returns its key. Callers must hold c.mu.
+func (c *StatefulClient) startOperation(instanceID string, async bool, complete, fail func()) string {
+	if !async {
+		complete()
+		return ""
+	}
+
+	key := fmt.Sprintf("fake-%d", c.nextID())
+	c.operations[operationKey{instanceID, key}] = &operation{
+		state:     v2.StateInProgress,
+		pollsLeft: c.PollsUntilDone,
+		failed:    c.failNext,
+		complete:  complete,
+		fail:      fail,
+	}
+	c.failNext = false
+	return key
+}
+
+// poll advances the operation with the given key by one poll and returns its
+// state. Callers must hold c.mu.
+func (c *StatefulClient) poll(instanceID, key string) (v2.LastOperationState, error) {
+	op, ok := c.operations[operationKey{instanceID, key}]
+	if !ok {
+		return "", statusError(http.StatusNotFound, "", "operation not found")
+	}
+
+	if op.state != v2.StateInProgress {
+		return op.state, nil
+	}
+	if op.pollsLeft > 0 {
+		op.pollsLeft--
+		return op.state, nil
+	}
+	if op.failed {
+		op.state = v2.StateFailed
+		op.fail()
+	} else {
+		op.state = v2.StateSucceeded
+		op.complete()
+	}
+	return op.state, nil
+}
+
+// inProgress returns whether the operation with the given key is still in
+// progress. Callers must hold c.mu.
+func (c *StatefulClient) inProgress(instanceID, key string) bool {
+	if key == "" {
+		return false
+	}
+	op, ok := c.operations[operationKey{instanceID, key}]
+	return ok && op.state == v2.StateInProgress
+}
+
+// existingInstance looks up the instance a call is for. If it does not exist
+// the call fails with the given status code. If another operation is in
+// progress on it, the call fails with a ConcurrencyError. Callers must hold
+// c.mu.
+func (c *StatefulClient) existingInstance(id string, notFoundStatus int) (*Instance, error) {
+	instance, ok := c.instances[id]
+	if !ok || instance.State == osbtest.StateDeleted {
+		return nil, statusError(notFoundStatus, instanceNotFound, "Instance not found")
+	}
+	if c.inProgress(instance.ID, instance.lastOperation) {
+		return nil, ConcurrencyError()
+	}
+	return instance, nil
+}
+
+// async returns whether a call is handled asynchronously. If the client
+// handles calls asynchronously but the call does not accept incomplete
+// operations, it fails with an AsyncRequired error.
+func (c *StatefulClient) async(acceptsIncomplete bool) (bool, error) {
+	if !c.Async {
+		return false, nil
+	}
+	if !acceptsIncomplete {
+		return false, AsyncRequiredError()
+	}
+	return true, nil
+}
+
+func (c *StatefulClient) inCatalog(serviceID, planID string) bool {
+	for _, service := range c.catalog.Services {
+		if service.ID != serviceID {
+			continue
+		}
+		for _, plan := range service.Plans {
+			if plan.ID == planID {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// getInstanceResponse returns the a9s representation of the instance.
+// Callers must hold c.mu.
+func (c *StatefulClient) getInstanceResponse(instance *Instance) v2.GetInstanceResponse {
+	response := v2.GetInstanceResponse{
+		ID:             instance.number,
+		PlanGUID:       instance.PlanID,
+		ServiceGUID:    instance.ServiceID,
+		DeploymentName: fmt.Sprintf("fake-%d", instance.number),
+		State:          instance.State,
+		GUIDAtTenant:   instance.ID,
+		TenantID:       "fake",
+		Credentials:    []v2.Credential{},
+		Context: v2.Context{
+			OrganizationGUID: instance.OrganizationGUID,
+			SpaceGUID:        instance.SpaceGUID,
+		},
+	}
+
+	var bindings []*Binding
+	for _, binding := range c.bindings {
+		if binding.InstanceID == instance.ID {
+			bindings = append(bindings, binding)
+		}
+	}
+	slices.SortFunc(bindings, func(a, b *Binding) int { return a.number - b.number })
+	for _, binding := range bindings {
+		response.Credentials = append(response.Credentials, v2.Credential{
+			ID:           binding.number,
+			InstanceID:   instance.number,
+			GUIDAtTenant: binding.ID,
+		})
+	}
+	return response
+}
+
+func (c *StatefulClient) nextID() int {
+	c.sequence++
+	return c.sequence
+}
+
+func operationKeyPtr(key string) *v2.OperationKey {
+	operationKey := v2.OperationKey(key)
+	return &operationKey
+}
+
+// statusError returns the error of a broker response with the given status
+// code, error message and description. Empty fields are omitted.
+func statusError(statusCode int, errorMessage, description string) error {
+	err := v2.HTTPStatusCodeError{StatusCode: statusCode}
+	if errorMessage != "" {
+		err.ErrorMessage = strPtr(errorMessage)
+	}
+	if description != "" {
+		err.Description = strPtr(description)
+	}
+	return err
+}
+
+// mergeParameters applies an update to the given parameters. Parameters set
+// to null in the update are removed.
+func mergeParameters(parameters, update map[string]interface{}) map[string]interface{} {
+	merged := maps.Clone(parameters)
+	if merged == nil {
+		merged = map[string]interface{}{}
+	}
+	for key, value := range update {
+		if value == nil {
+			delete(merged, key)
+			continue
+		}
+		merged[key] = value
+	}
+	return merged
+}
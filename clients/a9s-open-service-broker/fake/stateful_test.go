@@ -0,0 +1,307 @@
+/*
+Copyright 2024 Klutch Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package fake_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	v2 "github.com/anynines/klutchio/clients/a9s-open-service-broker"
+	"github.com/anynines/klutchio/clients/a9s-open-service-broker/fake"
+	"github.com/anynines/klutchio/clients/a9s-open-service-broker/osbtest"
+)
+
+const (
+	statefulInstanceID = "stateful-instance"
+	statefulBindingID  = "stateful-binding"
+)
+
+func statefulProvisionRequest() *v2.ProvisionRequest {
+	catalog := catalogResponse()
+	return &v2.ProvisionRequest{
+		InstanceID:        statefulInstanceID,
+		AcceptsIncomplete: true,
+		ServiceID:         catalog.Services[0].ID,
+		PlanID:            catalog.Services[0].Plans[0].ID,
+		OrganizationGUID:  "test-organization-guid",
+		SpaceGUID:         "test-space-guid",
+		Parameters:        map[string]interface{}{"a": "b"},
+	}
+}
+
+func statefulDeprovisionRequest() *v2.DeprovisionRequest {
+	provision := statefulProvisionRequest()
+	return &v2.DeprovisionRequest{
+		InstanceID:        statefulInstanceID,
+		AcceptsIncomplete: true,
+		ServiceID:         provision.ServiceID,
+		PlanID:            provision.PlanID,
+	}
+}
+
+func statefulBindRequest() *v2.BindRequest {
+	provision := statefulProvisionRequest()
+	return &v2.BindRequest{
+		BindingID:         statefulBindingID,
+		InstanceID:        statefulInstanceID,
+		AcceptsIncomplete: true,
+		ServiceID:         provision.ServiceID,
+		PlanID:            provision.PlanID,
+	}
+}
+
+// pollUntilDone polls the last operation of the instance until it is no
+// longer in progress and returns its final state.
+func pollUntilDone(t *testing.T, client *fake.StatefulClient, key *v2.OperationKey) v2.LastOperationState {
+	t.Helper()
+
+	for i := 0; i <= client.PollsUntilDone; i++ {
+		response, err := client.PollLastOperation(context.Background(), &v2.LastOperationRequest{
+			InstanceID:   statefulInstanceID,
+			OperationKey: key,
+		})
+		if err != nil {
+			t.Fatalf("PollLastOperation: unexpected error: %v", err)
+		}
+		if response.State != v2.StateInProgress {
+			return response.State
+		}
+	}
+	t.Fatalf("operation still in progress after %d polls", client.PollsUntilDone+1)
+	return ""
+}
+
+func TestStatefulClientInstanceLifecycle(t *testing.T) {
+	ctx := context.Background()
+	client := fake.NewStatefulClient(catalogResponse())
+	client.Async = true
+	client.PollsUntilDone = 2
+
+	provision, err := client.ProvisionInstance(ctx, statefulProvisionRequest())
+	if err != nil {
+		t.Fatalf("ProvisionInstance: unexpected error: %v", err)
+	}
+	if !provision.Async || provision.OperationKey == nil {
+		t.Fatalf("ProvisionInstance: want asynchronous response with operation key, got %+v", provision)
+	}
+
+	if _, err := client.ProvisionInstance(ctx, statefulProvisionRequest()); err != nil {
+		t.Errorf("ProvisionInstance: identical request must be accepted, got %v", err)
+	}
+	if _, err := client.DeprovisionInstance(ctx, statefulDeprovisionRequest()); !v2.IsConcurrencyError(err) {
+		t.Errorf("DeprovisionInstance: want ConcurrencyError while provisioning, got %v", err)
+	}
+	if instance, _ := client.Instance(statefulInstanceID); instance.State != osbtest.StateDeploying {
+		t.Errorf("want instance in state %q, got %q", osbtest.StateDeploying, instance.State)
+	}
+
+	if state := pollUntilDone(t, client, provision.OperationKey); state != v2.StateSucceeded {
+		t.Fatalf("want provision to succeed, got %q", state)
+	}
+	got, err := client.GetInstance(ctx, &v2.GetInstanceRequest{InstanceID: statefulInstanceID})
+	if err != nil {
+		t.Fatalf("GetInstance: unexpected error: %v", err)
+	}
+	if got.State != osbtest.StateProvisioned || got.GUIDAtTenant != statefulInstanceID {
+		t.Errorf("GetInstance: unexpected response %+v", got)
+	}
+
+	conflicting := statefulProvisionRequest()
+	conflicting.Parameters = map[string]interface{}{"a": "c"}
+	if _, err := client.ProvisionInstance(ctx, conflicting); !v2.IsConflictError(err) {
+		t.Errorf("ProvisionInstance: want conflict for different parameters, got %v", err)
+	}
+
+	deprovision, err := client.DeprovisionInstance(ctx, statefulDeprovisionRequest())
+	if err != nil {
+		t.Fatalf("DeprovisionInstance: unexpected error: %v", err)
+	}
+	if state := pollUntilDone(t, client, deprovision.OperationKey); state != v2.StateSucceeded {
+		t.Fatalf("want deprovision to succeed, got %q", state)
+	}
+	if _, err := client.DeprovisionInstance(ctx, statefulDeprovisionRequest()); !v2.IsGoneError(err) {
+		t.Errorf("DeprovisionInstance: want gone after deprovision, got %v", err)
+	}
+	if _, err := client.GetServiceInstance(ctx, &v2.GetInstanceRequest{InstanceID: statefulInstanceID}); err == nil {
+		t.Error("GetServiceInstance: want error for deleted instance")
+	}
+}
+
+func TestStatefulClientErrors(t *testing.T) {
+	cases := map[string]struct {
+		async bool
+		setup func(c *fake.StatefulClient)
+		call  func(c *fake.StatefulClient) error
+		check func(err error) bool
+	}{
+		"UnknownPlan": {
+			call: func(c *fake.StatefulClient) error {
+				r := statefulProvisionRequest()
+				r.PlanID = "unknown"
+				_, err := c.ProvisionInstance(context.Background(), r)
+				return err
+			},
+			check: func(err error) bool {
+				httpErr, ok := v2.IsHTTPError(err)
+				return ok && httpErr.StatusCode == http.StatusBadRequest
+			},
+		},
+		"AsyncRequired": {
+			async: true,
+			call: func(c *fake.StatefulClient) error {
+				r := statefulProvisionRequest()
+				r.AcceptsIncomplete = false
+				_, err := c.ProvisionInstance(context.Background(), r)
+				return err
+			},
+			check: v2.IsAsyncRequiredError,
+		},
+		"DeprovisionUnknownInstance": {
+			call: func(c *fake.StatefulClient) error {
+				_, err := c.DeprovisionInstance(context.Background(), statefulDeprovisionRequest())
+				return err
+			},
+			check: v2.IsGoneError,
+		},
+		"UpdateUnknownInstance": {
+			call: func(c *fake.StatefulClient) error {
+				_, err := c.UpdateInstance(context.Background(), &v2.UpdateInstanceRequest{
+					InstanceID: statefulInstanceID,
+					ServiceID:  statefulProvisionRequest().ServiceID,
+				})
+				return err
+			},
+			check: func(err error) bool {
+				httpErr, ok := v2.IsHTTPError(err)
+				return ok && httpErr.StatusCode == http.StatusNotFound
+			},
+		},
+		"UnbindUnknownBinding": {
+			setup: func(c *fake.StatefulClient) {
+				c.ProvisionInstance(context.Background(), statefulProvisionRequest())
+			},
+			call: func(c *fake.StatefulClient) error {
+				_, err := c.Unbind(context.Background(), &v2.UnbindRequest{InstanceID: statefulInstanceID, BindingID: statefulBindingID})
+				return err
+			},
+			check: v2.IsGoneError,
+		},
+		"InjectedFailure": {
+			setup: func(c *fake.StatefulClient) {
+				c.InjectFailure(&fake.Failure{Action: fake.ProvisionInstance, Error: errors.New("oops"), Times: 1})
+			},
+			call: func(c *fake.StatefulClient) error {
+				_, err := c.ProvisionInstance(context.Background(), statefulProvisionRequest())
+				return err
+			},
+			check: func(err error) bool { return err != nil && err.Error() == "oops" },
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			client := fake.NewStatefulClient(catalogResponse())
+			client.Async = tc.async
+			if tc.setup != nil {
+				tc.setup(client)
+			}
+			if err := tc.call(client); !tc.check(err) {
+				t.Errorf("unexpected error: %v", err)
+			}
+		})
+	}
+}
+
+func TestStatefulClientInjectedFailureTimes(t *testing.T) {
+	client := fake.NewStatefulClient(nil)
+	client.InjectFailure(&fake.Failure{Error: fake.ConcurrencyError(), Times: 2})
+
+	for i := 0; i < 2; i++ {
+		if _, err := client.GetCatalog(context.Background()); !v2.IsConcurrencyError(err) {
+			t.Errorf("call %d: want injected failure, got %v", i, err)
+		}
+	}
+	if _, err := client.GetCatalog(context.Background()); err != nil {
+		t.Errorf("want failure to be used up, got %v", err)
+	}
+	if got := len(client.Actions()); got != 3 {
+		t.Errorf("want 3 recorded actions, got %d", got)
+	}
+}
+
+func TestStatefulClientFailNextOperation(t *testing.T) {
+	client := fake.NewStatefulClient(catalogResponse())
+	client.Async = true
+	client.FailNextOperation()
+
+	provision, err := client.ProvisionInstance(context.Background(), statefulProvisionRequest())
+	if err != nil {
+		t.Fatalf("ProvisionInstance: unexpected error: %v", err)
+	}
+	if state := pollUntilDone(t, client, provision.OperationKey); state != v2.StateFailed {
+		t.Errorf("want provision to fail, got %q", state)
+	}
+	if instance, _ := client.Instance(statefulInstanceID); instance.State != osbtest.StateFailed {
+		t.Errorf("want instance in state %q, got %q", osbtest.StateFailed, instance.State)
+	}
+}
+
+func TestStatefulClientBindings(t *testing.T) {
+	ctx := context.Background()
+	client := fake.NewStatefulClient(catalogResponse())
+
+	if _, err := client.Bind(ctx, statefulBindRequest()); err == nil {
+		t.Error("Bind: want error for unknown instance")
+	}
+	if _, err := client.ProvisionInstance(ctx, statefulProvisionRequest()); err != nil {
+		t.Fatalf("ProvisionInstance: unexpected error: %v", err)
+	}
+
+	bind, err := client.Bind(ctx, statefulBindRequest())
+	if err != nil {
+		t.Fatalf("Bind: unexpected error: %v", err)
+	}
+	if bind.Credentials["username"] != "user-"+statefulBindingID {
+		t.Errorf("Bind: unexpected credentials %v", bind.Credentials)
+	}
+
+	conflicting := statefulBindRequest()
+	conflicting.Parameters = map[string]interface{}{"a": "b"}
+	if _, err := client.Bind(ctx, conflicting); !v2.IsConflictError(err) {
+		t.Errorf("Bind: want conflict for different parameters, got %v", err)
+	}
+
+	instance, err := client.GetInstance(ctx, &v2.GetInstanceRequest{InstanceID: statefulInstanceID})
+	if err != nil {
+		t.Fatalf("GetInstance: unexpected error: %v", err)
+	}
+	if len(instance.Credentials) != 1 || instance.Credentials[0].GUIDAtTenant != statefulBindingID {
+		t.Errorf("GetInstance: want binding in credentials, got %+v", instance.Credentials)
+	}
+
+	if _, err := client.Unbind(ctx, &v2.UnbindRequest{InstanceID: statefulInstanceID, BindingID: statefulBindingID}); err != nil {
+		t.Fatalf("Unbind: unexpected error: %v", err)
+	}
+	if _, ok := client.Binding(statefulInstanceID, statefulBindingID); ok {
+		t.Error("want binding to be removed after unbind")
+	}
+	if _, err := client.GetBinding(ctx, &v2.GetBindingRequest{InstanceID: statefulInstanceID, BindingID: statefulBindingID}); err == nil {
+		t.Error("GetBinding: want error after unbind")
+	}
+}
@@ -17,11 +17,12 @@ limitations under the License.
 package v2
 
 import (
+	"context"
 	"fmt"
 	"net/http"
 )
 
-func (c *client) GetBinding(r *GetBindingRequest) (*GetBindingResponse, error) {
+func (c *client) GetBinding(ctx context.Context, r *GetBindingRequest) (*GetBindingResponse, error) {
 	if err := c.validateClientVersionIsAtLeast(Version2_14()); err != nil {
 		return nil, GetBindingNotAllowedError{
 			reason: err.Error(),
@@ -30,7 +31,10 @@ func (c *client) GetBinding(r *GetBindingRequest) (*GetBindingResponse, error) {
 
 	fullURL := fmt.Sprintf(bindingURLFmt, c.URL, r.InstanceID, r.BindingID)
 
-	response, err := c.prepareAndDo(http.MethodGet, fullURL, nil /* params */, nil /* request body */, nil /* originating identity */)
+	ctx, span := c.startSpan(ctx, operationGetBinding, r, attributeInstanceID.String(r.InstanceID), attributeBindingID.String(r.BindingID))
+	defer span.End()
+
+	response, err := c.prepareAndDo(ctx, http.MethodGet, fullURL, nil /* params */, nil /* request body */, nil /* originating identity */)
 	if err != nil {
 		return nil, err
 	}
@@ -50,6 +54,9 @@ func (c *client) GetBinding(r *GetBindingRequest) (*GetBindingResponse, error) {
 		if !c.EnableAlphaFeatures {
 			userResponse.Endpoints = nil
 		}
+		if err := c.validateResponse(ctx, getBindingViolations(userResponse)); err != nil {
+			return nil, err
+		}
 
 		return userResponse, nil
 	default:
@@ -17,6 +17,7 @@ limitations under the License.
 package v2
 
 import (
+	"context"
 	"fmt"
 	"net/http"
 	"testing"
@@ -156,7 +157,7 @@ func TestGetBinding(t *testing.T) {
 
 		klient := newTestClient(t, tc.name, tc.APIVersion, tc.enableAlpha, httpChecks, tc.httpReaction)
 
-		response, err := klient.GetBinding(tc.request)
+		response, err := klient.GetBinding(context.Background(), tc.request)
 
 		doResponseChecks(t, tc.name, response, err, tc.expectedResponse, tc.expectedErrMessage, tc.expectedErr)
 	}
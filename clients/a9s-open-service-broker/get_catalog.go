@@ -17,16 +17,19 @@ limitations under the License.
 package v2
 
 import (
+	"context"
 	"fmt"
 	"net/http"
-
-	"k8s.io/klog/v2"
 )
 
-func (c *client) GetCatalog() (*CatalogResponse, error) {
+func (c *client) GetCatalog(ctx context.Context) (*CatalogResponse, error) {
+	if c.catalogCache != nil {
+		return c.catalogCache.get(ctx, c.catalogCacheKey(), c.URL, c.fetchCatalog)
+	}
+
 	catalog := c.getCatalogFromCache()
 	if catalog == nil {
-		return c.getCatalogFromBroker()
+		return c.getCatalogFromBroker(ctx)
 	} else {
 		return catalog, nil
 	}
@@ -38,12 +41,12 @@ func (c *client) getCatalogFromCache() *CatalogResponse {
 	} else {
 		catalog, exists, err := c.cache.GetByKey("catalog")
 		if err != nil {
-			klog.Info(fmt.Sprintf("There was an error retrieving the catalog from the cache\n%v", err))
+			c.logger.Error(err, "Cannot retrieve the catalog from the cache")
 			return nil
 		}
 		if !exists {
 			if c.Verbose {
-				klog.Info("Catalog was not found in cache - cache will be refreshed")
+				c.logger.Info("Catalog was not found in cache - cache will be refreshed")
 			}
 			return nil
 		}
@@ -53,30 +56,61 @@ func (c *client) getCatalogFromCache() *CatalogResponse {
 		}
 
 		if c.Verbose {
-			klog.Info("Fetching Catalog from cache")
+			c.logger.Info("Fetching Catalog from cache")
 		}
 
 		return convertedCatalog.value
 	}
 }
 
-func (c *client) setCatalogInCache(catalog *CatalogResponse) {
+// setCatalogInCache caches a catalog the client fetched from the broker.
+func (c *client) setCatalogInCache(entry *catalogCacheEntry) {
+	if c.catalogCache != nil {
+		c.catalogCache.set(c.catalogCacheKey(), c.URL, entry)
+		return
+	}
 	if c.cache != nil {
 		err := c.cache.Add(cachedCatalog{
 			key:   "catalog",
-			value: catalog,
+			value: entry.catalog,
 		})
 
 		if err != nil {
-			klog.Info(fmt.Sprintf("Unexpected error occurred while saving the catalog in the cache\n%v", err))
+			c.logger.Error(err, "Cannot save the catalog in the cache")
 		}
 	}
 }
 
-func (c *client) getCatalogFromBroker() (*CatalogResponse, error) {
+func (c *client) getCatalogFromBroker(ctx context.Context) (*CatalogResponse, error) {
+	entry, err := c.fetchCatalog(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	c.setCatalogInCache(entry)
+	return entry.catalog, nil
+}
+
+// fetchCatalog requests the catalog from the broker. If cached is set, the
+// request is conditional on the catalog having changed since, and cached is
+// returned if the broker answers 304 Not Modified.
+func (c *client) fetchCatalog(ctx context.Context, cached *catalogCacheEntry) (*catalogCacheEntry, error) {
 	fullURL := fmt.Sprintf(catalogURL, c.URL)
 
-	response, err := c.prepareAndDo(http.MethodGet, fullURL, nil /* params */, nil /* request body */, nil /* originating identity */)
+	ctx, span := c.startSpan(ctx, operationCatalog, nil)
+	defer span.End()
+
+	if cached != nil {
+		header := http.Header{}
+		if cached.etag != "" {
+			header.Set(ifNoneMatchHeader, cached.etag)
+		}
+		if cached.lastModified != "" {
+			header.Set(ifModifiedSinceHeader, cached.lastModified)
+		}
+		ctx = withRequestHeader(ctx, header)
+	}
+
+	response, err := c.prepareAndDo(ctx, http.MethodGet, fullURL, nil /* params */, nil /* request body */, nil /* originating identity */)
 	if err != nil {
 		return nil, err
 	}
@@ -86,33 +120,52 @@ func (c *client) getCatalogFromBroker() (*CatalogResponse, error) {
 		response.Body.Close()
 	}()
 
-	switch response.StatusCode {
-	case http.StatusOK:
-		catalogResponse := &CatalogResponse{}
-		if err := c.unmarshalResponse(response, catalogResponse); err != nil {
-			return nil, HTTPStatusCodeError{StatusCode: response.StatusCode, ResponseError: err}
-		}
-
-		if c.APIVersion.IsLessThan(Version2_13()) || !c.EnableAlphaFeatures {
-			c.pruneCatalogResponse(catalogResponse)
+	switch {
+	case response.StatusCode == http.StatusOK:
+		return c.catalogEntryFromResponse(ctx, response)
+	case response.StatusCode == http.StatusNotModified && cached != nil:
+		if c.Verbose {
+			c.logger.Info("Catalog was not modified")
 		}
-
-		c.setCatalogInCache(catalogResponse)
-
-		return catalogResponse, nil
+		return &catalogCacheEntry{
+			catalog:      cached.catalog,
+			etag:         cached.etag,
+			lastModified: cached.lastModified,
+		}, nil
 	default:
 		return nil, c.handleFailureResponse(response)
 	}
 }
 
+// catalogEntryFromResponse decodes the catalog of a successful catalog
+// response, along with its ETag and Last-Modified headers.
+func (c *client) catalogEntryFromResponse(ctx context.Context, response *http.Response) (*catalogCacheEntry, error) {
+	catalogResponse := &CatalogResponse{}
+	if err := c.unmarshalResponse(response, catalogResponse); err != nil {
+		return nil, HTTPStatusCodeError{StatusCode: response.StatusCode, ResponseError: err}
+	}
+	if err := c.validateResponse(ctx, catalogViolations(catalogResponse)); err != nil {
+		return nil, err
+	}
+
+	if c.Version().IsLessThan(Version2_13()) || !c.EnableAlphaFeatures {
+		c.pruneCatalogResponse(catalogResponse)
+	}
+
+	return &catalogCacheEntry{
+		catalog:      catalogResponse,
+		etag:         response.Header.Get(etagHeader),
+		lastModified: response.Header.Get(lastModifiedHeader),
+	}, nil
+}
+
 func (c *client) pruneCatalogResponse(catalogResponse *CatalogResponse) {
 	for ii := range catalogResponse.Services {
 		for jj := range catalogResponse.Services[ii].Plans {
-			if c.APIVersion.IsLessThan(Version2_13()) {
+			if c.Version().IsLessThan(Version2_13()) {
 				catalogResponse.Services[ii].Plans[jj].Schemas = nil
 			}
 			if !c.EnableAlphaFeatures {
-				catalogResponse.Services[ii].Plans[jj].MaintenanceInfo = nil
 				catalogResponse.Services[ii].Plans[jj].MaximumPollingDuration = nil
 				catalogResponse.Services[ii].Plans[jj].PlanUpdateable = nil
 			}
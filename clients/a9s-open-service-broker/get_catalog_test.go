@@ -17,6 +17,7 @@ limitations under the License.
 package v2
 
 import (
+	"context"
 	"fmt"
 	"net/http"
 	"reflect"
@@ -252,6 +253,16 @@ func okCatalog215Response() *CatalogResponse {
 	return response
 }
 
+func maintenanceInfoCatalogResponse() *CatalogResponse {
+	response := okCatalog2Response()
+	response.Services[0].Plans[0].MaintenanceInfo = &MaintenanceInfo{
+		Version:     "1.2.3",
+		Description: "Avast! Pieces o' madness are forever clear.",
+	}
+
+	return response
+}
+
 func TestGetCatalogWithoutCache(t *testing.T) {
 	cases := []struct {
 		name               string
@@ -338,14 +349,14 @@ func TestGetCatalogWithoutCache(t *testing.T) {
 			expectedResponse: okCatalog215Response(),
 		},
 		{
-			name:        "alpha disabled: plan has its own updateable attribute, max polling duration and maintenance info",
+			name:        "alpha disabled: plan keeps only its maintenance info",
 			version:     LatestAPIVersion(),
 			enableAlpha: false,
 			httpReaction: httpReaction{
 				status: http.StatusOK,
 				body:   okCatalog215Bytes,
 			},
-			expectedResponse: okCatalog2Response(),
+			expectedResponse: maintenanceInfoCatalogResponse(),
 		},
 	}
 
@@ -360,7 +371,7 @@ func TestGetCatalogWithoutCache(t *testing.T) {
 
 		klient := newTestClient(t, tc.name, tc.version, tc.enableAlpha, httpChecks, tc.httpReaction)
 
-		response, err := klient.GetCatalog()
+		response, err := klient.GetCatalog(context.Background())
 
 		doResponseChecks(t, tc.name, response, err, tc.expectedResponse, tc.expectedErrMessage, tc.expectedErr)
 	}
@@ -430,14 +441,14 @@ func TestGetCatalogWithCache(t *testing.T) {
 			expectedResponse: okCatalog215Response(),
 		},
 		{
-			name:        "alpha disabled: plan has its own updateable attribute, max polling duration and maintenance info",
+			name:        "alpha disabled: plan keeps only its maintenance info",
 			version:     LatestAPIVersion(),
 			enableAlpha: false,
 			httpReaction: httpReaction{
 				status: http.StatusOK,
 				body:   okCatalog215Bytes,
 			},
-			expectedResponse: okCatalog2Response(),
+			expectedResponse: maintenanceInfoCatalogResponse(),
 		},
 		{
 			name:             "stale cache and broker reachable",
@@ -479,7 +490,7 @@ func TestGetCatalogWithCache(t *testing.T) {
 			klient.cache = cache.NewTTLStore(cacheKeyFunc, 1*time.Second)
 
 			// fill up cache
-			response, err := klient.GetCatalog()
+			response, err := klient.GetCatalog(context.Background())
 			doResponseChecks(t, tc.name, response, err, tc.expectedResponse, tc.expectedErrMessage, tc.expectedErr)
 
 			// disable server response
@@ -488,11 +499,11 @@ func TestGetCatalogWithCache(t *testing.T) {
 				time.Sleep(2 * time.Second)
 
 				// read from cache
-				response, err = klient.GetCatalog()
+				response, err = klient.GetCatalog(context.Background())
 				doResponseChecks(t, tc.name, response, err, tc.expectedResponseAfterStaleness, tc.expectedErrMessageAfterStaleness, tc.expectedErrAfterStaleness)
 			} else {
 				// read from cache
-				response, err = klient.GetCatalog()
+				response, err = klient.GetCatalog(context.Background())
 				doResponseChecks(t, tc.name, response, err, tc.expectedResponse, tc.expectedErrMessage, tc.expectedErr)
 			}
 		})
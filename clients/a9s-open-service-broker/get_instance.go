@@ -17,11 +17,12 @@ limitations under the License.
 package v2
 
 import (
+	"context"
 	"fmt"
 	"net/http"
 )
 
-func (c *client) GetInstance(r *GetInstanceRequest) (*GetInstanceResponse, error) {
+func (c *client) GetInstance(ctx context.Context, r *GetInstanceRequest) (*GetInstanceResponse, error) {
 	if err := c.validateClientVersionIsAtLeast(Version2_14()); err != nil {
 		return nil, GetInstanceNotAllowedError{
 			reason: err.Error(),
@@ -30,7 +31,10 @@ func (c *client) GetInstance(r *GetInstanceRequest) (*GetInstanceResponse, error
 
 	fullURL := fmt.Sprintf(instanceURLFmt, c.URL, r.InstanceID)
 
-	response, err := c.prepareAndDo(http.MethodGet, fullURL, nil /* params */, nil /* request body */, nil /* originating identity */)
+	ctx, span := c.startSpan(ctx, operationGetInstance, r, attributeInstanceID.String(r.InstanceID))
+	defer span.End()
+
+	response, err := c.prepareAndDo(ctx, http.MethodGet, fullURL, nil /* params */, nil /* request body */, nil /* originating identity */)
 	if err != nil {
 		return nil, err
 	}
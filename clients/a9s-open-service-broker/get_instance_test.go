@@ -17,6 +17,7 @@ limitations under the License.
 package v2
 
 import (
+	"context"
 	"fmt"
 	"net/http"
 	"testing"
@@ -123,7 +124,7 @@ func TestGetInstance(t *testing.T) {
 
 		klient := newTestClient(t, tc.name, tc.APIVersion, tc.enableAlpha, httpChecks, tc.httpReaction)
 
-		response, err := klient.GetInstance(tc.request)
+		response, err := klient.GetInstance(context.Background(), tc.request)
 
 		doResponseChecks(t, tc.name, response, err, tc.expectedResponse, tc.expectedErrMessage, tc.expectedErr)
 	}
@@ -17,11 +17,12 @@ limitations under the License.
 package v2
 
 import (
+	"context"
 	"fmt"
 	"net/http"
 )
 
-func (c *client) GetInstances() (*GetInstancesResponse, error) {
+func (c *client) GetInstances(ctx context.Context) (*GetInstancesResponse, error) {
 	if err := c.validateClientVersionIsAtLeast(Version2_14()); err != nil {
 		return nil, GetInstanceNotAllowedError{
 			reason: err.Error(),
@@ -30,7 +31,10 @@ func (c *client) GetInstances() (*GetInstancesResponse, error) {
 
 	fullURL := fmt.Sprintf(instancesURLFmt, c.URL)
 
-	response, err := c.prepareAndDo(http.MethodGet, fullURL, nil /* params */, nil /* request body */, nil /* originating identity */)
+	ctx, span := c.startSpan(ctx, operationGetInstances, nil)
+	defer span.End()
+
+	response, err := c.prepareAndDo(ctx, http.MethodGet, fullURL, nil /* params */, nil /* request body */, nil /* originating identity */)
 	if err != nil {
 		return nil, err
 	}
@@ -17,18 +17,23 @@ limitations under the License.
 package v2
 
 import (
+	"context"
 	"fmt"
 	"net/http"
 )
 
-func (c *client) GetOperation(r *GetOperationRequest) (*GetOperationResponse, error) {
+func (c *client) GetOperation(ctx context.Context, r *GetOperationRequest) (*GetOperationResponse, error) {
 	fullUrl := fmt.Sprintf(lastOperationURLFmt, c.URL, r.InstanceID)
 
 	params := map[string]string{}
+	if r.OperationKey != "" {
+		params[VarKeyOperation] = string(r.OperationKey)
+	}
 
-	params[VarKeyOperation] = string(r.OperationKey)
+	ctx, span := c.startSpan(ctx, operationLastOperation, r, attributeInstanceID.String(r.InstanceID))
+	defer span.End()
 
-	response, err := c.prepareAndDo(http.MethodGet, fullUrl, params, nil /* request body */, nil /* originating identity */)
+	response, err := c.prepareAndDo(ctx, http.MethodGet, fullUrl, params, nil /* request body */, nil /* originating identity */)
 	if err != nil {
 		return nil, err
 	}
@@ -44,6 +49,10 @@ func (c *client) GetOperation(r *GetOperationRequest) (*GetOperationResponse, er
 		if err := c.unmarshalResponse(response, userResponse); err != nil {
 			return nil, HTTPStatusCodeError{StatusCode: response.StatusCode, ResponseError: err}
 		}
+		if err := c.validateResponse(ctx, lastOperationViolations(LastOperationState(userResponse.State))); err != nil {
+			return nil, err
+		}
+		userResponse.PollDelay = pollDelayFromHeader(response.Header)
 
 		return userResponse, nil
 	default:
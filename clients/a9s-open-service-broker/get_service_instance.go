@@ -17,11 +17,12 @@ limitations under the License.
 package v2
 
 import (
+	"context"
 	"fmt"
 	"net/http"
 )
 
-func (c *client) GetServiceInstance(r *GetInstanceRequest) (*GetServiceInstanceResponse, error) {
+func (c *client) GetServiceInstance(ctx context.Context, r *GetInstanceRequest) (*GetServiceInstanceResponse, error) {
 	if err := c.validateClientVersionIsAtLeast(Version2_14()); err != nil {
 		return nil, GetServiceInstanceNotAllowedError{
 			reason: err.Error(),
@@ -30,7 +31,10 @@ func (c *client) GetServiceInstance(r *GetInstanceRequest) (*GetServiceInstanceR
 
 	fullURL := fmt.Sprintf(serviceInstanceURLFmt, c.URL, r.InstanceID)
 
-	response, err := c.prepareAndDo(http.MethodGet, fullURL, nil /* params */, nil /* request body */, nil /* originating identity */)
+	ctx, span := c.startSpan(ctx, operationGetServiceInstance, r, attributeInstanceID.String(r.InstanceID))
+	defer span.End()
+
+	response, err := c.prepareAndDo(ctx, http.MethodGet, fullURL, nil /* params */, nil /* request body */, nil /* originating identity */)
 	if err != nil {
 		return nil, err
 	}
@@ -46,6 +50,9 @@ func (c *client) GetServiceInstance(r *GetInstanceRequest) (*GetServiceInstanceR
 		if err := c.unmarshalResponse(response, userResponse); err != nil {
 			return nil, HTTPStatusCodeError{StatusCode: response.StatusCode, ResponseError: err}
 		}
+		if err := c.validateResponse(ctx, getServiceInstanceViolations(userResponse)); err != nil {
+			return nil, err
+		}
 
 		return userResponse, nil
 	default:
@@ -0,0 +1,80 @@
+/*
+Copyright 2024 Klutch Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v2
+
+import (
+	"context"
+	"net/http"
+)
+
+// Invocation describes the call of a client method that a request is sent
+// for.
+type Invocation struct {
+	// Operation is the name of the operation, e.g. "provision" or "bind",
+	// which is also the operation label of the metrics of the request.
+	Operation string
+	// Request is the request the method was called with, e.g. a
+	// *ProvisionRequest. It is nil for methods without request, such as
+	// GetCatalog.
+	Request interface{}
+}
+
+// Invoker sends a request to the broker.
+type Invoker func(request *http.Request) (*http.Response, error)
+
+// Interceptor intercepts the requests a client sends to the broker, e.g. to
+// add headers, limit the rate of requests, audit or inject faults. It is
+// called with the invocation the request is sent for and must call next to
+// send the request, possibly a modified copy of it, unless it answers the
+// request by itself.
+//
+// Interceptors are called for every attempt to send a request, i.e. once per
+// endpoint a request fails over to and again for requests that are retried
+// with a refreshed OAuth2 token. The outcome they return is what is traced,
+// logged and used for failover.
+type Interceptor func(invocation Invocation, request *http.Request, next Invoker) (*http.Response, error)
+
+type invocationContextKey struct{}
+
+// withInvocation returns a copy of ctx that carries the invocation a request
+// is sent for.
+func withInvocation(ctx context.Context, invocation Invocation) context.Context {
+	return context.WithValue(ctx, invocationContextKey{}, invocation)
+}
+
+func invocationFromContext(ctx context.Context) Invocation {
+	if invocation, ok := ctx.Value(invocationContextKey{}).(Invocation); ok {
+		return invocation
+	}
+	return Invocation{Operation: operationUnknown}
+}
+
+// intercept sends request with invoke through interceptors, the first of
+// which is the outermost.
+func intercept(interceptors []Interceptor, invocation Invocation, request *http.Request, invoke Invoker) (*http.Response, error) {
+	if len(interceptors) == 0 {
+		return invoke(request)
+	}
+	return interceptors[0](invocation, request, func(request *http.Request) (*http.Response, error) {
+		return intercept(interceptors[1:], invocation, request, invoke)
+	})
+}
+
+// do sends the request through the interceptors of the client.
+func (c *client) do(request *http.Request) (*http.Response, error) {
+	return intercept(c.interceptors, invocationFromContext(request.Context()), request, Invoker(c.doRequestFunc))
+}
@@ -0,0 +1,166 @@
+/*
+Copyright 2024 Klutch Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v2
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestInterceptors(t *testing.T) {
+	errInjected := errors.New("injected fault")
+
+	provisionRequest := &ProvisionRequest{
+		InstanceID:       "instance-id",
+		ServiceID:        "service-id",
+		PlanID:           "plan-id",
+		OrganizationGUID: "organization-guid",
+		SpaceGUID:        "space-guid",
+	}
+	provision := func(c *client) error {
+		_, err := c.ProvisionInstance(context.Background(), provisionRequest)
+		return err
+	}
+
+	cases := map[string]struct {
+		interceptors func(calls *[]string) []Interceptor
+		request      func(c *client) error
+		wantCalls    []string
+		wantHeader   string
+		wantSent     bool
+		wantErr      error
+	}{
+		"interceptors are called in order": {
+			interceptors: func(calls *[]string) []Interceptor {
+				return []Interceptor{recordingInterceptor("outer", calls), recordingInterceptor("inner", calls)}
+			},
+			request:   getCatalog,
+			wantCalls: []string{"outer", "inner"},
+			wantSent:  true,
+		},
+		"interceptors are called with the invocation": {
+			interceptors: func(calls *[]string) []Interceptor {
+				return []Interceptor{func(invocation Invocation, request *http.Request, next Invoker) (*http.Response, error) {
+					*calls = append(*calls, invocation.Operation)
+					if invocation.Request != provisionRequest {
+						t.Errorf("unexpected request %v", invocation.Request)
+					}
+					return next(request)
+				}}
+			},
+			request:   provision,
+			wantCalls: []string{"provision"},
+			wantSent:  true,
+		},
+		"interceptors may add headers": {
+			interceptors: func(calls *[]string) []Interceptor {
+				return []Interceptor{func(invocation Invocation, request *http.Request, next Invoker) (*http.Response, error) {
+					request.Header.Set("X-Audit", invocation.Operation)
+					return next(request)
+				}}
+			},
+			request:    getCatalog,
+			wantHeader: "catalog",
+			wantSent:   true,
+		},
+		"interceptors may answer requests": {
+			interceptors: func(calls *[]string) []Interceptor {
+				return []Interceptor{
+					func(Invocation, *http.Request, Invoker) (*http.Response, error) { return nil, errInjected },
+					recordingInterceptor("inner", calls),
+				}
+			},
+			request: getCatalog,
+			wantErr: errInjected,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			klient := newTestClient(t, name, Version2_14(), false, httpChecks{}, httpReaction{})
+			var calls []string
+			klient.interceptors = tc.interceptors(&calls)
+
+			sent := false
+			header := ""
+			klient.doRequestFunc = func(request *http.Request) (*http.Response, error) {
+				sent = true
+				header = request.Header.Get("X-Audit")
+				status := http.StatusOK
+				if request.Method == http.MethodPut {
+					status = http.StatusCreated
+				}
+				return &http.Response{StatusCode: status, Body: io.NopCloser(strings.NewReader(okCatalogBytes))}, nil
+			}
+
+			err := tc.request(klient)
+			if !errors.Is(err, tc.wantErr) {
+				t.Errorf("want error %v, got %v", tc.wantErr, err)
+			}
+			if diff := cmp.Diff(tc.wantCalls, calls); diff != "" {
+				t.Errorf("interceptor calls: -want, +got:\n%s", diff)
+			}
+			if sent != tc.wantSent {
+				t.Errorf("want request sent %t, got %t", tc.wantSent, sent)
+			}
+			if header != tc.wantHeader {
+				t.Errorf("want X-Audit header %q, got %q", tc.wantHeader, header)
+			}
+		})
+	}
+}
+
+func TestInterceptorsFailover(t *testing.T) {
+	klient := newTestClient(t, t.Name(), Version2_14(), false, httpChecks{}, httpReaction{})
+	klient.URL = "https://a.example.com"
+	klient.endpoints = []string{"https://a.example.com", "https://b.example.com"}
+	klient.endpointHealth = NewEndpointHealth(time.Minute)
+
+	var hosts []string
+	klient.interceptors = []Interceptor{func(invocation Invocation, request *http.Request, next Invoker) (*http.Response, error) {
+		hosts = append(hosts, request.URL.Host)
+		if request.URL.Host == "a.example.com" {
+			return nil, errConnectionRefused
+		}
+		return next(request)
+	}}
+	klient.doRequestFunc = func(request *http.Request) (*http.Response, error) {
+		return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(strings.NewReader(okCatalogBytes))}, nil
+	}
+
+	if err := getCatalog(klient); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if diff := cmp.Diff([]string{"a.example.com", "b.example.com"}, hosts); diff != "" {
+		t.Errorf("intercepted hosts: -want, +got:\n%s", diff)
+	}
+}
+
+// recordingInterceptor returns an interceptor that appends name to calls.
+func recordingInterceptor(name string, calls *[]string) Interceptor {
+	return func(invocation Invocation, request *http.Request, next Invoker) (*http.Response, error) {
+		*calls = append(*calls, name)
+		return next(request)
+	}
+}
@@ -17,15 +17,21 @@ limitations under the License.
 package v2
 
 import (
+	"context"
 	"crypto/tls"
+	"net/http"
+
+	"github.com/go-logr/logr"
+	"go.opentelemetry.io/otel/trace"
 )
 
 // AuthConfig is a union-type representing the possible auth configurations a
-// client may use to authenticate to a broker.  Currently, only basic auth is
-// supported.
+// client may use to authenticate to a broker.  Exactly one of its fields must
+// be set.
 type AuthConfig struct {
 	BasicAuthConfig *BasicAuthConfig
 	BearerConfig    *BearerConfig
+	OAuth2Config    *OAuth2Config
 }
 
 // BasicAuthConfig represents a set of basic auth credentials.
@@ -42,6 +48,21 @@ type BearerConfig struct {
 	Token string
 }
 
+// OAuth2Config represents OAuth2 client credentials. The client obtains an
+// access token from the token endpoint, sends it as bearer token with every
+// request and fetches a new one shortly before it expires. If the broker
+// rejects a token with HTTP 401 the request is retried once with a new token.
+type OAuth2Config struct {
+	// TokenURL is the URL of the token endpoint of the authorization server.
+	TokenURL string
+	// ClientID is the OAuth2 client ID.
+	ClientID string
+	// ClientSecret is the OAuth2 client secret.
+	ClientSecret string
+	// Scopes are the scopes to request. If empty, no scope is requested.
+	Scopes []string
+}
+
 // ClientConfiguration represents the configuration of a Client.
 type ClientConfiguration struct {
 	// Name is the name to use for this client in log messages.  Using the
@@ -51,7 +72,9 @@ type ClientConfiguration struct {
 	URL string
 	// APIVersion is the APIVersion to use for this client.  API features
 	// adopted after the 2.11 version of the API will only be sent if
-	// APIVersion is an API version that supports them.
+	// APIVersion is an API version that supports them.  If the client
+	// negotiates the API version with NegotiateAPIVersion, APIVersion is the
+	// highest version it offers the broker.
 	APIVersion APIVersion
 	// AuthInfo is the auth configuration the client should use to authenticate
 	// to the broker.
@@ -71,6 +94,14 @@ type ClientConfiguration struct {
 	// refreshed with a request to the service broker. A value of 0 disables the
 	// cache. The default value is 15 seconds.
 	CacheFreshnessSeconds *int
+	// CatalogCache, if set, is used instead of the catalog cache of the
+	// client, so that clients for the same broker share the catalog they
+	// fetched. Its freshness overrides CacheFreshnessSeconds.
+	CatalogCache *CatalogCache
+	// TokenCache, if set, caches the OAuth2 access tokens of the client, so
+	// that clients with the same OAuth2 credentials share their tokens. If it
+	// is nil, the client caches its tokens by itself.
+	TokenCache *TokenCache
 	// EnableAlphaFeatures controls whether alpha features in the Open Service
 	// Broker API are enabled in a client.  Features are considered to be
 	// alpha if they have been accepted into the Open Service Broker API but
@@ -88,8 +119,47 @@ type ClientConfiguration struct {
 	// OverrideServerName overrides the server name used for certificate verification.
 	// This is useful when the certificate is issued for a different name than the URL hostname.
 	OverrideServerName string
-	// Verbose is whether the client will log to klog.
+	// Verbose is whether the client logs the requests it sends and the
+	// responses it receives. Credentials, Authorization headers and encryption
+	// keys are redacted.
 	Verbose bool
+	// Logger receives the structured log messages of the client. If it is the
+	// zero Logger, klog is used.
+	Logger logr.Logger
+	// Metrics, if set, records the metrics of every request the client sends
+	// to the broker.
+	Metrics *Metrics
+	// ProviderConfig is the name of the ProviderConfig the client is created
+	// for. It is only used to label metrics.
+	ProviderConfig string
+	// TracerProvider creates the spans of requests to the broker. The global
+	// TracerProvider is used if it is nil.
+	TracerProvider trace.TracerProvider
+	// WrapTransport, if set, wraps the transport the client sends its
+	// requests with, e.g. to record and replay them with the recorder
+	// package. Metrics are recorded for the wrapped transport.
+	WrapTransport func(http.RoundTripper) http.RoundTripper
+	// FailoverURLs are the URLs of further endpoints of the broker, in order
+	// of preference after URL. Requests that cannot be delivered to an
+	// endpoint, or that are idempotent and fail in transit or are answered
+	// with 502, 503 or 504, are sent to the next endpoint.
+	FailoverURLs []string
+	// EndpointHealth records the endpoints that failed, so that requests are
+	// sent to healthy endpoints first. It can be shared by clients. If it is
+	// nil, the client records the health of its endpoints by itself.
+	EndpointHealth *EndpointHealth
+	// Interceptors intercept every request the client sends to the broker,
+	// in order: the first interceptor is the outermost and the last one
+	// calls the transport.
+	Interceptors []Interceptor
+	// RateLimiter, if set, limits the rate and concurrency of the requests
+	// the client sends to the broker and retries requests that are answered
+	// with 429 Too Many Requests. It can be shared by clients.
+	RateLimiter *RateLimiter
+	// ResponseValidation is the mode in which the client validates the
+	// responses of the broker against the Open Service Broker API
+	// specification. By default, responses are not validated.
+	ResponseValidation ResponseValidation
 }
 
 // DefaultClientConfiguration returns a default ClientConfiguration:
@@ -119,14 +189,31 @@ func DefaultClientConfiguration() *ClientConfiguration {
 //
 // 1.  Create a new binding to an instance of a service with the Bind method
 // 2.  Delete a binding to an instance with the Unbind method
+//
+// Every method takes a context.Context as its first argument.  The context
+// governs the lifetime of the underlying HTTP request: when it is cancelled
+// or its deadline expires, the request to the broker is aborted and the
+// method returns the context's error.  The overall request timeout from
+// ClientConfiguration.TimeoutSeconds still applies in addition.
 type Client interface {
 	// CheckAvailability attempts to contact the service broker, and authenticate
 	// with it. If an error occurs doing that, the error is returned.
-	CheckAvailability(string) error
+	CheckAvailability(ctx context.Context, endpoint string) error
+	// NegotiateAPIVersion determines the highest API version, up to the
+	// configured APIVersion, that the broker supports and uses it for every
+	// later request.  It requests the catalog with decreasing API versions
+	// until the broker no longer answers with 412 Precondition Failed, and
+	// caches the catalog it receives.
+	NegotiateAPIVersion(ctx context.Context) (APIVersion, error)
+	// Version returns the API version the client sends its requests with.
+	Version() APIVersion
+	// Capabilities returns the features of the API that the client can use
+	// with the broker, as determined by Version.
+	Capabilities() Capabilities
 	// GetCatalog returns information about the services the broker offers and
 	// their plans or an error.  GetCatalog calls GET on the Broker's catalog
 	// endpoint (/v2/catalog).
-	GetCatalog() (*CatalogResponse, error)
+	GetCatalog(ctx context.Context) (*CatalogResponse, error)
 	// ProvisionInstance requests that a new instance of a service be
 	// provisioned and returns information about the instance or an error.
 	// ProvisionInstance does a PUT on the Broker's endpoint for the requested
@@ -136,7 +223,7 @@ type Client interface {
 	// broker may complete the request asynchronously.  Callers should check
 	// the value of the Async field on the response and check the operation
 	// status using PollLastOperation if the Async field is true.
-	ProvisionInstance(r *ProvisionRequest) (*ProvisionResponse, error)
+	ProvisionInstance(ctx context.Context, r *ProvisionRequest) (*ProvisionResponse, error)
 	// UpdateInstance requests that an instances plan or parameters be updated
 	// and returns information about asynchronous responses or an error.
 	// UpdateInstance does a PATCH on the Broker's endpoint for the requested
@@ -146,7 +233,7 @@ type Client interface {
 	// broker may complete the request asynchronously.  Callers should check
 	// the value of the Async field on the response and check the operation
 	// status using PollLastOperation if the Async field is true.
-	UpdateInstance(r *UpdateInstanceRequest) (*UpdateInstanceResponse, error)
+	UpdateInstance(ctx context.Context, r *UpdateInstanceRequest) (*UpdateInstanceResponse, error)
 	// DeprovisionInstance requests that an instances plan or parameters be
 	// updated and returns information about asynchronous responses or an
 	// error. DeprovisionInstance does a DELETE on the Broker's endpoint for
@@ -158,25 +245,25 @@ type Client interface {
 	// status using PollLastOperation if the Async field is true.  Note that
 	// there are special semantics for PollLastOperation when checking the
 	// status of deprovision operations; see the doc for that method.
-	DeprovisionInstance(r *DeprovisionRequest) (*DeprovisionResponse, error)
+	DeprovisionInstance(ctx context.Context, r *DeprovisionRequest) (*DeprovisionResponse, error)
 	// GetInstance requires a client API version >= 2.14.
 	//
 	// GetInstance returns information about an existing instance.
 	// GetInstance calls GET on the Broker's endpoint for the requested
 	// instance ID (/instances/instance-id)
-	GetInstance(r *GetInstanceRequest) (*GetInstanceResponse, error)
+	GetInstance(ctx context.Context, r *GetInstanceRequest) (*GetInstanceResponse, error)
 	// GetServiceInstance requires a client API version >= 2.14.
 	//
 	// GetServiceInstance returns information about an existing instance.
 	// GetServiceInstance calls GET on the Broker's endpoint for the requested
 	// instance ID (/v2/service_instances/instance-id)
-	GetServiceInstance(r *GetInstanceRequest) (*GetServiceInstanceResponse, error)
+	GetServiceInstance(ctx context.Context, r *GetInstanceRequest) (*GetServiceInstanceResponse, error)
 	// GetInstances requires a client API version >= 2.14.
 	//
 	// GetInstances returns information about all existing instances.
 	// GetInstances calls GET on the Broker's endpoint for all existing
 	// instances (/instances)
-	GetInstances() (*GetInstancesResponse, error)
+	GetInstances(ctx context.Context) (*GetInstancesResponse, error)
 	// PollLastOperation sends a request to query the last operation for a
 	// service instance to the broker and returns information about the
 	// operation or an error.  PollLastOperation does a GET on the broker's
@@ -191,7 +278,7 @@ type Client interface {
 	// an asynchronous deprovision, callers check the status of an
 	// asynchronous deprovision, callers should test the value of the returned
 	// error with IsGoneError.
-	PollLastOperation(r *LastOperationRequest) (*LastOperationResponse, error)
+	PollLastOperation(ctx context.Context, r *LastOperationRequest) (*LastOperationResponse, error)
 	// PollBindingLastOperation requires a client API version >= 2.14.
 	//
 	// PollBindingLastOperation sends a request to query the last operation
@@ -207,26 +294,26 @@ type Client interface {
 	// deleted.  When calling PollBindingLastOperation to check the status of
 	// an asynchronous unbind, callers should test the value of the returned
 	// error with IsGoneError.
-	PollBindingLastOperation(r *BindingLastOperationRequest) (*LastOperationResponse, error)
+	PollBindingLastOperation(ctx context.Context, r *BindingLastOperationRequest) (*LastOperationResponse, error)
 	// Bind requests a new binding between a service instance and an
 	// application and returns information about the binding or an error. Bind
 	// does a PUT on the Broker's endpoint for the requested instance and
 	// binding IDs (/v2/service_instances/instance-id/service_bindings/binding-id).
-	Bind(r *BindRequest) (*BindResponse, error)
+	Bind(ctx context.Context, r *BindRequest) (*BindResponse, error)
 	// Unbind requests that a binding between a service instance and an
 	// application be deleted and returns information about the binding or an
 	// error. Unbind does a DELETE on the Broker's endpoint for the requested
 	// instance and binding IDs (/v2/service_instances/instance-id/service_bindings/binding-id).
-	Unbind(r *UnbindRequest) (*UnbindResponse, error)
+	Unbind(ctx context.Context, r *UnbindRequest) (*UnbindResponse, error)
 	// GetBinding requires a client API version >= 2.14.
 	//
 	// GetBinding returns configuration and credential information
 	// about an existing binding. GetBindings calls GET on the Broker's
 	// binding endpoint
 	// (/v2/service_instances/instance-id/service_bindings/binding-id)
-	GetBinding(r *GetBindingRequest) (*GetBindingResponse, error)
+	GetBinding(ctx context.Context, r *GetBindingRequest) (*GetBindingResponse, error)
 	// GetOperation returns information about an asynchronous operation
-	GetOperation(r *GetOperationRequest) (*GetOperationResponse, error)
+	GetOperation(ctx context.Context, r *GetOperationRequest) (*GetOperationResponse, error)
 }
 
 // CreateFunc allows control over which implementation of a Client is
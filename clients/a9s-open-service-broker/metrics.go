@@ -0,0 +1,168 @@
+/*
+Copyright 2024 Klutch Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v2
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Operation names used to label the metrics of requests to the broker.
+const (
+	operationCatalog              = "catalog"
+	operationProvision            = "provision"
+	operationUpdateInstance       = "update_instance"
+	operationDeprovision          = "deprovision"
+	operationLastOperation        = "last_operation"
+	operationBind                 = "bind"
+	operationUnbind               = "unbind"
+	operationGetBinding           = "get_binding"
+	operationBindingLastOperation = "binding_last_operation"
+	operationGetServiceInstance   = "get_service_instance"
+	operationGetInstance          = "get_instance"
+	operationGetInstances         = "get_instances"
+	operationCheckAvailability    = "check_availability"
+	operationOAuth2Token          = "oauth2_token"
+	operationNegotiateAPIVersion  = "negotiate_api_version"
+	operationUnknown              = "unknown"
+)
+
+// Error classes used to label failed requests to the broker.
+const (
+	errorClassTimeout     = "timeout"
+	errorClassCanceled    = "canceled"
+	errorClassNetwork     = "network"
+	errorClassClientError = "client_error"
+	errorClassServerError = "server_error"
+)
+
+// withOperation returns a copy of ctx that carries the name of the operation
+// a request belongs to.
+func withOperation(ctx context.Context, operation string) context.Context {
+	return withInvocation(ctx, Invocation{Operation: operation})
+}
+
+func operationFromContext(ctx context.Context) string {
+	return invocationFromContext(ctx).Operation
+}
+
+// Metrics collects request counts, latencies and errors of requests to
+// service brokers, labeled by ProviderConfig and operation. It implements
+// prometheus.Collector and must be registered before its values are exposed.
+// A single Metrics is meant to be shared by all clients of a process.
+type Metrics struct {
+	requests *prometheus.CounterVec
+	duration *prometheus.HistogramVec
+	errors   *prometheus.CounterVec
+}
+
+// NewMetrics returns a new Metrics.
+func NewMetrics() *Metrics {
+	return &Metrics{
+		requests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "a9s_osb_client_requests_total",
+			Help: "Number of requests to service brokers, by ProviderConfig, operation and HTTP status code.",
+		}, []string{"provider_config", "operation", "code"}),
+		duration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "a9s_osb_client_request_duration_seconds",
+			Help:    "Latency of requests to service brokers until the response headers are received, by ProviderConfig and operation.",
+			Buckets: prometheus.DefBuckets,
+		}, []string{"provider_config", "operation"}),
+		errors: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "a9s_osb_client_request_errors_total",
+			Help: "Number of failed requests to service brokers, by ProviderConfig, operation and error class.",
+		}, []string{"provider_config", "operation", "class"}),
+	}
+}
+
+// Describe implements prometheus.Collector.
+func (m *Metrics) Describe(ch chan<- *prometheus.Desc) {
+	m.requests.Describe(ch)
+	m.duration.Describe(ch)
+	m.errors.Describe(ch)
+}
+
+// Collect implements prometheus.Collector.
+func (m *Metrics) Collect(ch chan<- prometheus.Metric) {
+	m.requests.Collect(ch)
+	m.duration.Collect(ch)
+	m.errors.Collect(ch)
+}
+
+// InstrumentedTransport returns an http.RoundTripper that records the metrics
+// of every request sent through next. Requests are labeled with the given
+// ProviderConfig and the operation of the client method that sent them.
+func (m *Metrics) InstrumentedTransport(next http.RoundTripper, providerConfig string) http.RoundTripper {
+	return &instrumentedTransport{next: next, metrics: m, providerConfig: providerConfig}
+}
+
+type instrumentedTransport struct {
+	next           http.RoundTripper
+	metrics        *Metrics
+	providerConfig string
+}
+
+func (t *instrumentedTransport) RoundTrip(request *http.Request) (*http.Response, error) {
+	operation := operationFromContext(request.Context())
+
+	start := time.Now()
+	response, err := t.next.RoundTrip(request)
+	t.metrics.duration.WithLabelValues(t.providerConfig, operation).Observe(time.Since(start).Seconds())
+
+	code := "error"
+	if err == nil {
+		code = strconv.Itoa(response.StatusCode)
+	}
+	t.metrics.requests.WithLabelValues(t.providerConfig, operation, code).Inc()
+
+	if class := errorClass(response, err); class != "" {
+		t.metrics.errors.WithLabelValues(t.providerConfig, operation, class).Inc()
+	}
+
+	return response, err
+}
+
+// errorClass classifies the outcome of a request. It returns an empty string
+// for successful requests.
+func errorClass(response *http.Response, err error) string {
+	if err != nil {
+		var netErr net.Error
+		switch {
+		case errors.Is(err, context.Canceled):
+			return errorClassCanceled
+		case errors.Is(err, context.DeadlineExceeded), errors.As(err, &netErr) && netErr.Timeout():
+			return errorClassTimeout
+		default:
+			return errorClassNetwork
+		}
+	}
+
+	switch {
+	case response.StatusCode >= 500:
+		return errorClassServerError
+	case response.StatusCode >= 400:
+		return errorClassClientError
+	default:
+		return ""
+	}
+}
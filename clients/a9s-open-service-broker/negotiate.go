@@ -0,0 +1,130 @@
+/*
+Copyright 2024 Klutch Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v2
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+)
+
+// apiVersionContextKey is the context key of the API version a request is
+// sent with while the client negotiates the API version.
+type apiVersionContextKey struct{}
+
+func withAPIVersion(ctx context.Context, version APIVersion) context.Context {
+	return context.WithValue(ctx, apiVersionContextKey{}, version)
+}
+
+// NegotiateAPIVersion requests the catalog of the broker with every API
+// version, starting with the configured APIVersion, until the broker accepts
+// one. Brokers reject API versions they do not support with 412 Precondition
+// Failed. If the broker answers with a lower version in its
+// X-Broker-API-Version header, that version is used. The negotiated version is
+// used by every later request of the client and returned by Version.
+func (c *client) NegotiateAPIVersion(ctx context.Context) (APIVersion, error) {
+	ctx, span := c.startSpan(ctx, operationNegotiateAPIVersion, nil)
+	defer span.End()
+
+	fullURL := fmt.Sprintf(catalogURL, c.URL)
+	for _, version := range c.candidateAPIVersions() {
+		response, err := c.prepareAndDo(withAPIVersion(ctx, version), http.MethodGet, fullURL, nil /* params */, nil /* request body */, nil /* originating identity */)
+		if err != nil {
+			return APIVersion{}, err
+		}
+
+		switch response.StatusCode {
+		case http.StatusPreconditionFailed:
+			_ = drainReader(response.Body)
+			response.Body.Close()
+			c.logger.V(1).Info("Broker rejected API version", "apiVersion", version.String())
+			continue
+		case http.StatusOK:
+			if announced, err := ParseAPIVersion(response.Header.Get(APIVersionHeader)); err == nil && announced.IsLessThan(version) {
+				version = announced
+			}
+			c.setNegotiatedAPIVersion(version)
+
+			entry, err := c.catalogEntryFromResponse(ctx, response)
+			_ = drainReader(response.Body)
+			response.Body.Close()
+			if err == nil {
+				c.setCatalogInCache(entry)
+			}
+
+			c.logger.V(1).Info("Negotiated API version", "apiVersion", version.String())
+			return version, nil
+		default:
+			defer func() {
+				_ = drainReader(response.Body)
+				response.Body.Close()
+			}()
+			return APIVersion{}, c.handleFailureResponse(response)
+		}
+	}
+
+	return APIVersion{}, APIVersionNegotiationError{MaxVersion: c.APIVersion}
+}
+
+// candidateAPIVersions returns the API versions up to the configured
+// APIVersion, highest first.
+func (c *client) candidateAPIVersions() []APIVersion {
+	var versions []APIVersion
+	for _, version := range APIVersions() {
+		if c.APIVersion.AtLeast(version) {
+			versions = append(versions, version)
+		}
+	}
+	sort.Slice(versions, func(i, j int) bool {
+		return versions[j].IsLessThan(versions[i])
+	})
+	return versions
+}
+
+func (c *client) setNegotiatedAPIVersion(version APIVersion) {
+	c.versionLock.Lock()
+	defer c.versionLock.Unlock()
+	c.negotiatedAPIVersion = &version
+}
+
+// Version returns the API version the client sends its requests with: the
+// negotiated version, if the client negotiated one, and the configured
+// APIVersion otherwise.
+func (c *client) Version() APIVersion {
+	c.versionLock.RLock()
+	defer c.versionLock.RUnlock()
+	if c.negotiatedAPIVersion != nil {
+		return *c.negotiatedAPIVersion
+	}
+	return c.APIVersion
+}
+
+// Capabilities returns the capabilities of the API version the client sends
+// its requests with.
+func (c *client) Capabilities() Capabilities {
+	return c.Version().Capabilities()
+}
+
+// requestAPIVersion returns the API version a request with the given context
+// is sent with.
+func (c *client) requestAPIVersion(ctx context.Context) APIVersion {
+	if version, ok := ctx.Value(apiVersionContextKey{}).(APIVersion); ok {
+		return version
+	}
+	return c.Version()
+}
@@ -0,0 +1,193 @@
+/*
+Copyright 2024 Klutch Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v2
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"k8s.io/client-go/tools/cache"
+)
+
+// versionedBroker answers catalog requests with an API version up to max and
+// rejects higher versions with 412 Precondition Failed. If announce is set,
+// it is sent in the X-Broker-API-Version header of successful responses.
+func versionedBroker(max APIVersion, announce string, requested *[]string) doRequestFunc {
+	return func(request *http.Request) (*http.Response, error) {
+		header := request.Header.Get(APIVersionHeader)
+		*requested = append(*requested, header)
+
+		version, err := ParseAPIVersion(header)
+		if err != nil || max.IsLessThan(version) {
+			return &http.Response{
+				StatusCode: http.StatusPreconditionFailed,
+				Body:       closer(`{"description":"unsupported API version"}`),
+			}, nil
+		}
+
+		response := &http.Response{
+			StatusCode: http.StatusOK,
+			Header:     http.Header{},
+			Body:       closer(okCatalogBytes),
+		}
+		if announce != "" {
+			response.Header.Set(APIVersionHeader, announce)
+		}
+		return response, nil
+	}
+}
+
+func TestNegotiateAPIVersion(t *testing.T) {
+	cases := []struct {
+		name              string
+		configured        APIVersion
+		brokerMax         APIVersion
+		announce          string
+		expectedVersion   APIVersion
+		expectedRequested []string
+	}{
+		{
+			name:              "broker supports the configured version",
+			configured:        Version2_14(),
+			brokerMax:         Version2_14(),
+			expectedVersion:   Version2_14(),
+			expectedRequested: []string{"2.14"},
+		},
+		{
+			name:              "falls back on precondition failed",
+			configured:        Version2_14(),
+			brokerMax:         Version2_12(),
+			expectedVersion:   Version2_12(),
+			expectedRequested: []string{"2.14", "2.13", "2.12"},
+		},
+		{
+			name:              "never offers more than the configured version",
+			configured:        Version2_13(),
+			brokerMax:         Version2_14(),
+			expectedVersion:   Version2_13(),
+			expectedRequested: []string{"2.13"},
+		},
+		{
+			name:              "broker announces a lower version",
+			configured:        Version2_14(),
+			brokerMax:         Version2_14(),
+			announce:          "2.13",
+			expectedVersion:   Version2_13(),
+			expectedRequested: []string{"2.14"},
+		},
+		{
+			name:              "announced higher version is ignored",
+			configured:        Version2_13(),
+			brokerMax:         Version2_14(),
+			announce:          "2.14",
+			expectedVersion:   Version2_13(),
+			expectedRequested: []string{"2.13"},
+		},
+	}
+
+	for _, tc := range cases {
+		var requested []string
+		klient := &client{
+			Name:          "test client",
+			URL:           "https://example.com",
+			APIVersion:    tc.configured,
+			cache:         cache.NewTTLStore(cacheKeyFunc, time.Minute),
+			doRequestFunc: versionedBroker(tc.brokerMax, tc.announce, &requested),
+		}
+
+		version, err := klient.NegotiateAPIVersion(context.Background())
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", tc.name, err)
+			continue
+		}
+		if version != tc.expectedVersion || klient.Version() != tc.expectedVersion {
+			t.Errorf("%s: expected version %v, got %v (client uses %v)", tc.name, tc.expectedVersion, version, klient.Version())
+		}
+		if len(requested) != len(tc.expectedRequested) {
+			t.Errorf("%s: expected requests with versions %v, got %v", tc.name, tc.expectedRequested, requested)
+			continue
+		}
+		for i := range requested {
+			if requested[i] != tc.expectedRequested[i] {
+				t.Errorf("%s: expected requests with versions %v, got %v", tc.name, tc.expectedRequested, requested)
+				break
+			}
+		}
+
+		// The catalog of the negotiation is cached.
+		if _, err := klient.GetCatalog(context.Background()); err != nil || len(requested) != len(tc.expectedRequested) {
+			t.Errorf("%s: expected the catalog to be served from the cache, got %v requests (error %v)", tc.name, len(requested), err)
+		}
+	}
+}
+
+func TestNegotiateAPIVersionRestrictsOperations(t *testing.T) {
+	var requested []string
+	klient := &client{
+		Name:          "test client",
+		URL:           "https://example.com",
+		APIVersion:    Version2_14(),
+		doRequestFunc: versionedBroker(Version2_13(), "", &requested),
+	}
+
+	if _, err := klient.NegotiateAPIVersion(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if klient.Capabilities().GetBinding {
+		t.Errorf("expected no GetBinding capability with API version 2.13")
+	}
+
+	_, err := klient.GetBinding(context.Background(), &GetBindingRequest{InstanceID: "instance", BindingID: "binding"})
+	if !errors.As(err, &GetBindingNotAllowedError{}) {
+		t.Errorf("expected GetBindingNotAllowedError, got %v", err)
+	}
+}
+
+func TestNegotiateAPIVersionFails(t *testing.T) {
+	var requested []string
+	klient := &client{
+		Name:       "test client",
+		URL:        "https://example.com",
+		APIVersion: Version2_12(),
+		doRequestFunc: func(request *http.Request) (*http.Response, error) {
+			requested = append(requested, request.Header.Get(APIVersionHeader))
+			return &http.Response{StatusCode: http.StatusPreconditionFailed, Body: closer(`{}`)}, nil
+		},
+	}
+
+	_, err := klient.NegotiateAPIVersion(context.Background())
+	if !errors.As(err, &APIVersionNegotiationError{}) {
+		t.Errorf("expected APIVersionNegotiationError, got %v", err)
+	}
+	if len(requested) != 2 {
+		t.Errorf("expected requests with versions 2.12 and 2.11, got %v", requested)
+	}
+	if klient.Version() != Version2_12() {
+		t.Errorf("expected the configured version to be kept, got %v", klient.Version())
+	}
+
+	klient.doRequestFunc = func(*http.Request) (*http.Response, error) {
+		return &http.Response{StatusCode: http.StatusInternalServerError, Body: closer(`{}`)}, nil
+	}
+	_, err = klient.NegotiateAPIVersion(context.Background())
+	if httpErr, ok := IsHTTPError(err); !ok || httpErr.StatusCode != http.StatusInternalServerError {
+		t.Errorf("expected an HTTP error with status 500, got %v", err)
+	}
+}
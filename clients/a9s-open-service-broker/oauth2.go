@@ -0,0 +1,133 @@
+/*
+Copyright 2024 Klutch Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v2
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"sync"
+	"time"
+
+	"golang.org/x/oauth2"
+	"golang.org/x/oauth2/clientcredentials"
+)
+
+// tokenRefreshMargin is how long before its expiry a cached access token is
+// replaced by a new one, so that it does not expire while a request is in
+// flight.
+const tokenRefreshMargin = 30 * time.Second
+
+// TokenCache caches the OAuth2 access tokens of clients and can be shared by
+// any number of clients, e.g. by every client a controller creates for the
+// same broker, so that a new client does not fetch a new access token.
+// Tokens are cached per token URL and client ID. If a client uses other
+// credentials for the same token URL and client ID, e.g. after the client
+// secret was rotated, the cached token is dropped.
+type TokenCache struct {
+	mu      sync.Mutex
+	sources map[string]*tokenSource
+}
+
+// NewTokenCache returns an empty TokenCache.
+func NewTokenCache() *TokenCache {
+	return &TokenCache{sources: map[string]*tokenSource{}}
+}
+
+// tokenSource returns the token source of config in the cache, or adds a new
+// one.
+func (c *TokenCache) tokenSource(config *OAuth2Config) (*tokenSource, error) {
+	source, err := newTokenSource(config)
+	if err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	key := config.TokenURL + "\x00" + config.ClientID
+	if cached, ok := c.sources[key]; ok && cached.sameCredentials(source) {
+		return cached, nil
+	}
+	c.sources[key] = source
+	return source, nil
+}
+
+// tokenSource fetches OAuth2 access tokens with the client credentials grant
+// and caches them until shortly before they expire.
+type tokenSource struct {
+	config *clientcredentials.Config
+
+	mu    sync.Mutex
+	token *oauth2.Token
+}
+
+func newTokenSource(config *OAuth2Config) (*tokenSource, error) {
+	if config.TokenURL == "" {
+		return nil, errors.New("OAuth2 token URL must not be empty")
+	}
+	if config.ClientID == "" {
+		return nil, errors.New("OAuth2 client ID must not be empty")
+	}
+
+	return &tokenSource{
+		config: &clientcredentials.Config{
+			ClientID:     config.ClientID,
+			ClientSecret: config.ClientSecret,
+			TokenURL:     config.TokenURL,
+			Scopes:       slices.Clone(config.Scopes),
+		},
+	}, nil
+}
+
+// sameCredentials returns whether both token sources request tokens with the
+// same credentials and scopes.
+func (s *tokenSource) sameCredentials(other *tokenSource) bool {
+	return s.config.ClientSecret == other.config.ClientSecret && slices.Equal(s.config.Scopes, other.config.Scopes)
+}
+
+// Token returns the cached access token, or fetches a new one with httpClient
+// if there is no cached token or it is about to expire.
+func (s *tokenSource) Token(ctx context.Context, httpClient *http.Client) (*oauth2.Token, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.token != nil && (s.token.Expiry.IsZero() || time.Now().Add(tokenRefreshMargin).Before(s.token.Expiry)) {
+		return s.token, nil
+	}
+
+	token, err := s.config.Token(context.WithValue(withOperation(ctx, operationOAuth2Token), oauth2.HTTPClient, httpClient))
+	if err != nil {
+		return nil, fmt.Errorf("cannot get OAuth2 access token: %w", err)
+	}
+	s.token = token
+
+	return token, nil
+}
+
+// Invalidate drops the given token from the cache, unless it has been
+// replaced already.
+func (s *tokenSource) Invalidate(token *oauth2.Token) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.token == token {
+		s.token = nil
+	}
+}
@@ -0,0 +1,208 @@
+/*
+Copyright 2024 Klutch Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package osbtest provides an in-process stand-in for an a9s Open Service
+// Broker. Unlike the reaction based fake client, it serves real HTTP, so the
+// client and the controllers built on top of it can be exercised end-to-end,
+// including authentication, JSON encoding and the a9s specific /instances
+// endpoints, without an a9s deployment.
+package osbtest
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"time"
+
+	v2 "github.com/anynines/klutchio/clients/a9s-open-service-broker"
+	"github.com/anynines/klutchio/clients/a9s-open-service-broker/generator"
+)
+
+// Options configures a Broker.
+type Options struct {
+	// Catalog is the catalog served by the broker. If nil, DefaultCatalog is
+	// used.
+	Catalog *v2.CatalogResponse
+	// Username and Password, if set, are the basic auth credentials the
+	// broker requires on every request.
+	Username string
+	Password string
+	// Async makes the broker complete provision, update, deprovision, bind
+	// and unbind requests asynchronously. Clients that do not accept
+	// incomplete operations get an AsyncRequired error for instance
+	// operations, binding operations are then completed synchronously.
+	Async bool
+	// PollsUntilDone is the number of times the last operation of an
+	// asynchronous operation is reported as in progress before it succeeds.
+	PollsUntilDone int
+	// RetryAfter, if positive, is sent as Retry-After header with every last
+	// operation response of an operation that is still in progress.
+	RetryAfter time.Duration
+}
+
+// Broker is an in-process Open Service Broker backed by an httptest.Server.
+// It keeps service instances, bindings and operations in memory and is safe
+// for concurrent use.
+type Broker struct {
+	// URL is the base URL of the broker, suitable for
+	// v2.ClientConfiguration.URL.
+	URL string
+
+	server  *httptest.Server
+	options Options
+	catalog *v2.CatalogResponse
+
+	mu         sync.Mutex
+	instances  map[string]*Instance
+	bindings   map[bindingKey]*Binding
+	operations map[operationKey]*operation
+	faults     []*Fault
+	requests   []Request
+	sequence   int
+	failNext   bool
+}
+
+// Request is a record of a request the Broker received.
+type Request struct {
+	Route  Route
+	Method string
+	Path   string
+	Header http.Header
+}
+
+// NewBroker starts a Broker configured by the given Options. Callers must call
+// Close when they are done with it.
+func NewBroker(options Options) *Broker {
+	b := &Broker{
+		options:    options,
+		catalog:    options.Catalog,
+		instances:  map[string]*Instance{},
+		bindings:   map[bindingKey]*Binding{},
+		operations: map[operationKey]*operation{},
+	}
+	if b.catalog == nil {
+		b.catalog = DefaultCatalog()
+	}
+
+	b.server = httptest.NewServer(b.handler())
+	b.URL = b.server.URL
+
+	return b
+}
+
+// Close shuts down the Broker and blocks until all outstanding requests have
+// completed.
+func (b *Broker) Close() {
+	b.server.Close()
+}
+
+// ClientConfiguration returns the default client configuration pointed at
+// the Broker, including its basic auth credentials if it requires them.
+func (b *Broker) ClientConfiguration() *v2.ClientConfiguration {
+	config := v2.DefaultClientConfiguration()
+	config.Name = "osbtest"
+	config.URL = b.URL
+	if b.options.Username != "" || b.options.Password != "" {
+		config.AuthConfig = &v2.AuthConfig{
+			BasicAuthConfig: &v2.BasicAuthConfig{
+				Username: b.options.Username,
+				Password: b.options.Password,
+			},
+		}
+	}
+	return config
+}
+
+// Catalog returns the catalog served by the Broker.
+func (b *Broker) Catalog() *v2.CatalogResponse {
+	return b.catalog
+}
+
+// Requests returns all requests the Broker received so far, in order.
+func (b *Broker) Requests() []Request {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return append([]Request(nil), b.requests...)
+}
+
+// DefaultCatalog returns the catalog a Broker serves if Options.Catalog is
+// nil. It is generated by the generator package once per process, so every
+// call returns an equal catalog.
+func DefaultCatalog() *v2.CatalogResponse {
+	catalog := &v2.CatalogResponse{}
+	// Deep copy the catalog so callers cannot modify the shared one.
+	if err := json.Unmarshal(defaultCatalog(), catalog); err != nil {
+		panic(fmt.Sprintf("cannot decode default catalog: %v", err))
+	}
+	return catalog
+}
+
+var defaultCatalog = sync.OnceValue(func() []byte {
+	g := generator.CreateGenerator(2, generator.Parameters{
+		Services: generator.ServiceRanges{
+			Plans:    4,
+			Tags:     3,
+			Metadata: 2,
+		},
+		Plans: generator.PlanRanges{
+			Metadata: 2,
+			Free:     2,
+		},
+	})
+	generator.AssignPoolGoT(g)
+
+	catalog, err := g.GetCatalog()
+	if err != nil {
+		// The generator only fails without services, which cannot happen
+		// here.
+		panic(fmt.Sprintf("cannot generate default catalog: %v", err))
+	}
+
+	// The stand-in supports binding every service.
+	for i := range catalog.Services {
+		catalog.Services[i].Bindable = true
+	}
+
+	encoded, err := json.Marshal(catalog)
+	if err != nil {
+		panic(fmt.Sprintf("cannot encode default catalog: %v", err))
+	}
+	return encoded
+})
+
+func (b *Broker) authorized(r *http.Request) bool {
+	if b.options.Username == "" && b.options.Password == "" {
+		return true
+	}
+
+	username, password, ok := r.BasicAuth()
+	if !ok {
+		return false
+	}
+
+	usernameMatches := subtle.ConstantTimeCompare([]byte(username), []byte(b.options.Username)) == 1
+	passwordMatches := subtle.ConstantTimeCompare([]byte(password), []byte(b.options.Password)) == 1
+	return usernameMatches && passwordMatches
+}
+
+func (b *Broker) nextID() int {
+	b.sequence++
+	return b.sequence
+}
@@ -0,0 +1,361 @@
+/*
+Copyright 2024 Klutch Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package osbtest
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+
+	v2 "github.com/anynines/klutchio/clients/a9s-open-service-broker"
+)
+
+const (
+	testInstanceID = "5b4c1e2a-8d2f-4a4f-9a4e-0b6f2b9c7d11"
+	testBindingID  = "9e0f8b6c-3c1d-4f0a-b7a9-2d5e6f7a8b90"
+)
+
+func newTestClient(t *testing.T, b *Broker) v2.Client {
+	t.Helper()
+
+	klient, err := v2.NewClient(b.ClientConfiguration())
+	if err != nil {
+		t.Fatalf("cannot create client: %v", err)
+	}
+	return klient
+}
+
+func provisionRequest(b *Broker, acceptsIncomplete bool) *v2.ProvisionRequest {
+	service := b.Catalog().Services[0]
+	return &v2.ProvisionRequest{
+		InstanceID:        testInstanceID,
+		AcceptsIncomplete: acceptsIncomplete,
+		ServiceID:         service.ID,
+		PlanID:            service.Plans[0].ID,
+		OrganizationGUID:  "organization",
+		SpaceGUID:         "space",
+		Parameters:        map[string]interface{}{"max_connections": float64(100)},
+	}
+}
+
+func TestGetCatalog(t *testing.T) {
+	b := NewBroker(Options{Username: "admin", Password: "secret"})
+	defer b.Close()
+
+	catalog, err := newTestClient(t, b).GetCatalog(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if diff := cmp.Diff(DefaultCatalog(), catalog); diff != "" {
+		t.Errorf("GetCatalog(...): -want, +got:\n%s", diff)
+	}
+
+	config := b.ClientConfiguration()
+	config.AuthConfig.BasicAuthConfig.Password = "wrong"
+	klient, err := v2.NewClient(config)
+	if err != nil {
+		t.Fatalf("cannot create client: %v", err)
+	}
+	_, err = klient.GetCatalog(context.Background())
+	if httpErr, ok := v2.IsHTTPError(err); !ok || httpErr.StatusCode != http.StatusUnauthorized {
+		t.Errorf("expected unauthorized error, got %v", err)
+	}
+}
+
+func TestSynchronousLifecycle(t *testing.T) {
+	ctx := context.Background()
+	b := NewBroker(Options{})
+	defer b.Close()
+	klient := newTestClient(t, b)
+
+	provision := provisionRequest(b, false)
+	if _, err := klient.ProvisionInstance(ctx, provision); err != nil {
+		t.Fatalf("ProvisionInstance: unexpected error: %v", err)
+	}
+
+	instance, err := klient.GetInstance(ctx, &v2.GetInstanceRequest{InstanceID: testInstanceID})
+	if err != nil {
+		t.Fatalf("GetInstance: unexpected error: %v", err)
+	}
+	if instance.State != StateProvisioned || instance.GUIDAtTenant != testInstanceID || instance.PlanGUID != provision.PlanID {
+		t.Errorf("GetInstance: unexpected instance %+v", instance)
+	}
+
+	if _, err := klient.UpdateInstance(ctx, &v2.UpdateInstanceRequest{
+		InstanceID: testInstanceID,
+		ServiceID:  provision.ServiceID,
+		Parameters: map[string]interface{}{"max_connections": nil, "shared_buffers": float64(512)},
+	}); err != nil {
+		t.Fatalf("UpdateInstance: unexpected error: %v", err)
+	}
+
+	serviceInstance, err := klient.GetServiceInstance(ctx, &v2.GetInstanceRequest{InstanceID: testInstanceID})
+	if err != nil {
+		t.Fatalf("GetServiceInstance: unexpected error: %v", err)
+	}
+	if diff := cmp.Diff(map[string]interface{}{"shared_buffers": float64(512)}, serviceInstance.Parameters); diff != "" {
+		t.Errorf("GetServiceInstance: -want parameters, +got parameters:\n%s", diff)
+	}
+
+	bind, err := klient.Bind(ctx, &v2.BindRequest{
+		BindingID:  testBindingID,
+		InstanceID: testInstanceID,
+		ServiceID:  provision.ServiceID,
+		PlanID:     provision.PlanID,
+	})
+	if err != nil {
+		t.Fatalf("Bind: unexpected error: %v", err)
+	}
+
+	binding, err := klient.GetBinding(ctx, &v2.GetBindingRequest{InstanceID: testInstanceID, BindingID: testBindingID})
+	if err != nil {
+		t.Fatalf("GetBinding: unexpected error: %v", err)
+	}
+	if diff := cmp.Diff(bind.Credentials, binding.Credentials); diff != "" {
+		t.Errorf("GetBinding: -want credentials, +got credentials:\n%s", diff)
+	}
+
+	instances, err := klient.GetInstances(ctx)
+	if err != nil {
+		t.Fatalf("GetInstances: unexpected error: %v", err)
+	}
+	if instances.TotalResults != 1 || len(instances.Resources[0].Credentials) != 1 {
+		t.Errorf("GetInstances: unexpected response %+v", instances)
+	}
+
+	if _, err := klient.Unbind(ctx, &v2.UnbindRequest{
+		InstanceID: testInstanceID,
+		BindingID:  testBindingID,
+		ServiceID:  provision.ServiceID,
+		PlanID:     provision.PlanID,
+	}); err != nil {
+		t.Fatalf("Unbind: unexpected error: %v", err)
+	}
+
+	deprovision := &v2.DeprovisionRequest{
+		InstanceID: testInstanceID,
+		ServiceID:  provision.ServiceID,
+		PlanID:     provision.PlanID,
+	}
+	if _, err := klient.DeprovisionInstance(ctx, deprovision); err != nil {
+		t.Fatalf("DeprovisionInstance: unexpected error: %v", err)
+	}
+	// Deprovisioning a deleted instance is answered with HTTP GONE, which
+	// the client treats as success.
+	if _, err := klient.DeprovisionInstance(ctx, deprovision); err != nil {
+		t.Fatalf("DeprovisionInstance: unexpected error for deleted instance: %v", err)
+	}
+
+	instance, err = klient.GetInstance(ctx, &v2.GetInstanceRequest{InstanceID: testInstanceID})
+	if err != nil {
+		t.Fatalf("GetInstance: unexpected error: %v", err)
+	}
+	if instance.State != StateDeleted || instance.DeletedAt == "" {
+		t.Errorf("GetInstance: expected deleted instance, got %+v", instance)
+	}
+}
+
+func TestAsynchronousLifecycle(t *testing.T) {
+	ctx := context.Background()
+	b := NewBroker(Options{Async: true, PollsUntilDone: 1, RetryAfter: 30 * time.Second})
+	defer b.Close()
+	klient := newTestClient(t, b)
+
+	_, err := klient.ProvisionInstance(ctx, provisionRequest(b, false))
+	if !v2.IsAsyncRequiredError(err) {
+		t.Fatalf("expected AsyncRequired error, got %v", err)
+	}
+
+	provision := provisionRequest(b, true)
+	response, err := klient.ProvisionInstance(ctx, provision)
+	if err != nil {
+		t.Fatalf("ProvisionInstance: unexpected error: %v", err)
+	}
+	if !response.Async || response.OperationKey == nil {
+		t.Fatalf("ProvisionInstance: expected asynchronous response, got %+v", response)
+	}
+
+	// Provisioning the same instance again returns the pending operation.
+	again, err := klient.ProvisionInstance(ctx, provision)
+	if err != nil || again.OperationKey == nil || *again.OperationKey != *response.OperationKey {
+		t.Errorf("ProvisionInstance: expected pending operation %v, got %+v, %v", *response.OperationKey, again, err)
+	}
+
+	_, err = klient.UpdateInstance(ctx, &v2.UpdateInstanceRequest{
+		InstanceID:        testInstanceID,
+		AcceptsIncomplete: true,
+		ServiceID:         provision.ServiceID,
+	})
+	if !v2.IsConcurrencyError(err) {
+		t.Errorf("UpdateInstance: expected ConcurrencyError while provisioning, got %v", err)
+	}
+
+	instance, ok := b.Instance(testInstanceID)
+	if !ok || instance.State != StateDeploying {
+		t.Errorf("expected deploying instance, got %+v", instance)
+	}
+
+	request := &v2.GetOperationRequest{InstanceID: testInstanceID, OperationKey: *response.OperationKey}
+	operation, err := klient.GetOperation(ctx, request)
+	if err != nil {
+		t.Fatalf("GetOperation: unexpected error: %v", err)
+	}
+	wantDelay := 30 * time.Second
+	if diff := cmp.Diff(&v2.GetOperationResponse{State: string(v2.StateInProgress), PollDelay: &wantDelay}, operation); diff != "" {
+		t.Errorf("GetOperation: -want, +got:\n%s", diff)
+	}
+
+	operation, err = klient.GetOperation(ctx, request)
+	if err != nil {
+		t.Fatalf("GetOperation: unexpected error: %v", err)
+	}
+	if !operation.IsDone() || operation.PollDelay != nil {
+		t.Errorf("GetOperation: expected finished operation without delay, got %+v", operation)
+	}
+
+	instance, _ = b.Instance(testInstanceID)
+	if instance.State != StateProvisioned {
+		t.Errorf("expected provisioned instance, got %+v", instance)
+	}
+}
+
+func TestMaintenanceInfo(t *testing.T) {
+	ctx := context.Background()
+	b := NewBroker(Options{})
+	defer b.Close()
+	klient := newTestClient(t, b)
+
+	provision := provisionRequest(b, false)
+	provision.MaintenanceInfo = &v2.MaintenanceInfo{Version: "1.0.0"}
+	if _, err := klient.ProvisionInstance(ctx, provision); err != nil {
+		t.Fatalf("ProvisionInstance: unexpected error: %v", err)
+	}
+
+	if _, err := klient.UpdateInstance(ctx, &v2.UpdateInstanceRequest{
+		InstanceID:      testInstanceID,
+		ServiceID:       provision.ServiceID,
+		MaintenanceInfo: &v2.MaintenanceInfo{Version: "1.1.0"},
+	}); err != nil {
+		t.Fatalf("UpdateInstance: unexpected error: %v", err)
+	}
+
+	serviceInstance, err := klient.GetServiceInstance(ctx, &v2.GetInstanceRequest{InstanceID: testInstanceID})
+	if err != nil {
+		t.Fatalf("GetServiceInstance: unexpected error: %v", err)
+	}
+	if diff := cmp.Diff(&v2.MaintenanceInfo{Version: "1.1.0"}, serviceInstance.MaintenanceInfo); diff != "" {
+		t.Errorf("GetServiceInstance: -want maintenance info, +got maintenance info:\n%s", diff)
+	}
+}
+
+func TestFailNextOperation(t *testing.T) {
+	ctx := context.Background()
+	b := NewBroker(Options{Async: true})
+	defer b.Close()
+	klient := newTestClient(t, b)
+
+	b.FailNextOperation()
+	response, err := klient.ProvisionInstance(ctx, provisionRequest(b, true))
+	if err != nil {
+		t.Fatalf("ProvisionInstance: unexpected error: %v", err)
+	}
+
+	_, err = v2.WaitForOperation(ctx, klient, &v2.LastOperationRequest{
+		InstanceID:   testInstanceID,
+		OperationKey: response.OperationKey,
+	}, nil)
+	if !errors.Is(err, v2.OperationStateError{State: string(v2.StateFailed)}) {
+		t.Errorf("expected failed operation, got %v", err)
+	}
+
+	instance, _ := b.Instance(testInstanceID)
+	if instance.State != StateFailed {
+		t.Errorf("expected failed instance, got %+v", instance)
+	}
+}
+
+func TestFaults(t *testing.T) {
+	cases := []struct {
+		name    string
+		fault   *Fault
+		timeout time.Duration
+		check   func(error) bool
+	}{
+		{
+			name:    "latency",
+			fault:   Latency(RouteCatalog, time.Minute),
+			timeout: 50 * time.Millisecond,
+			check:   func(err error) bool { return errors.Is(err, context.DeadlineExceeded) },
+		},
+		{
+			name:  "server error",
+			fault: ServerError(RouteCatalog, http.StatusServiceUnavailable),
+			check: func(err error) bool {
+				httpErr, ok := v2.IsHTTPError(err)
+				return ok && httpErr.StatusCode == http.StatusServiceUnavailable
+			},
+		},
+		{
+			name:  "gone",
+			fault: Gone(""),
+			check: v2.IsGoneError,
+		},
+		{
+			name:  "concurrency error",
+			fault: ConcurrencyError(RouteCatalog),
+			check: v2.IsConcurrencyError,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			b := NewBroker(Options{})
+			defer b.Close()
+			klient := newTestClient(t, b)
+
+			tc.fault.Times = 1
+			b.InjectFault(tc.fault)
+			// A fault for another route must not interfere.
+			b.InjectFault(ServerError(RouteGetInstances, http.StatusInternalServerError))
+
+			ctx := context.Background()
+			if tc.timeout > 0 {
+				var cancel context.CancelFunc
+				ctx, cancel = context.WithTimeout(ctx, tc.timeout)
+				defer cancel()
+			}
+
+			if _, err := klient.GetCatalog(ctx); !tc.check(err) {
+				t.Errorf("unexpected error %v", err)
+			}
+
+			// The fault applied only once.
+			if _, err := klient.GetCatalog(context.Background()); err != nil {
+				t.Errorf("unexpected error after the fault was used up: %v", err)
+			}
+
+			b.ClearFaults()
+			if _, err := klient.GetInstances(context.Background()); err != nil {
+				t.Errorf("unexpected error after the faults were cleared: %v", err)
+			}
+		})
+	}
+}
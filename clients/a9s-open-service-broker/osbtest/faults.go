@@ -0,0 +1,152 @@
+/*
+Copyright 2024 Klutch Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package osbtest
+
+import (
+	"net/http"
+	"time"
+
+	v2 "github.com/anynines/klutchio/clients/a9s-open-service-broker"
+)
+
+// Route identifies an endpoint served by the Broker.
+type Route string
+
+// These are the routes served by the Broker.
+const (
+	RouteCatalog              Route = "GetCatalog"
+	RouteProvision            Route = "ProvisionInstance"
+	RouteUpdateInstance       Route = "UpdateInstance"
+	RouteDeprovision          Route = "DeprovisionInstance"
+	RouteGetServiceInstance   Route = "GetServiceInstance"
+	RouteLastOperation        Route = "PollLastOperation"
+	RouteBind                 Route = "Bind"
+	RouteUnbind               Route = "Unbind"
+	RouteGetBinding           Route = "GetBinding"
+	RouteBindingLastOperation Route = "PollBindingLastOperation"
+	RouteGetInstance          Route = "GetInstance"
+	RouteGetInstances         Route = "GetInstances"
+)
+
+// Fault describes a failure the Broker injects into the requests it serves.
+type Fault struct {
+	// Route restricts the fault to requests for the given route. An empty
+	// Route matches every request.
+	Route Route
+	// Latency delays the response by the given duration. The delay ends
+	// early if the client gives up on the request.
+	Latency time.Duration
+	// StatusCode, if set, is returned instead of handling the request.
+	StatusCode int
+	// ErrorMessage and Description are returned in the body of a failure
+	// response as the "error" and "description" fields.
+	ErrorMessage string
+	Description  string
+	// Times limits how many requests the fault applies to. A value of 0
+	// means the fault applies until it is cleared.
+	Times int
+}
+
+// Latency returns a Fault that delays every response of the given route.
+func Latency(route Route, latency time.Duration) *Fault {
+	return &Fault{Route: route, Latency: latency}
+}
+
+// ServerError returns a Fault that makes the given route fail with the given
+// 5xx status code.
+func ServerError(route Route, statusCode int) *Fault {
+	return &Fault{
+		Route:       route,
+		StatusCode:  statusCode,
+		Description: http.StatusText(statusCode),
+	}
+}
+
+// Gone returns a Fault that makes the given route respond with HTTP GONE.
+func Gone(route Route) *Fault {
+	return &Fault{Route: route, StatusCode: http.StatusGone}
+}
+
+// ConcurrencyError returns a Fault that makes the given route respond with
+// the error a broker returns while another operation on the same resource is
+// in progress.
+func ConcurrencyError(route Route) *Fault {
+	return &Fault{
+		Route:        route,
+		StatusCode:   http.StatusUnprocessableEntity,
+		ErrorMessage: v2.ConcurrencyErrorMessage,
+		Description:  v2.ConcurrencyErrorDescription,
+	}
+}
+
+// InjectFault makes the Broker apply the given Fault to matching requests.
+// Faults are applied in the order they were injected; only the first
+// matching fault applies to a request.
+func (b *Broker) InjectFault(f *Fault) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.faults = append(b.faults, f)
+}
+
+// ClearFaults removes all injected faults.
+func (b *Broker) ClearFaults() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.faults = nil
+}
+
+// takeFault returns the first fault matching the route, if any, and uses up
+// one of its applications. Callers must hold b.mu.
+func (b *Broker) takeFault(route Route) *Fault {
+	for i, f := range b.faults {
+		if f.Route != "" && f.Route != route {
+			continue
+		}
+
+		if f.Times > 0 {
+			f.Times--
+			if f.Times == 0 {
+				b.faults = append(b.faults[:i:i], b.faults[i+1:]...)
+			}
+		}
+		return f
+	}
+	return nil
+}
+
+// applyFault delays and, if the fault asks for it, answers the request. It
+// returns true if the request has been answered.
+func applyFault(w http.ResponseWriter, r *http.Request, f *Fault) bool {
+	if f.Latency > 0 {
+		timer := time.NewTimer(f.Latency)
+		select {
+		case <-r.Context().Done():
+			timer.Stop()
+			return true
+		case <-timer.C:
+		}
+	}
+
+	if f.StatusCode == 0 {
+		return false
+	}
+
+	writeError(w, f.StatusCode, f.ErrorMessage, f.Description)
+	return true
+}
@@ -0,0 +1,553 @@
+/*
+Copyright 2024 Klutch Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package osbtest
+
+import (
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"slices"
+	"strconv"
+	"time"
+
+	v2 "github.com/anynines/klutchio/clients/a9s-open-service-broker"
+)
+
+const (
+	// instanceNotFound is the error an a9s broker returns for requests
+	// about service instances it does not know.
+	instanceNotFound            = "InstanceNotFound"
+	instanceNotFoundDescription = "Instance not found"
+
+	instancePath = "/v2/service_instances/{" + v2.VarKeyInstanceID + "}"
+	bindingPath  = instancePath + "/service_bindings/{" + v2.VarKeyBindingID + "}"
+)
+
+type provisionRequestBody struct {
+	ServiceID        string                 `json:"service_id"`
+	PlanID           string                 `json:"plan_id"`
+	OrganizationGUID string                 `json:"organization_guid"`
+	SpaceGUID        string                 `json:"space_guid"`
+	Parameters       map[string]interface{} `json:"parameters,omitempty"`
+	MaintenanceInfo  *v2.MaintenanceInfo    `json:"maintenance_info,omitempty"`
+}
+
+type updateInstanceRequestBody struct {
+	ServiceID       string                 `json:"service_id"`
+	PlanID          *string                `json:"plan_id,omitempty"`
+	Parameters      map[string]interface{} `json:"parameters,omitempty"`
+	MaintenanceInfo *v2.MaintenanceInfo    `json:"maintenance_info,omitempty"`
+}
+
+type bindRequestBody struct {
+	ServiceID  string                 `json:"service_id"`
+	PlanID     string                 `json:"plan_id"`
+	Parameters map[string]interface{} `json:"parameters,omitempty"`
+}
+
+type operationResponseBody struct {
+	Operation string `json:"operation,omitempty"`
+}
+
+type serviceInstanceResponseBody struct {
+	ServiceID       string                 `json:"service_id"`
+	PlanID          string                 `json:"plan_id"`
+	Parameters      map[string]interface{} `json:"parameters,omitempty"`
+	MaintenanceInfo *v2.MaintenanceInfo    `json:"maintenance_info,omitempty"`
+}
+
+type bindingResponseBody struct {
+	Credentials map[string]interface{} `json:"credentials,omitempty"`
+	Parameters  map[string]interface{} `json:"parameters,omitempty"`
+	Operation   string                 `json:"operation,omitempty"`
+}
+
+type lastOperationResponseBody struct {
+	State v2.LastOperationState `json:"state"`
+}
+
+type errorResponseBody struct {
+	Error       string `json:"error,omitempty"`
+	Description string `json:"description,omitempty"`
+}
+
+func (b *Broker) handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.Handle("GET /v2/catalog", b.route(RouteCatalog, b.getCatalog))
+	mux.Handle("PUT "+instancePath, b.route(RouteProvision, b.provision))
+	mux.Handle("PATCH "+instancePath, b.route(RouteUpdateInstance, b.updateInstance))
+	mux.Handle("DELETE "+instancePath, b.route(RouteDeprovision, b.deprovision))
+	mux.Handle("GET "+instancePath, b.route(RouteGetServiceInstance, b.getServiceInstance))
+	mux.Handle("GET "+instancePath+"/last_operation", b.route(RouteLastOperation, b.lastOperation))
+	mux.Handle("PUT "+bindingPath, b.route(RouteBind, b.bind))
+	mux.Handle("DELETE "+bindingPath, b.route(RouteUnbind, b.unbind))
+	mux.Handle("GET "+bindingPath, b.route(RouteGetBinding, b.getBinding))
+	mux.Handle("GET "+bindingPath+"/last_operation", b.route(RouteBindingLastOperation, b.bindingLastOperation))
+	mux.Handle("GET /instances/{"+v2.VarKeyInstanceID+"}", b.route(RouteGetInstance, b.getInstance))
+	mux.Handle("GET /instances", b.route(RouteGetInstances, b.getInstances))
+	return mux
+}
+
+// route records the request, applies injected faults, checks the request
+// headers and then calls handle with b.mu held.
+func (b *Broker) route(route Route, handle http.HandlerFunc) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b.mu.Lock()
+		b.requests = append(b.requests, Request{
+			Route:  route,
+			Method: r.Method,
+			Path:   r.URL.Path,
+			Header: r.Header.Clone(),
+		})
+		fault := b.takeFault(route)
+		b.mu.Unlock()
+
+		if fault != nil && applyFault(w, r, fault) {
+			return
+		}
+
+		if !b.authorized(r) {
+			writeError(w, http.StatusUnauthorized, "", "invalid credentials")
+			return
+		}
+		if r.Header.Get(v2.APIVersionHeader) == "" {
+			writeError(w, http.StatusPreconditionFailed, "", "missing "+v2.APIVersionHeader+" header")
+			return
+		}
+
+		b.mu.Lock()
+		defer b.mu.Unlock()
+
+		handle(w, r)
+	})
+}
+
+func (b *Broker) getCatalog(w http.ResponseWriter, _ *http.Request) {
+	writeJSON(w, http.StatusOK, b.catalog)
+}
+
+func (b *Broker) provision(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue(v2.VarKeyInstanceID)
+
+	body := &provisionRequestBody{}
+	if !decodeBody(w, r, body) {
+		return
+	}
+	if !b.inCatalog(body.ServiceID, body.PlanID) {
+		writeError(w, http.StatusBadRequest, "", "unknown service or plan")
+		return
+	}
+
+	async, ok := b.async(w, r)
+	if !ok {
+		return
+	}
+
+	if existing, ok := b.instances[id]; ok && !existing.deleted() {
+		identical := existing.ServiceID == body.ServiceID &&
+			existing.PlanID == body.PlanID &&
+			reflect.DeepEqual(existing.Parameters, mergeParameters(nil, body.Parameters))
+		switch {
+		case !identical:
+			writeError(w, http.StatusConflict, "", "instance already exists with different attributes")
+		case b.inProgress(id, existing.lastOperation):
+			writeJSON(w, http.StatusAccepted, operationResponseBody{Operation: existing.lastOperation})
+		default:
+			writeJSON(w, http.StatusOK, struct{}{})
+		}
+		return
+	}
+
+	now := time.Now()
+	instance := &Instance{
+		ID:               id,
+		ServiceID:        body.ServiceID,
+		PlanID:           body.PlanID,
+		OrganizationGUID: body.OrganizationGUID,
+		SpaceGUID:        body.SpaceGUID,
+		Parameters:       mergeParameters(nil, body.Parameters),
+		MaintenanceInfo:  body.MaintenanceInfo,
+		State:            StateDeploying,
+		CreatedAt:        now,
+		UpdatedAt:        now,
+		number:           b.nextID(),
+	}
+	b.instances[id] = instance
+
+	instance.lastOperation = b.startOperation(id, async, func() {
+		instance.State = StateProvisioned
+		instance.ProvisionedAt = time.Now()
+		instance.UpdatedAt = instance.ProvisionedAt
+	}, func() {
+		instance.State = StateFailed
+	})
+
+	if async {
+		writeJSON(w, http.StatusAccepted, operationResponseBody{Operation: instance.lastOperation})
+		return
+	}
+	writeJSON(w, http.StatusCreated, struct{}{})
+}
+
+func (b *Broker) updateInstance(w http.ResponseWriter, r *http.Request) {
+	body := &updateInstanceRequestBody{}
+	if !decodeBody(w, r, body) {
+		return
+	}
+
+	instance, ok := b.existingInstance(w, r, http.StatusNotFound)
+	if !ok {
+		return
+	}
+
+	planID := instance.PlanID
+	if body.PlanID != nil {
+		planID = *body.PlanID
+	}
+	if body.ServiceID != instance.ServiceID || !b.inCatalog(body.ServiceID, planID) {
+		writeError(w, http.StatusBadRequest, "", "unknown service or plan")
+		return
+	}
+
+	async, ok := b.async(w, r)
+	if !ok {
+		return
+	}
+
+	previousState := instance.State
+	instance.State = StateDeploying
+	instance.lastOperation = b.startOperation(instance.ID, async, func() {
+		instance.PlanID = planID
+		instance.Parameters = mergeParameters(instance.Parameters, body.Parameters)
+		if body.MaintenanceInfo != nil {
+			instance.MaintenanceInfo = body.MaintenanceInfo
+		}
+		instance.State = StateProvisioned
+		instance.UpdatedAt = time.Now()
+	}, func() {
+		instance.State = previousState
+	})
+
+	if async {
+		writeJSON(w, http.StatusAccepted, operationResponseBody{Operation: instance.lastOperation})
+		return
+	}
+	writeJSON(w, http.StatusOK, struct{}{})
+}
+
+func (b *Broker) deprovision(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	if query.Get(v2.VarKeyServiceID) == "" || query.Get(v2.VarKeyPlanID) == "" {
+		writeError(w, http.StatusBadRequest, "", "service_id and plan_id are required")
+		return
+	}
+
+	instance, ok := b.existingInstance(w, r, http.StatusGone)
+	if !ok {
+		return
+	}
+
+	async, ok := b.async(w, r)
+	if !ok {
+		return
+	}
+
+	previousState := instance.State
+	instance.State = StateDeleting
+	instance.lastOperation = b.startOperation(instance.ID, async, func() {
+		for key, binding := range b.bindings {
+			if binding.InstanceID == instance.ID {
+				delete(b.bindings, key)
+			}
+		}
+		instance.State = StateDeleted
+		instance.DeletedAt = time.Now()
+		instance.UpdatedAt = instance.DeletedAt
+	}, func() {
+		instance.State = previousState
+	})
+
+	if async {
+		writeJSON(w, http.StatusAccepted, operationResponseBody{Operation: instance.lastOperation})
+		return
+	}
+	writeJSON(w, http.StatusOK, struct{}{})
+}
+
+func (b *Broker) getServiceInstance(w http.ResponseWriter, r *http.Request) {
+	instance, ok := b.instances[r.PathValue(v2.VarKeyInstanceID)]
+	if !ok || instance.deleted() {
+		writeError(w, http.StatusNotFound, instanceNotFound, instanceNotFoundDescription)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, serviceInstanceResponseBody{
+		ServiceID:       instance.ServiceID,
+		PlanID:          instance.PlanID,
+		Parameters:      instance.Parameters,
+		MaintenanceInfo: instance.MaintenanceInfo,
+	})
+}
+
+func (b *Broker) lastOperation(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue(v2.VarKeyInstanceID)
+
+	key := r.URL.Query().Get(v2.VarKeyOperation)
+	if key == "" {
+		if instance, ok := b.instances[id]; ok {
+			key = instance.lastOperation
+		}
+	}
+
+	b.writeLastOperation(w, operationKey{id, key})
+}
+
+func (b *Broker) bind(w http.ResponseWriter, r *http.Request) {
+	key := bindingKey{r.PathValue(v2.VarKeyInstanceID), r.PathValue(v2.VarKeyBindingID)}
+
+	body := &bindRequestBody{}
+	if !decodeBody(w, r, body) {
+		return
+	}
+
+	instance, ok := b.existingInstance(w, r, http.StatusNotFound)
+	if !ok {
+		return
+	}
+	if body.ServiceID != instance.ServiceID || body.PlanID != instance.PlanID {
+		writeError(w, http.StatusBadRequest, "", "service or plan does not match the instance")
+		return
+	}
+
+	if existing, ok := b.bindings[key]; ok {
+		identical := reflect.DeepEqual(existing.Parameters, mergeParameters(nil, body.Parameters))
+		switch {
+		case !identical:
+			writeError(w, http.StatusConflict, "", "binding already exists with different attributes")
+		case b.inProgress(key.instanceID, existing.lastOperation):
+			writeJSON(w, http.StatusAccepted, bindingResponseBody{Operation: existing.lastOperation})
+		default:
+			writeJSON(w, http.StatusOK, bindingResponseBody{Credentials: existing.Credentials})
+		}
+		return
+	}
+
+	binding := &Binding{
+		ID:         key.bindingID,
+		InstanceID: key.instanceID,
+		ServiceID:  body.ServiceID,
+		PlanID:     body.PlanID,
+		Parameters: mergeParameters(nil, body.Parameters),
+		Credentials: map[string]interface{}{
+			"host":     "osbtest.local",
+			"port":     5432,
+			"username": "user-" + key.bindingID,
+			"password": "password-" + key.bindingID,
+		},
+		number: b.nextID(),
+	}
+	b.bindings[key] = binding
+
+	// Unlike instance operations, bindings are created synchronously for
+	// clients that do not accept incomplete operations.
+	async := b.options.Async && acceptsIncomplete(r)
+	binding.lastOperation = b.startOperation(key.instanceID, async, func() {
+		binding.Ready = true
+	}, func() {
+		delete(b.bindings, key)
+	})
+
+	if async {
+		writeJSON(w, http.StatusAccepted, bindingResponseBody{Operation: binding.lastOperation})
+		return
+	}
+	writeJSON(w, http.StatusCreated, bindingResponseBody{Credentials: binding.Credentials})
+}
+
+func (b *Broker) unbind(w http.ResponseWriter, r *http.Request) {
+	key := bindingKey{r.PathValue(v2.VarKeyInstanceID), r.PathValue(v2.VarKeyBindingID)}
+
+	binding, ok := b.bindings[key]
+	if !ok {
+		writeError(w, http.StatusGone, "", "")
+		return
+	}
+	if b.inProgress(key.instanceID, binding.lastOperation) {
+		writeError(w, http.StatusUnprocessableEntity, v2.ConcurrencyErrorMessage, v2.ConcurrencyErrorDescription)
+		return
+	}
+
+	async := b.options.Async && acceptsIncomplete(r)
+	binding.lastOperation = b.startOperation(key.instanceID, async, func() {
+		delete(b.bindings, key)
+	}, func() {})
+
+	if async {
+		writeJSON(w, http.StatusAccepted, operationResponseBody{Operation: binding.lastOperation})
+		return
+	}
+	writeJSON(w, http.StatusOK, struct{}{})
+}
+
+func (b *Broker) getBinding(w http.ResponseWriter, r *http.Request) {
+	binding, ok := b.bindings[bindingKey{r.PathValue(v2.VarKeyInstanceID), r.PathValue(v2.VarKeyBindingID)}]
+	if !ok || !binding.Ready && b.inProgress(binding.InstanceID, binding.lastOperation) {
+		writeError(w, http.StatusNotFound, "", "binding not found")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, bindingResponseBody{
+		Credentials: binding.Credentials,
+		Parameters:  binding.Parameters,
+	})
+}
+
+func (b *Broker) bindingLastOperation(w http.ResponseWriter, r *http.Request) {
+	instanceID := r.PathValue(v2.VarKeyInstanceID)
+
+	key := r.URL.Query().Get(v2.VarKeyOperation)
+	if key == "" {
+		if binding, ok := b.bindings[bindingKey{instanceID, r.PathValue(v2.VarKeyBindingID)}]; ok {
+			key = binding.lastOperation
+		}
+	}
+
+	b.writeLastOperation(w, operationKey{instanceID, key})
+}
+
+func (b *Broker) getInstance(w http.ResponseWriter, r *http.Request) {
+	instance, ok := b.instances[r.PathValue(v2.VarKeyInstanceID)]
+	if !ok {
+		writeError(w, http.StatusNotFound, instanceNotFound, instanceNotFoundDescription)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, instance.getInstanceResponse(b.bindingsOf(instance.ID)))
+}
+
+func (b *Broker) getInstances(w http.ResponseWriter, _ *http.Request) {
+	instances := make([]*Instance, 0, len(b.instances))
+	for _, instance := range b.instances {
+		instances = append(instances, instance)
+	}
+	slices.SortFunc(instances, func(a, b *Instance) int { return a.number - b.number })
+
+	response := v2.GetInstancesResponse{
+		TotalResults: len(instances),
+		TotalPages:   1,
+		CurrentPage:  1,
+		Resources:    make([]v2.GetInstanceResponse, 0, len(instances)),
+	}
+	for _, instance := range instances {
+		response.Resources = append(response.Resources, instance.getInstanceResponse(b.bindingsOf(instance.ID)))
+	}
+
+	writeJSON(w, http.StatusOK, response)
+}
+
+func (b *Broker) writeLastOperation(w http.ResponseWriter, key operationKey) {
+	op, ok := b.operations[key]
+	if !ok {
+		writeError(w, http.StatusNotFound, "", "operation not found")
+		return
+	}
+
+	state := op.poll()
+	if state == v2.StateInProgress && b.options.RetryAfter > 0 {
+		w.Header().Set(v2.PollingDelayHeader, strconv.Itoa(int(b.options.RetryAfter.Seconds())))
+	}
+
+	writeJSON(w, http.StatusOK, lastOperationResponseBody{State: state})
+}
+
+// existingInstance looks up the instance a request is for. If it does not
+// exist the request is answered with the given status code. If another
+// operation is in progress on it, the request is answered with a
+// ConcurrencyError.
+func (b *Broker) existingInstance(w http.ResponseWriter, r *http.Request, notFoundStatus int) (*Instance, bool) {
+	instance, ok := b.instances[r.PathValue(v2.VarKeyInstanceID)]
+	if !ok || instance.deleted() {
+		writeError(w, notFoundStatus, instanceNotFound, instanceNotFoundDescription)
+		return nil, false
+	}
+	if b.inProgress(instance.ID, instance.lastOperation) {
+		writeError(w, http.StatusUnprocessableEntity, v2.ConcurrencyErrorMessage, v2.ConcurrencyErrorDescription)
+		return nil, false
+	}
+	return instance, true
+}
+
+// async returns whether the request is handled asynchronously. If the
+// Broker requires asynchronous operations but the client does not accept
+// them, the request is answered with an AsyncRequired error.
+func (b *Broker) async(w http.ResponseWriter, r *http.Request) (bool, bool) {
+	if !b.options.Async {
+		return false, true
+	}
+	if !acceptsIncomplete(r) {
+		writeError(w, http.StatusUnprocessableEntity, v2.AsyncErrorMessage, v2.AsyncErrorDescription)
+		return false, false
+	}
+	return true, true
+}
+
+func (b *Broker) inCatalog(serviceID, planID string) bool {
+	for _, service := range b.catalog.Services {
+		if service.ID != serviceID {
+			continue
+		}
+		for _, plan := range service.Plans {
+			if plan.ID == planID {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func (b *Broker) bindingsOf(instanceID string) []*Binding {
+	var bindings []*Binding
+	for _, binding := range b.bindings {
+		if binding.InstanceID == instanceID {
+			bindings = append(bindings, binding)
+		}
+	}
+	slices.SortFunc(bindings, func(a, b *Binding) int { return a.number - b.number })
+	return bindings
+}
+
+func acceptsIncomplete(r *http.Request) bool {
+	return r.URL.Query().Get(v2.AcceptsIncomplete) == "true"
+}
+
+func decodeBody(w http.ResponseWriter, r *http.Request, body interface{}) bool {
+	if err := json.NewDecoder(r.Body).Decode(body); err != nil {
+		writeError(w, http.StatusBadRequest, "", "malformed request body: "+err.Error())
+		return false
+	}
+	return true
+}
+
+func writeJSON(w http.ResponseWriter, statusCode int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+	_ = json.NewEncoder(w).Encode(body)
+}
+
+func writeError(w http.ResponseWriter, statusCode int, errorMessage, description string) {
+	writeJSON(w, statusCode, errorResponseBody{Error: errorMessage, Description: description})
+}
@@ -0,0 +1,245 @@
+/*
+Copyright 2024 Klutch Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package osbtest
+
+import (
+	"fmt"
+	"maps"
+	"time"
+
+	v2 "github.com/anynines/klutchio/clients/a9s-open-service-broker"
+)
+
+// These are the states the Broker reports for a service instance on the a9s
+// /instances endpoints.
+const (
+	StateDeploying   = "deploying"
+	StateProvisioned = "provisioned"
+	StateDeleting    = "deleting"
+	StateDeleted     = "deleted"
+	StateFailed      = "failed"
+)
+
+// Instance is a service instance known to the Broker.
+type Instance struct {
+	ID               string
+	ServiceID        string
+	PlanID           string
+	OrganizationGUID string
+	SpaceGUID        string
+	Parameters       map[string]interface{}
+	State            string
+	CreatedAt        time.Time
+	UpdatedAt        time.Time
+	ProvisionedAt    time.Time
+	DeletedAt        time.Time
+	// MaintenanceInfo is the maintenance info the instance was provisioned
+	// or last upgraded with.
+	MaintenanceInfo *v2.MaintenanceInfo
+
+	number        int
+	lastOperation string
+}
+
+// Binding is a service binding known to the Broker.
+type Binding struct {
+	ID          string
+	InstanceID  string
+	ServiceID   string
+	PlanID      string
+	Parameters  map[string]interface{}
+	Credentials map[string]interface{}
+	// Ready is false while an asynchronous bind is in progress.
+	Ready bool
+
+	number        int
+	lastOperation string
+}
+
+type bindingKey struct {
+	instanceID string
+	bindingID  string
+}
+
+type operationKey struct {
+	instanceID string
+	key        string
+}
+
+// operation is an asynchronous operation. It reports StateInProgress until
+// it has been polled pollsLeft more times, then calls complete or fail and
+// reports the final state.
+type operation struct {
+	state     v2.LastOperationState
+	pollsLeft int
+	failed    bool
+	complete  func()
+	fail      func()
+}
+
+// Instance returns a copy of the service instance with the given ID.
+func (b *Broker) Instance(id string) (Instance, bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	instance, ok := b.instances[id]
+	if !ok {
+		return Instance{}, false
+	}
+	result := *instance
+	result.Parameters = maps.Clone(instance.Parameters)
+	if instance.MaintenanceInfo != nil {
+		maintenanceInfo := *instance.MaintenanceInfo
+		result.MaintenanceInfo = &maintenanceInfo
+	}
+	return result, true
+}
+
+// Binding returns a copy of the service binding with the given IDs.
+func (b *Broker) Binding(instanceID, bindingID string) (Binding, bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	binding, ok := b.bindings[bindingKey{instanceID, bindingID}]
+	if !ok {
+		return Binding{}, false
+	}
+	result := *binding
+	result.Parameters = maps.Clone(binding.Parameters)
+	result.Credentials = maps.Clone(binding.Credentials)
+	return result, true
+}
+
+// FailNextOperation makes the next asynchronous operation the Broker starts
+// end in the failed state.
+func (b *Broker) FailNextOperation() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.failNext = true
+}
+
+// startOperation runs complete right away if the request is handled
+// synchronously. Otherwise it registers an asynchronous operation for the
+// instance and returns its key. Callers must hold b.mu.
+func (b *Broker) startOperation(instanceID string, async bool, complete, fail func()) string {
+	if !async {
+		complete()
+		return ""
+	}
+
+	key := fmt.Sprintf("osbtest-%d", b.nextID())
+	b.operations[operationKey{instanceID, key}] = &operation{
+		state:     v2.StateInProgress,
+		pollsLeft: b.options.PollsUntilDone,
+		failed:    b.failNext,
+		complete:  complete,
+		fail:      fail,
+	}
+	b.failNext = false
+
+	return key
+}
+
+// poll advances the operation by one poll and returns its state. Callers
+// must hold b.mu.
+func (o *operation) poll() v2.LastOperationState {
+	if o.state != v2.StateInProgress {
+		return o.state
+	}
+
+	if o.pollsLeft > 0 {
+		o.pollsLeft--
+		return o.state
+	}
+
+	if o.failed {
+		o.state = v2.StateFailed
+		o.fail()
+	} else {
+		o.state = v2.StateSucceeded
+		o.complete()
+	}
+	return o.state
+}
+
+// inProgress returns whether the operation with the given key is still in
+// progress. Callers must hold b.mu.
+func (b *Broker) inProgress(instanceID, key string) bool {
+	if key == "" {
+		return false
+	}
+	op, ok := b.operations[operationKey{instanceID, key}]
+	return ok && op.state == v2.StateInProgress
+}
+
+func (i *Instance) deleted() bool {
+	return i.State == StateDeleted
+}
+
+func (i *Instance) getInstanceResponse(bindings []*Binding) v2.GetInstanceResponse {
+	response := v2.GetInstanceResponse{
+		ID:             i.number,
+		PlanGUID:       i.PlanID,
+		ServiceGUID:    i.ServiceID,
+		DeploymentName: fmt.Sprintf("osbtest-%d", i.number),
+		State:          i.State,
+		GUIDAtTenant:   i.ID,
+		TenantID:       "osbtest",
+		ProvisionedAt:  formatTime(i.ProvisionedAt),
+		DeletedAt:      formatTime(i.DeletedAt),
+		CreatedAt:      formatTime(i.CreatedAt),
+		UpdatedAt:      formatTime(i.UpdatedAt),
+		Credentials:    []v2.Credential{},
+		Context: v2.Context{
+			OrganizationGUID: i.OrganizationGUID,
+			SpaceGUID:        i.SpaceGUID,
+		},
+	}
+	for _, binding := range bindings {
+		response.Credentials = append(response.Credentials, v2.Credential{
+			ID:           binding.number,
+			InstanceID:   i.number,
+			GUIDAtTenant: binding.ID,
+		})
+	}
+	return response
+}
+
+func formatTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339)
+}
+
+// mergeParameters applies an update to the given parameters. Parameters set
+// to null in the update are removed.
+func mergeParameters(parameters, update map[string]interface{}) map[string]interface{} {
+	merged := maps.Clone(parameters)
+	if merged == nil {
+		merged = map[string]interface{}{}
+	}
+	for key, value := range update {
+		if value == nil {
+			delete(merged, key)
+			continue
+		}
+		merged[key] = value
+	}
+	return merged
+}
@@ -17,13 +17,12 @@ limitations under the License.
 package v2
 
 import (
+	"context"
 	"fmt"
 	"net/http"
-	"strconv"
-	"time"
 )
 
-func (c *client) PollBindingLastOperation(r *BindingLastOperationRequest) (*LastOperationResponse, error) {
+func (c *client) PollBindingLastOperation(ctx context.Context, r *BindingLastOperationRequest) (*LastOperationResponse, error) {
 	if err := c.validateClientVersionIsAtLeast(Version2_14()); err != nil {
 		return nil, AsyncBindingOperationsNotAllowedError{
 			reason: err.Error(),
@@ -49,7 +48,10 @@ func (c *client) PollBindingLastOperation(r *BindingLastOperationRequest) (*Last
 		params[VarKeyOperation] = opStr
 	}
 
-	response, err := c.prepareAndDo(http.MethodGet, fullURL, params, nil /* request body */, r.OriginatingIdentity)
+	ctx, span := c.startSpan(ctx, operationBindingLastOperation, r, attributeInstanceID.String(r.InstanceID), attributeBindingID.String(r.BindingID))
+	defer span.End()
+
+	response, err := c.prepareAndDo(ctx, http.MethodGet, fullURL, params, nil /* request body */, r.OriginatingIdentity)
 	if err != nil {
 		return nil, err
 	}
@@ -65,15 +67,12 @@ func (c *client) PollBindingLastOperation(r *BindingLastOperationRequest) (*Last
 		if err := c.unmarshalResponse(response, userResponse); err != nil {
 			return nil, HTTPStatusCodeError{StatusCode: response.StatusCode, ResponseError: err}
 		}
-
-		if c.EnableAlphaFeatures {
-			delayInSeconds := response.Header.Get(PollingDelayHeader)
-			if delay, err := strconv.Atoi(delayInSeconds); err == nil {
-				pollDelay := time.Duration(delay) * time.Second
-				userResponse.PollDelay = &pollDelay
-			}
+		if err := c.validateResponse(ctx, lastOperationViolations(userResponse.State)); err != nil {
+			return nil, err
 		}
 
+		userResponse.PollDelay = pollDelayFromHeader(response.Header)
+
 		return userResponse, nil
 	default:
 		return nil, c.handleFailureResponse(response)
@@ -17,6 +17,7 @@ limitations under the License.
 package v2
 
 import (
+	"context"
 	"fmt"
 	"net/http"
 	"testing"
@@ -148,7 +149,7 @@ func TestPollBindingLastOperation(t *testing.T) {
 			},
 		},
 		{
-			name:       "polling delay header is ignored when alpha feature is disabled",
+			name:       "polling delay header is decoded when alpha feature is disabled",
 			APIVersion: LatestAPIVersion(),
 			httpReaction: httpReaction{
 				status: http.StatusOK,
@@ -158,7 +159,7 @@ func TestPollBindingLastOperation(t *testing.T) {
 			expectedResponse: &LastOperationResponse{
 				State:       StateInProgress,
 				Description: strPtr("test description"),
-				PollDelay:   nil,
+				PollDelay:   durationPtr(300 * time.Second),
 			},
 		},
 		{
@@ -201,7 +202,7 @@ func TestPollBindingLastOperation(t *testing.T) {
 
 		klient := newTestClient(t, tc.name, tc.APIVersion, tc.enableAlpha, tc.httpChecks, tc.httpReaction)
 
-		response, err := klient.PollBindingLastOperation(tc.request)
+		response, err := klient.PollBindingLastOperation(context.Background(), tc.request)
 
 		doResponseChecks(t, tc.name, response, err, tc.expectedResponse, tc.expectedErrMessage, tc.expectedErr)
 	}
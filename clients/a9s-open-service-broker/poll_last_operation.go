@@ -17,13 +17,12 @@ limitations under the License.
 package v2
 
 import (
+	"context"
 	"fmt"
 	"net/http"
-	"strconv"
-	"time"
 )
 
-func (c *client) PollLastOperation(r *LastOperationRequest) (*LastOperationResponse, error) {
+func (c *client) PollLastOperation(ctx context.Context, r *LastOperationRequest) (*LastOperationResponse, error) {
 	if err := validateLastOperationRequest(r); err != nil {
 		return nil, err
 	}
@@ -43,7 +42,10 @@ func (c *client) PollLastOperation(r *LastOperationRequest) (*LastOperationRespo
 		params[VarKeyOperation] = opStr
 	}
 
-	response, err := c.prepareAndDo(http.MethodGet, fullURL, params, nil /* request body */, r.OriginatingIdentity)
+	ctx, span := c.startSpan(ctx, operationLastOperation, r, attributeInstanceID.String(r.InstanceID))
+	defer span.End()
+
+	response, err := c.prepareAndDo(ctx, http.MethodGet, fullURL, params, nil /* request body */, r.OriginatingIdentity)
 	if err != nil {
 		return nil, err
 	}
@@ -59,14 +61,12 @@ func (c *client) PollLastOperation(r *LastOperationRequest) (*LastOperationRespo
 		if err := c.unmarshalResponse(response, userResponse); err != nil {
 			return nil, HTTPStatusCodeError{StatusCode: response.StatusCode, ResponseError: err}
 		}
-
-		if c.EnableAlphaFeatures {
-			if delay, err := strconv.Atoi(response.Header.Get(PollingDelayHeader)); err == nil && delay > 0 {
-				duration := time.Duration(delay) * time.Second
-				userResponse.PollDelay = &duration
-			}
+		if err := c.validateResponse(ctx, lastOperationViolations(userResponse.State)); err != nil {
+			return nil, err
 		}
 
+		userResponse.PollDelay = pollDelayFromHeader(response.Header)
+
 		return userResponse, nil
 	default:
 		return nil, c.handleFailureResponse(response)
@@ -17,6 +17,7 @@ limitations under the License.
 package v2
 
 import (
+	"context"
 	"fmt"
 	"net/http"
 	"testing"
@@ -192,6 +193,21 @@ func TestPollLastOperation(t *testing.T) {
 				body:   inProgressLastOperationResponseBody,
 				header: map[string][]string{PollingDelayHeader: {"600"}},
 			},
+			expectedResponse: &LastOperationResponse{
+				State:       StateInProgress,
+				Description: strPtr("test description"),
+				PollDelay:   durationPtr(600 * time.Second),
+			},
+		},
+		{
+			name:        "non-positive retry delay header is ignored",
+			version:     LatestAPIVersion(),
+			enableAlpha: true,
+			httpReaction: httpReaction{
+				status: http.StatusOK,
+				body:   inProgressLastOperationResponseBody,
+				header: map[string][]string{PollingDelayHeader: {"0"}},
+			},
 			expectedResponse: &LastOperationResponse{
 				State:       StateInProgress,
 				Description: strPtr("test description"),
@@ -237,7 +253,7 @@ func TestPollLastOperation(t *testing.T) {
 
 		klient := newTestClient(t, tc.name, tc.version, tc.enableAlpha, tc.httpChecks, tc.httpReaction)
 
-		response, err := klient.PollLastOperation(tc.request)
+		response, err := klient.PollLastOperation(context.Background(), tc.request)
 
 		doResponseChecks(t, tc.name, response, err, tc.expectedResponse, tc.expectedErrMessage, tc.expectedErr)
 	}
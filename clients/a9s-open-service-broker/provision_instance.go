@@ -17,10 +17,9 @@ limitations under the License.
 package v2
 
 import (
+	"context"
 	"fmt"
 	"net/http"
-
-	"k8s.io/klog/v2"
 )
 
 // internal message body types
@@ -32,6 +31,7 @@ type provisionRequestBody struct {
 	SpaceGUID        string                 `json:"space_guid"`
 	Parameters       map[string]interface{} `json:"parameters,omitempty"`
 	Context          map[string]interface{} `json:"context,omitempty"`
+	MaintenanceInfo  *MaintenanceInfo       `json:"maintenance_info,omitempty"`
 }
 
 type provisionSuccessResponseBody struct {
@@ -39,7 +39,7 @@ type provisionSuccessResponseBody struct {
 	Operation    *string `json:"operation"`
 }
 
-func (c *client) ProvisionInstance(r *ProvisionRequest) (*ProvisionResponse, error) {
+func (c *client) ProvisionInstance(ctx context.Context, r *ProvisionRequest) (*ProvisionResponse, error) {
 	if err := validateProvisionRequest(r); err != nil {
 		return nil, err
 	}
@@ -57,13 +57,17 @@ func (c *client) ProvisionInstance(r *ProvisionRequest) (*ProvisionResponse, err
 		OrganizationGUID: r.OrganizationGUID,
 		SpaceGUID:        r.SpaceGUID,
 		Parameters:       r.Parameters,
+		MaintenanceInfo:  r.MaintenanceInfo,
 	}
 
-	if c.APIVersion.AtLeast(Version2_12()) {
+	if c.Version().AtLeast(Version2_12()) {
 		requestBody.Context = r.Context
 	}
 
-	response, err := c.prepareAndDo(http.MethodPut, fullURL, params, requestBody, r.OriginatingIdentity)
+	ctx, span := c.startSpan(ctx, operationProvision, r, attributeInstanceID.String(r.InstanceID))
+	defer span.End()
+
+	response, err := c.prepareAndDo(ctx, http.MethodPut, fullURL, params, requestBody, r.OriginatingIdentity)
 	if err != nil {
 		return nil, err
 	}
@@ -79,6 +83,9 @@ func (c *client) ProvisionInstance(r *ProvisionRequest) (*ProvisionResponse, err
 		if err := c.unmarshalResponse(response, userResponse); err != nil {
 			return nil, HTTPStatusCodeError{StatusCode: response.StatusCode, ResponseError: err}
 		}
+		if err := c.validateResponse(ctx, provisionViolations(userResponse)); err != nil {
+			return nil, err
+		}
 
 		return userResponse, nil
 	case http.StatusAccepted:
@@ -106,8 +113,12 @@ func (c *client) ProvisionInstance(r *ProvisionRequest) (*ProvisionResponse, err
 			OperationKey: opPtr,
 		}
 
+		if err := c.validateResponse(ctx, provisionViolations(userResponse)); err != nil {
+			return nil, err
+		}
+
 		if c.Verbose {
-			klog.Infof("broker %q: received asynchronous response", c.Name)
+			c.logger.Info("Received asynchronous response")
 		}
 
 		return userResponse, nil
@@ -17,6 +17,7 @@ limitations under the License.
 package v2
 
 import (
+	"context"
 	"fmt"
 	"net/http"
 	"testing"
@@ -73,6 +74,8 @@ func successProvisionResponseAsync() *ProvisionResponse {
 	return r
 }
 
+const maintenanceInfoProvisionRequestBody = `{"service_id":"test-service-id","plan_id":"test-plan-id","organization_guid":"test-organization-guid","space_guid":"test-space-guid","maintenance_info":{"version":"1.2.3"}}`
+
 const contextProvisionRequestBody = `{"service_id":"test-service-id","plan_id":"test-plan-id","organization_guid":"test-organization-guid","space_guid":"test-space-guid","context":{"foo":"bar"}}`
 
 func TestProvisionInstance(t *testing.T) {
@@ -185,6 +188,22 @@ func TestProvisionInstance(t *testing.T) {
 			},
 			expectedResponse: successProvisionResponse(),
 		},
+		{
+			name: "maintenance info",
+			request: func() *ProvisionRequest {
+				r := defaultProvisionRequest()
+				r.MaintenanceInfo = &MaintenanceInfo{Version: "1.2.3"}
+				return r
+			}(),
+			httpChecks: httpChecks{
+				body: maintenanceInfoProvisionRequestBody,
+			},
+			httpReaction: httpReaction{
+				status: http.StatusCreated,
+				body:   successProvisionResponseBody,
+			},
+			expectedResponse: successProvisionResponse(),
+		},
 		{
 			name: "context - 2.11",
 			request: func() *ProvisionRequest {
@@ -259,7 +278,7 @@ func TestProvisionInstance(t *testing.T) {
 
 		klient := newTestClient(t, tc.name, tc.version, tc.enableAlpha, tc.httpChecks, tc.httpReaction)
 
-		response, err := klient.ProvisionInstance(tc.request)
+		response, err := klient.ProvisionInstance(context.Background(), tc.request)
 
 		doResponseChecks(t, tc.name, response, err, tc.expectedResponse, tc.expectedErrMessage, tc.expectedErr)
 	}
@@ -0,0 +1,223 @@
+/*
+Copyright 2024 Klutch Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v2
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+const (
+	// maxRateLimitRetries is how often a request that is answered with 429
+	// Too Many Requests is retried.
+	maxRateLimitRetries = 3
+	// defaultRetryAfter is how long requests are held back after a 429 Too
+	// Many Requests response without valid Retry-After header.
+	defaultRetryAfter = time.Second
+	// maxRetryAfter caps the Retry-After of 429 Too Many Requests responses.
+	maxRetryAfter = time.Minute
+)
+
+// RateLimit limits the requests clients send to a broker.
+type RateLimit struct {
+	// QPS is the sustained number of requests per second. Zero means
+	// unlimited.
+	QPS float64
+	// Burst is the number of requests that may be sent at once. It defaults
+	// to the next integer of QPS.
+	Burst int
+	// MaxInFlight is the number of requests that may be in flight at the same
+	// time, i.e. until their response bodies are closed. Zero means
+	// unlimited.
+	MaxInFlight int
+}
+
+// RateLimiter limits the rate and concurrency of the requests clients send to
+// a broker. It can be shared by any number of clients, e.g. by every client a
+// controller creates for the same broker, so that the limit applies to all of
+// them.
+//
+// Requests wait for the limiter until their context is done. Requests that
+// are answered with 429 Too Many Requests hold back every request of the
+// limiter for the Retry-After announced by the broker and are retried.
+type RateLimiter struct {
+	limit    RateLimit
+	limiter  *rate.Limiter
+	inFlight chan struct{}
+
+	mu          sync.Mutex
+	pausedUntil time.Time
+}
+
+// NewRateLimiter returns a RateLimiter that limits requests to limit.
+func NewRateLimiter(limit RateLimit) *RateLimiter {
+	l := &RateLimiter{limit: limit}
+	if limit.QPS > 0 {
+		burst := limit.Burst
+		if burst < 1 {
+			burst = int(limit.QPS)
+			if float64(burst) < limit.QPS {
+				burst++
+			}
+		}
+		l.limiter = rate.NewLimiter(rate.Limit(limit.QPS), burst)
+	}
+	if limit.MaxInFlight > 0 {
+		l.inFlight = make(chan struct{}, limit.MaxInFlight)
+	}
+	return l
+}
+
+// Limit returns the limit of the RateLimiter.
+func (l *RateLimiter) Limit() RateLimit {
+	return l.limit
+}
+
+// intercept sends the request once the limiter admits it and retries it if
+// it is answered with 429 Too Many Requests.
+func (l *RateLimiter) intercept(invocation Invocation, request *http.Request, next Invoker) (*http.Response, error) {
+	ctx := request.Context()
+	for attempt := 0; ; attempt++ {
+		release, err := l.wait(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("waiting for the rate limit of the broker: %w", err)
+		}
+
+		response, err := next(request)
+		if err != nil {
+			release()
+			return nil, err
+		}
+		response.Body = &releasingBody{ReadCloser: response.Body, release: release}
+		if response.StatusCode != http.StatusTooManyRequests || attempt == maxRateLimitRetries {
+			return response, nil
+		}
+
+		delay := retryAfter(response.Header)
+		l.pause(delay)
+		if deadline, ok := ctx.Deadline(); ok && deadline.Before(time.Now().Add(delay)) {
+			return response, nil
+		}
+		retry, err := replay(request)
+		if err != nil {
+			return response, nil
+		}
+		_ = drainReader(response.Body)
+		response.Body.Close()
+		request = retry
+	}
+}
+
+// wait blocks until the limiter admits a request or ctx is done. The
+// returned function releases the in-flight slot of the request.
+func (l *RateLimiter) wait(ctx context.Context) (func(), error) {
+	if err := l.waitForPause(ctx); err != nil {
+		return nil, err
+	}
+	if l.limiter != nil {
+		if err := l.limiter.Wait(ctx); err != nil {
+			return nil, err
+		}
+	}
+	if l.inFlight == nil {
+		return func() {}, nil
+	}
+	select {
+	case l.inFlight <- struct{}{}:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+	var once sync.Once
+	return func() { once.Do(func() { <-l.inFlight }) }, nil
+}
+
+// pause holds back every request of the limiter for delay.
+func (l *RateLimiter) pause(delay time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if until := time.Now().Add(delay); until.After(l.pausedUntil) {
+		l.pausedUntil = until
+	}
+}
+
+func (l *RateLimiter) waitForPause(ctx context.Context) error {
+	l.mu.Lock()
+	delay := time.Until(l.pausedUntil)
+	l.mu.Unlock()
+	if delay <= 0 {
+		return nil
+	}
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// retryAfter returns the delay announced by the Retry-After header of a 429
+// Too Many Requests response, in seconds or as HTTP date.
+func retryAfter(header http.Header) time.Duration {
+	value := header.Get("Retry-After")
+	delay := defaultRetryAfter
+	if seconds, err := strconv.Atoi(value); err == nil && seconds >= 0 {
+		delay = time.Duration(seconds) * time.Second
+	} else if date, err := http.ParseTime(value); err == nil {
+		delay = max(time.Until(date), 0)
+	}
+	return min(delay, maxRetryAfter)
+}
+
+// replay returns a copy of request whose body can be sent again.
+func replay(request *http.Request) (*http.Request, error) {
+	retry := request.Clone(request.Context())
+	if request.Body == nil || request.Body == http.NoBody {
+		return retry, nil
+	}
+	if request.GetBody == nil {
+		return nil, errors.New("the body of the request cannot be replayed")
+	}
+	body, err := request.GetBody()
+	if err != nil {
+		return nil, err
+	}
+	retry.Body = body
+	return retry, nil
+}
+
+// releasingBody releases the in-flight slot of a request when its response
+// body is closed.
+type releasingBody struct {
+	io.ReadCloser
+	release func()
+}
+
+func (b *releasingBody) Close() error {
+	defer b.release()
+	return b.ReadCloser.Close()
+}
@@ -0,0 +1,174 @@
+/*
+Copyright 2024 Klutch Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v2
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRateLimiterTooManyRequests(t *testing.T) {
+	cases := map[string]struct {
+		// tooManyRequests is the number of requests answered with 429.
+		tooManyRequests int
+		request         func(c *client) error
+		wantRequests    int
+		wantStatus      int
+	}{
+		"request is retried": {
+			tooManyRequests: 1,
+			request:         getCatalog,
+			wantRequests:    2,
+		},
+		"request body is replayed": {
+			tooManyRequests: 2,
+			request:         updateInstance,
+			wantRequests:    3,
+		},
+		"retries are limited": {
+			tooManyRequests: 10,
+			request:         getCatalog,
+			wantRequests:    maxRateLimitRetries + 1,
+			wantStatus:      http.StatusTooManyRequests,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			klient := newTestClient(t, name, Version2_14(), false, httpChecks{}, httpReaction{})
+			klient.interceptors = []Interceptor{NewRateLimiter(RateLimit{}).intercept}
+
+			requests := 0
+			klient.doRequestFunc = func(request *http.Request) (*http.Response, error) {
+				requests++
+				if request.Body != nil {
+					if body, _ := io.ReadAll(request.Body); !strings.Contains(string(body), "plan-id") {
+						t.Errorf("unexpected request body %q", body)
+					}
+				}
+				if requests <= tc.tooManyRequests {
+					return &http.Response{
+						StatusCode: http.StatusTooManyRequests,
+						Header:     http.Header{"Retry-After": []string{"0"}},
+						Body:       io.NopCloser(strings.NewReader("{}")),
+					}, nil
+				}
+				body := okCatalogBytes
+				if request.Method == http.MethodPatch {
+					body = "{}"
+				}
+				return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(strings.NewReader(body))}, nil
+			}
+
+			err := tc.request(klient)
+			status := 0
+			statusErr := HTTPStatusCodeError{}
+			if errors.As(err, &statusErr) {
+				status = statusErr.StatusCode
+			} else if err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+			if status != tc.wantStatus {
+				t.Errorf("want status %d, got %d", tc.wantStatus, status)
+			}
+			if requests != tc.wantRequests {
+				t.Errorf("want %d requests, got %d", tc.wantRequests, requests)
+			}
+		})
+	}
+}
+
+func TestRateLimiterMaxInFlight(t *testing.T) {
+	limiter := NewRateLimiter(RateLimit{MaxInFlight: 1})
+	next := func(request *http.Request) (*http.Response, error) {
+		return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(strings.NewReader(""))}, nil
+	}
+
+	first, err := limiter.intercept(Invocation{}, newRateLimitTestRequest(t, context.Background()), next)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, err := limiter.intercept(Invocation{}, newRateLimitTestRequest(t, ctx), next); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("want queued request to be canceled, got %v", err)
+	}
+
+	first.Body.Close()
+	second, err := limiter.intercept(Invocation{}, newRateLimitTestRequest(t, context.Background()), next)
+	if err != nil {
+		t.Fatalf("unexpected error after the first request completed: %v", err)
+	}
+	second.Body.Close()
+}
+
+func TestRateLimiterQPS(t *testing.T) {
+	limiter := NewRateLimiter(RateLimit{QPS: 0.1})
+	next := func(request *http.Request) (*http.Response, error) {
+		return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(strings.NewReader(""))}, nil
+	}
+
+	if _, err := limiter.intercept(Invocation{}, newRateLimitTestRequest(t, context.Background()), next); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if _, err := limiter.intercept(Invocation{}, newRateLimitTestRequest(t, ctx), next); err == nil {
+		t.Error("want request above the rate limit to fail before its deadline")
+	}
+}
+
+func TestRetryAfter(t *testing.T) {
+	cases := map[string]struct {
+		value string
+		want  time.Duration
+	}{
+		"Seconds":   {value: "3", want: 3 * time.Second},
+		"Missing":   {want: defaultRetryAfter},
+		"Invalid":   {value: "soon", want: defaultRetryAfter},
+		"Capped":    {value: "3600", want: maxRetryAfter},
+		"PastDate":  {value: "Mon, 02 Jan 2006 15:04:05 GMT", want: 0},
+		"ZeroDelay": {value: "0", want: 0},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			header := http.Header{}
+			if tc.value != "" {
+				header.Set("Retry-After", tc.value)
+			}
+			if got := retryAfter(header); got != tc.want {
+				t.Errorf("retryAfter(%q): want %v, got %v", tc.value, tc.want, got)
+			}
+		})
+	}
+}
+
+func newRateLimitTestRequest(t *testing.T, ctx context.Context) *http.Request {
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, "https://example.com/v2/catalog", nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	return request
+}
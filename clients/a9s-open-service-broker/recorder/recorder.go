@@ -0,0 +1,279 @@
+/*
+Copyright 2024 Klutch Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package recorder records the requests an Open Service Broker client sends
+// and the responses it receives to a cassette file, and replays them later
+// without a broker. Credentials are redacted with v2.RedactHeader and
+// v2.RedactBody before they are recorded, so cassettes captured against a live
+// a9s environment can be checked in as test data.
+//
+// A Recorder is installed with v2.ClientConfiguration.WrapTransport:
+//
+//	rec, err := recorder.New("testdata/failed-update.json", recorder.ModeReplay)
+//	...
+//	config.WrapTransport = rec.Transport
+package recorder
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+
+	v2 "github.com/anynines/klutchio/clients/a9s-open-service-broker"
+)
+
+// Redacted replaces every recorded credential.
+const Redacted = v2.Redacted
+
+// Mode is the mode a Recorder operates in.
+type Mode int
+
+const (
+	// ModeRecord sends requests to the broker and records them along with
+	// their responses.
+	ModeRecord Mode = iota
+	// ModeReplay answers requests with the responses of a cassette, without
+	// sending them to the broker.
+	ModeReplay
+)
+
+// Cassette is a sequence of recorded interactions with a broker.
+type Cassette struct {
+	Interactions []Interaction `json:"interactions"`
+}
+
+// Interaction is a request and either the response to it or the error
+// sending it failed with.
+type Interaction struct {
+	Request  Request   `json:"request"`
+	Response *Response `json:"response,omitempty"`
+	Error    string    `json:"error,omitempty"`
+}
+
+// Request is a recorded request. URL only contains the path and query of the
+// request, so that a cassette can be replayed against any broker URL.
+type Request struct {
+	Method string      `json:"method"`
+	URL    string      `json:"url"`
+	Header http.Header `json:"header,omitempty"`
+	Body   string      `json:"body,omitempty"`
+}
+
+// Response is a recorded response.
+type Response struct {
+	StatusCode int         `json:"statusCode"`
+	Header     http.Header `json:"header,omitempty"`
+	Body       string      `json:"body,omitempty"`
+}
+
+// Load reads a cassette from the file at path.
+func Load(path string) (*Cassette, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	cassette := &Cassette{}
+	if err := json.Unmarshal(data, cassette); err != nil {
+		return nil, fmt.Errorf("cannot decode cassette %s: %w", path, err)
+	}
+	return cassette, nil
+}
+
+// Save writes the cassette to the file at path.
+func (c *Cassette) Save(path string) error {
+	data, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0o600)
+}
+
+// Recorder records or replays the interactions of a cassette. It is safe for
+// concurrent use.
+type Recorder struct {
+	path string
+	mode Mode
+
+	mu       sync.Mutex
+	cassette *Cassette
+	played   []bool
+}
+
+// New returns a Recorder for the cassette file at path. In ModeReplay the
+// cassette is loaded from path, in ModeRecord it is written to path by Save.
+func New(path string, mode Mode) (*Recorder, error) {
+	r := &Recorder{path: path, mode: mode, cassette: &Cassette{}}
+	if mode == ModeReplay {
+		cassette, err := Load(path)
+		if err != nil {
+			return nil, err
+		}
+		r.cassette = cassette
+		r.played = make([]bool, len(cassette.Interactions))
+	}
+	return r, nil
+}
+
+// Save writes the interactions recorded so far to the cassette file. It does
+// nothing in ModeReplay.
+func (r *Recorder) Save() error {
+	if r.mode == ModeReplay {
+		return nil
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.cassette.Save(r.path)
+}
+
+// Unplayed returns the number of interactions of the cassette that have not
+// been replayed yet.
+func (r *Recorder) Unplayed() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	unplayed := 0
+	for _, played := range r.played {
+		if !played {
+			unplayed++
+		}
+	}
+	return unplayed
+}
+
+// Transport returns an http.RoundTripper that records the requests sent
+// through next, or replays them from the cassette without using next. It is
+// suitable for v2.ClientConfiguration.WrapTransport.
+func (r *Recorder) Transport(next http.RoundTripper) http.RoundTripper {
+	if r.mode == ModeReplay {
+		return &replayTransport{recorder: r}
+	}
+	return &recordTransport{recorder: r, next: next}
+}
+
+type recordTransport struct {
+	recorder *Recorder
+	next     http.RoundTripper
+}
+
+func (t *recordTransport) RoundTrip(request *http.Request) (*http.Response, error) {
+	var requestBody []byte
+	if request.Body != nil {
+		var err error
+		requestBody, err = io.ReadAll(request.Body)
+		request.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		request = request.Clone(request.Context())
+		request.Body = io.NopCloser(bytes.NewReader(requestBody))
+	}
+
+	interaction := Interaction{
+		Request: Request{
+			Method: request.Method,
+			URL:    request.URL.RequestURI(),
+			Header: v2.RedactHeader(request.Header),
+			Body:   v2.RedactBody(request.Header.Get("Content-Type"), requestBody),
+		},
+	}
+
+	response, err := t.next.RoundTrip(request)
+	if err != nil {
+		interaction.Error = err.Error()
+		t.recorder.record(interaction)
+		return nil, err
+	}
+
+	responseBody, err := io.ReadAll(response.Body)
+	response.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	response.Body = io.NopCloser(bytes.NewReader(responseBody))
+
+	interaction.Response = &Response{
+		StatusCode: response.StatusCode,
+		Header:     v2.RedactHeader(response.Header),
+		Body:       v2.RedactBody(response.Header.Get("Content-Type"), responseBody),
+	}
+	// The length of a redacted body differs from the length of the body that
+	// was received.
+	interaction.Response.Header.Del("Content-Length")
+	t.recorder.record(interaction)
+
+	return response, nil
+}
+
+func (r *Recorder) record(interaction Interaction) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.cassette.Interactions = append(r.cassette.Interactions, interaction)
+}
+
+type replayTransport struct {
+	recorder *Recorder
+}
+
+// RoundTrip answers the request with the first interaction of the cassette
+// that has the same method and URL and has not been replayed yet. Requests are
+// matched in the order they were recorded, so repeated requests, such as
+// polls of the last operation, replay the responses in the recorded order.
+func (t *replayTransport) RoundTrip(request *http.Request) (*http.Response, error) {
+	if request.Body != nil {
+		request.Body.Close()
+	}
+
+	interaction, ok := t.recorder.next(request.Method, request.URL.RequestURI())
+	if !ok {
+		return nil, fmt.Errorf("no unplayed interaction for %s %s in cassette %s", request.Method, request.URL.RequestURI(), t.recorder.path)
+	}
+	if interaction.Response == nil {
+		return nil, errors.New(interaction.Error)
+	}
+
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", interaction.Response.StatusCode, http.StatusText(interaction.Response.StatusCode)),
+		StatusCode:    interaction.Response.StatusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        interaction.Response.Header.Clone(),
+		Body:          io.NopCloser(strings.NewReader(interaction.Response.Body)),
+		ContentLength: int64(len(interaction.Response.Body)),
+		Request:       request,
+	}, nil
+}
+
+func (r *Recorder) next(method, uri string) (Interaction, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for i, interaction := range r.cassette.Interactions {
+		if r.played[i] || interaction.Request.Method != method || interaction.Request.URL != uri {
+			continue
+		}
+		r.played[i] = true
+		return interaction, true
+	}
+	return Interaction{}, false
+}
@@ -0,0 +1,223 @@
+/*
+Copyright 2024 Klutch Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package recorder_test
+
+import (
+	"context"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	v2 "github.com/anynines/klutchio/clients/a9s-open-service-broker"
+	"github.com/anynines/klutchio/clients/a9s-open-service-broker/osbtest"
+	"github.com/anynines/klutchio/clients/a9s-open-service-broker/recorder"
+)
+
+const (
+	testInstanceID = "5b4c1e2a-8d2f-4a4f-9a4e-0b6f2b9c7d11"
+	testBindingID  = "9e0f8b6c-3c1d-4f0a-b7a9-2d5e6f7a8b90"
+)
+
+type results struct {
+	catalog  *v2.CatalogResponse
+	instance *v2.GetServiceInstanceResponse
+	bind     *v2.BindResponse
+	polls    []*v2.LastOperationResponse
+}
+
+// lifecycle asynchronously provisions an instance of the first plan of the
+// catalog, polls the last operation until it succeeded and binds the instance.
+func lifecycle(t *testing.T, klient v2.Client) results {
+	t.Helper()
+	ctx := context.Background()
+
+	var r results
+	var err error
+	if r.catalog, err = klient.GetCatalog(ctx); err != nil {
+		t.Fatalf("GetCatalog: unexpected error: %v", err)
+	}
+	service := r.catalog.Services[0]
+	provision, err := klient.ProvisionInstance(ctx, &v2.ProvisionRequest{
+		InstanceID:        testInstanceID,
+		AcceptsIncomplete: true,
+		ServiceID:         service.ID,
+		PlanID:            service.Plans[0].ID,
+		OrganizationGUID:  "organization",
+		SpaceGUID:         "space",
+	})
+	if err != nil {
+		t.Fatalf("ProvisionInstance: unexpected error: %v", err)
+	}
+	for {
+		poll, err := klient.PollLastOperation(ctx, &v2.LastOperationRequest{
+			InstanceID:   testInstanceID,
+			OperationKey: provision.OperationKey,
+		})
+		if err != nil {
+			t.Fatalf("PollLastOperation: unexpected error: %v", err)
+		}
+		r.polls = append(r.polls, poll)
+		if poll.State != v2.StateInProgress {
+			break
+		}
+	}
+	if r.instance, err = klient.GetServiceInstance(ctx, &v2.GetInstanceRequest{InstanceID: testInstanceID}); err != nil {
+		t.Fatalf("GetServiceInstance: unexpected error: %v", err)
+	}
+	if r.bind, err = klient.Bind(ctx, &v2.BindRequest{
+		BindingID:  testBindingID,
+		InstanceID: testInstanceID,
+		ServiceID:  service.ID,
+		PlanID:     service.Plans[0].ID,
+	}); err != nil {
+		t.Fatalf("Bind: unexpected error: %v", err)
+	}
+	return r
+}
+
+func TestRecordAndReplay(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cassette.json")
+
+	b := osbtest.NewBroker(osbtest.Options{Username: "admin", Password: "hunter2", Async: true, PollsUntilDone: 1})
+	defer b.Close()
+
+	rec, err := recorder.New(path, recorder.ModeRecord)
+	if err != nil {
+		t.Fatalf("New: unexpected error: %v", err)
+	}
+	config := b.ClientConfiguration()
+	config.WrapTransport = rec.Transport
+	klient, err := v2.NewClient(config)
+	if err != nil {
+		t.Fatalf("cannot create client: %v", err)
+	}
+	recorded := lifecycle(t, klient)
+	if err := rec.Save(); err != nil {
+		t.Fatalf("Save: unexpected error: %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("cannot read cassette: %v", err)
+	}
+	for _, secret := range []string{
+		"hunter2",
+		"YWRtaW46aHVudGVyMg==", // base64 of admin:hunter2
+		recorded.bind.Credentials["password"].(string),
+	} {
+		if strings.Contains(string(data), secret) {
+			t.Errorf("expected cassette not to contain %q", secret)
+		}
+	}
+
+	cassette, err := recorder.Load(path)
+	if err != nil {
+		t.Fatalf("Load: unexpected error: %v", err)
+	}
+	if got := cassette.Interactions[0].Request.Header.Get("Authorization"); got != recorder.Redacted {
+		t.Errorf("expected Authorization header %q, got %q", recorder.Redacted, got)
+	}
+
+	// Replay against a broker that does not exist. Every request has to be
+	// answered from the cassette.
+	rep, err := recorder.New(path, recorder.ModeReplay)
+	if err != nil {
+		t.Fatalf("New: unexpected error: %v", err)
+	}
+	config.URL = "http://broker.invalid"
+	config.WrapTransport = rep.Transport
+	klient, err = v2.NewClient(config)
+	if err != nil {
+		t.Fatalf("cannot create client: %v", err)
+	}
+	replayed := lifecycle(t, klient)
+
+	if diff := cmp.Diff(recorded.catalog, replayed.catalog); diff != "" {
+		t.Errorf("GetCatalog: -recorded, +replayed:\n%s", diff)
+	}
+	if diff := cmp.Diff(recorded.instance, replayed.instance); diff != "" {
+		t.Errorf("GetServiceInstance: -recorded, +replayed:\n%s", diff)
+	}
+	if diff := cmp.Diff(recorded.polls, replayed.polls); diff != "" {
+		t.Errorf("PollLastOperation: -recorded, +replayed:\n%s", diff)
+	}
+	if got := replayed.bind.Credentials["password"]; got != recorder.Redacted {
+		t.Errorf("expected replayed password %q, got %v", recorder.Redacted, got)
+	}
+	if got := replayed.bind.Credentials["host"]; got != recorder.Redacted {
+		t.Errorf("expected replayed host %q, got %v", recorder.Redacted, got)
+	}
+	if n := rep.Unplayed(); n != 0 {
+		t.Errorf("expected all interactions to be replayed, %d are left", n)
+	}
+
+	// The cassette is exhausted, further requests fail.
+	if _, err := klient.GetServiceInstance(context.Background(), &v2.GetInstanceRequest{InstanceID: testInstanceID}); err == nil {
+		t.Errorf("expected error for request without recorded interaction")
+	}
+}
+
+func TestReplayOrder(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cassette.json")
+	lastOperation := "/v2/service_instances/" + testInstanceID + "/last_operation"
+	cassette := &recorder.Cassette{Interactions: []recorder.Interaction{
+		{
+			Request:  recorder.Request{Method: http.MethodGet, URL: lastOperation},
+			Response: &recorder.Response{StatusCode: http.StatusOK, Body: `{"state":"in progress"}`},
+		},
+		{
+			Request: recorder.Request{Method: http.MethodGet, URL: lastOperation},
+			Error:   "connection reset by peer",
+		},
+		{
+			Request:  recorder.Request{Method: http.MethodGet, URL: lastOperation},
+			Response: &recorder.Response{StatusCode: http.StatusOK, Body: `{"state":"failed","description":"disk full"}`},
+		},
+	}}
+	if err := cassette.Save(path); err != nil {
+		t.Fatalf("Save: unexpected error: %v", err)
+	}
+
+	rep, err := recorder.New(path, recorder.ModeReplay)
+	if err != nil {
+		t.Fatalf("New: unexpected error: %v", err)
+	}
+	config := v2.DefaultClientConfiguration()
+	config.URL = "http://broker.invalid"
+	config.WrapTransport = rep.Transport
+	klient, err := v2.NewClient(config)
+	if err != nil {
+		t.Fatalf("cannot create client: %v", err)
+	}
+
+	request := &v2.LastOperationRequest{InstanceID: testInstanceID}
+	response, err := klient.PollLastOperation(context.Background(), request)
+	if err != nil || response.State != v2.StateInProgress {
+		t.Errorf("expected state %q, got %+v (error %v)", v2.StateInProgress, response, err)
+	}
+	if _, err := klient.PollLastOperation(context.Background(), request); err == nil || !strings.Contains(err.Error(), "connection reset by peer") {
+		t.Errorf("expected recorded error, got %v", err)
+	}
+	response, err = klient.PollLastOperation(context.Background(), request)
+	if err != nil || response.State != v2.StateFailed {
+		t.Errorf("expected state %q, got %+v (error %v)", v2.StateFailed, response, err)
+	}
+}
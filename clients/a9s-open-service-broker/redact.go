@@ -0,0 +1,133 @@
+/*
+Copyright 2024 Klutch Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v2
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// Redacted replaces every credential the client logs.
+const Redacted = "REDACTED"
+
+// redactedHeaders are the headers whose values are redacted.
+var redactedHeaders = []string{"Authorization", "Proxy-Authorization", "Cookie", "Set-Cookie"}
+
+// redactedFields are the fields of JSON and form encoded bodies whose values
+// are redacted. The values of objects, such as the credentials of a binding,
+// are redacted recursively so that their structure is kept.
+var redactedFields = map[string]bool{
+	"credentials":    true,
+	"password":       true,
+	"encryption_key": true,
+	"client_secret":  true,
+	"access_token":   true,
+	"refresh_token":  true,
+	"id_token":       true,
+}
+
+// RedactHeader returns a copy of header with the values of the Authorization,
+// Proxy-Authorization, Cookie and Set-Cookie headers replaced by Redacted.
+func RedactHeader(header http.Header) http.Header {
+	if len(header) == 0 {
+		return nil
+	}
+	redacted := header.Clone()
+	for _, name := range redactedHeaders {
+		if values := redacted.Values(name); len(values) > 0 {
+			redacted.Set(name, Redacted)
+		}
+	}
+	return redacted
+}
+
+// RedactBody returns body with the values of credentials, passwords,
+// encryption keys and OAuth2 secrets and tokens replaced by Redacted. JSON and
+// form encoded bodies are redacted, other bodies are returned as they are.
+func RedactBody(contentType string, body []byte) string {
+	if len(body) == 0 {
+		return ""
+	}
+
+	if strings.HasPrefix(contentType, "application/x-www-form-urlencoded") {
+		values, err := url.ParseQuery(string(body))
+		if err != nil {
+			return string(body)
+		}
+		for key := range values {
+			if redactedFields[key] {
+				values.Set(key, Redacted)
+			}
+		}
+		return values.Encode()
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.UseNumber()
+	var value interface{}
+	if err := decoder.Decode(&value); err != nil {
+		return string(body)
+	}
+	redacted, err := json.Marshal(redactFields(value))
+	if err != nil {
+		return string(body)
+	}
+	return string(redacted)
+}
+
+// redactFields redacts the values of redactedFields in a decoded JSON value.
+func redactFields(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, field := range v {
+			if redactedFields[key] {
+				v[key] = redactAll(field)
+			} else {
+				v[key] = redactFields(field)
+			}
+		}
+	case []interface{}:
+		for i := range v {
+			v[i] = redactFields(v[i])
+		}
+	}
+	return value
+}
+
+// redactAll redacts every value of a decoded JSON value, keeping the structure
+// of objects and arrays.
+func redactAll(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key := range v {
+			v[key] = redactAll(v[key])
+		}
+		return v
+	case []interface{}:
+		for i := range v {
+			v[i] = redactAll(v[i])
+		}
+		return v
+	case nil:
+		return nil
+	default:
+		return Redacted
+	}
+}
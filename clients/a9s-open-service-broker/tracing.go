@@ -0,0 +1,90 @@
+/*
+Copyright 2024 Klutch Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v2
+
+import (
+	"context"
+	"net/http"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// tracerName is the name of the tracer that creates the spans of the client.
+const tracerName = "github.com/anynines/klutchio/clients/a9s-open-service-broker"
+
+// Attributes set on the spans of requests to the broker.
+const (
+	attributeOperation    = attribute.Key("osb.operation")
+	attributeOperationKey = attribute.Key("osb.operation_key")
+	attributeInstanceID   = attribute.Key("osb.instance_id")
+	attributeBindingID    = attribute.Key("osb.binding_id")
+)
+
+// startSpan starts a client span for the given operation, which is called
+// with request. The returned context carries the span and the invocation, so
+// that prepareAndDo adds the outcome of the request to the span, the metrics
+// are labeled with the operation and the interceptors are called with the
+// invocation. The caller must end the span.
+func (c *client) startSpan(ctx context.Context, operation string, request interface{}, attributes ...attribute.KeyValue) (context.Context, trace.Span) {
+	tracerProvider := c.tracerProvider
+	if tracerProvider == nil {
+		tracerProvider = otel.GetTracerProvider()
+	}
+
+	attributes = append(attributes, attributeOperation.String(operation))
+	return tracerProvider.Tracer(tracerName).Start(withInvocation(ctx, Invocation{Operation: operation, Request: request}), "osb."+operation,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(attributes...),
+	)
+}
+
+// traceRequest adds the method, URL and operation key of the request to the
+// span of its context and propagates the span to the broker in the request
+// headers, using the globally configured propagator.
+func traceRequest(request *http.Request) {
+	span := trace.SpanFromContext(request.Context())
+	span.SetAttributes(
+		semconv.HTTPRequestMethodKey.String(request.Method),
+		semconv.URLFull(request.URL.String()),
+	)
+	if operationKey := request.URL.Query().Get(VarKeyOperation); operationKey != "" {
+		span.SetAttributes(attributeOperationKey.String(operationKey))
+	}
+
+	otel.GetTextMapPropagator().Inject(request.Context(), propagation.HeaderCarrier(request.Header))
+}
+
+// recordResponse records the outcome of a request on the span of its
+// context. Transport errors and error status codes mark the span as failed.
+func recordResponse(request *http.Request, response *http.Response, err error) {
+	span := trace.SpanFromContext(request.Context())
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return
+	}
+
+	span.SetAttributes(semconv.HTTPResponseStatusCode(response.StatusCode))
+	if response.StatusCode >= http.StatusBadRequest {
+		span.SetStatus(codes.Error, http.StatusText(response.StatusCode))
+	}
+}
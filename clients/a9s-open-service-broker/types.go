@@ -127,16 +127,20 @@ type Plan struct {
 	// MaximumPollingDuration is a duration, in seconds, that the should
 	// be used as the Service's maximum polling duration.
 	MaximumPollingDuration *int64 `json:"maximum_polling_duration,omitempty"`
-	// MaintenanceInfo requires alpha features flag to be enabled.
-	//
 	// MaintenanceInfo represents maintenance information for a Service
 	// Instance which is provisioned using the Service Plan. Optional;
 	// defaults to unset
 	MaintenanceInfo *MaintenanceInfo `json:"maintenance_info,omitempty"`
 }
 
+// MaintenanceInfo describes the version of the software a Service Instance of
+// a Service Plan is deployed with. An instance whose MaintenanceInfo version
+// differs from the one of its plan in the catalog can be upgraded by
+// sending the plan's MaintenanceInfo in an update request.
 type MaintenanceInfo struct {
-	Version     string `json:"version"`
+	// Version is a semantic version of the maintenance info.
+	Version string `json:"version"`
+	// Description is a human readable summary of the changes of the version.
 	Description string `json:"description,omitempty"`
 }
 
@@ -233,6 +237,10 @@ type ProvisionRequest struct {
 	// Context is platform-specific contextual information under which the
 	// service instance is to be provisioned.
 	Context map[string]interface{} `json:"context,omitempty"`
+	// MaintenanceInfo is the maintenance info of the plan in the catalog the
+	// client provisions the instance for. The broker rejects the request if
+	// it does not match the plan. Optional.
+	MaintenanceInfo *MaintenanceInfo `json:"maintenance_info,omitempty"`
 	// OriginatingIdentity requires a client API version >= 2.13.
 	//
 	// OriginatingIdentity is the identity on the platform of the user making
@@ -286,6 +294,11 @@ type UpdateInstanceRequest struct {
 	// Context is platform-specific contextual information under which the
 	// service instance was created.
 	Context map[string]interface{} `json:"context,omitempty"`
+	// MaintenanceInfo is the maintenance info of the plan in the catalog to
+	// upgrade the instance to. If unset, or equal to the maintenance info the
+	// instance already has, indicates that the client does not wish to
+	// upgrade the instance.
+	MaintenanceInfo *MaintenanceInfo `json:"maintenance_info,omitempty"`
 	// OriginatingIdentity requires a client API version >= 2.13.
 	//
 	// OriginatingIdentity is the identity on the platform of the user making
@@ -298,6 +311,8 @@ type PreviousValues struct {
 	// ID of the plan prior to the update. If present, MUST be a non-empty
 	// string.
 	PlanID string `json:"plan_id,omitempty"`
+	// Maintenance info of the service instance prior to the update.
+	MaintenanceInfo *MaintenanceInfo `json:"maintenance_info,omitempty"`
 	// Deprecated; determined to be unnecessary as the value is immutable. ID of
 	// the service for the service instance. If present, MUST be a non-empty
 	// string.
@@ -380,12 +395,13 @@ type GetInstanceResponse struct {
 }
 
 type GetServiceInstanceResponse struct {
-	ID           string                 `json:"id"`
-	PlanGUID     string                 `json:"plan_id"`
-	ServiceGUID  string                 `json:"service_id"`
-	DashboardURL string                 `json:"dashboard_url"`
-	Parameters   map[string]interface{} `json:"parameters"`
-	Context      Context                `json:"context"`
+	ID              string                 `json:"id"`
+	PlanGUID        string                 `json:"plan_id"`
+	ServiceGUID     string                 `json:"service_id"`
+	DashboardURL    string                 `json:"dashboard_url"`
+	Parameters      map[string]interface{} `json:"parameters"`
+	Context         Context                `json:"context"`
+	MaintenanceInfo *MaintenanceInfo       `json:"maintenance_info,omitempty"`
 }
 
 // GetInstancesResponse is sent as the response to doing a GET on the /instances endpoint
@@ -477,9 +493,10 @@ type LastOperationResponse struct {
 	// Description is a message from the broker describing the current state
 	// of the operation.
 	Description *string `json:"description,omitempty"`
-	// PollDelay is the time interval that may be returned by a broker using
-	// API >= 1.15 indicating how long the client should wait before retrying
-	// polling for the operation result again.
+	// PollDelay is the time interval that may be returned by a broker in the
+	// Retry-After header (API >= 2.15) indicating how long the client should
+	// wait before retrying polling for the operation result again. It is nil
+	// if the broker did not suggest a delay.
 	PollDelay *time.Duration `json:"-"`
 }
 
@@ -531,6 +548,22 @@ type BindRequest struct {
 	// OriginatingIdentity is the identity on the platform of the user making
 	// this request.
 	OriginatingIdentity *OriginatingIdentity `json:"originatingIdentity,omitempty"`
+	// PredecessorBindingID requires a client API version >= 2.17.
+	//
+	// PredecessorBindingID is the ID of the binding the new binding rotates.
+	// The broker creates the new binding with fresh credentials for the same
+	// purpose as the predecessor, which stays usable until it is unbound.
+	PredecessorBindingID *string `json:"predecessor_binding_id,omitempty"`
+}
+
+// BindingMetadata contains the lifetime of the credentials of a binding.
+type BindingMetadata struct {
+	// ExpiresAt is the time, in ISO 8601 format, at which the credentials of
+	// the binding expire. Empty if they do not expire.
+	ExpiresAt string `json:"expires_at,omitempty"`
+	// RenewBefore is the time, in ISO 8601 format, before which the binding
+	// should be rotated. Empty if the broker did not suggest a time.
+	RenewBefore string `json:"renew_before,omitempty"`
 }
 
 // BindResource contains data for platform resources associated with a
@@ -588,6 +621,10 @@ type BindResponse struct {
 	// The network endpoints that the Application uses to connect to the
 	// Service Instance.
 	Endpoints *[]Endpoint `json:"endpoints,omitempty"`
+	// Metadata requires a client API version >= 2.17.
+	//
+	// Metadata is the lifetime of the credentials of the binding.
+	Metadata *BindingMetadata `json:"metadata,omitempty"`
 }
 
 // UnbindRequest represents a request to unbind a particular binding.
@@ -662,6 +699,10 @@ type GetBindingResponse struct {
 	// The network endpoints that the Application uses to connect to the
 	// Service Instance.
 	Endpoints *[]Endpoint `json:"endpoints,omitempty"`
+	// Metadata requires a client API version >= 2.17.
+	//
+	// Metadata is the lifetime of the credentials of the binding.
+	Metadata *BindingMetadata `json:"metadata,omitempty"`
 }
 
 type GetOperationRequest struct {
@@ -672,6 +713,10 @@ type GetOperationRequest struct {
 
 type GetOperationResponse struct {
 	State string `json:"state"`
+	// PollDelay is the time interval the broker suggested, via the
+	// Retry-After header, to wait before polling the operation again. It is
+	// nil if the broker did not suggest a delay.
+	PollDelay *time.Duration `json:"-"`
 }
 
 func (r *GetOperationResponse) IsDone() bool {
@@ -17,17 +17,16 @@ limitations under the License.
 package v2
 
 import (
+	"context"
 	"fmt"
 	"net/http"
-
-	"k8s.io/klog/v2"
 )
 
 type unbindSuccessResponseBody struct {
 	Operation *string `json:"operation"`
 }
 
-func (c *client) Unbind(r *UnbindRequest) (*UnbindResponse, error) {
+func (c *client) Unbind(ctx context.Context, r *UnbindRequest) (*UnbindResponse, error) {
 	if r.AcceptsIncomplete {
 		if err := c.validateClientVersionIsAtLeast(Version2_14()); err != nil {
 			return nil, AsyncBindingOperationsNotAllowedError{
@@ -48,7 +47,10 @@ func (c *client) Unbind(r *UnbindRequest) (*UnbindResponse, error) {
 		params[AcceptsIncomplete] = "true"
 	}
 
-	response, err := c.prepareAndDo(http.MethodDelete, fullURL, params, nil, r.OriginatingIdentity)
+	ctx, span := c.startSpan(ctx, operationUnbind, r, attributeInstanceID.String(r.InstanceID), attributeBindingID.String(r.BindingID))
+	defer span.End()
+
+	response, err := c.prepareAndDo(ctx, http.MethodDelete, fullURL, params, nil, r.OriginatingIdentity)
 	if err != nil {
 		return nil, err
 	}
@@ -88,7 +90,7 @@ func (c *client) Unbind(r *UnbindRequest) (*UnbindResponse, error) {
 		}
 		if response.StatusCode == http.StatusAccepted {
 			if c.Verbose {
-				klog.Infof("broker %q: received asynchronous response", c.Name)
+				c.logger.Info("Received asynchronous response")
 			}
 			userResponse.Async = true
 		}
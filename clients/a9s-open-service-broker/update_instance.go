@@ -17,6 +17,7 @@ limitations under the License.
 package v2
 
 import (
+	"context"
 	"fmt"
 	"net/http"
 )
@@ -24,11 +25,12 @@ import (
 // internal message body types
 
 type updateInstanceRequestBody struct {
-	ServiceID      string                 `json:"service_id"`
-	PlanID         *string                `json:"plan_id,omitempty"`
-	Parameters     map[string]interface{} `json:"parameters,omitempty"`
-	Context        map[string]interface{} `json:"context,omitempty"`
-	PreviousValues *PreviousValues        `json:"previous_values,omitempty"`
+	ServiceID       string                 `json:"service_id"`
+	PlanID          *string                `json:"plan_id,omitempty"`
+	Parameters      map[string]interface{} `json:"parameters,omitempty"`
+	Context         map[string]interface{} `json:"context,omitempty"`
+	MaintenanceInfo *MaintenanceInfo       `json:"maintenance_info,omitempty"`
+	PreviousValues  *PreviousValues        `json:"previous_values,omitempty"`
 }
 
 type updateInstanceResponseBody struct {
@@ -36,7 +38,7 @@ type updateInstanceResponseBody struct {
 	Operation    *string `json:"operation"`
 }
 
-func (c *client) UpdateInstance(r *UpdateInstanceRequest) (*UpdateInstanceResponse, error) {
+func (c *client) UpdateInstance(ctx context.Context, r *UpdateInstanceRequest) (*UpdateInstanceResponse, error) {
 	if err := validateUpdateInstanceRequest(r); err != nil {
 		return nil, err
 	}
@@ -48,17 +50,21 @@ func (c *client) UpdateInstance(r *UpdateInstanceRequest) (*UpdateInstanceRespon
 	}
 
 	requestBody := &updateInstanceRequestBody{
-		ServiceID:      r.ServiceID,
-		PlanID:         r.PlanID,
-		Parameters:     r.Parameters,
-		PreviousValues: r.PreviousValues,
+		ServiceID:       r.ServiceID,
+		PlanID:          r.PlanID,
+		Parameters:      r.Parameters,
+		MaintenanceInfo: r.MaintenanceInfo,
+		PreviousValues:  r.PreviousValues,
 	}
 
-	if c.APIVersion.AtLeast(Version2_12()) {
+	if c.Version().AtLeast(Version2_12()) {
 		requestBody.Context = r.Context
 	}
 
-	response, err := c.prepareAndDo(http.MethodPatch, fullURL, params, requestBody, r.OriginatingIdentity)
+	ctx, span := c.startSpan(ctx, operationUpdateInstance, r, attributeInstanceID.String(r.InstanceID))
+	defer span.End()
+
+	response, err := c.prepareAndDo(ctx, http.MethodPatch, fullURL, params, requestBody, r.OriginatingIdentity)
 	if err != nil {
 		return nil, err
 	}
@@ -79,9 +85,12 @@ func (c *client) UpdateInstance(r *UpdateInstanceRequest) (*UpdateInstanceRespon
 			Async:        false,
 			OperationKey: nil,
 		}
-		if c.APIVersion.AtLeast(Version2_14()) {
+		if c.Version().AtLeast(Version2_14()) {
 			userResponse.DashboardURL = responseBodyObj.DashboardURL
 		}
+		if err := c.validateResponse(ctx, updateInstanceViolations(userResponse)); err != nil {
+			return nil, err
+		}
 
 		return userResponse, nil
 	case http.StatusAccepted:
@@ -107,9 +116,12 @@ func (c *client) UpdateInstance(r *UpdateInstanceRequest) (*UpdateInstanceRespon
 			Async:        true,
 			OperationKey: opPtr,
 		}
-		if c.APIVersion.AtLeast(Version2_14()) {
+		if c.Version().AtLeast(Version2_14()) {
 			userResponse.DashboardURL = responseBodyObj.DashboardURL
 		}
+		if err := c.validateResponse(ctx, updateInstanceViolations(userResponse)); err != nil {
+			return nil, err
+		}
 
 		// TODO: fix op key handling
 
@@ -17,6 +17,7 @@ limitations under the License.
 package v2
 
 import (
+	"context"
 	"fmt"
 	"net/http"
 	"testing"
@@ -78,6 +79,8 @@ const contextUpdateInstanceRequestBody = `{"service_id":"test-service-id","plan_
 
 const previousValuesUpdateInstanceRequestBody = `{"service_id":"test-service-id","plan_id":"test-plan-id","previous_values":{"plan_id":"previous-plan-id"}}`
 
+const maintenanceInfoUpdateInstanceRequestBody = `{"service_id":"test-service-id","plan_id":"test-plan-id","maintenance_info":{"version":"1.2.4","description":"security fixes"},"previous_values":{"plan_id":"test-plan-id","maintenance_info":{"version":"1.2.3"}}}`
+
 func TestUpdateInstanceInstance(t *testing.T) {
 	cases := []struct {
 		name                string
@@ -230,6 +233,29 @@ func TestUpdateInstanceInstance(t *testing.T) {
 			},
 			expectedResponse: successUpdateInstanceResponse(),
 		},
+		{
+			name: "maintenance info",
+			request: func() *UpdateInstanceRequest {
+				r := defaultUpdateInstanceRequest()
+				r.MaintenanceInfo = &MaintenanceInfo{
+					Version:     "1.2.4",
+					Description: "security fixes",
+				}
+				r.PreviousValues = &PreviousValues{
+					PlanID:          "test-plan-id",
+					MaintenanceInfo: &MaintenanceInfo{Version: "1.2.3"},
+				}
+				return r
+			}(),
+			httpChecks: httpChecks{
+				body: maintenanceInfoUpdateInstanceRequestBody,
+			},
+			httpReaction: httpReaction{
+				status: http.StatusOK,
+				body:   successUpdateInstanceResponseBody,
+			},
+			expectedResponse: successUpdateInstanceResponse(),
+		},
 		{
 			name:                "originating identity included",
 			version:             Version2_13(),
@@ -319,7 +345,7 @@ func TestUpdateInstanceInstance(t *testing.T) {
 
 		klient := newTestClient(t, tc.name, tc.version, tc.enableAlpha, tc.httpChecks, tc.httpReaction)
 
-		response, err := klient.UpdateInstance(tc.request)
+		response, err := klient.UpdateInstance(context.Background(), tc.request)
 
 		doResponseChecks(t, tc.name, response, err, tc.expectedResponse, tc.expectedErrMessage, tc.expectedErr)
 	}
@@ -0,0 +1,228 @@
+/*
+Copyright 2024 Klutch Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v2
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/santhosh-tekuri/jsonschema/v6"
+	"github.com/santhosh-tekuri/jsonschema/v6/kind"
+	"golang.org/x/text/language"
+	"golang.org/x/text/message"
+)
+
+// parametersSchemaURL is the URL under which a plan's parameters schema is
+// compiled. It only shows up in errors about invalid schemas.
+const parametersSchemaURL = "parameters.json"
+
+var violationPrinter = message.NewPrinter(language.English)
+
+// ParameterViolation describes a single parameter that does not match the
+// schema of a plan.
+type ParameterViolation struct {
+	// Field is the path of the offending parameter, with the names of nested
+	// fields separated by dots. It is empty if the violation concerns the
+	// parameters as a whole.
+	Field string
+	// Message is a human-readable description of the violation.
+	Message string
+}
+
+func (v ParameterViolation) String() string {
+	if v.Field == "" {
+		return v.Message
+	}
+	return fmt.Sprintf("%s: %s", v.Field, v.Message)
+}
+
+// ParameterValidationError is returned by ValidateParameters and the request
+// specific validation functions if the parameters do not match the schema of
+// the plan. It lists every violation, ordered by field.
+type ParameterValidationError struct {
+	Violations []ParameterViolation
+}
+
+func (e ParameterValidationError) Error() string {
+	violations := make([]string, len(e.Violations))
+	for i, violation := range e.Violations {
+		violations[i] = violation.String()
+	}
+	return fmt.Sprintf("parameters do not match the schema of the plan: %s", strings.Join(violations, "; "))
+}
+
+// IsParameterValidationError returns whether the error, or any error it
+// wraps, is a ParameterValidationError.
+func IsParameterValidationError(err error) (*ParameterValidationError, bool) {
+	var validationErr ParameterValidationError
+	if errors.As(err, &validationErr) {
+		return &validationErr, true
+	}
+	return nil, false
+}
+
+// ValidateProvisionRequest validates the parameters of a provision request
+// against the service instance create schema of the given plan.
+func ValidateProvisionRequest(plan *Plan, r *ProvisionRequest) error {
+	if plan.Schemas == nil || plan.Schemas.ServiceInstance == nil {
+		return nil
+	}
+	return ValidateParameters(plan.Schemas.ServiceInstance.Create, r.Parameters)
+}
+
+// ValidateUpdateInstanceRequest validates the parameters of an update request
+// against the service instance update schema of the given plan. Parameters
+// set to nil, which reset a parameter to its default, are not validated.
+// Requests without parameters, such as plan changes and maintenance info
+// upgrades, leave the parameters as they are and are not validated either.
+func ValidateUpdateInstanceRequest(plan *Plan, r *UpdateInstanceRequest) error {
+	if plan.Schemas == nil || plan.Schemas.ServiceInstance == nil || len(r.Parameters) == 0 {
+		return nil
+	}
+
+	parameters := make(map[string]interface{}, len(r.Parameters))
+	for key, value := range r.Parameters {
+		if value != nil {
+			parameters[key] = value
+		}
+	}
+	return ValidateParameters(plan.Schemas.ServiceInstance.Update, parameters)
+}
+
+// ValidateBindRequest validates the parameters of a bind request against the
+// service binding create schema of the given plan.
+func ValidateBindRequest(plan *Plan, r *BindRequest) error {
+	if plan.Schemas == nil || plan.Schemas.ServiceBinding == nil {
+		return nil
+	}
+	return ValidateParameters(plan.Schemas.ServiceBinding.Create, r.Parameters)
+}
+
+// ValidateParameters validates parameters against a JSON schema from the
+// catalog. Schemas without a $schema keyword are interpreted as draft-04, as
+// mandated by the Open Service Broker API. A nil schema accepts any
+// parameters.
+//
+// If the parameters do not match the schema, the returned error is a
+// ParameterValidationError. Any other error means the schema itself is
+// invalid.
+func ValidateParameters(schema *InputParametersSchema, parameters map[string]interface{}) error {
+	if schema == nil || schema.Parameters == nil {
+		return nil
+	}
+
+	compiled, err := compileParametersSchema(schema.Parameters)
+	if err != nil {
+		return err
+	}
+
+	// Round-trip the parameters through JSON, so that they are made up of the
+	// types the validator expects regardless of how they were constructed.
+	if parameters == nil {
+		parameters = map[string]interface{}{}
+	}
+	instance, err := toJSONValue(parameters)
+	if err != nil {
+		return fmt.Errorf("cannot encode parameters: %w", err)
+	}
+
+	err = compiled.Validate(instance)
+	var validationErr *jsonschema.ValidationError
+	if errors.As(err, &validationErr) {
+		return ParameterValidationError{Violations: violations(validationErr)}
+	}
+	return err
+}
+
+func compileParametersSchema(schema interface{}) (*jsonschema.Schema, error) {
+	document, err := toJSONValue(schema)
+	if err != nil {
+		return nil, fmt.Errorf("cannot encode parameters schema: %w", err)
+	}
+
+	compiler := jsonschema.NewCompiler()
+	compiler.DefaultDraft(jsonschema.Draft4)
+	if err := compiler.AddResource(parametersSchemaURL, document); err != nil {
+		return nil, fmt.Errorf("invalid parameters schema: %w", err)
+	}
+	compiled, err := compiler.Compile(parametersSchemaURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid parameters schema: %w", err)
+	}
+	return compiled, nil
+}
+
+func toJSONValue(v interface{}) (interface{}, error) {
+	encoded, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	return jsonschema.UnmarshalJSON(bytes.NewReader(encoded))
+}
+
+// violations flattens the tree of validation errors into one violation per
+// offending field.
+func violations(err *jsonschema.ValidationError) []ParameterViolation {
+	seen := map[ParameterViolation]bool{}
+	var result []ParameterViolation
+	add := func(violation ParameterViolation) {
+		if !seen[violation] {
+			seen[violation] = true
+			result = append(result, violation)
+		}
+	}
+
+	var walk func(*jsonschema.ValidationError)
+	walk = func(err *jsonschema.ValidationError) {
+		if len(err.Causes) > 0 {
+			for _, cause := range err.Causes {
+				walk(cause)
+			}
+			return
+		}
+
+		switch errorKind := err.ErrorKind.(type) {
+		case *kind.Required:
+			for _, property := range errorKind.Missing {
+				add(ParameterViolation{Field: fieldPath(err.InstanceLocation, property), Message: "is required"})
+			}
+		case *kind.AdditionalProperties:
+			for _, property := range errorKind.Properties {
+				add(ParameterViolation{Field: fieldPath(err.InstanceLocation, property), Message: "is not allowed"})
+			}
+		default:
+			add(ParameterViolation{
+				Field:   fieldPath(err.InstanceLocation),
+				Message: err.ErrorKind.LocalizedString(violationPrinter),
+			})
+		}
+	}
+	walk(err)
+
+	sort.SliceStable(result, func(i, j int) bool {
+		return result[i].Field < result[j].Field
+	})
+	return result
+}
+
+func fieldPath(location []string, field ...string) string {
+	return strings.Join(append(append([]string(nil), location...), field...), ".")
+}
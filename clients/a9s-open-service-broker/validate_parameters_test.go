@@ -0,0 +1,162 @@
+/*
+Copyright 2024 Klutch Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v2
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+const testParametersSchema = `{
+	"$schema": "http://json-schema.org/draft-04/schema#",
+	"type": "object",
+	"additionalProperties": false,
+	"required": ["max_connections"],
+	"properties": {
+		"max_connections": {"type": "integer", "minimum": 1},
+		"backup": {
+			"type": "object",
+			"required": ["retention"],
+			"properties": {
+				"retention": {"type": "string", "enum": ["7d", "30d"]}
+			}
+		}
+	}
+}`
+
+func testSchemaPlan(t *testing.T) *Plan {
+	t.Helper()
+
+	var parameters interface{}
+	if err := json.Unmarshal([]byte(testParametersSchema), &parameters); err != nil {
+		t.Fatalf("cannot decode schema: %v", err)
+	}
+	schema := &InputParametersSchema{Parameters: parameters}
+
+	return &Plan{
+		Schemas: &Schemas{
+			ServiceInstance: &ServiceInstanceSchema{Create: schema, Update: schema},
+			ServiceBinding:  &ServiceBindingSchema{Create: schema},
+		},
+	}
+}
+
+func TestValidateParameters(t *testing.T) {
+	cases := []struct {
+		name       string
+		parameters map[string]interface{}
+		violations []ParameterViolation
+	}{
+		{
+			name:       "valid",
+			parameters: map[string]interface{}{"max_connections": 100, "backup": map[string]interface{}{"retention": "7d"}},
+		},
+		{
+			name:       "missing required parameter",
+			parameters: nil,
+			violations: []ParameterViolation{{Field: "max_connections", Message: "is required"}},
+		},
+		{
+			name:       "unknown parameter",
+			parameters: map[string]interface{}{"max_connection": 100, "max_connections": 100},
+			violations: []ParameterViolation{{Field: "max_connection", Message: "is not allowed"}},
+		},
+		{
+			name: "multiple violations",
+			parameters: map[string]interface{}{
+				"max_connections": 0,
+				"backup":          map[string]interface{}{"retention": "1y"},
+			},
+			violations: []ParameterViolation{
+				{Field: "backup.retention", Message: "value must be one of '7d', '30d'"},
+				{Field: "max_connections", Message: "minimum: got 0, want 1"},
+			},
+		},
+		{
+			name:       "wrong type",
+			parameters: map[string]interface{}{"max_connections": "100"},
+			violations: []ParameterViolation{{Field: "max_connections", Message: "got string, want integer"}},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := ValidateParameters(testSchemaPlan(t).Schemas.ServiceInstance.Create, tc.parameters)
+			if tc.violations == nil {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				return
+			}
+
+			validationErr, ok := IsParameterValidationError(fmt.Errorf("wrapped: %w", err))
+			if !ok {
+				t.Fatalf("expected ParameterValidationError, got %v", err)
+			}
+			if diff := cmp.Diff(tc.violations, validationErr.Violations); diff != "" {
+				t.Errorf("-want violations, +got violations:\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestValidateParametersWithoutSchema(t *testing.T) {
+	parameters := map[string]interface{}{"anything": true}
+
+	if err := ValidateParameters(nil, parameters); err != nil {
+		t.Errorf("expected nil schema to accept parameters, got %v", err)
+	}
+	if err := ValidateProvisionRequest(&Plan{}, &ProvisionRequest{Parameters: parameters}); err != nil {
+		t.Errorf("expected plan without schemas to accept parameters, got %v", err)
+	}
+}
+
+func TestValidateParametersInvalidSchema(t *testing.T) {
+	err := ValidateParameters(&InputParametersSchema{Parameters: map[string]interface{}{"type": 42}}, nil)
+	if err == nil {
+		t.Fatal("expected error for invalid schema")
+	}
+	if _, ok := IsParameterValidationError(err); ok {
+		t.Errorf("expected invalid schema not to be reported as parameter violation, got %v", err)
+	}
+}
+
+func TestValidateRequests(t *testing.T) {
+	plan := testSchemaPlan(t)
+
+	if err := ValidateProvisionRequest(plan, &ProvisionRequest{}); err == nil {
+		t.Error("ValidateProvisionRequest: expected error for missing parameter")
+	}
+
+	// Resetting a parameter to its default is not subject to the schema.
+	update := &UpdateInstanceRequest{Parameters: map[string]interface{}{"max_connections": 50, "backup": nil}}
+	if err := ValidateUpdateInstanceRequest(plan, update); err != nil {
+		t.Errorf("ValidateUpdateInstanceRequest: unexpected error: %v", err)
+	}
+
+	// Updates without parameters, e.g. plan changes, keep the parameters.
+	if err := ValidateUpdateInstanceRequest(plan, &UpdateInstanceRequest{PlanID: strPtr("other-plan")}); err != nil {
+		t.Errorf("ValidateUpdateInstanceRequest: unexpected error for update without parameters: %v", err)
+	}
+
+	if err := ValidateBindRequest(plan, &BindRequest{Parameters: map[string]interface{}{"max_connections": -1}}); err == nil {
+		t.Error("ValidateBindRequest: expected error for parameter below minimum")
+	}
+}
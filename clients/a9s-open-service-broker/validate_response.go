@@ -0,0 +1,305 @@
+/*
+Copyright 2024 Klutch Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v2
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// ResponseValidation is the mode in which a client validates the responses of
+// the broker against the Open Service Broker API specification.
+type ResponseValidation string
+
+const (
+	// ResponseValidationDisabled accepts every response that can be decoded.
+	ResponseValidationDisabled ResponseValidation = ""
+	// ResponseValidationLog logs the violations of responses and accepts
+	// them, e.g. to find out whether a broker can be validated strictly.
+	ResponseValidationLog ResponseValidation = "Log"
+	// ResponseValidationStrict rejects responses that violate the
+	// specification with a ResponseValidationError.
+	ResponseValidationStrict ResponseValidation = "Strict"
+)
+
+// ResponseViolation describes a single field of a response that violates the
+// Open Service Broker API specification.
+type ResponseViolation struct {
+	// Field is the path of the offending field, e.g. services[0].plans[1].id.
+	Field string
+	// Message is a human-readable description of the violation.
+	Message string
+}
+
+func (v ResponseViolation) String() string {
+	return fmt.Sprintf("%s: %s", v.Field, v.Message)
+}
+
+// ResponseValidationError is returned by clients in strict response
+// validation mode if a response of the broker violates the Open Service
+// Broker API specification.
+type ResponseValidationError struct {
+	// Operation is the operation the response belongs to, e.g. "catalog".
+	Operation string
+	// Violations lists every violation of the response.
+	Violations []ResponseViolation
+}
+
+func (e ResponseValidationError) Error() string {
+	violations := make([]string, len(e.Violations))
+	for i, violation := range e.Violations {
+		violations[i] = violation.String()
+	}
+	return fmt.Sprintf("%s response of the broker violates the Open Service Broker API: %s", e.Operation, strings.Join(violations, "; "))
+}
+
+// IsResponseValidationError returns whether the error, or any error it
+// wraps, is a ResponseValidationError.
+func IsResponseValidationError(err error) (*ResponseValidationError, bool) {
+	var validationErr ResponseValidationError
+	if errors.As(err, &validationErr) {
+		return &validationErr, true
+	}
+	return nil, false
+}
+
+// validateResponse handles the violations of a response according to the
+// response validation mode of the client.
+func (c *client) validateResponse(ctx context.Context, violations []ResponseViolation) error {
+	if len(violations) == 0 || c.responseValidation == ResponseValidationDisabled {
+		return nil
+	}
+
+	operation := operationFromContext(ctx)
+	if c.responseValidation == ResponseValidationStrict {
+		return ResponseValidationError{Operation: operation, Violations: violations}
+	}
+
+	messages := make([]string, len(violations))
+	for i, violation := range violations {
+		messages[i] = violation.String()
+	}
+	c.logger.Info("Response of the broker violates the Open Service Broker API", "operation", operation, "violations", messages)
+	return nil
+}
+
+// catalogViolations returns the violations of a catalog: services and plans
+// need an ID, a name and a description, every service needs a plan and IDs
+// must be unique.
+func catalogViolations(catalog *CatalogResponse) []ResponseViolation {
+	var violations []ResponseViolation
+	if catalog.Services == nil {
+		return append(violations, ResponseViolation{Field: "services", Message: "is required"})
+	}
+
+	ids := map[string]string{}
+	unique := func(field, id string) {
+		if id == "" {
+			return
+		}
+		if other, ok := ids[id]; ok {
+			violations = append(violations, ResponseViolation{Field: field, Message: fmt.Sprintf("%q is also the ID of %s", id, other)})
+			return
+		}
+		ids[id] = field
+	}
+	required := func(field, value string) {
+		if value == "" {
+			violations = append(violations, ResponseViolation{Field: field, Message: "is required"})
+		}
+	}
+
+	for i, service := range catalog.Services {
+		field := fmt.Sprintf("services[%d]", i)
+		required(field+".id", service.ID)
+		required(field+".name", service.Name)
+		required(field+".description", service.Description)
+		unique(field+".id", service.ID)
+		if len(service.Plans) == 0 {
+			violations = append(violations, ResponseViolation{Field: field + ".plans", Message: "must contain at least one plan"})
+		}
+
+		for j, plan := range service.Plans {
+			field := fmt.Sprintf("%s.plans[%d]", field, j)
+			required(field+".id", plan.ID)
+			required(field+".name", plan.Name)
+			required(field+".description", plan.Description)
+			unique(field+".id", plan.ID)
+		}
+	}
+	return violations
+}
+
+// lastOperationViolations returns the violations of the state of a last
+// operation response.
+func lastOperationViolations(state LastOperationState) []ResponseViolation {
+	switch state {
+	case StateInProgress, StateSucceeded, StateFailed:
+		return nil
+	case "":
+		return []ResponseViolation{{Field: "state", Message: "is required"}}
+	default:
+		return []ResponseViolation{{Field: "state", Message: fmt.Sprintf("unknown state %q, must be one of %q, %q or %q", state, StateInProgress, StateSucceeded, StateFailed)}}
+	}
+}
+
+// maxOperationLength is the maximum length of the operation of an
+// asynchronous response.
+const maxOperationLength = 10000
+
+// provisionViolations returns the violations of a provision response.
+func provisionViolations(r *ProvisionResponse) []ResponseViolation {
+	return append(urlViolations("dashboard_url", r.DashboardURL), operationViolations(r.OperationKey)...)
+}
+
+// updateInstanceViolations returns the violations of an update response.
+func updateInstanceViolations(r *UpdateInstanceResponse) []ResponseViolation {
+	return append(urlViolations("dashboard_url", r.DashboardURL), operationViolations(r.OperationKey)...)
+}
+
+// deprovisionViolations returns the violations of a deprovision response.
+func deprovisionViolations(r *DeprovisionResponse) []ResponseViolation {
+	return operationViolations(r.OperationKey)
+}
+
+// bindViolations returns the violations of a bind response.
+func bindViolations(r *BindResponse) []ResponseViolation {
+	violations := bindingViolations(r.SyslogDrainURL, r.RouteServiceURL, r.VolumeMounts, r.Endpoints, r.Metadata)
+	return append(violations, operationViolations(r.OperationKey)...)
+}
+
+// getServiceInstanceViolations returns the violations of a response to
+// fetching a service instance: the dashboard URL must be a URL and maintenance
+// info needs a version.
+func getServiceInstanceViolations(r *GetServiceInstanceResponse) []ResponseViolation {
+	violations := urlViolations("dashboard_url", &r.DashboardURL)
+	if r.MaintenanceInfo != nil && r.MaintenanceInfo.Version == "" {
+		violations = append(violations, ResponseViolation{Field: "maintenance_info.version", Message: "is required"})
+	}
+	return violations
+}
+
+// getBindingViolations returns the violations of a response to fetching a
+// binding.
+func getBindingViolations(r *GetBindingResponse) []ResponseViolation {
+	return bindingViolations(r.SyslogDrainURL, r.RouteServiceURL, r.VolumeMounts, r.Endpoints, r.Metadata)
+}
+
+// bindingViolations returns the violations of the fields bind and get binding
+// responses share: the route service URL must use https, volume mounts and
+// endpoints need their required fields and the metadata must hold ISO 8601
+// times.
+func bindingViolations(syslogDrainURL, routeServiceURL *string, volumeMounts []interface{}, endpoints *[]Endpoint, metadata *BindingMetadata) []ResponseViolation {
+	violations := urlViolations("syslog_drain_url", syslogDrainURL)
+	violations = append(violations, urlViolations("route_service_url", routeServiceURL)...)
+	if routeServiceURL != nil && *routeServiceURL != "" && !strings.HasPrefix(*routeServiceURL, "https://") {
+		violations = append(violations, ResponseViolation{Field: "route_service_url", Message: "must use https"})
+	}
+
+	for i, mount := range volumeMounts {
+		violations = append(violations, volumeMountViolations(fmt.Sprintf("volume_mounts[%d]", i), mount)...)
+	}
+
+	if endpoints != nil {
+		for i, endpoint := range *endpoints {
+			field := fmt.Sprintf("endpoints[%d]", i)
+			if endpoint.Host == "" {
+				violations = append(violations, ResponseViolation{Field: field + ".host", Message: "is required"})
+			}
+			if len(endpoint.Ports) == 0 {
+				violations = append(violations, ResponseViolation{Field: field + ".ports", Message: "must contain at least one port"})
+			}
+		}
+	}
+
+	if metadata != nil {
+		expiresAt, expiresOK := timeViolation(&violations, "metadata.expires_at", metadata.ExpiresAt)
+		renewBefore, renewOK := timeViolation(&violations, "metadata.renew_before", metadata.RenewBefore)
+		if expiresOK && renewOK && renewBefore.After(expiresAt) {
+			violations = append(violations, ResponseViolation{Field: "metadata.renew_before", Message: "must not be after expires_at"})
+		}
+	}
+	return violations
+}
+
+// volumeMountViolations returns the violations of a volume mount: it needs a
+// driver, a container directory, a mode of "r" or "rw", the device type
+// "shared" and a device with a volume ID.
+func volumeMountViolations(field string, mount interface{}) []ResponseViolation {
+	fields, ok := mount.(map[string]interface{})
+	if !ok {
+		return []ResponseViolation{{Field: field, Message: "must be an object"}}
+	}
+
+	var violations []ResponseViolation
+	for _, name := range []string{"driver", "container_dir"} {
+		if value, _ := fields[name].(string); value == "" {
+			violations = append(violations, ResponseViolation{Field: field + "." + name, Message: "is required"})
+		}
+	}
+	if mode, _ := fields["mode"].(string); mode != "r" && mode != "rw" {
+		violations = append(violations, ResponseViolation{Field: field + ".mode", Message: `must be "r" or "rw"`})
+	}
+	if deviceType, _ := fields["device_type"].(string); deviceType != "shared" {
+		violations = append(violations, ResponseViolation{Field: field + ".device_type", Message: `must be "shared"`})
+	}
+	device, _ := fields["device"].(map[string]interface{})
+	if volumeID, _ := device["volume_id"].(string); volumeID == "" {
+		violations = append(violations, ResponseViolation{Field: field + ".device.volume_id", Message: "is required"})
+	}
+	return violations
+}
+
+// urlViolations returns the violations of an optional URL, which must be
+// absolute.
+func urlViolations(field string, value *string) []ResponseViolation {
+	if value == nil || *value == "" {
+		return nil
+	}
+	if parsed, err := url.Parse(*value); err != nil || !parsed.IsAbs() || parsed.Host == "" {
+		return []ResponseViolation{{Field: field, Message: fmt.Sprintf("%q is not an absolute URL", *value)}}
+	}
+	return nil
+}
+
+// operationViolations returns the violations of the operation of an
+// asynchronous response.
+func operationViolations(operation *OperationKey) []ResponseViolation {
+	if operation != nil && utf8.RuneCountInString(string(*operation)) > maxOperationLength {
+		return []ResponseViolation{{Field: "operation", Message: fmt.Sprintf("must not be longer than %d characters", maxOperationLength)}}
+	}
+	return nil
+}
+
+// timeViolation parses an optional ISO 8601 time and records a violation if
+// it is malformed. It returns whether the time is set and valid.
+func timeViolation(violations *[]ResponseViolation, field, value string) (time.Time, bool) {
+	if value == "" {
+		return time.Time{}, false
+	}
+	parsed, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		*violations = append(*violations, ResponseViolation{Field: field, Message: fmt.Sprintf("%q is not an ISO 8601 time", value)})
+		return time.Time{}, false
+	}
+	return parsed, true
+}
@@ -0,0 +1,388 @@
+/*
+Copyright 2024 Klutch Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v2
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestCatalogViolations(t *testing.T) {
+	cases := map[string]struct {
+		catalog *CatalogResponse
+		want    []ResponseViolation
+	}{
+		"ValidCatalog": {
+			catalog: okCatalogResponse(),
+		},
+		"MissingServices": {
+			catalog: &CatalogResponse{},
+			want:    []ResponseViolation{{Field: "services", Message: "is required"}},
+		},
+		"EmptyPlanID": {
+			catalog: func() *CatalogResponse {
+				catalog := okCatalogResponse()
+				catalog.Services[0].Plans[0].ID = ""
+				return catalog
+			}(),
+			want: []ResponseViolation{{Field: "services[0].plans[0].id", Message: "is required"}},
+		},
+		"DuplicateID": {
+			catalog: func() *CatalogResponse {
+				catalog := okCatalogResponse()
+				catalog.Services[0].Plans[0].ID = catalog.Services[0].ID
+				return catalog
+			}(),
+			want: []ResponseViolation{{Field: "services[0].plans[0].id", Message: `"` + okCatalogResponse().Services[0].ID + `" is also the ID of services[0].id`}},
+		},
+		"ServiceWithoutPlans": {
+			catalog: &CatalogResponse{Services: []Service{{ID: "service-id", Name: "service", Description: "a service"}}},
+			want:    []ResponseViolation{{Field: "services[0].plans", Message: "must contain at least one plan"}},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			if diff := cmp.Diff(tc.want, catalogViolations(tc.catalog)); diff != "" {
+				t.Errorf("catalogViolations(...): -want, +got:\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestLastOperationViolations(t *testing.T) {
+	cases := map[string]struct {
+		state LastOperationState
+		want  []ResponseViolation
+	}{
+		"InProgress": {state: StateInProgress},
+		"Succeeded":  {state: StateSucceeded},
+		"Failed":     {state: StateFailed},
+		"Missing": {
+			want: []ResponseViolation{{Field: "state", Message: "is required"}},
+		},
+		"Unknown": {
+			state: "done",
+			want:  []ResponseViolation{{Field: "state", Message: `unknown state "done", must be one of "in progress", "succeeded" or "failed"`}},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			if diff := cmp.Diff(tc.want, lastOperationViolations(tc.state)); diff != "" {
+				t.Errorf("lastOperationViolations(%q): -want, +got:\n%s", tc.state, diff)
+			}
+		})
+	}
+}
+
+func opKeyPtr(operation OperationKey) *OperationKey {
+	return &operation
+}
+
+func TestProvisionViolations(t *testing.T) {
+	cases := map[string]struct {
+		response *ProvisionResponse
+		want     []ResponseViolation
+	}{
+		"Synchronous": {
+			response: &ProvisionResponse{DashboardURL: strPtr("https://dashboard.example.com/instance")},
+		},
+		"Asynchronous": {
+			response: &ProvisionResponse{Async: true, OperationKey: opKeyPtr("provision")},
+		},
+		"RelativeDashboardURL": {
+			response: &ProvisionResponse{DashboardURL: strPtr("/instance")},
+			want:     []ResponseViolation{{Field: "dashboard_url", Message: `"/instance" is not an absolute URL`}},
+		},
+		"OperationTooLong": {
+			response: &ProvisionResponse{Async: true, OperationKey: opKeyPtr(OperationKey(strings.Repeat("o", maxOperationLength+1)))},
+			want:     []ResponseViolation{{Field: "operation", Message: "must not be longer than 10000 characters"}},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			if diff := cmp.Diff(tc.want, provisionViolations(tc.response)); diff != "" {
+				t.Errorf("provisionViolations(...): -want, +got:\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestUpdateInstanceViolations(t *testing.T) {
+	cases := map[string]struct {
+		response *UpdateInstanceResponse
+		want     []ResponseViolation
+	}{
+		"Synchronous": {
+			response: &UpdateInstanceResponse{DashboardURL: strPtr("https://dashboard.example.com/instance")},
+		},
+		"Asynchronous": {
+			response: &UpdateInstanceResponse{Async: true, OperationKey: opKeyPtr("update")},
+		},
+		"DashboardURLWithoutHost": {
+			response: &UpdateInstanceResponse{DashboardURL: strPtr("https://")},
+			want:     []ResponseViolation{{Field: "dashboard_url", Message: `"https://" is not an absolute URL`}},
+		},
+		"OperationTooLong": {
+			response: &UpdateInstanceResponse{Async: true, OperationKey: opKeyPtr(OperationKey(strings.Repeat("ö", maxOperationLength+1)))},
+			want:     []ResponseViolation{{Field: "operation", Message: "must not be longer than 10000 characters"}},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			if diff := cmp.Diff(tc.want, updateInstanceViolations(tc.response)); diff != "" {
+				t.Errorf("updateInstanceViolations(...): -want, +got:\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestDeprovisionViolations(t *testing.T) {
+	cases := map[string]struct {
+		response *DeprovisionResponse
+		want     []ResponseViolation
+	}{
+		"Synchronous": {
+			response: &DeprovisionResponse{},
+		},
+		"Asynchronous": {
+			response: &DeprovisionResponse{Async: true, OperationKey: opKeyPtr("deprovision")},
+		},
+		"OperationOfMaximumLength": {
+			response: &DeprovisionResponse{Async: true, OperationKey: opKeyPtr(OperationKey(strings.Repeat("ö", maxOperationLength)))},
+		},
+		"OperationTooLong": {
+			response: &DeprovisionResponse{Async: true, OperationKey: opKeyPtr(OperationKey(strings.Repeat("o", maxOperationLength+1)))},
+			want:     []ResponseViolation{{Field: "operation", Message: "must not be longer than 10000 characters"}},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			if diff := cmp.Diff(tc.want, deprovisionViolations(tc.response)); diff != "" {
+				t.Errorf("deprovisionViolations(...): -want, +got:\n%s", diff)
+			}
+		})
+	}
+}
+
+func okVolumeMount() map[string]interface{} {
+	return map[string]interface{}{
+		"driver":        "nfs",
+		"container_dir": "/data",
+		"mode":          "rw",
+		"device_type":   "shared",
+		"device":        map[string]interface{}{"volume_id": "volume-1"},
+	}
+}
+
+func TestBindViolations(t *testing.T) {
+	cases := map[string]struct {
+		response *BindResponse
+		want     []ResponseViolation
+	}{
+		"Synchronous": {
+			response: &BindResponse{
+				Credentials:     map[string]interface{}{"username": "user"},
+				RouteServiceURL: strPtr("https://route.example.com"),
+				SyslogDrainURL:  strPtr("syslog://logs.example.com:514"),
+				VolumeMounts:    []interface{}{okVolumeMount()},
+				Endpoints:       &[]Endpoint{{Host: "pg.example.com", Ports: []uint16{5432}}},
+				Metadata:        &BindingMetadata{ExpiresAt: "2026-12-31T00:00:00Z", RenewBefore: "2026-12-01T00:00:00Z"},
+			},
+		},
+		"Asynchronous": {
+			response: &BindResponse{Async: true, OperationKey: opKeyPtr("bind")},
+		},
+		"RouteServiceURLWithoutHTTPS": {
+			response: &BindResponse{RouteServiceURL: strPtr("http://route.example.com")},
+			want:     []ResponseViolation{{Field: "route_service_url", Message: "must use https"}},
+		},
+		"InvalidVolumeMount": {
+			response: &BindResponse{VolumeMounts: []interface{}{
+				okVolumeMount(),
+				map[string]interface{}{"driver": "nfs", "mode": "w", "device_type": "dedicated"},
+				"nfs:/data",
+			}},
+			want: []ResponseViolation{
+				{Field: "volume_mounts[1].container_dir", Message: "is required"},
+				{Field: "volume_mounts[1].mode", Message: `must be "r" or "rw"`},
+				{Field: "volume_mounts[1].device_type", Message: `must be "shared"`},
+				{Field: "volume_mounts[1].device.volume_id", Message: "is required"},
+				{Field: "volume_mounts[2]", Message: "must be an object"},
+			},
+		},
+		"InvalidEndpoint": {
+			response: &BindResponse{Endpoints: &[]Endpoint{{}}},
+			want: []ResponseViolation{
+				{Field: "endpoints[0].host", Message: "is required"},
+				{Field: "endpoints[0].ports", Message: "must contain at least one port"},
+			},
+		},
+		"MalformedExpiry": {
+			response: &BindResponse{Metadata: &BindingMetadata{ExpiresAt: "tomorrow"}},
+			want:     []ResponseViolation{{Field: "metadata.expires_at", Message: `"tomorrow" is not an ISO 8601 time`}},
+		},
+		"RenewAfterExpiry": {
+			response: &BindResponse{Metadata: &BindingMetadata{ExpiresAt: "2026-12-01T00:00:00Z", RenewBefore: "2026-12-31T00:00:00Z"}},
+			want:     []ResponseViolation{{Field: "metadata.renew_before", Message: "must not be after expires_at"}},
+		},
+		"OperationTooLong": {
+			response: &BindResponse{Async: true, OperationKey: opKeyPtr(OperationKey(strings.Repeat("o", maxOperationLength+1)))},
+			want:     []ResponseViolation{{Field: "operation", Message: "must not be longer than 10000 characters"}},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			if diff := cmp.Diff(tc.want, bindViolations(tc.response)); diff != "" {
+				t.Errorf("bindViolations(...): -want, +got:\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestGetServiceInstanceViolations(t *testing.T) {
+	cases := map[string]struct {
+		response *GetServiceInstanceResponse
+		want     []ResponseViolation
+	}{
+		"Valid": {
+			response: &GetServiceInstanceResponse{
+				DashboardURL:    "https://dashboard.example.com/instance",
+				MaintenanceInfo: &MaintenanceInfo{Version: "1.0.0"},
+			},
+		},
+		"WithoutDashboardURL": {
+			response: &GetServiceInstanceResponse{},
+		},
+		"RelativeDashboardURL": {
+			response: &GetServiceInstanceResponse{DashboardURL: "dashboard"},
+			want:     []ResponseViolation{{Field: "dashboard_url", Message: `"dashboard" is not an absolute URL`}},
+		},
+		"MaintenanceInfoWithoutVersion": {
+			response: &GetServiceInstanceResponse{MaintenanceInfo: &MaintenanceInfo{Description: "patch"}},
+			want:     []ResponseViolation{{Field: "maintenance_info.version", Message: "is required"}},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			if diff := cmp.Diff(tc.want, getServiceInstanceViolations(tc.response)); diff != "" {
+				t.Errorf("getServiceInstanceViolations(...): -want, +got:\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestGetBindingViolations(t *testing.T) {
+	cases := map[string]struct {
+		response *GetBindingResponse
+		want     []ResponseViolation
+	}{
+		"Valid": {
+			response: &GetBindingResponse{
+				Credentials:  map[string]interface{}{"username": "user"},
+				VolumeMounts: []interface{}{okVolumeMount()},
+				Metadata:     &BindingMetadata{ExpiresAt: "2026-12-31T00:00:00Z"},
+			},
+		},
+		"RelativeSyslogDrainURL": {
+			response: &GetBindingResponse{SyslogDrainURL: strPtr("logs")},
+			want:     []ResponseViolation{{Field: "syslog_drain_url", Message: `"logs" is not an absolute URL`}},
+		},
+		"InvalidVolumeMount": {
+			response: &GetBindingResponse{VolumeMounts: []interface{}{map[string]interface{}{}}},
+			want: []ResponseViolation{
+				{Field: "volume_mounts[0].driver", Message: "is required"},
+				{Field: "volume_mounts[0].container_dir", Message: "is required"},
+				{Field: "volume_mounts[0].mode", Message: `must be "r" or "rw"`},
+				{Field: "volume_mounts[0].device_type", Message: `must be "shared"`},
+				{Field: "volume_mounts[0].device.volume_id", Message: "is required"},
+			},
+		},
+		"MalformedRenewBefore": {
+			response: &GetBindingResponse{Metadata: &BindingMetadata{RenewBefore: "2026-12-01"}},
+			want:     []ResponseViolation{{Field: "metadata.renew_before", Message: `"2026-12-01" is not an ISO 8601 time`}},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			if diff := cmp.Diff(tc.want, getBindingViolations(tc.response)); diff != "" {
+				t.Errorf("getBindingViolations(...): -want, +got:\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestResponseValidation(t *testing.T) {
+	cases := map[string]struct {
+		mode    ResponseValidation
+		body    string
+		wantErr *ResponseValidationError
+	}{
+		"DisabledAcceptsViolations": {
+			body: `{}`,
+		},
+		"LogAcceptsViolations": {
+			mode: ResponseValidationLog,
+			body: `{}`,
+		},
+		"StrictRejectsViolations": {
+			mode: ResponseValidationStrict,
+			body: `{"state":"unknown"}`,
+			wantErr: &ResponseValidationError{
+				Operation:  operationLastOperation,
+				Violations: lastOperationViolations("unknown"),
+			},
+		},
+		"StrictAcceptsValidResponses": {
+			mode: ResponseValidationStrict,
+			body: successLastOperationResponseBody,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			klient := newTestClient(t, name, Version2_14(), false, httpChecks{}, httpReaction{status: http.StatusOK, body: tc.body})
+			klient.responseValidation = tc.mode
+
+			_, err := klient.PollLastOperation(context.Background(), defaultLastOperationRequest())
+			if tc.wantErr == nil {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				return
+			}
+			validationErr, ok := IsResponseValidationError(err)
+			if !ok {
+				t.Fatalf("want ResponseValidationError, got %v", err)
+			}
+			if diff := cmp.Diff(tc.wantErr, validationErr); diff != "" {
+				t.Errorf("error: -want, +got:\n%s", diff)
+			}
+		})
+	}
+}
@@ -16,6 +16,8 @@ limitations under the License.
 
 package v2
 
+import "fmt"
+
 // APIVersion represents a specific version of the OSB API.
 type APIVersion struct {
 	label string
@@ -42,8 +44,60 @@ func (v APIVersion) IsLessThan(other APIVersion) bool {
 	return !v.AtLeast(other)
 }
 
-// LatestAPIVersion returns the latest supported API version in the current
-// release of this library.
+// ParseAPIVersion returns the APIVersion with the given header value, such as
+// "2.14".
+func ParseAPIVersion(value string) (APIVersion, error) {
+	for _, version := range APIVersions() {
+		if version.label == value {
+			return version, nil
+		}
+	}
+	return APIVersion{}, fmt.Errorf("unsupported API version %q", value)
+}
+
+// Capabilities are the features of the Open Service Broker API that a client
+// can use with a broker, as determined by the API version it talks to the
+// broker with.
+type Capabilities struct {
+	// Context is whether provision and update requests carry a context
+	// (2.12).
+	Context bool
+	// Schemas is whether plans in the catalog carry parameter schemas (2.13).
+	Schemas bool
+	// OriginatingIdentity is whether requests carry the identity of the user
+	// that caused them (2.13).
+	OriginatingIdentity bool
+	// GetInstance is whether service instances can be fetched with
+	// GetInstance, GetServiceInstance and GetInstances (2.14).
+	GetInstance bool
+	// GetBinding is whether bindings can be fetched with GetBinding (2.14).
+	GetBinding bool
+	// AsyncBindings is whether bindings can be created and deleted
+	// asynchronously and their operations polled with
+	// PollBindingLastOperation (2.14).
+	AsyncBindings bool
+	// BindingRotation is whether a binding can be created as the successor of
+	// another binding with BindRequest.PredecessorBindingID, and whether
+	// brokers report when the credentials of a binding expire (2.17).
+	BindingRotation bool
+}
+
+// Capabilities returns the capabilities of the API version.
+func (v APIVersion) Capabilities() Capabilities {
+	return Capabilities{
+		Context:             v.AtLeast(Version2_12()),
+		Schemas:             v.AtLeast(Version2_13()),
+		OriginatingIdentity: v.AtLeast(Version2_13()),
+		GetInstance:         v.AtLeast(Version2_14()),
+		GetBinding:          v.AtLeast(Version2_14()),
+		AsyncBindings:       v.AtLeast(Version2_14()),
+		BindingRotation:     v.AtLeast(Version2_17()),
+	}
+}
+
+// LatestAPIVersion returns the API version clients use by default. Later
+// versions are supported, but only used if a client is configured with them,
+// because brokers that do not know them reject every request.
 func LatestAPIVersion() APIVersion {
 	return Version2_14()
 }
@@ -56,6 +110,9 @@ func APIVersions() []APIVersion {
 		Version2_12(),
 		Version2_13(),
 		Version2_14(),
+		Version2_15(),
+		Version2_16(),
+		Version2_17(),
 	}
 }
 
@@ -75,6 +132,18 @@ const (
 	// internalAPIVersion2_14 represents the 2.14 version of the Open Service
 	// Broker API.
 	internalAPIVersion2_14 = "2.14"
+
+	// internalAPIVersion2_15 represents the 2.15 version of the Open Service
+	// Broker API.
+	internalAPIVersion2_15 = "2.15"
+
+	// internalAPIVersion2_16 represents the 2.16 version of the Open Service
+	// Broker API.
+	internalAPIVersion2_16 = "2.16"
+
+	// internalAPIVersion2_17 represents the 2.17 version of the Open Service
+	// Broker API.
+	internalAPIVersion2_17 = "2.17"
 )
 
 // Version2_11 returns an APIVersion struct with the internal API version set to "2.11"
@@ -96,3 +165,18 @@ func Version2_13() APIVersion {
 func Version2_14() APIVersion {
 	return APIVersion{label: internalAPIVersion2_14, order: 3}
 }
+
+// Version2_15 returns an APIVersion struct with the internal API version set to "2.15"
+func Version2_15() APIVersion {
+	return APIVersion{label: internalAPIVersion2_15, order: 4}
+}
+
+// Version2_16 returns an APIVersion struct with the internal API version set to "2.16"
+func Version2_16() APIVersion {
+	return APIVersion{label: internalAPIVersion2_16, order: 5}
+}
+
+// Version2_17 returns an APIVersion struct with the internal API version set to "2.17"
+func Version2_17() APIVersion {
+	return APIVersion{label: internalAPIVersion2_17, order: 6}
+}
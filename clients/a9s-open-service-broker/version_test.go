@@ -55,3 +55,31 @@ func TestLatestAPIVersion(t *testing.T) {
 		t.Error("Unexpected Latest API Version--expected 2.14")
 	}
 }
+
+func TestParseAPIVersion(t *testing.T) {
+	for _, version := range APIVersions() {
+		parsed, err := ParseAPIVersion(version.HeaderValue())
+		if err != nil || parsed != version {
+			t.Errorf("ParseAPIVersion(%q): expected %v, got %v (error %v)", version.HeaderValue(), version, parsed, err)
+		}
+	}
+
+	if _, err := ParseAPIVersion("2.99"); err == nil {
+		t.Error("expected an error for an unsupported API version")
+	}
+}
+
+func TestCapabilities(t *testing.T) {
+	if c := Version2_11().Capabilities(); c != (Capabilities{}) {
+		t.Errorf("expected 2.11 to have no capabilities, got %+v", c)
+	}
+	if c := Version2_13().Capabilities(); !c.OriginatingIdentity || c.GetBinding {
+		t.Errorf("expected 2.13 to have originating identity but not GetBinding, got %+v", c)
+	}
+	if c := Version2_14().Capabilities(); !c.GetInstance || !c.GetBinding || !c.AsyncBindings || c.BindingRotation {
+		t.Errorf("expected 2.14 to have every capability but binding rotation, got %+v", c)
+	}
+	if c := Version2_17().Capabilities(); !c.AsyncBindings || !c.BindingRotation {
+		t.Errorf("expected 2.17 to have every capability, got %+v", c)
+	}
+}
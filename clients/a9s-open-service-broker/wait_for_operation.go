@@ -0,0 +1,156 @@
+/*
+Copyright 2024 Klutch Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v2
+
+import (
+	"context"
+	"math"
+	"math/rand/v2"
+	"time"
+)
+
+// WaitOptions configures how WaitForOperation and WaitForBindingOperation
+// poll a broker for the outcome of an asynchronous operation.
+//
+// Whenever the broker suggests a polling delay through the Retry-After header
+// that delay is used as is. Otherwise the delay between two polls grows
+// exponentially from InitialInterval up to MaxInterval, randomised by Jitter.
+type WaitOptions struct {
+	// InitialInterval is the delay before the second poll.
+	InitialInterval time.Duration
+	// MaxInterval caps the delay between two polls.
+	MaxInterval time.Duration
+	// Multiplier is the factor by which the delay grows after every poll.
+	// Values below 1 are treated as 1.
+	Multiplier float64
+	// Jitter is the fraction, between 0 and 1, by which every computed delay
+	// is randomly lengthened or shortened. It is not applied to delays
+	// suggested by the broker.
+	Jitter float64
+	// MaxDuration is the maximum time to wait for the operation to complete.
+	// A value of 0 means that only the context limits the wait.
+	MaxDuration time.Duration
+}
+
+// DefaultWaitOptions returns the default WaitOptions:
+//
+//   - 2 second initial interval, doubling up to 1 minute between polls
+//   - 20% jitter
+//   - no maximum duration
+func DefaultWaitOptions() *WaitOptions {
+	return &WaitOptions{
+		InitialInterval: 2 * time.Second,
+		MaxInterval:     time.Minute,
+		Multiplier:      2,
+		Jitter:          0.2,
+	}
+}
+
+// Delay returns how long to wait after the given poll attempt, counted from
+// zero, before polling again. If the broker suggested a delay it takes
+// precedence over the computed backoff.
+func (o *WaitOptions) Delay(attempt int, suggested *time.Duration) time.Duration {
+	if suggested != nil && *suggested > 0 {
+		return *suggested
+	}
+
+	if o.InitialInterval <= 0 {
+		return 0
+	}
+
+	multiplier := math.Max(o.Multiplier, 1)
+	delay := float64(o.InitialInterval) * math.Pow(multiplier, float64(attempt))
+	if o.MaxInterval > 0 && delay > float64(o.MaxInterval) {
+		delay = float64(o.MaxInterval)
+	}
+	// Without MaxInterval the delay of late attempts overflows, up to +Inf,
+	// and converting that to a time.Duration is implementation-defined.
+	delay = math.Min(delay, math.MaxInt64)
+
+	if jitter := math.Min(math.Max(o.Jitter, 0), 1); jitter > 0 {
+		delay += delay * jitter * (2*rand.Float64() - 1)
+	}
+
+	if delay >= math.MaxInt64 {
+		return time.Duration(math.MaxInt64)
+	}
+	return time.Duration(delay)
+}
+
+// WaitForOperation polls the last operation endpoint of a service instance
+// until the operation is no longer in progress, and returns the final
+// response. Between two polls it waits as described by WaitOptions; if opts
+// is nil, DefaultWaitOptions is used.
+//
+// If the operation failed, the final response is returned together with an
+// OperationStateError. Errors returned by the broker, including the HTTP GONE
+// error that signals a finished asynchronous deprovision, are returned as is.
+// If the operation is still in progress after WaitOptions.MaxDuration a
+// PollingTimeoutError is returned, and if ctx is done its error is returned.
+func WaitForOperation(ctx context.Context, c Client, r *LastOperationRequest, opts *WaitOptions) (*LastOperationResponse, error) {
+	return waitForOperation(ctx, opts, func(ctx context.Context) (*LastOperationResponse, error) {
+		return c.PollLastOperation(ctx, r)
+	})
+}
+
+// WaitForBindingOperation polls the last operation endpoint of a service
+// binding until the operation is no longer in progress, and returns the final
+// response. It behaves like WaitForOperation.
+func WaitForBindingOperation(ctx context.Context, c Client, r *BindingLastOperationRequest, opts *WaitOptions) (*LastOperationResponse, error) {
+	return waitForOperation(ctx, opts, func(ctx context.Context) (*LastOperationResponse, error) {
+		return c.PollBindingLastOperation(ctx, r)
+	})
+}
+
+func waitForOperation(ctx context.Context, opts *WaitOptions, poll func(context.Context) (*LastOperationResponse, error)) (*LastOperationResponse, error) {
+	if opts == nil {
+		opts = DefaultWaitOptions()
+	}
+
+	var deadline time.Time
+	if opts.MaxDuration > 0 {
+		deadline = time.Now().Add(opts.MaxDuration)
+	}
+
+	for attempt := 0; ; attempt++ {
+		response, err := poll(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		switch response.State {
+		case StateInProgress:
+		case StateFailed:
+			return response, OperationStateError{State: string(response.State)}
+		default:
+			return response, nil
+		}
+
+		delay := opts.Delay(attempt, response.PollDelay)
+		if !deadline.IsZero() && time.Now().Add(delay).After(deadline) {
+			return response, PollingTimeoutError{MaxDuration: opts.MaxDuration}
+		}
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return response, ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
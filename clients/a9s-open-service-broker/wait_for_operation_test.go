@@ -0,0 +1,201 @@
+/*
+Copyright 2024 Klutch Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v2
+
+import (
+	"context"
+	"errors"
+	"math"
+	"net/http"
+	"testing"
+	"time"
+)
+
+// sequencedHTTP returns a doRequestFunc that answers the n-th request with
+// the n-th reaction, repeating the last reaction once all are used up.
+func sequencedHTTP(reactions ...httpReaction) (doRequestFunc, *int) {
+	calls := 0
+	return func(request *http.Request) (*http.Response, error) {
+		reaction := reactions[min(calls, len(reactions)-1)]
+		calls++
+		return &http.Response{
+			StatusCode: reaction.status,
+			Header:     reaction.header,
+			Body:       closer(reaction.body),
+		}, reaction.err
+	}, &calls
+}
+
+func fastWaitOptions() *WaitOptions {
+	return &WaitOptions{
+		InitialInterval: time.Millisecond,
+		MaxInterval:     5 * time.Millisecond,
+		Multiplier:      2,
+	}
+}
+
+func TestWaitForOperation(t *testing.T) {
+	inProgress := httpReaction{status: http.StatusOK, body: inProgressLastOperationResponseBody}
+
+	cases := []struct {
+		name             string
+		reactions        []httpReaction
+		opts             *WaitOptions
+		expectedResponse *LastOperationResponse
+		expectedErr      error
+		expectedCalls    int
+	}{
+		{
+			name: "succeeds after polling",
+			reactions: []httpReaction{
+				inProgress,
+				inProgress,
+				{status: http.StatusOK, body: successLastOperationResponseBody},
+			},
+			opts:             fastWaitOptions(),
+			expectedResponse: successLastOperationResponse(),
+			expectedCalls:    3,
+		},
+		{
+			name: "failed operation",
+			reactions: []httpReaction{
+				inProgress,
+				{status: http.StatusOK, body: failedLastOperationResponseBody},
+			},
+			opts:             fastWaitOptions(),
+			expectedResponse: failedLastOperationResponse(),
+			expectedErr:      OperationStateError{State: string(StateFailed)},
+			expectedCalls:    2,
+		},
+		{
+			name: "gone is returned as is",
+			reactions: []httpReaction{
+				inProgress,
+				{status: http.StatusGone, body: "{}"},
+			},
+			opts:          fastWaitOptions(),
+			expectedErr:   HTTPStatusCodeError{StatusCode: http.StatusGone},
+			expectedCalls: 2,
+		},
+		{
+			name:      "broker suggested delay exceeds maximum duration",
+			reactions: []httpReaction{{status: http.StatusOK, body: inProgressLastOperationResponseBody, header: http.Header{PollingDelayHeader: {"60"}}}},
+			opts: &WaitOptions{
+				InitialInterval: time.Millisecond,
+				MaxDuration:     time.Second,
+			},
+			expectedResponse: &LastOperationResponse{
+				State:       StateInProgress,
+				Description: strPtr("test description"),
+				PollDelay:   durationPtr(60 * time.Second),
+			},
+			expectedErr:   PollingTimeoutError{MaxDuration: time.Second},
+			expectedCalls: 1,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			klient := newTestClient(t, tc.name, LatestAPIVersion(), false, httpChecks{}, httpReaction{})
+			var calls *int
+			klient.doRequestFunc, calls = sequencedHTTP(tc.reactions...)
+
+			response, err := WaitForOperation(context.Background(), klient, defaultLastOperationRequest(), tc.opts)
+
+			doResponseChecks(t, tc.name, response, err, tc.expectedResponse, "", tc.expectedErr)
+			if tc.expectedErr != nil && err == nil {
+				t.Errorf("%v: expected error %v, got none", tc.name, tc.expectedErr)
+			}
+			if *calls != tc.expectedCalls {
+				t.Errorf("%v: expected %d polls, got %d", tc.name, tc.expectedCalls, *calls)
+			}
+		})
+	}
+}
+
+func TestWaitForOperationHonoursContext(t *testing.T) {
+	klient := newTestClient(t, "context", LatestAPIVersion(), false, httpChecks{}, httpReaction{})
+	klient.doRequestFunc, _ = sequencedHTTP(httpReaction{status: http.StatusOK, body: inProgressLastOperationResponseBody})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	_, err := WaitForOperation(ctx, klient, defaultLastOperationRequest(), &WaitOptions{InitialInterval: time.Hour})
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected %v, got %v", context.DeadlineExceeded, err)
+	}
+}
+
+func TestWaitForBindingOperation(t *testing.T) {
+	klient := newTestClient(t, "binding", LatestAPIVersion(), false, httpChecks{}, httpReaction{})
+	var calls *int
+	klient.doRequestFunc, calls = sequencedHTTP(
+		httpReaction{status: http.StatusOK, body: inProgressLastOperationResponseBody, header: http.Header{PollingDelayHeader: {"1"}}},
+		httpReaction{status: http.StatusOK, body: successLastOperationResponseBody},
+	)
+
+	start := time.Now()
+	response, err := WaitForBindingOperation(context.Background(), klient, defaultBindingLastOperationRequest(), fastWaitOptions())
+	doResponseChecks(t, "binding", response, err, successLastOperationResponse(), "", nil)
+
+	if *calls != 2 {
+		t.Errorf("expected 2 polls, got %d", *calls)
+	}
+	if elapsed := time.Since(start); elapsed < time.Second {
+		t.Errorf("expected the broker suggested delay of 1s to be honoured, waited %v", elapsed)
+	}
+}
+
+func TestWaitOptionsDelay(t *testing.T) {
+	opts := &WaitOptions{
+		InitialInterval: time.Second,
+		MaxInterval:     5 * time.Second,
+		Multiplier:      2,
+	}
+
+	cases := []struct {
+		name      string
+		attempt   int
+		suggested *time.Duration
+		expected  time.Duration
+	}{
+		{name: "first attempt", attempt: 0, expected: time.Second},
+		{name: "exponential growth", attempt: 2, expected: 4 * time.Second},
+		{name: "capped at max interval", attempt: 5, expected: 5 * time.Second},
+		{name: "broker suggestion wins", attempt: 5, suggested: durationPtr(42 * time.Second), expected: 42 * time.Second},
+	}
+
+	for _, tc := range cases {
+		if got := opts.Delay(tc.attempt, tc.suggested); got != tc.expected {
+			t.Errorf("%v: expected %v, got %v", tc.name, tc.expected, got)
+		}
+	}
+
+	uncapped := &WaitOptions{InitialInterval: time.Second, Multiplier: 2, Jitter: 0.2}
+	for _, attempt := range []int{64, 1100, math.MaxInt32} {
+		if got := uncapped.Delay(attempt, nil); got < time.Duration(math.MaxInt64/10*8) {
+			t.Errorf("attempt %d without max interval: expected about %v, got %v", attempt, time.Duration(math.MaxInt64), got)
+		}
+	}
+
+	jittered := &WaitOptions{InitialInterval: time.Second, Multiplier: 1, Jitter: 0.5}
+	for i := 0; i < 100; i++ {
+		if got := jittered.Delay(0, nil); got < 500*time.Millisecond || got > 1500*time.Millisecond {
+			t.Fatalf("jittered delay %v out of bounds", got)
+		}
+	}
+}
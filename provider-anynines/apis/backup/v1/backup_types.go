@@ -30,24 +30,34 @@ import (
 const (
 	// StatusQueued is the status that the a9s Backup Manager returns when queried about a backup
 	// that is queued for execution but hasn't begun yet.
+	//
+	// Deprecated: Use BackupStatusQueued of the a9s Backup Manager client.
 	StatusQueued = "queued"
 
 	// StatusRunning is the status that the a9s Backup Manager returns when queried about a backup
 	// that is currently being executed.
+	//
+	// Deprecated: Use BackupStatusRunning of the a9s Backup Manager client.
 	StatusRunning = "running"
 
 	// StatusDone is the status that the a9s Backup Manager returns when queried about a backup that
 	// has been successfully executed.
+	//
+	// Deprecated: Use BackupStatusDone of the a9s Backup Manager client.
 	StatusDone = "done"
 
 	// StatusFailed is the status that the a9s Backup Manager returns when queried about a backup
 	// whose execution was not successful.
+	//
+	// Deprecated: Use BackupStatusFailed of the a9s Backup Manager client.
 	StatusFailed = "failed"
 
 	// StatusDeleted is the status that the a9s Backup Manager returns when queried about a backup
 	// whose backup file has been deleted.
 	// This means the metadata of the backup is still intact on the a9s Backup Manager but the
 	// contents of the backup have been removed from the cloud storage where it was hosted.
+	//
+	// Deprecated: Use BackupStatusDeleted of the a9s Backup Manager client.
 	StatusDeleted = "deleted"
 
 	errBackupNotFound = utilerr.PlainUserErr("backup was not found")
@@ -87,13 +97,12 @@ type BackupObservation struct {
 	// "running", "done", "failed" or "deleted".
 	Status string `json:"status,omitempty"`
 
-	// TriggeredAt is the timestamp from when the backup was triggered in the format
-	// "YYYY-MM-DDThh:mm:ss.sssZ", e.g. "2023-05-01T01:30:00.742Z"
-	TriggeredAt string `json:"triggered_at,omitempty"`
+	// TriggeredAt is the time the backup was triggered.
+	TriggeredAt *metav1.Time `json:"triggered_at,omitempty"`
 
-	// FinishedAt is the timestamp from when the backup was finished in the format
-	// "YYYY-MM-DDThh:mm:ss.sssZ", e.g. "2023-05-01T01:30:28.300Z"
-	FinishedAt string `json:"finished_at,omitempty"`
+	// FinishedAt is the time the backup was done or failed. It is unset while the backup is
+	// queued or running.
+	FinishedAt *metav1.Time `json:"finished_at,omitempty"`
 
 	// Downloadable indicates whether the the files that constitute this backup can be downloaded
 	// from the cloud storage provider where it is hosted or not.
@@ -121,6 +130,8 @@ type BackupStatus struct {
 // +kubebuilder:printcolumn:name="READY",type="string",JSONPath=".status.conditions[?(@.type=='Ready')].status"
 // +kubebuilder:printcolumn:name="SYNCED",type="string",JSONPath=".status.conditions[?(@.type=='Synced')].status"
 // +kubebuilder:printcolumn:name="EXTERNAL-NAME",type="string",JSONPath=".metadata.annotations.crossplane\\.io/external-name"
+// +kubebuilder:printcolumn:name="STATUS",type="string",JSONPath=".status.atProvider.status"
+// +kubebuilder:printcolumn:name="FINISHED",type="date",JSONPath=".status.atProvider.finished_at"
 // +kubebuilder:printcolumn:name="AGE",type="date",JSONPath=".metadata.creationTimestamp"
 // +kubebuilder:subresource:status
 // +kubebuilder:resource:scope=Cluster,categories={crossplane,managed,anynines}
@@ -0,0 +1,202 @@
+/*
+Copyright 2024 Klutch Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	"reflect"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	"github.com/crossplane/crossplane-runtime/pkg/reference"
+	"github.com/crossplane/crossplane-runtime/pkg/resource"
+)
+
+const (
+	// ExportStateExporting is the state of a BackupExport whose backup is
+	// being streamed to its destination.
+	ExportStateExporting = "exporting"
+
+	// ExportStateDone is the state of a BackupExport whose backup has been
+	// written to its destination completely.
+	ExportStateDone = "done"
+)
+
+// BackupExportParameters are the configurable fields of a BackupExport.
+// +kubebuilder:validation:XValidation:rule="has(self.backupName) || has(self.backupRef) || has(self.backupSelector)",message="one of backupName, backupRef and backupSelector must be set"
+type BackupExportParameters struct {
+	// BackupName is the name of the Backup managed resource to export. The
+	// backup must be done and downloadable.
+	// +crossplane:generate:reference:type=Backup
+	// +crossplane:generate:reference:extractor=BackupName()
+	// +crossplane:generate:reference:refFieldName=BackupRef
+	// +crossplane:generate:reference:selectorFieldName=BackupSelector
+	// +optional
+	BackupName string `json:"backupName,omitempty"`
+
+	// BackupRef references the Backup managed resource to export and sets
+	// BackupName.
+	// +optional
+	BackupRef *xpv1.Reference `json:"backupRef,omitempty"`
+
+	// BackupSelector selects a reference to the Backup managed resource to
+	// export and sets BackupRef.
+	// +optional
+	BackupSelector *xpv1.Selector `json:"backupSelector,omitempty"`
+
+	// Destination is where the backup is exported to.
+	Destination BackupExportDestination `json:"destination"`
+}
+
+// BackupName extracts the name of a referenced Backup, since the name of a
+// Backup managed resource differs from its external name.
+func BackupName() reference.ExtractValueFn {
+	return func(mg resource.Managed) string {
+		return mg.GetName()
+	}
+}
+
+// BackupExportDestination is where a backup is exported to. Exactly one of
+// its fields must be set.
+// +kubebuilder:validation:XValidation:rule="has(self.s3) != has(self.volume)",message="exactly one of s3 and volume must be set"
+type BackupExportDestination struct {
+	// S3 exports the backup to a bucket of an S3-compatible object storage.
+	S3 *S3Destination `json:"s3,omitempty"`
+
+	// Volume exports the backup to a PersistentVolumeClaim.
+	Volume *VolumeDestination `json:"volume,omitempty"`
+}
+
+// S3Destination is an object in a bucket of an S3-compatible object storage.
+type S3Destination struct {
+	// Endpoint is the host and optional port of the object storage, e.g.
+	// "s3.eu-central-1.amazonaws.com" or "minio.example.com:9000".
+	Endpoint string `json:"endpoint"`
+
+	// Insecure connects to the object storage with HTTP instead of HTTPS.
+	Insecure bool `json:"insecure,omitempty"`
+
+	// Region is the region of the bucket.
+	// +kubebuilder:default:="us-east-1"
+	Region string `json:"region,omitempty"`
+
+	// Bucket is the name of the bucket.
+	Bucket string `json:"bucket"`
+
+	// Key is the key of the object the backup is written to. It defaults to
+	// "<instance ID>/<backup ID>".
+	Key string `json:"key,omitempty"`
+
+	// CredentialsSecretRef references the secret that contains the access
+	// key of the object storage in the keys "accessKeyID" and
+	// "secretAccessKey".
+	CredentialsSecretRef xpv1.SecretReference `json:"credentialsSecretRef"`
+}
+
+// VolumeDestination is a file on a PersistentVolumeClaim. The claim must be
+// mounted into the pod of the provider at "<volume root>/<claim name>", where
+// the volume root is set by the --backup-export-volume-root flag of the
+// provider and defaults to "/exports".
+type VolumeDestination struct {
+	// ClaimName is the name of the PersistentVolumeClaim.
+	// +kubebuilder:validation:Pattern:=`^[a-z0-9]([-a-z0-9.]*[a-z0-9])?$`
+	ClaimName string `json:"claimName"`
+
+	// Path is the path of the file within the volume the backup is written
+	// to. It defaults to "<instance ID>/<backup ID>".
+	Path string `json:"path,omitempty"`
+}
+
+// BackupExportObservation are the observable fields of a BackupExport.
+type BackupExportObservation struct {
+	// InstanceID is the ID of the data service instance the backup was taken
+	// from.
+	InstanceID string `json:"instanceId,omitempty"`
+
+	// BackupID is the ID of the exported backup.
+	BackupID *int `json:"backupId,omitempty"`
+
+	// State is the state of the export, "exporting" or "done".
+	// +kubebuilder:validation:Enum:=exporting;done
+	State string `json:"state,omitempty"`
+
+	// Location is where the backup is exported to, e.g.
+	// "s3://bucket/instance-id/1" or "pvc://claim-name/instance-id/1".
+	Location string `json:"location,omitempty"`
+
+	// SizeInBytes is the number of bytes of the backup exported so far.
+	SizeInBytes int64 `json:"size,omitempty"`
+
+	// Checksum is the hex-encoded SHA-256 checksum of the exported backup.
+	Checksum string `json:"checksum,omitempty"`
+
+	// FinishedAt is the time the export was done.
+	FinishedAt *metav1.Time `json:"finishedAt,omitempty"`
+}
+
+// A BackupExportSpec defines the desired state of a BackupExport.
+type BackupExportSpec struct {
+	xpv1.ResourceSpec `json:",inline"`
+	ForProvider       BackupExportParameters `json:"forProvider"`
+}
+
+// A BackupExportStatus represents the observed state of a BackupExport.
+type BackupExportStatus struct {
+	xpv1.ResourceStatus `json:",inline"`
+	AtProvider          BackupExportObservation `json:"atProvider,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// A BackupExport streams the file of a finished Backup to an S3-compatible
+// bucket or a PersistentVolumeClaim. Deleting a BackupExport stops a running
+// export but keeps the exported file.
+// +kubebuilder:printcolumn:name="READY",type="string",JSONPath=".status.conditions[?(@.type=='Ready')].status"
+// +kubebuilder:printcolumn:name="SYNCED",type="string",JSONPath=".status.conditions[?(@.type=='Synced')].status"
+// +kubebuilder:printcolumn:name="LOCATION",type="string",JSONPath=".status.atProvider.location"
+// +kubebuilder:printcolumn:name="AGE",type="date",JSONPath=".metadata.creationTimestamp"
+// +kubebuilder:subresource:status
+// +kubebuilder:resource:scope=Cluster,categories={crossplane,managed,anynines}
+type BackupExport struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   BackupExportSpec   `json:"spec"`
+	Status BackupExportStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// BackupExportList contains a list of BackupExport
+type BackupExportList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []BackupExport `json:"items"`
+}
+
+// BackupExport type metadata.
+var (
+	BackupExportKind             = reflect.TypeOf(BackupExport{}).Name()
+	BackupExportGroupKind        = schema.GroupKind{Group: Group, Kind: BackupExportKind}.String()
+	BackupExportKindAPIVersion   = BackupExportKind + "." + SchemeGroupVersion.String()
+	BackupExportGroupVersionKind = SchemeGroupVersion.WithKind(BackupExportKind)
+)
+
+func init() {
+	SchemeBuilder.Register(&BackupExport{}, &BackupExportList{})
+}
@@ -4,6 +4,15 @@ package v1
 
 import resource "github.com/crossplane/crossplane-runtime/pkg/resource"
 
+// GetItems of this BackupExportList.
+func (l *BackupExportList) GetItems() []resource.Managed {
+	items := make([]resource.Managed, len(l.Items))
+	for i := range l.Items {
+		items[i] = &l.Items[i]
+	}
+	return items
+}
+
 // GetItems of this BackupList.
 func (l *BackupList) GetItems() []resource.Managed {
 	items := make([]resource.Managed, len(l.Items))
@@ -0,0 +1,36 @@
+// Code generated by angryjet. DO NOT EDIT.
+
+package v1
+
+import (
+	"context"
+	reference "github.com/crossplane/crossplane-runtime/pkg/reference"
+	errors "github.com/pkg/errors"
+	client "sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// ResolveReferences of this BackupExport.
+func (mg *BackupExport) ResolveReferences(ctx context.Context, c client.Reader) error {
+	r := reference.NewAPIResolver(c, mg)
+
+	var rsp reference.ResolutionResponse
+	var err error
+
+	rsp, err = r.Resolve(ctx, reference.ResolutionRequest{
+		CurrentValue: mg.Spec.ForProvider.BackupName,
+		Extract:      BackupName(),
+		Reference:    mg.Spec.ForProvider.BackupRef,
+		Selector:     mg.Spec.ForProvider.BackupSelector,
+		To: reference.To{
+			List:    &BackupList{},
+			Managed: &Backup{},
+		},
+	})
+	if err != nil {
+		return errors.Wrap(err, "mg.Spec.ForProvider.BackupName")
+	}
+	mg.Spec.ForProvider.BackupName = rsp.ResolvedValue
+	mg.Spec.ForProvider.BackupRef = rsp.ResolvedReference
+
+	return nil
+}
@@ -28,18 +28,28 @@ import (
 const (
 	// StatusQueued is the status that the a9s Backup Manager returns when queried about a restore
 	// that is queued for execution but hasn't begun yet.
+	//
+	// Deprecated: Use RestoreStatusQueued of the a9s Backup Manager client.
 	StatusQueued = "queued"
 	// StatusRunning is the status that the a9s Backup Manager returns when queried about a restore
 	// that is currently being executed.
+	//
+	// Deprecated: Use RestoreStatusRunning of the a9s Backup Manager client.
 	StatusRunning = "running"
 	// StatusDone is the status that the a9s Backup Manager returns when queried about a restore that
 	// has been successfully executed.
+	//
+	// Deprecated: Use RestoreStatusDone of the a9s Backup Manager client.
 	StatusDone = "done"
 	// StatusFailed is the status that the a9s Backup Manager returns when queried about a restore
 	// whose execution was not successful.
+	//
+	// Deprecated: Use RestoreStatusFailed of the a9s Backup Manager client.
 	StatusFailed = "failed"
 	// StatusDeleted is the status that the a9s Backup Manager returns when queried about a restore
 	// which was deleted.
+	//
+	// Deprecated: Use RestoreStatusDeleted of the a9s Backup Manager client.
 	StatusDeleted = "deleted"
 )
 
@@ -62,12 +72,11 @@ type RestoreObservation struct {
 	// e.g. queued, running, done, failed, deleted.
 	// +kubebuilder:validation:Enum:=queued;running;done;failed;deleted
 	State string `json:"state,omitempty"`
-	// TriggeredAt represents the timestamp of when the restore was
-	// initiated(YYYY-MM-DDThh:mm:ss.sssZ), e.g. 2023-05-01T01:30:28.300Z.
-	TriggeredAt string `json:"triggeredAt,omitempty"`
-	// FinishedAt represents the timestamp of when the restore was
-	// completed(YYYY-MM-DDThh:mm:ss.sssZ), e.g. 2023-05-01T01:30:28.300Z.
-	FinishedAt string `json:"finishedAt,omitempty"`
+	// TriggeredAt is the time the restore was triggered.
+	TriggeredAt *metav1.Time `json:"triggeredAt,omitempty"`
+	// FinishedAt is the time the restore was done or failed. It is unset
+	// while the restore is queued or running.
+	FinishedAt *metav1.Time `json:"finishedAt,omitempty"`
 }
 
 // A RestoreSpec defines the desired state of a Restore.
@@ -89,6 +98,8 @@ type RestoreStatus struct {
 // +kubebuilder:printcolumn:name="READY",type="string",JSONPath=".status.conditions[?(@.type=='Ready')].status"
 // +kubebuilder:printcolumn:name="SYNCED",type="string",JSONPath=".status.conditions[?(@.type=='Synced')].status"
 // +kubebuilder:printcolumn:name="EXTERNAL-NAME",type="string",JSONPath=".metadata.annotations.crossplane\\.io/external-name"
+// +kubebuilder:printcolumn:name="STATE",type="string",JSONPath=".status.atProvider.state"
+// +kubebuilder:printcolumn:name="FINISHED",type="date",JSONPath=".status.atProvider.finishedAt"
 // +kubebuilder:printcolumn:name="AGE",type="date",JSONPath=".metadata.creationTimestamp"
 // +kubebuilder:subresource:status
 // +kubebuilder:resource:scope=Cluster,categories={crossplane,managed,anynines}
@@ -80,6 +80,14 @@ func (in *RestoreObservation) DeepCopyInto(out *RestoreObservation) {
 		*out = new(int)
 		**out = **in
 	}
+	if in.TriggeredAt != nil {
+		in, out := &in.TriggeredAt, &out.TriggeredAt
+		*out = (*in).DeepCopy()
+	}
+	if in.FinishedAt != nil {
+		in, out := &in.FinishedAt, &out.FinishedAt
+		*out = (*in).DeepCopy()
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RestoreObservation.
@@ -23,6 +23,7 @@ import (
 	"k8s.io/apimachinery/pkg/runtime/schema"
 
 	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	"github.com/crossplane/crossplane-runtime/pkg/meta"
 )
 
 type ServiceBindingParameters struct {
@@ -65,6 +66,31 @@ type ServiceBindingParameters struct {
 	// Credentials is a free-form hash of credentials that can be used by
 	// applications or users to access the service.
 	Credentials map[string]string `json:"credentials,omitempty"`
+
+	// Rotation configures the periodic rotation of the credentials of the
+	// binding. Independently of it, a rotation is triggered by setting the
+	// anynines.crossplane.io/rotate-credentials annotation to a new value.
+	// +optional
+	Rotation *RotationPolicy `json:"rotation,omitempty"`
+}
+
+// RotationPolicy configures the rotation of the credentials of a binding.
+// Credentials are rotated by creating a successor binding, publishing its
+// credentials to the connection secret and unbinding the predecessor binding
+// once the grace period is over, so that applications can pick up the new
+// credentials before the old ones stop working.
+type RotationPolicy struct {
+	// Interval is the time after which the credentials are rotated, measured
+	// from the creation of the binding or its last rotation. If the service
+	// broker reports a time before which the credentials should be renewed,
+	// they are rotated at that time at the latest.
+	// +optional
+	Interval *metav1.Duration `json:"interval,omitempty"`
+
+	// GracePeriod is the time the predecessor binding is kept after the
+	// credentials of its successor are published. Defaults to 1h.
+	// +optional
+	GracePeriod *metav1.Duration `json:"gracePeriod,omitempty"`
 }
 
 // BindResource contains data for platform resources associated with a
@@ -109,6 +135,26 @@ type ServiceBindingObservation struct {
 
 	// ConnectionDetails is a struct that contains the network details of the data service instance.
 	ConnectionDetails []ConnectionDetails `json:"connectionDetails,omitempty"`
+
+	// PredecessorBindingID is the ID of the binding replaced by the last
+	// rotation, until it is unbound.
+	// +optional
+	PredecessorBindingID string `json:"predecessorBindingID,omitempty"`
+
+	// PredecessorUnbindTime is the time at which the predecessor binding is
+	// unbound.
+	// +optional
+	PredecessorUnbindTime *metav1.Time `json:"predecessorUnbindTime,omitempty"`
+
+	// LastRotationTime is the time the credentials were last rotated.
+	// +optional
+	LastRotationTime *metav1.Time `json:"lastRotationTime,omitempty"`
+
+	// RotationTrigger is the value of the
+	// anynines.crossplane.io/rotate-credentials annotation the last rotation
+	// was triggered with.
+	// +optional
+	RotationTrigger string `json:"rotationTrigger,omitempty"`
 }
 
 // ConnectionDetails contains the network details required for connecting to the data service instance.
@@ -176,6 +222,18 @@ func init() {
 	SchemeBuilder.Register(&ServiceBinding{}, &ServiceBindingList{})
 }
 
+// CurrentBindingID returns the ID of the binding at the service broker. It is
+// the external name of the ServiceBinding, which is set to the UID when the
+// binding is created and to the ID of the successor when its credentials are
+// rotated. Until then the external name is the name of the ServiceBinding, as
+// set by the managed reconciler, and the binding ID is the UID.
+func (sb *ServiceBinding) CurrentBindingID() string {
+	if name := meta.GetExternalName(sb); name != "" && name != sb.GetName() {
+		return name
+	}
+	return string(sb.UID)
+}
+
 func (sbo *ServiceBindingObservation) HasMissingFields() bool {
 	return sbo.InstanceID == "" ||
 		sbo.ServiceID == "" ||
@@ -82,6 +82,18 @@ type ServiceInstanceObservation struct {
 	InstanceID string `json:"instanceId,omitempty"`
 	// Parameters are the user parameters of the currently deployed instance.
 	Parameters map[string]apiextv1.JSON `json:"parameters,omitempty"`
+	// MaintenanceInfo is the maintenance info of the currently deployed
+	// instance, as reported by the a9s Service Broker.
+	MaintenanceInfo *MaintenanceInfo `json:"maintenanceInfo,omitempty"`
+}
+
+// MaintenanceInfo describes the version of the software a service instance is
+// deployed with.
+type MaintenanceInfo struct {
+	// Version is a semantic version of the maintenance info.
+	Version string `json:"version"`
+	// Description is a human readable summary of the changes of the version.
+	Description string `json:"description,omitempty"`
 }
 
 // A ServiceInstanceSpec defines the desired state of a ServiceInstance.
@@ -131,6 +143,12 @@ type ServiceInstanceParameters struct {
 	// OriginatingIdentity is the identity on the platform of the user making
 	// this request.
 	OriginatingIdentity *OriginatingIdentity `json:"originatingIdentity,omitempty"`
+	// AutoUpgrade enables upgrades of the instance to the maintenance info
+	// version of its plan in the service broker catalog. If it is false or
+	// unset, an available upgrade is only reported in the UpgradeAvailable
+	// condition.
+	// +optional
+	AutoUpgrade *bool `json:"autoUpgrade,omitempty"`
 }
 
 // Available options are:
@@ -37,7 +37,14 @@ const (
 
 // A ProviderConfigSpec defines the desired state of a ProviderConfig.
 type ProviderConfigSpec struct {
+	// Url is the URL of the service broker or backup manager.
 	Url string `json:"url"`
+	// FailoverURLs are the URLs of further endpoints of the same service
+	// broker or backup manager, in order of preference after Url. Requests
+	// are sent to the first healthy endpoint and fail over to the next one
+	// if an endpoint is unreachable.
+	// +kubebuilder:validation:Optional
+	FailoverURLs []string `json:"failoverUrls,omitempty"`
 	// ServiceType identifies the type of backend service.
 	// +kubebuilder:validation:Enum=servicebroker;backupmanager
 	ServiceType ServiceType `json:"serviceType"`
@@ -49,6 +56,43 @@ type ProviderConfigSpec struct {
 	// TLS configuration for the provider connection.
 	// +kubebuilder:validation:Optional
 	TLS *ProviderConfigTLS `json:"tls,omitempty"`
+	// RateLimit limits the requests the provider sends to the service broker
+	// or backup manager. Requests are not limited if it is not set.
+	// +kubebuilder:validation:Optional
+	RateLimit *RateLimit `json:"rateLimit,omitempty"`
+	// ResponseValidation is the mode in which responses of a service broker
+	// are validated against the Open Service Broker API specification.
+	// Disabled accepts every response, Log logs the violations of responses
+	// and accepts them, Strict rejects responses that violate the
+	// specification. Not supported by backup managers.
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Enum=Disabled;Log;Strict
+	// +kubebuilder:default=Disabled
+	ResponseValidation string `json:"responseValidation,omitempty"`
+}
+
+// RateLimit limits the rate and concurrency of requests. Requests that exceed
+// the limit are queued until the limit admits them or they time out. Requests
+// that are answered with 429 Too Many Requests are retried after the
+// Retry-After announced in the response.
+type RateLimit struct {
+	// RequestsPerSecond is the sustained number of requests per second.
+	// Zero means unlimited.
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Minimum=0
+	RequestsPerSecond int32 `json:"requestsPerSecond,omitempty"`
+	// Burst is the number of requests that may be sent at once. It defaults
+	// to RequestsPerSecond.
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Minimum=0
+	Burst int32 `json:"burst,omitempty"`
+	// MaxInFlight is the number of requests that may be in flight at the
+	// same time. Zero means unlimited. A request is in flight until its
+	// response is read completely, so a running BackupExport occupies one
+	// request for its whole download.
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Minimum=0
+	MaxInFlight int32 `json:"maxInFlight,omitempty"`
 }
 
 // ProviderCredentials required to authenticate.
@@ -57,8 +101,33 @@ type ProviderCredentials struct {
 	// +kubebuilder:validation:Enum=None;Secret;InjectedIdentity;Environment;Filesystem
 	Source xpv1.CredentialsSource `json:"source"`
 
+	// Username used for basic auth. Required unless OAuth2 is set.
+	// +kubebuilder:validation:Optional
 	Username xpv1.CommonCredentialSelectors `json:"username"`
+	// Password used for basic auth. Required unless OAuth2 is set.
+	// +kubebuilder:validation:Optional
 	Password xpv1.CommonCredentialSelectors `json:"password"`
+
+	// OAuth2 configures authentication with OAuth2 client credentials instead
+	// of basic auth. Only supported by service brokers.
+	// +kubebuilder:validation:Optional
+	OAuth2 *OAuth2Credentials `json:"oauth2,omitempty"`
+}
+
+// OAuth2Credentials configure the OAuth2 client credentials grant. The
+// provider fetches access tokens from the token URL and refreshes them before
+// they expire.
+type OAuth2Credentials struct {
+	// TokenURL is the URL of the token endpoint of the authorization server.
+	TokenURL string `json:"tokenURL"`
+	// Scopes to request for the access token.
+	// +kubebuilder:validation:Optional
+	Scopes []string `json:"scopes,omitempty"`
+
+	// ClientID is the OAuth2 client ID, read from Source.
+	ClientID xpv1.CommonCredentialSelectors `json:"clientID"`
+	// ClientSecret is the OAuth2 client secret, read from Source.
+	ClientSecret xpv1.CommonCredentialSelectors `json:"clientSecret"`
 }
 
 // ProviderConfigTLS contains TLS configuration for the provider connection.
@@ -84,6 +153,40 @@ type ProviderConfigStatus struct {
 	// by trying to reach the configured URL.
 	// +kubebuilder:validation:Optional
 	Health ProviderConfigHealth `json:"health"`
+
+	// APIVersion is the Open Service Broker API version negotiated with the
+	// service broker by the health check. Clients for this ProviderConfig use
+	// it instead of the latest API version the provider supports.
+	// +kubebuilder:validation:Optional
+	APIVersion string `json:"apiVersion,omitempty"`
+
+	// ActiveEndpoint is the URL of the endpoint requests are sent to, i.e.
+	// the first endpoint that was healthy in the last health check.
+	// +kubebuilder:validation:Optional
+	ActiveEndpoint string `json:"activeEndpoint,omitempty"`
+
+	// Endpoints contains the result of the last health check of each
+	// endpoint, in the order of the spec.
+	// +kubebuilder:validation:Optional
+	Endpoints []EndpointHealth `json:"endpoints,omitempty"`
+}
+
+// EndpointHealth is the result of the health check of an endpoint.
+type EndpointHealth struct {
+	// URL of the endpoint.
+	URL string `json:"url"`
+	// Healthy indicates if the health check of the endpoint was successful.
+	Healthy bool `json:"healthy"`
+	// Message contains a human-readable message with details about the
+	// health check result.
+	// +kubebuilder:validation:Optional
+	Message string `json:"message,omitempty"`
+}
+
+// Endpoints returns the URLs of the endpoints of the service broker or backup
+// manager in order of preference.
+func (s ProviderConfigSpec) Endpoints() []string {
+	return append([]string{s.Url}, s.FailoverURLs...)
 }
 
 type ProviderConfigHealth struct {
@@ -108,6 +211,8 @@ type ProviderConfigHealth struct {
 // +kubebuilder:printcolumn:name="AGE",type="date",JSONPath=".metadata.creationTimestamp"
 // +kubebuilder:printcolumn:name="SECRET-NAME",type="string",JSONPath=".spec.credentials.secretRef.name",priority=1
 // +kubebuilder:printcolumn:name="HEALTHY",type="boolean",JSONPath=".status.health.lastStatus"
+// +kubebuilder:printcolumn:name="API-VERSION",type="string",JSONPath=".status.apiVersion",priority=1
+// +kubebuilder:printcolumn:name="ACTIVE-ENDPOINT",type="string",JSONPath=".status.activeEndpoint",priority=1
 // +kubebuilder:resource:scope=Cluster
 type ProviderConfig struct {
 	metav1.TypeMeta   `json:",inline"`
@@ -29,6 +29,7 @@ import (
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/cache"
 	"sigs.k8s.io/controller-runtime/pkg/log/zap"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
 
 	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
 	"github.com/crossplane/crossplane-runtime/pkg/controller"
@@ -44,6 +45,8 @@ import (
 	anynines "github.com/anynines/klutchio/provider-anynines/internal/controller"
 	"github.com/anynines/klutchio/provider-anynines/internal/controller/features"
 	"github.com/anynines/klutchio/provider-anynines/pkg/healthz"
+	"github.com/anynines/klutchio/provider-anynines/pkg/originatingidentity"
+	"github.com/anynines/klutchio/provider-anynines/pkg/tracing"
 )
 
 func main() {
@@ -60,6 +63,15 @@ func main() {
 		namespace                  = app.Flag("namespace", "Namespace used to set as default scope in default secret store config.").Default("crossplane-system").Envar("POD_NAMESPACE").String()
 		enableExternalSecretStores = app.Flag("enable-external-secret-stores", "Enable support for ExternalSecretStores.").Default("false").Envar("ENABLE_EXTERNAL_SECRET_STORES").Bool()
 		enableManagementPolicies   = app.Flag("enable-management-policies", "Enable support for Management Policies.").Default("false").Envar("ENABLE_MANAGEMENT_POLICIES").Bool()
+
+		otlpEndpoint     = app.Flag("otlp-endpoint", "The host and port of the OTLP gRPC receiver traces are exported to. Traces are not exported if empty.").Default("").Envar("OTLP_ENDPOINT").String()
+		otlpInsecure     = app.Flag("otlp-insecure", "Disable TLS for the connection to the OTLP receiver.").Default("false").Envar("OTLP_INSECURE").Bool()
+		traceSampleRatio = app.Flag("trace-sample-ratio", "The fraction of traces started by the provider that are sampled.").Default("1").Envar("TRACE_SAMPLE_RATIO").Float64()
+
+		enableOriginatingIdentityWebhook = app.Flag("enable-originating-identity-webhook", "Serve the webhook that records the users who create or change resources, so that broker requests carry their originating identity.").Default("false").Envar("ENABLE_ORIGINATING_IDENTITY_WEBHOOK").Bool()
+		webhookTLSCertDir                = app.Flag("webhook-tls-cert-dir", "The directory of the TLS certificate and key of the webhook server.").Default("/tls/server").Envar("TLS_SERVER_CERTS_DIR").String()
+
+		backupExportVolumeRoot = app.Flag("backup-export-volume-root", "The directory the PersistentVolumeClaims that backups are exported to are mounted in, each at a subdirectory named like the claim.").Default("/exports").Envar("BACKUP_EXPORT_VOLUME_ROOT").String()
 	)
 	kingpin.MustParse(app.Parse(os.Args[1:]))
 
@@ -76,6 +88,13 @@ func main() {
 		ctrl.SetLogger(logr.New(ctrllog.NullLogSink{}))
 	}
 
+	shutdownTracing, err := tracing.Setup(context.Background(), tracing.Config{
+		Endpoint:    *otlpEndpoint,
+		Insecure:    *otlpInsecure,
+		SampleRatio: *traceSampleRatio,
+	})
+	kingpin.FatalIfError(err, "Cannot set up tracing")
+
 	cfg, err := ctrl.GetConfig()
 	kingpin.FatalIfError(err, "Cannot get API server rest config")
 
@@ -96,6 +115,11 @@ func main() {
 		LeaderElectionResourceLock: resourcelock.LeasesResourceLock,
 		LeaseDuration:              func() *time.Duration { d := 60 * time.Second; return &d }(),
 		RenewDeadline:              func() *time.Duration { d := 50 * time.Second; return &d }(),
+
+		WebhookServer: webhook.NewServer(webhook.Options{
+			Port:    9443,
+			CertDir: *webhookTLSCertDir,
+		}),
 	})
 	kingpin.FatalIfError(err, "Cannot create controller manager")
 	kingpin.FatalIfError(apis.AddToScheme(mgr.GetScheme()), "Cannot add anynines APIs to scheme")
@@ -136,6 +160,24 @@ func main() {
 		log.Info("Alpha feature enabled", "flag", features.EnableAlphaManagementPolicies)
 	}
 
-	kingpin.FatalIfError(anynines.Setup(mgr, o), "Cannot setup anynines controllers")
-	kingpin.FatalIfError(mgr.Start(ctrl.SetupSignalHandler()), "Cannot start controller manager")
+	if *enableOriginatingIdentityWebhook {
+		// Service accounts of the provider namespace, i.e. Crossplane and
+		// the provider, propagate the recorded users to composed resources.
+		mgr.GetWebhookServer().Register(originatingidentity.WebhookPath, &webhook.Admission{
+			Handler: &originatingidentity.Webhook{TrustedNamespace: *namespace},
+		})
+		log.Info("Originating identity webhook enabled", "path", originatingidentity.WebhookPath)
+	}
+
+	kingpin.FatalIfError(anynines.Setup(mgr, o, *backupExportVolumeRoot), "Cannot setup anynines controllers")
+	err = mgr.Start(ctrl.SetupSignalHandler())
+
+	// Flush the spans of the last reconciles before exiting.
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := shutdownTracing(ctx); err != nil {
+		log.Info("Cannot flush pending spans", "error", err)
+	}
+
+	kingpin.FatalIfError(err, "Cannot start controller manager")
 }
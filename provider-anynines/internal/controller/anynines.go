@@ -21,6 +21,7 @@ import (
 	ctrl "sigs.k8s.io/controller-runtime"
 
 	"github.com/anynines/klutchio/provider-anynines/internal/controller/backup"
+	"github.com/anynines/klutchio/provider-anynines/internal/controller/backupexport"
 	"github.com/anynines/klutchio/provider-anynines/internal/controller/config"
 	"github.com/anynines/klutchio/provider-anynines/internal/controller/confighealth"
 	"github.com/anynines/klutchio/provider-anynines/internal/controller/restore"
@@ -29,14 +30,18 @@ import (
 )
 
 // Setup creates all anynines controllers with the supplied logger and adds them to
-// the supplied manager.
-func Setup(mgr ctrl.Manager, o controller.Options) error {
+// the supplied manager. Backups are exported to the PersistentVolumeClaims
+// mounted in exportVolumeRoot.
+func Setup(mgr ctrl.Manager, o controller.Options, exportVolumeRoot string) error {
 	for _, setup := range []func(ctrl.Manager, controller.Options) error{
 		config.Setup,
 		confighealth.Setup,
 		serviceinstance.Setup,
 		servicebinding.Setup,
 		backup.Setup,
+		func(mgr ctrl.Manager, o controller.Options) error {
+			return backupexport.Setup(mgr, o, exportVolumeRoot)
+		},
 		restore.Setup,
 	} {
 		if err := setup(mgr, o); err != nil {
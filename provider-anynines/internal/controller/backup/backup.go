@@ -43,6 +43,7 @@ import (
 	util "github.com/anynines/klutchio/provider-anynines/internal/controller/utils"
 	bkpclient "github.com/anynines/klutchio/provider-anynines/pkg/client/backupmanager"
 	"github.com/anynines/klutchio/provider-anynines/pkg/constants"
+	"github.com/anynines/klutchio/provider-anynines/pkg/tracing"
 	utilerr "github.com/anynines/klutchio/provider-anynines/pkg/utilerr"
 )
 
@@ -100,10 +101,13 @@ func Setup(mgr ctrl.Manager, o controller.Options) error {
 	r := managed.NewReconciler(mgr,
 		resource.ManagedKind(v1.BackupGroupVersionKind),
 		managed.WithExternalConnecter(utilerr.ConnectDecorator{
-			Connector: &connector{
-				kube:         mgr.GetClient(),
-				usage:        resource.NewProviderConfigUsageTracker(mgr.GetClient(), &apisv1.ProviderConfigUsage{}),
-				newServiceFn: bkpclient.NewBackupManagerServiceWithTLS,
+			Connector: tracing.ConnectDecorator{
+				Connector: &connector{
+					kube:         mgr.GetClient(),
+					usage:        resource.NewProviderConfigUsageTracker(mgr.GetClient(), &apisv1.ProviderConfigUsage{}),
+					newServiceFn: bkpclient.NewBackupManagerServiceWithOptions,
+				},
+				Kind: v1.BackupKind,
 			},
 			Logger: log,
 		}),
@@ -123,7 +127,7 @@ func Setup(mgr ctrl.Manager, o controller.Options) error {
 type connector struct {
 	kube         k8sclient.Client
 	usage        resource.Tracker
-	newServiceFn func(username, password []byte, url string, insecureSkipVerify bool, caBundle []byte, overrideServerName string) (bkpmgrclient.Client, error)
+	newServiceFn func(url string, opts ...bkpclient.Option) (bkpmgrclient.Client, error)
 }
 
 // Connect typically produces an ExternalClient by:
@@ -150,8 +154,16 @@ func (c *connector) Connect(ctx context.Context, mg resource.Managed) (managed.E
 	if err != nil {
 		return nil, err
 	}
+	if err := credentials.AssertBasicAuth(); err != nil {
+		return nil, err
+	}
 
-	svc, err := c.newServiceFn(credentials.Username, credentials.Password, pc.Spec.Url, credentials.InsecureSkipVerify, credentials.CABundle, credentials.OverrideServerName)
+	svc, err := c.newServiceFn(pc.Spec.Url,
+		bkpclient.WithBasicAuth(credentials.Username, credentials.Password),
+		bkpclient.WithTLS(credentials.InsecureSkipVerify, credentials.CABundle, credentials.OverrideServerName),
+		bkpclient.ForProviderConfig(pc),
+		bkpclient.WithLogger(ctrl.LoggerFrom(ctx)),
+	)
 	if err != nil {
 		return nil, fmt.Errorf("%s: %w", errNewClient, err)
 	}
@@ -227,7 +239,7 @@ func (c *External) Observe(ctx context.Context, mg resource.Managed) (managed.Ex
 		bkp.Status.AtProvider.BackupID = &bkpID
 	}
 
-	getBackupResponse, err := c.Client.GetBackup(&bkpmgrclient.GetBackupRequest{
+	getBackupResponse, err := c.Client.GetBackup(ctx, &bkpmgrclient.GetBackupRequest{
 		InstanceID: bkp.Status.AtProvider.InstanceID,
 		BackupID:   strconv.Itoa(*bkp.Status.AtProvider.BackupID),
 	})
@@ -243,7 +255,7 @@ func (c *External) Observe(ctx context.Context, mg resource.Managed) (managed.Ex
 		return managed.ExternalObservation{}, err
 	}
 
-	if bkp.Status.AtProvider.Status == v1.StatusDeleted {
+	if bkpmgrclient.BackupStatus(bkp.Status.AtProvider.Status) == bkpmgrclient.BackupStatusDeleted {
 		// When a backup is deleted the object might be still retrievable from
 		// the API depending on the deletion method used but it is in a
 		// deleted state. We need to return early here so that we don't
@@ -267,16 +279,16 @@ func (c *External) Observe(ctx context.Context, mg resource.Managed) (managed.Ex
 }
 
 func setConditions(bkp *v1.Backup) error {
-	switch bkp.Status.AtProvider.Status {
-	case v1.StatusQueued:
+	switch bkpmgrclient.BackupStatus(bkp.Status.AtProvider.Status) {
+	case bkpmgrclient.BackupStatusQueued:
 		bkp.SetConditions(xpv1.Creating())
-	case v1.StatusRunning:
+	case bkpmgrclient.BackupStatusRunning:
 		bkp.SetConditions(xpv1.Creating())
-	case v1.StatusDone:
+	case bkpmgrclient.BackupStatusDone:
 		bkp.SetConditions(xpv1.Available())
-	case v1.StatusFailed:
+	case bkpmgrclient.BackupStatusFailed:
 		bkp.SetConditions(xpv1.Unavailable().WithMessage("Backup has failed"))
-	case v1.StatusDeleted:
+	case bkpmgrclient.BackupStatusDeleted:
 		bkp.SetConditions(xpv1.Unavailable().WithMessage("Backup has been deleted"))
 	default:
 		return fmt.Errorf(errUnknownState, bkp.Status.AtProvider.Status)
@@ -306,7 +318,7 @@ func (c *External) Create(ctx context.Context, mg resource.Managed) (managed.Ext
 	// We asked the team maintaining the a9s Backup Manager to expand the Backup Manager's API so
 	// that we have the option of providing the BackupID ourselves, if that change has happened we
 	// will update this method to use the new API endpoint to prevent the aforementioned behavior.
-	response, err := c.Client.CreateBackup(&bkpmgrclient.CreateBackupRequest{
+	response, err := c.Client.CreateBackup(ctx, &bkpmgrclient.CreateBackupRequest{
 		InstanceID: bkp.Status.AtProvider.InstanceID,
 	})
 	if err != nil {
@@ -360,7 +372,7 @@ func (c *External) Delete(ctx context.Context, mg resource.Managed) (managed.Ext
 		}
 	}
 
-	_, err := c.Client.DeleteBackup(&bkpmgrclient.DeleteBackupRequest{
+	_, err := c.Client.DeleteBackup(ctx, &bkpmgrclient.DeleteBackupRequest{
 		InstanceID: bkp.Status.AtProvider.InstanceID,
 		BackupID:   bkp.Status.AtProvider.BackupID,
 	})
@@ -20,6 +20,7 @@ import (
 	"context"
 	"net/http"
 	"testing"
+	"time"
 
 	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
 	"github.com/crossplane/crossplane-runtime/pkg/reconciler/managed"
@@ -33,6 +34,7 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 
 	bkpmgrclient "github.com/anynines/klutchio/clients/a9s-backup-manager"
+	"github.com/anynines/klutchio/clients/a9s-backup-manager/bmtest"
 	fakebkpmgr "github.com/anynines/klutchio/clients/a9s-backup-manager/fake"
 	v1 "github.com/anynines/klutchio/provider-anynines/apis/backup/v1"
 	dsv1 "github.com/anynines/klutchio/provider-anynines/apis/serviceinstance/v1"
@@ -50,6 +52,11 @@ import (
 // https://github.com/golang/go/wiki/TestComments
 // https://github.com/crossplane/crossplane/blob/master/CONTRIBUTING.md#contributing-code
 
+var (
+	triggeredAt = time.Date(2023, 5, 1, 1, 30, 0, 742*int(time.Millisecond), time.UTC)
+	finishedAt  = time.Date(2023, 5, 1, 1, 30, 28, 300*int(time.Millisecond), time.UTC)
+)
+
 type (
 	BackupOption           func(*v1.Backup)
 	serviceInstanceOptions func(*dsv1.ServiceInstance)
@@ -102,8 +109,8 @@ func withStatusAtProvider() BackupOption {
 				InstanceID:   "23df2cf9-2ecc-414c-9333-6401f0c54365",
 				BackupID:     ptr.To[int](1),
 				SizeInBytes:  10,
-				TriggeredAt:  "2023-05-01T01:30:00.742Z",
-				FinishedAt:   "2023-05-01T01:30:28.300Z",
+				TriggeredAt:  &metav1.Time{Time: triggeredAt},
+				FinishedAt:   &metav1.Time{Time: finishedAt},
 				Downloadable: true,
 			},
 		}
@@ -140,9 +147,9 @@ func successfulRetrievalResponse(status string) *bkpmgrclient.GetBackupResponse
 	return &bkpmgrclient.GetBackupResponse{
 		BackupID:     ptr.To[int](1),
 		Size:         10,
-		Status:       status,
-		TriggeredAt:  "2023-05-01T01:30:00.742Z",
-		FinishedAt:   "2023-05-01T01:30:28.300Z",
+		Status:       bkpmgrclient.BackupStatus(status),
+		TriggeredAt:  triggeredAt,
+		FinishedAt:   finishedAt,
 		Downloadable: true,
 	}
 }
@@ -921,3 +928,90 @@ func TestDelete(t *testing.T) {
 		})
 	}
 }
+
+func TestBackupManagerLifecycle(t *testing.T) {
+	t.Parallel()
+
+	cases := map[string]struct {
+		fail          bool
+		wantStatus    string
+		wantCondition xpv1.Condition
+	}{
+		"successBackupIsDone": {
+			wantStatus:    v1.StatusDone,
+			wantCondition: xpv1.Available(),
+		},
+		"successBackupHasFailed": {
+			fail:          true,
+			wantStatus:    v1.StatusFailed,
+			wantCondition: xpv1.Unavailable().WithMessage("Backup has failed"),
+		},
+	}
+
+	for name, tc := range cases {
+		tc := tc
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			ctx := context.Background()
+			manager := bmtest.NewBackupManager(bmtest.Options{
+				Username:      "admin",
+				Password:      "secret",
+				PollsPerState: 1,
+			})
+			defer manager.Close()
+			manager.AddInstance("23df2cf9-2ecc-414c-9333-6401f0c54365")
+			if tc.fail {
+				manager.FailNextBackup()
+			}
+
+			klient, err := bkpmgrclient.NewClient(manager.ClientConfiguration())
+			if err != nil {
+				t.Fatalf("cannot create client: %v", err)
+			}
+			e := &bkpcontroller.External{Client: klient}
+			mr := newBackup(withStatusAtProviderInstanceID())
+
+			observe := func(step string) managed.ExternalObservation {
+				t.Helper()
+				observation, err := e.Observe(ctx, mr)
+				if err != nil {
+					t.Fatalf("%s: Observe(...): unexpected error: %v", step, err)
+				}
+				return observation
+			}
+
+			if observation := observe("before create"); observation.ResourceExists {
+				t.Fatalf("before create: expected the backup not to exist")
+			}
+			if _, err := e.Create(ctx, mr); err != nil {
+				t.Fatalf("Create(...): unexpected error: %v", err)
+			}
+
+			for _, status := range []string{v1.StatusQueued, v1.StatusRunning} {
+				if observation := observe("while " + status); !observation.ResourceExists || mr.Status.AtProvider.Status != status {
+					t.Errorf("while %s: unexpected observation %+v, status %q", status, observation, mr.Status.AtProvider.Status)
+				}
+				if diff := cmp.Diff(xpv1.Creating(), mr.GetCondition(xpv1.TypeReady), test.EquateConditions()); diff != "" {
+					t.Errorf("while %s: -want condition, +got condition:\n%s", status, diff)
+				}
+			}
+
+			observe("when finished")
+			if mr.Status.AtProvider.Status != tc.wantStatus {
+				t.Errorf("when finished: expected status %q, got %q", tc.wantStatus, mr.Status.AtProvider.Status)
+			}
+			if diff := cmp.Diff(tc.wantCondition, mr.GetCondition(xpv1.TypeReady), test.EquateConditions()); diff != "" {
+				t.Errorf("when finished: -want condition, +got condition:\n%s", diff)
+			}
+
+			if _, err := e.Delete(ctx, mr); err != nil {
+				t.Fatalf("Delete(...): unexpected error: %v", err)
+			}
+			if observation := observe("after delete"); observation.ResourceExists {
+				t.Errorf("after delete: expected the backup not to exist")
+			}
+		})
+	}
+}
@@ -0,0 +1,346 @@
+/*
+Copyright 2024 Klutch Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package backupexport
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	"github.com/crossplane/crossplane-runtime/pkg/controller"
+	"github.com/crossplane/crossplane-runtime/pkg/event"
+	"github.com/crossplane/crossplane-runtime/pkg/meta"
+	"github.com/crossplane/crossplane-runtime/pkg/ratelimiter"
+	"github.com/crossplane/crossplane-runtime/pkg/reconciler/managed"
+	"github.com/crossplane/crossplane-runtime/pkg/resource"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	k8sclient "sigs.k8s.io/controller-runtime/pkg/client"
+
+	bkpmgrclient "github.com/anynines/klutchio/clients/a9s-backup-manager"
+	v1 "github.com/anynines/klutchio/provider-anynines/apis/backup/v1"
+	apisv1 "github.com/anynines/klutchio/provider-anynines/apis/v1"
+	util "github.com/anynines/klutchio/provider-anynines/internal/controller/utils"
+	bkpclient "github.com/anynines/klutchio/provider-anynines/pkg/client/backupmanager"
+	"github.com/anynines/klutchio/provider-anynines/pkg/tracing"
+	utilerr "github.com/anynines/klutchio/provider-anynines/pkg/utilerr"
+)
+
+const (
+	// errNotBackupExport is the message of the error that is triggered when the managed resource
+	// handed to one of the controller's functions is not a BackupExport custom resource.
+	errNotBackupExport = "something went wrong with crossplane as managed resource reconciled is not a BackupExport custom resource, THIS SHOULD NOT HAPPEN"
+
+	// errExport is the message of the error that is triggered when an export fails.
+	errExport = "cannot export backup"
+	// errStartExport is the message of the error that is triggered when the controller fails
+	// to start an export during the Create() function.
+	errStartExport = "cannot start export of backup"
+
+	// errTrackPCUsage is the message of the error that is triggered when the controller fails to
+	// track that the managed resource is using a ProviderConfig.
+	errTrackPCUsage = "cannot track ProviderConfig usage"
+	// errGetPC is the message of the error that is triggered when the ProviderConfig handed
+	// to the controller's Connect() function is not retrievable.
+	errGetPC = "cannot get ProviderConfig"
+	// errNewClient is the message of the error that is triggered when the creation of a new client
+	// fails  during the Connect() function of the controller.
+	errNewClient = "cannot create new client"
+
+	// errBackupNotResolved is the message of the error that is triggered when
+	// neither the name nor a reference or selector of the backup to export is
+	// set.
+	errBackupNotResolved = utilerr.PlainUserErr("backup to export is not set, set backupName, backupRef or backupSelector")
+	// errBackupNotFound is the message of the error that is triggered when the
+	// backup to export does not exist.
+	errBackupNotFound = utilerr.PlainUserErr("backup was not found")
+	// errBackupNotDone is the message of the error that is triggered when the backup to export
+	// has not been taken successfully (yet).
+	errBackupNotDone = utilerr.PlainUserErr("backup is not done")
+	// errBackupNotDownloadable is the message of the error that is triggered when the backup to
+	// export cannot be downloaded from the a9s Backup Manager.
+	errBackupNotDownloadable = utilerr.PlainUserErr("backup is not downloadable, the credentials of the data service instance must have been updated before the backup was taken")
+)
+
+// Setup adds a controller that reconciles BackupExport managed resources.
+// Backups are exported to the PersistentVolumeClaims mounted in volumeRoot,
+// each at a subdirectory named like the claim.
+func Setup(mgr ctrl.Manager, o controller.Options, volumeRoot string) error {
+	name := managed.ControllerName(v1.BackupExportGroupKind)
+	cps := util.GetConnectionPublisher(mgr, o)
+
+	log := o.Logger.WithValues("controller", name)
+
+	r := managed.NewReconciler(mgr,
+		resource.ManagedKind(v1.BackupExportGroupVersionKind),
+		managed.WithExternalConnecter(utilerr.ConnectDecorator{
+			Connector: tracing.ConnectDecorator{
+				Connector: &connector{
+					kube:         mgr.GetClient(),
+					usage:        resource.NewProviderConfigUsageTracker(mgr.GetClient(), &apisv1.ProviderConfigUsage{}),
+					newServiceFn: bkpclient.NewBackupManagerServiceWithOptions,
+					exports:      newExports(),
+					volumeRoot:   volumeRoot,
+				},
+				Kind: v1.BackupExportKind,
+			},
+			Logger: log,
+		}),
+		managed.WithReferenceResolver(managed.NewAPISimpleReferenceResolver(mgr.GetClient())),
+		managed.WithLogger(log),
+		managed.WithRecorder(event.NewAPIRecorder(mgr.GetEventRecorderFor(name))),
+		managed.WithConnectionPublishers(cps...))
+
+	return ctrl.NewControllerManagedBy(mgr).
+		Named(name).
+		WithOptions(o.ForControllerRuntime()).
+		For(&v1.BackupExport{}).
+		Complete(ratelimiter.NewReconciler(name, r, o.GlobalRateLimiter))
+}
+
+// A connector is expected to produce an ExternalClient when its Connect method
+// is called.
+type connector struct {
+	kube         k8sclient.Client
+	usage        resource.Tracker
+	newServiceFn func(url string, opts ...bkpclient.Option) (bkpmgrclient.Client, error)
+	exports      *exports
+	volumeRoot   string
+}
+
+// Connect typically produces an ExternalClient by:
+// 1. Tracking that the managed resource is using a ProviderConfig.
+// 2. Getting the managed resource's ProviderConfig.
+// 3. Getting the credentials specified by the ProviderConfig.
+// 4. Using the credentials to form a client.
+func (c *connector) Connect(ctx context.Context, mg resource.Managed) (managed.ExternalClient, error) {
+	ex, ok := mg.(*v1.BackupExport)
+	if !ok {
+		return nil, errors.New(errNotBackupExport)
+	}
+
+	if err := c.usage.Track(ctx, mg); err != nil {
+		return nil, fmt.Errorf("%s: %w", errTrackPCUsage, err)
+	}
+
+	pc := &apisv1.ProviderConfig{}
+	if err := c.kube.Get(ctx, types.NamespacedName{Name: ex.GetProviderConfigReference().Name}, pc); err != nil {
+		return nil, fmt.Errorf("%s: %w", errGetPC, err)
+	}
+
+	credentials, err := util.GetCredentialsFromProvider(ctx, pc, c.kube)
+	if err != nil {
+		return nil, err
+	}
+	if err := credentials.AssertBasicAuth(); err != nil {
+		return nil, err
+	}
+
+	svc, err := c.newServiceFn(pc.Spec.Url,
+		bkpclient.WithBasicAuth(credentials.Username, credentials.Password),
+		bkpclient.WithTLS(credentials.InsecureSkipVerify, credentials.CABundle, credentials.OverrideServerName),
+		bkpclient.ForProviderConfig(pc),
+		bkpclient.WithLogger(ctrl.LoggerFrom(ctx)),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", errNewClient, err)
+	}
+
+	return &External{
+		Client:     svc,
+		Kube:       c.kube,
+		exports:    c.exports,
+		volumeRoot: c.volumeRoot,
+	}, nil
+}
+
+// NewExternal returns an External that tracks its own exports and exports
+// backups to the volumes mounted at volumeRoot.
+func NewExternal(client bkpmgrclient.Client, kube k8sclient.Client, volumeRoot string) *External {
+	return &External{
+		Client:     client,
+		Kube:       kube,
+		exports:    newExports(),
+		volumeRoot: volumeRoot,
+	}
+}
+
+// An External streams backups from the a9s Backup Manager to their export
+// destinations. Exports run in the background, since they usually take longer
+// than a reconcile may, and are observed until they are done.
+type External struct {
+	// Client downloads the backups from the a9s Backup Manager.
+	Client bkpmgrclient.Client
+
+	// Kube retrieves the Backup MRs to export and the credentials of
+	// S3 destinations.
+	Kube k8sclient.Client
+
+	exports    *exports
+	volumeRoot string
+}
+
+func (c *External) Observe(ctx context.Context, mg resource.Managed) (managed.ExternalObservation, error) {
+	ex, ok := mg.(*v1.BackupExport)
+	if !ok {
+		return managed.ExternalObservation{}, errors.New(errNotBackupExport)
+	}
+
+	running := c.exports.get(ex.GetUID())
+	if running == nil {
+		if ex.Status.AtProvider.State != v1.ExportStateDone || meta.WasDeleted(ex) {
+			// The export has not been started yet, or it was running when the
+			// provider restarted and is started again.
+			return managed.ExternalObservation{}, nil
+		}
+		ex.SetConditions(xpv1.Available())
+		return managed.ExternalObservation{ResourceExists: true, ResourceUpToDate: true}, nil
+	}
+
+	ex.Status.AtProvider.InstanceID = running.instanceID
+	ex.Status.AtProvider.BackupID = &running.backupID
+	ex.Status.AtProvider.Location = running.location
+	ex.Status.AtProvider.SizeInBytes = running.written.Load()
+
+	select {
+	case <-running.done:
+		c.exports.remove(ex.GetUID())
+		if running.err != nil {
+			// The export is started again by the next reconcile.
+			ex.Status.AtProvider.State = ""
+			return managed.ExternalObservation{}, fmt.Errorf("%s: %w", errExport, running.err)
+		}
+		ex.Status.AtProvider.State = v1.ExportStateDone
+		ex.Status.AtProvider.Checksum = running.checksum
+		ex.Status.AtProvider.FinishedAt = &metav1.Time{Time: running.finishedAt}
+		ex.SetConditions(xpv1.Available())
+	default:
+		ex.Status.AtProvider.State = v1.ExportStateExporting
+		ex.SetConditions(xpv1.Creating())
+	}
+
+	return managed.ExternalObservation{
+		ResourceExists: true,
+		// An export cannot be updated, changes of the destination only apply
+		// to the next export.
+		ResourceUpToDate: true,
+	}, nil
+}
+
+func (c *External) Create(ctx context.Context, mg resource.Managed) (managed.ExternalCreation, error) {
+	ex, ok := mg.(*v1.BackupExport)
+	if !ok {
+		return managed.ExternalCreation{}, errors.New(errNotBackupExport)
+	}
+
+	bkp, err := c.getBackupManagedResource(ctx, ex)
+	if err != nil {
+		return managed.ExternalCreation{}, fmt.Errorf("%s: %w", errStartExport, err)
+	}
+	switch {
+	case bkpmgrclient.BackupStatus(bkp.Status.AtProvider.Status) != bkpmgrclient.BackupStatusDone || bkp.Status.AtProvider.BackupID == nil:
+		return managed.ExternalCreation{}, fmt.Errorf("%s: %w", errStartExport, errBackupNotDone)
+	case !bkp.Status.AtProvider.Downloadable:
+		return managed.ExternalCreation{}, fmt.Errorf("%s: %w", errStartExport, errBackupNotDownloadable)
+	}
+
+	instanceID, backupID := bkp.Status.AtProvider.InstanceID, *bkp.Status.AtProvider.BackupID
+	dest, err := c.newDestination(ctx, ex.Spec.ForProvider.Destination, fmt.Sprintf("%s/%d", instanceID, backupID))
+	if err != nil {
+		return managed.ExternalCreation{}, fmt.Errorf("%s: %w", errStartExport, err)
+	}
+
+	log := ctrl.LoggerFrom(ctx).WithValues("location", dest.location())
+	c.exports.start(ex.GetUID(), &export{
+		instanceID: instanceID,
+		backupID:   backupID,
+		location:   dest.location(),
+	}, func(ctx context.Context, running *export) error {
+		log.Info("Exporting backup")
+		if err := c.export(ctx, running, dest); err != nil {
+			log.Info("Export of backup failed", "error", err.Error())
+			return err
+		}
+		log.Info("Exported backup", "size", running.written.Load(), "checksum", running.checksum)
+		return nil
+	})
+
+	return managed.ExternalCreation{}, nil
+}
+
+// export streams the backup from the a9s Backup Manager to dest. The download
+// counts against the MaxInFlight rate limit of the ProviderConfig until the
+// reader is closed.
+func (c *External) export(ctx context.Context, running *export, dest destination) error {
+	reader, err := c.Client.DownloadBackup(ctx, running.instanceID, strconv.Itoa(running.backupID))
+	if err != nil {
+		return utilerr.HandleHttpError(err)
+	}
+	defer reader.Close()
+
+	hash := sha256.New()
+	if err := dest.write(ctx, io.TeeReader(reader, io.MultiWriter(hash, progress{&running.written}))); err != nil {
+		return err
+	}
+	running.checksum = hex.EncodeToString(hash.Sum(nil))
+	return nil
+}
+
+func (c *External) Update(ctx context.Context, mg resource.Managed) (managed.ExternalUpdate, error) {
+	// Exports are never updated, as Observe always reports them up to date.
+	return managed.ExternalUpdate{}, nil
+}
+
+func (c *External) Delete(ctx context.Context, mg resource.Managed) (managed.ExternalDelete, error) {
+	ex, ok := mg.(*v1.BackupExport)
+	if !ok {
+		return managed.ExternalDelete{}, errors.New(errNotBackupExport)
+	}
+
+	// The exported file is kept, only a running export is stopped.
+	c.exports.stop(ex.GetUID())
+	return managed.ExternalDelete{}, nil
+}
+
+func (c *External) Disconnect(ctx context.Context) error {
+	// Unimplemented, required by newer versions of crossplane-runtime
+	return nil
+}
+
+// getBackupManagedResource retrieves the Backup MR to export, which the
+// reference resolver of the managed reconciler set in BackupName.
+func (c *External) getBackupManagedResource(ctx context.Context, ex *v1.BackupExport) (*v1.Backup, error) {
+	if ex.Spec.ForProvider.BackupName == "" {
+		return nil, errBackupNotResolved
+	}
+
+	bkp := &v1.Backup{}
+	if err := c.Kube.Get(ctx, types.NamespacedName{Name: ex.Spec.ForProvider.BackupName}, bkp); err != nil {
+		if kerrors.IsNotFound(err) {
+			return nil, errBackupNotFound
+		}
+		return nil, fmt.Errorf("cannot get backup to export: %w", err)
+	}
+	return bkp, nil
+}
@@ -0,0 +1,513 @@
+/*
+Copyright 2024 Klutch Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package backupexport_test
+
+import (
+	"bufio"
+	"context"
+	"crypto/md5"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	"github.com/crossplane/crossplane-runtime/pkg/reconciler/managed"
+	"github.com/google/go-cmp/cmp"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	fakebkpmgr "github.com/anynines/klutchio/clients/a9s-backup-manager/fake"
+	v1 "github.com/anynines/klutchio/provider-anynines/apis/backup/v1"
+	"github.com/anynines/klutchio/provider-anynines/internal/controller/backupexport"
+	"github.com/anynines/klutchio/provider-anynines/pkg/constants"
+)
+
+const (
+	instanceID    = "23df2cf9-2ecc-414c-9333-6401f0c54365"
+	backupContent = "pg_dump of the instance"
+)
+
+type backupExportOption func(*v1.BackupExport)
+
+func newBackupExport(modifiers ...backupExportOption) *v1.BackupExport {
+	ex := &v1.BackupExport{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "export-k7c2x",
+			UID:  "5b7a3c1e-0d4f-4c8e-9a61-2f1b7e6d9c40",
+		},
+		Spec: v1.BackupExportSpec{
+			ForProvider: v1.BackupExportParameters{
+				BackupName: "backup-x8d2f",
+				Destination: v1.BackupExportDestination{
+					Volume: &v1.VolumeDestination{ClaimName: "exports"},
+				},
+			},
+		},
+	}
+	for _, modifier := range modifiers {
+		modifier(ex)
+	}
+	return ex
+}
+
+func withDestination(destination v1.BackupExportDestination) backupExportOption {
+	return func(ex *v1.BackupExport) {
+		ex.Spec.ForProvider.Destination = destination
+	}
+}
+
+func withBackupName(name string) backupExportOption {
+	return func(ex *v1.BackupExport) {
+		ex.Spec.ForProvider.BackupName = name
+	}
+}
+
+func newBackup(status string, downloadable bool) *v1.Backup {
+	return &v1.Backup{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "backup-x8d2f",
+			Labels: map[string]string{
+				constants.LabelKeyClaimName:      "backup",
+				constants.LabelKeyClaimNamespace: "default",
+			},
+		},
+		Status: v1.BackupStatus{
+			AtProvider: v1.BackupObservation{
+				InstanceID:   instanceID,
+				BackupID:     ptr.To(1),
+				Status:       status,
+				Downloadable: downloadable,
+			},
+		},
+	}
+}
+
+func newS3Secret() *corev1.Secret {
+	return &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "s3", Namespace: "crossplane-system"},
+		Data: map[string][]byte{
+			"accessKeyID":     []byte("access-key"),
+			"secretAccessKey": []byte("secret-key"),
+		},
+	}
+}
+
+func newKube(objs ...runtime.Object) *fake.ClientBuilder {
+	sc := runtime.NewScheme()
+	sc.AddKnownTypes(v1.SchemeGroupVersion, &v1.Backup{}, &v1.BackupList{}, &v1.BackupExport{})
+	sc.AddKnownTypes(corev1.SchemeGroupVersion, &corev1.Secret{})
+	return fake.NewClientBuilder().WithRuntimeObjects(objs...).WithScheme(sc)
+}
+
+func downloadClient(content string, err error) *fakebkpmgr.Client {
+	return fakebkpmgr.NewFakeClient(&fakebkpmgr.FakeClientConfiguration{
+		DownloadBackupReaction: fakebkpmgr.DownloadBackupReaction{
+			Response: []byte(content),
+			Error:    err,
+		},
+	})
+}
+
+func TestCreate(t *testing.T) {
+	cases := map[string]struct {
+		export  *v1.BackupExport
+		objs    []runtime.Object
+		wantErr string
+	}{
+		"BackupNotResolved": {
+			export:  newBackupExport(withBackupName("")),
+			objs:    []runtime.Object{newBackup(v1.StatusDone, true)},
+			wantErr: "backup to export is not set",
+		},
+		"BackupNotFound": {
+			export:  newBackupExport(withBackupName("other")),
+			objs:    []runtime.Object{newBackup(v1.StatusDone, true)},
+			wantErr: "backup was not found",
+		},
+		"BackupNotDone": {
+			export:  newBackupExport(),
+			objs:    []runtime.Object{newBackup(v1.StatusRunning, true)},
+			wantErr: "backup is not done",
+		},
+		"BackupNotDownloadable": {
+			export:  newBackupExport(),
+			objs:    []runtime.Object{newBackup(v1.StatusDone, false)},
+			wantErr: "backup is not downloadable",
+		},
+		"PathLeavesVolume": {
+			export: newBackupExport(withDestination(v1.BackupExportDestination{
+				Volume: &v1.VolumeDestination{ClaimName: "exports", Path: "../other/backup"},
+			})),
+			objs:    []runtime.Object{newBackup(v1.StatusDone, true)},
+			wantErr: "must not leave the volume",
+		},
+		"MissingS3Credentials": {
+			export: newBackupExport(withDestination(v1.BackupExportDestination{
+				S3: &v1.S3Destination{
+					Endpoint:             "s3.example.com",
+					Bucket:               "backups",
+					CredentialsSecretRef: xpv1.SecretReference{Name: "s3", Namespace: "crossplane-system"},
+				},
+			})),
+			objs:    []runtime.Object{newBackup(v1.StatusDone, true)},
+			wantErr: "cannot get credentials of S3 destination",
+		},
+		"Started": {
+			export: newBackupExport(),
+			objs:   []runtime.Object{newBackup(v1.StatusDone, true)},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			e := backupexport.NewExternal(downloadClient(backupContent, nil), newKube(tc.objs...).Build(), t.TempDir())
+
+			_, err := e.Create(context.Background(), tc.export)
+			if tc.wantErr == "" && err != nil {
+				t.Fatalf("Create(...): unexpected error: %v", err)
+			}
+			if tc.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tc.wantErr)) {
+				t.Fatalf("Create(...): want error containing %q, got %v", tc.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestResolveReferences(t *testing.T) {
+	cases := map[string]struct {
+		export *v1.BackupExport
+		want   string
+	}{
+		"Reference": {
+			export: newBackupExport(withBackupName(""), func(ex *v1.BackupExport) {
+				ex.Spec.ForProvider.BackupRef = &xpv1.Reference{Name: "backup-x8d2f"}
+			}),
+			want: "backup-x8d2f",
+		},
+		"Selector": {
+			export: newBackupExport(withBackupName(""), func(ex *v1.BackupExport) {
+				ex.Spec.ForProvider.BackupSelector = &xpv1.Selector{
+					MatchLabels: map[string]string{constants.LabelKeyClaimName: "backup"},
+				}
+			}),
+			want: "backup-x8d2f",
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			kube := newKube(newBackup(v1.StatusDone, true)).Build()
+			if err := tc.export.ResolveReferences(context.Background(), kube); err != nil {
+				t.Fatalf("ResolveReferences(...): unexpected error: %v", err)
+			}
+			if diff := cmp.Diff(tc.want, tc.export.Spec.ForProvider.BackupName); diff != "" {
+				t.Errorf("ResolveReferences(...): -want backup name, +got backup name:\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestExportToVolume(t *testing.T) {
+	root := t.TempDir()
+	if err := os.Mkdir(filepath.Join(root, "exports"), 0o750); err != nil {
+		t.Fatal(err)
+	}
+	e := backupexport.NewExternal(downloadClient(backupContent, nil), newKube(newBackup(v1.StatusDone, true)).Build(), root)
+	ex := newBackupExport()
+
+	observation, err := e.Observe(context.Background(), ex)
+	if err != nil || observation.ResourceExists {
+		t.Fatalf("Observe(...) before Create: want export to not exist, got %+v, %v", observation, err)
+	}
+	if _, err := e.Create(context.Background(), ex); err != nil {
+		t.Fatalf("Create(...): unexpected error: %v", err)
+	}
+	observation, err = observeUntilDone(t, e, ex)
+	if err != nil {
+		t.Fatalf("Observe(...): unexpected error: %v", err)
+	}
+
+	want := managed.ExternalObservation{ResourceExists: true, ResourceUpToDate: true}
+	if diff := cmp.Diff(want, observation); diff != "" {
+		t.Errorf("Observe(...): -want, +got:\n%s", diff)
+	}
+	checksum := sha256.Sum256([]byte(backupContent))
+	wantStatus := v1.BackupExportObservation{
+		InstanceID:  instanceID,
+		BackupID:    ptr.To(1),
+		State:       v1.ExportStateDone,
+		Location:    "pvc://exports/" + instanceID + "/1",
+		SizeInBytes: int64(len(backupContent)),
+		Checksum:    hex.EncodeToString(checksum[:]),
+	}
+	if diff := cmp.Diff(wantStatus, ex.Status.AtProvider, cmpIgnoreFinishedAt()); diff != "" {
+		t.Errorf("status: -want, +got:\n%s", diff)
+	}
+	if ex.Status.AtProvider.FinishedAt == nil {
+		t.Error("want FinishedAt to be set")
+	}
+	if condition := ex.GetCondition(xpv1.TypeReady); condition.Reason != xpv1.ReasonAvailable {
+		t.Errorf("want export to be available, got %+v", condition)
+	}
+
+	exported, err := os.ReadFile(filepath.Join(root, "exports", instanceID, "1"))
+	if err != nil || string(exported) != backupContent {
+		t.Errorf("want %q to be exported, got %q, %v", backupContent, exported, err)
+	}
+
+	// Exports that are done stay done.
+	observation, err = e.Observe(context.Background(), ex)
+	if err != nil || !observation.ResourceExists {
+		t.Errorf("Observe(...) after export: want export to exist, got %+v, %v", observation, err)
+	}
+}
+
+func TestExportToS3(t *testing.T) {
+	s3 := newS3StandIn()
+	server := httptest.NewServer(s3)
+	defer server.Close()
+
+	ex := newBackupExport(withDestination(v1.BackupExportDestination{
+		S3: &v1.S3Destination{
+			Endpoint:             strings.TrimPrefix(server.URL, "http://"),
+			Insecure:             true,
+			Bucket:               "backups",
+			Key:                  "postgres/latest.dump",
+			CredentialsSecretRef: xpv1.SecretReference{Name: "s3", Namespace: "crossplane-system"},
+		},
+	}))
+	e := backupexport.NewExternal(downloadClient(backupContent, nil), newKube(newBackup(v1.StatusDone, true), newS3Secret()).Build(), t.TempDir())
+
+	if _, err := e.Create(context.Background(), ex); err != nil {
+		t.Fatalf("Create(...): unexpected error: %v", err)
+	}
+	if _, err := observeUntilDone(t, e, ex); err != nil {
+		t.Fatalf("Observe(...): unexpected error: %v", err)
+	}
+
+	if ex.Status.AtProvider.Location != "s3://backups/postgres/latest.dump" {
+		t.Errorf("unexpected location %q", ex.Status.AtProvider.Location)
+	}
+	if got := string(s3.object("backups/postgres/latest.dump")); got != backupContent {
+		t.Errorf("want %q to be uploaded, got %q", backupContent, got)
+	}
+}
+
+func TestFailedExportIsStartedAgain(t *testing.T) {
+	root := t.TempDir()
+	if err := os.Mkdir(filepath.Join(root, "exports"), 0o750); err != nil {
+		t.Fatal(err)
+	}
+	e := backupexport.NewExternal(downloadClient("", errors.New("connection refused")), newKube(newBackup(v1.StatusDone, true)).Build(), root)
+	ex := newBackupExport()
+
+	if _, err := e.Create(context.Background(), ex); err != nil {
+		t.Fatalf("Create(...): unexpected error: %v", err)
+	}
+	if _, err := observeUntilDone(t, e, ex); err == nil || !strings.Contains(err.Error(), "connection refused") {
+		t.Fatalf("Observe(...): want export to fail, got %v", err)
+	}
+	observation, err := e.Observe(context.Background(), ex)
+	if err != nil || observation.ResourceExists {
+		t.Errorf("Observe(...) after failure: want export to be created again, got %+v, %v", observation, err)
+	}
+	if entries, _ := os.ReadDir(filepath.Join(root, "exports", instanceID)); len(entries) != 0 {
+		t.Errorf("want no partial file to be left behind, got %v", entries)
+	}
+}
+
+func TestDeleteStopsExport(t *testing.T) {
+	root := t.TempDir()
+	if err := os.Mkdir(filepath.Join(root, "exports"), 0o750); err != nil {
+		t.Fatal(err)
+	}
+	e := backupexport.NewExternal(blockingClient{fakebkpmgr.NewFakeClient(nil)}, newKube(newBackup(v1.StatusDone, true)).Build(), root)
+	ex := newBackupExport()
+
+	if _, err := e.Create(context.Background(), ex); err != nil {
+		t.Fatalf("Create(...): unexpected error: %v", err)
+	}
+	if _, err := e.Observe(context.Background(), ex); err != nil || ex.Status.AtProvider.State != v1.ExportStateExporting {
+		t.Fatalf("Observe(...): want export to be running, got state %q, %v", ex.Status.AtProvider.State, err)
+	}
+
+	ex.SetDeletionTimestamp(&metav1.Time{Time: time.Now()})
+	if _, err := e.Delete(context.Background(), ex); err != nil {
+		t.Fatalf("Delete(...): unexpected error: %v", err)
+	}
+	observation, err := e.Observe(context.Background(), ex)
+	if err != nil || observation.ResourceExists {
+		t.Errorf("Observe(...) after Delete: want export to be gone, got %+v, %v", observation, err)
+	}
+}
+
+// observeUntilDone observes ex until its export is no longer running.
+func observeUntilDone(t *testing.T, e *backupexport.External, ex *v1.BackupExport) (managed.ExternalObservation, error) {
+	t.Helper()
+
+	deadline := time.Now().Add(10 * time.Second)
+	for {
+		observation, err := e.Observe(context.Background(), ex)
+		if err != nil || ex.Status.AtProvider.State != v1.ExportStateExporting {
+			return observation, err
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("export did not finish in time")
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func cmpIgnoreFinishedAt() cmp.Option {
+	return cmp.FilterPath(func(p cmp.Path) bool {
+		return p.Last().String() == ".FinishedAt"
+	}, cmp.Ignore())
+}
+
+// blockingClient downloads backups that never end.
+type blockingClient struct {
+	*fakebkpmgr.Client
+}
+
+func (blockingClient) DownloadBackup(ctx context.Context, _, _ string) (io.ReadCloser, error) {
+	return io.NopCloser(blockingReader{ctx: ctx}), nil
+}
+
+type blockingReader struct {
+	ctx context.Context
+}
+
+func (r blockingReader) Read([]byte) (int, error) {
+	<-r.ctx.Done()
+	return 0, r.ctx.Err()
+}
+
+// s3StandIn is an S3-compatible object storage that keeps the objects in
+// memory. Objects of unknown size are uploaded in multipart uploads.
+type s3StandIn struct {
+	mu      sync.Mutex
+	objects map[string][]byte
+	// uploads are the parts of the multipart uploads in progress, by upload
+	// ID and part number.
+	uploads map[string]map[int][]byte
+}
+
+func newS3StandIn() *s3StandIn {
+	return &s3StandIn{objects: map[string][]byte{}, uploads: map[string]map[int][]byte{}}
+}
+
+func (s *s3StandIn) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	path := strings.TrimPrefix(r.URL.Path, "/")
+	query := r.URL.Query()
+	uploadID := query.Get("uploadId")
+	switch {
+	case r.Method == http.MethodPost && query.Has("uploads"):
+		uploadID = strconv.Itoa(len(s.uploads) + 1)
+		s.uploads[uploadID] = map[int][]byte{}
+		fmt.Fprintf(w, "<InitiateMultipartUploadResult><UploadId>%s</UploadId></InitiateMultipartUploadResult>", uploadID)
+	case r.Method == http.MethodPut && s.uploads[uploadID] != nil:
+		part, err := readS3Body(r)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		number, _ := strconv.Atoi(query.Get("partNumber"))
+		s.uploads[uploadID][number] = part
+		w.Header().Set("ETag", fmt.Sprintf(`"%x"`, md5.Sum(part)))
+	case r.Method == http.MethodPost && s.uploads[uploadID] != nil:
+		var object []byte
+		for number := 1; number <= len(s.uploads[uploadID]); number++ {
+			object = append(object, s.uploads[uploadID][number]...)
+		}
+		s.objects[path] = object
+		delete(s.uploads, uploadID)
+		bucket, key, _ := strings.Cut(path, "/")
+		fmt.Fprintf(w, "<CompleteMultipartUploadResult><Bucket>%s</Bucket><Key>%s</Key></CompleteMultipartUploadResult>", bucket, key)
+	case r.Method == http.MethodDelete && s.uploads[uploadID] != nil:
+		delete(s.uploads, uploadID)
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		http.Error(w, "not implemented", http.StatusNotImplemented)
+	}
+}
+
+func (s *s3StandIn) object(path string) []byte {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.objects[path]
+}
+
+// readS3Body reads the body of an upload request. Uploads over HTTP are
+// signed chunk by chunk.
+func readS3Body(r *http.Request) ([]byte, error) {
+	if strings.HasPrefix(r.Header.Get("X-Amz-Content-Sha256"), "STREAMING-") {
+		return io.ReadAll(awsChunkedReader(r.Body))
+	}
+	return io.ReadAll(r.Body)
+}
+
+// awsChunkedReader decodes a body in the aws-chunked encoding, i.e. chunks
+// of the form "<hex size>;chunk-signature=<signature>\r\n<data>\r\n".
+func awsChunkedReader(r io.Reader) io.Reader {
+	reader, writer := io.Pipe()
+	go func() {
+		chunks := bufio.NewReader(r)
+		for {
+			header, err := chunks.ReadString('\n')
+			if err != nil {
+				writer.CloseWithError(err)
+				return
+			}
+			hexSize, _, _ := strings.Cut(strings.TrimSpace(header), ";")
+			size, err := strconv.ParseInt(hexSize, 16, 64)
+			if err != nil {
+				writer.CloseWithError(err)
+				return
+			}
+			if size == 0 {
+				writer.Close()
+				return
+			}
+			if _, err := io.CopyN(writer, chunks, size); err != nil {
+				writer.CloseWithError(err)
+				return
+			}
+			if _, err := chunks.Discard(2); err != nil {
+				writer.CloseWithError(err)
+				return
+			}
+		}
+	}()
+	return reader
+}
@@ -0,0 +1,185 @@
+/*
+Copyright 2024 Klutch Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package backupexport
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/minio/minio-go/v7"
+	"github.com/minio/minio-go/v7/pkg/credentials"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	v1 "github.com/anynines/klutchio/provider-anynines/apis/backup/v1"
+	utilerr "github.com/anynines/klutchio/provider-anynines/pkg/utilerr"
+)
+
+const (
+	// keyAccessKeyID and keySecretAccessKey are the keys of the access key
+	// in the credentials secret of an S3 destination.
+	keyAccessKeyID     = "accessKeyID"
+	keySecretAccessKey = "secretAccessKey"
+
+	// s3PartSize is the size of the parts backups are uploaded to S3 in. The
+	// size of backups is not known upfront, so every part is buffered.
+	s3PartSize = 16 << 20
+
+	errNoDestination    = utilerr.PlainUserErr("destination must be s3 or volume")
+	errNoLocalPath      = utilerr.PlainUserErr("path of volume destination must be relative and must not leave the volume")
+	errGetS3Credentials = "cannot get credentials of S3 destination"
+)
+
+// A destination receives the file of an exported backup.
+type destination interface {
+	// write writes the backup read from r to the destination. It does not
+	// leave a partial file behind if r fails.
+	write(ctx context.Context, r io.Reader) error
+	// location returns the URI of the exported file.
+	location() string
+}
+
+// newDestination returns the destination of params, which is written to name
+// unless params specifies the key or path of the file.
+func (c *External) newDestination(ctx context.Context, params v1.BackupExportDestination, name string) (destination, error) {
+	switch {
+	case params.S3 != nil:
+		return c.newS3Destination(ctx, params.S3, name)
+	case params.Volume != nil:
+		return newVolumeDestination(c.volumeRoot, params.Volume, name)
+	}
+	return nil, errNoDestination
+}
+
+type s3Destination struct {
+	client *minio.Client
+	bucket string
+	key    string
+}
+
+func (c *External) newS3Destination(ctx context.Context, params *v1.S3Destination, name string) (*s3Destination, error) {
+	secret := &corev1.Secret{}
+	ref := params.CredentialsSecretRef
+	if err := c.Kube.Get(ctx, types.NamespacedName{Namespace: ref.Namespace, Name: ref.Name}, secret); err != nil {
+		return nil, fmt.Errorf("%s: %w", errGetS3Credentials, err)
+	}
+	accessKeyID, secretAccessKey := secret.Data[keyAccessKeyID], secret.Data[keySecretAccessKey]
+	if len(accessKeyID) == 0 || len(secretAccessKey) == 0 {
+		return nil, fmt.Errorf("%s: secret %s/%s must contain the keys %q and %q", errGetS3Credentials, ref.Namespace, ref.Name, keyAccessKeyID, keySecretAccessKey)
+	}
+
+	region := params.Region
+	if region == "" {
+		region = "us-east-1"
+	}
+	client, err := minio.New(params.Endpoint, &minio.Options{
+		Creds:  credentials.NewStaticV4(string(accessKeyID), string(secretAccessKey), ""),
+		Secure: !params.Insecure,
+		// Setting the region saves a request for the location of the bucket.
+		Region: region,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("cannot create client of S3 destination: %w", err)
+	}
+
+	key := params.Key
+	if key == "" {
+		key = name
+	}
+	return &s3Destination{client: client, bucket: params.Bucket, key: key}, nil
+}
+
+func (d *s3Destination) write(ctx context.Context, r io.Reader) error {
+	// A failed multipart upload is aborted, so no partial object is left
+	// behind.
+	_, err := d.client.PutObject(ctx, d.bucket, d.key, r, -1, minio.PutObjectOptions{
+		ContentType: "application/octet-stream",
+		PartSize:    s3PartSize,
+	})
+	if err != nil {
+		return fmt.Errorf("cannot upload backup to %s: %w", d.location(), err)
+	}
+	return nil
+}
+
+func (d *s3Destination) location() string {
+	return fmt.Sprintf("s3://%s/%s", d.bucket, d.key)
+}
+
+type volumeDestination struct {
+	claimName string
+	path      string
+	// volume and file are the paths of the volume and the file in the file
+	// system of the provider.
+	volume string
+	file   string
+}
+
+func newVolumeDestination(volumeRoot string, params *v1.VolumeDestination, name string) (*volumeDestination, error) {
+	path := params.Path
+	if path == "" {
+		path = name
+	}
+	if !filepath.IsLocal(params.ClaimName) || !filepath.IsLocal(path) {
+		return nil, errNoLocalPath
+	}
+	volume := filepath.Join(volumeRoot, params.ClaimName)
+	return &volumeDestination{
+		claimName: params.ClaimName,
+		path:      path,
+		volume:    volume,
+		file:      filepath.Join(volume, path),
+	}, nil
+}
+
+func (d *volumeDestination) write(_ context.Context, r io.Reader) error {
+	if _, err := os.Stat(d.volume); err != nil {
+		return fmt.Errorf("volume %s is not mounted into the provider: %w", d.claimName, err)
+	}
+	if err := os.MkdirAll(filepath.Dir(d.file), 0o750); err != nil {
+		return err
+	}
+
+	// The backup is written to a temporary file first, which replaces the
+	// file once it is complete.
+	tmp, err := os.CreateTemp(filepath.Dir(d.file), "."+filepath.Base(d.file)+"-*")
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(tmp, r)
+	if err == nil {
+		err = tmp.Sync()
+	}
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), d.file)
+	}
+	if err != nil {
+		return errors.Join(fmt.Errorf("cannot write backup to %s: %w", d.location(), err), os.Remove(tmp.Name()))
+	}
+	return nil
+}
+
+func (d *volumeDestination) location() string {
+	return fmt.Sprintf("pvc://%s/%s", d.claimName, filepath.ToSlash(d.path))
+}
@@ -0,0 +1,115 @@
+/*
+Copyright 2024 Klutch Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package backupexport
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// exports tracks the exports running in the background by the UID of their
+// BackupExport. Exports outlive the reconciles that start them, but not the
+// provider: an export that was running when the provider stopped is started
+// again.
+type exports struct {
+	mu      sync.Mutex
+	running map[types.UID]*export
+}
+
+func newExports() *exports {
+	return &exports{running: map[types.UID]*export{}}
+}
+
+// An export streams a backup to its destination.
+type export struct {
+	instanceID string
+	backupID   int
+	location   string
+
+	// written is the number of bytes exported so far.
+	written atomic.Int64
+
+	cancel context.CancelFunc
+	// done is closed when the export ended. The fields below are only
+	// set then.
+	done       chan struct{}
+	err        error
+	checksum   string
+	finishedAt time.Time
+}
+
+// start runs the export with run in the background. The context run is called
+// with is cancelled when the export is stopped.
+func (e *exports) start(uid types.UID, running *export, run func(ctx context.Context, running *export) error) {
+	ctx, cancel := context.WithCancel(context.Background())
+	running.cancel = cancel
+	running.done = make(chan struct{})
+
+	e.mu.Lock()
+	if previous, ok := e.running[uid]; ok {
+		previous.cancel()
+	}
+	e.running[uid] = running
+	e.mu.Unlock()
+
+	go func() {
+		defer cancel()
+		running.err = run(ctx, running)
+		running.finishedAt = time.Now()
+		close(running.done)
+	}()
+}
+
+// get returns the export of the BackupExport with the given UID, or nil if
+// none is tracked.
+func (e *exports) get(uid types.UID) *export {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.running[uid]
+}
+
+// remove stops tracking the export of the BackupExport with the given UID.
+func (e *exports) remove(uid types.UID) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	delete(e.running, uid)
+}
+
+// stop cancels the export of the BackupExport with the given UID, if it is
+// still running, and stops tracking it.
+func (e *exports) stop(uid types.UID) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if running, ok := e.running[uid]; ok {
+		running.cancel()
+		delete(e.running, uid)
+	}
+}
+
+// progress counts the bytes written to it.
+type progress struct {
+	written *atomic.Int64
+}
+
+func (p progress) Write(b []byte) (int, error) {
+	p.written.Add(int64(len(b)))
+	return len(b), nil
+}
@@ -19,6 +19,7 @@ package confighealth
 import (
 	"context"
 	"fmt"
+	"strings"
 	"time"
 
 	bmclient "github.com/anynines/klutchio/clients/a9s-backup-manager"
@@ -67,9 +68,12 @@ func Setup(mgr ctrl.Manager, o controller.Options) error {
 		kube:               mgr.GetClient(),
 		log:                o.Logger.WithValues("controller", name),
 		nowFn:              time.Now,
-		newOsbServiceFn:    osbpkg.NewOsbServiceWithTLS,
-		newBackupManagerFn: bmpkg.NewBackupManagerServiceWithTLS,
+		newOsbServiceFn:    osbpkg.NewOsbServiceWithOptions,
+		newBackupManagerFn: bmpkg.NewBackupManagerServiceWithOptions,
 		recorder:           mgr.GetEventRecorderFor(name),
+
+		osbEndpointHealth:           osbpkg.EndpointHealth,
+		backupManagerEndpointHealth: bmpkg.EndpointHealth,
 	}
 
 	return ctrl.NewControllerManagedBy(mgr).
@@ -83,9 +87,21 @@ type reconciler struct {
 	kube               k8sclient.Client
 	log                logging.Logger
 	nowFn              func() time.Time
-	newOsbServiceFn    func(username, password []byte, url string, insecureSkipVerify bool, caBundle []byte, overrideServerName string) (osbclient.Client, error)
-	newBackupManagerFn func(username, password []byte, url string, insecureSkipVerify bool, caBundle []byte, overrideServerName string) (bmclient.Client, error)
+	newOsbServiceFn    func(url string, opts ...osbpkg.Option) (osbclient.Client, error)
+	newBackupManagerFn func(url string, opts ...bmpkg.Option) (bmclient.Client, error)
 	recorder           record.EventRecorder
+
+	// osbEndpointHealth and backupManagerEndpointHealth receive the results
+	// of the checks, so that clients send their requests to healthy
+	// endpoints.
+	osbEndpointHealth           endpointHealth
+	backupManagerEndpointHealth endpointHealth
+}
+
+// endpointHealth records the health of endpoints for clients.
+type endpointHealth interface {
+	MarkFailed(endpointURL string)
+	MarkHealthy(endpointURL string)
 }
 
 func (r reconciler) Reconcile(ctx context.Context, req reconcile.Request) (reconcile.Result, error) {
@@ -96,6 +112,10 @@ func (r reconciler) Reconcile(ctx context.Context, req reconcile.Request) (recon
 
 	if err := r.kube.Get(ctx, req.NamespacedName, &pc); err != nil {
 		if k8serrors.IsNotFound(err) {
+			// The clients of deleted ProviderConfigs no longer share a rate
+			// limiter.
+			osbpkg.ForgetRateLimit(req.Name)
+			bmpkg.ForgetRateLimit(req.Name)
 			err = nil
 		}
 		return ctrl.Result{}, err
@@ -109,7 +129,6 @@ func (r reconciler) Reconcile(ctx context.Context, req reconcile.Request) (recon
 		log := r.log.WithValues("request", req)
 		log.Debug("Performing Check")
 		// by passing a dedicated context, we put a limit on how long the check is allowed to take
-		// FIXME the a9s client should handle contexts correctly, it currently ignores them
 		updated := r.getUpdatedProviderConfig(timeoutContext, &pc, now)
 		status := updated.Status.Health.LastStatus
 		log.Debug("Check complete", "status", status)
@@ -160,9 +179,8 @@ func isCheckNeeded(pc *v1.ProviderConfig, now time.Time) bool {
 }
 
 func (r reconciler) getUpdatedProviderConfig(ctx context.Context, pc *v1.ProviderConfig, now time.Time) *v1.ProviderConfig {
-	status, message := r.performCheck(ctx, pc)
-
 	updated := pc.DeepCopy()
+	status, message := r.performCheck(ctx, updated)
 
 	updated.Status.Health.LastStatus = status
 	updated.Status.Health.LastMessage = message
@@ -178,43 +196,111 @@ func (r reconciler) performCheck(ctx context.Context, pc *v1.ProviderConfig) (bo
 		return false, fmt.Sprintf("Extracting credentials: %v", err)
 	}
 
+	checkEndpoint, health := r.performOsbCheck, r.osbEndpointHealth
 	if pc.Spec.ServiceType == v1.ServiceTypeBackupManager {
-		return r.performBackupManagerCheck(ctx, pc, credentials)
+		if err := credentials.AssertBasicAuth(); err != nil {
+			return false, err.Error()
+		}
+		checkEndpoint, health = r.performBackupManagerCheck, r.backupManagerEndpointHealth
+	}
+
+	// Every endpoint is checked, so that clients fail over to endpoints that
+	// are known to be healthy and the status shows which endpoints are down.
+	endpoints := pc.Spec.Endpoints()
+	pc.Status.Endpoints = make([]v1.EndpointHealth, 0, len(endpoints))
+	pc.Status.ActiveEndpoint = ""
+	var failures []string
+	for _, endpoint := range endpoints {
+		check := checkEndpoint(ctx, pc, credentials, endpoint)
+		pc.Status.Endpoints = append(pc.Status.Endpoints, v1.EndpointHealth{
+			URL:     endpoint,
+			Healthy: check.healthy,
+			Message: check.message,
+		})
+		if !check.healthy {
+			health.MarkFailed(endpoint)
+			failures = append(failures, fmt.Sprintf("%s: %s", endpoint, check.message))
+			continue
+		}
+
+		health.MarkHealthy(endpoint)
+		if pc.Status.ActiveEndpoint == "" {
+			pc.Status.ActiveEndpoint = endpoint
+			if check.apiVersion != "" {
+				pc.Status.APIVersion = check.apiVersion
+			}
+		}
+	}
+
+	switch {
+	case len(endpoints) == 1 && len(failures) == 1:
+		return false, pc.Status.Endpoints[0].Message
+	case len(failures) == len(endpoints):
+		return false, fmt.Sprintf("No endpoint is available: %s", strings.Join(failures, "; "))
+	case pc.Status.ActiveEndpoint != endpoints[0]:
+		return true, fmt.Sprintf("Available at failover endpoint %s", pc.Status.ActiveEndpoint)
 	}
+	return true, "Available"
+}
 
-	return r.performOsbCheck(ctx, pc, credentials)
+// endpointCheck is the result of the health check of an endpoint.
+type endpointCheck struct {
+	healthy bool
+	message string
+	// apiVersion is the OSB API version negotiated with the endpoint.
+	apiVersion string
 }
 
-func (r reconciler) performOsbCheck(ctx context.Context, pc *v1.ProviderConfig, credentials credhelp.Credentials) (bool, string) {
-	svc, err := r.newOsbServiceFn(credentials.Username, credentials.Password, pc.Spec.Url, credentials.InsecureSkipVerify, credentials.CABundle, credentials.OverrideServerName)
+// performOsbCheck checks the service broker endpoint at url. The client is
+// rate limited like every client of the ProviderConfig, but it only sends its
+// requests to url, and it negotiates the API version from the latest one
+// instead of the one recorded in the ProviderConfig, so that upgraded
+// brokers are picked up.
+func (r reconciler) performOsbCheck(ctx context.Context, pc *v1.ProviderConfig, credentials credhelp.Credentials, url string) endpointCheck {
+	svc, err := r.newOsbServiceFn(url,
+		osbpkg.WithBasicAuth(credentials.Username, credentials.Password),
+		osbpkg.WithOAuth2(credentials.OAuth2),
+		osbpkg.WithTLS(credentials.InsecureSkipVerify, credentials.CABundle, credentials.OverrideServerName),
+		osbpkg.ForProviderConfig(pc),
+		osbpkg.WithAPIVersion(osbclient.LatestAPIVersion().String()),
+		osbpkg.WithFailoverURLs(nil),
+	)
 	if err != nil {
-		return false, fmt.Sprintf("Constructing OSB service client: %v", err)
+		return endpointCheck{message: fmt.Sprintf("Constructing OSB service client: %v", err)}
 	}
 
-	if err := svc.CheckAvailability(pc.Spec.HealthCheckEndpoint); err != nil {
-		return false, err.Error()
+	if err := svc.CheckAvailability(ctx, pc.Spec.HealthCheckEndpoint); err != nil {
+		return endpointCheck{message: err.Error()}
 	}
 
-	return true, "Available"
+	// Negotiate the API version on every check, so that brokers that are
+	// upgraded or downgraded are picked up.
+	version, err := svc.NegotiateAPIVersion(ctx)
+	if err != nil {
+		return endpointCheck{message: fmt.Sprintf("Negotiating API version: %v", err)}
+	}
+
+	return endpointCheck{healthy: true, message: "Available", apiVersion: version.String()}
 }
 
-func (r reconciler) performBackupManagerCheck(ctx context.Context, pc *v1.ProviderConfig, credentials credhelp.Credentials) (bool, string) {
-	svc, err := r.newBackupManagerFn(
-		credentials.Username,
-		credentials.Password,
-		pc.Spec.Url,
-		credentials.InsecureSkipVerify,
-		credentials.CABundle,
-		credentials.OverrideServerName,
+// performBackupManagerCheck checks the backup manager endpoint at url. The
+// client is rate limited like every client of the ProviderConfig, but it only
+// sends its requests to url.
+func (r reconciler) performBackupManagerCheck(ctx context.Context, pc *v1.ProviderConfig, credentials credhelp.Credentials, url string) endpointCheck {
+	svc, err := r.newBackupManagerFn(url,
+		bmpkg.WithBasicAuth(credentials.Username, credentials.Password),
+		bmpkg.WithTLS(credentials.InsecureSkipVerify, credentials.CABundle, credentials.OverrideServerName),
+		bmpkg.ForProviderConfig(pc),
+		bmpkg.WithFailoverURLs(nil),
 	)
 	if err != nil {
-		return false, fmt.Sprintf("Constructing backup manager client: %v", err)
+		return endpointCheck{message: fmt.Sprintf("Constructing backup manager client: %v", err)}
 	}
 
 	// For backup manager, check if we can reach the health check endpoint
-	if err := svc.CheckAvailability(pc.Spec.HealthCheckEndpoint); err != nil {
-		return false, err.Error()
+	if err := svc.CheckAvailability(ctx, pc.Spec.HealthCheckEndpoint); err != nil {
+		return endpointCheck{message: err.Error()}
 	}
 
-	return true, "Available"
+	return endpointCheck{healthy: true, message: "Available"}
 }
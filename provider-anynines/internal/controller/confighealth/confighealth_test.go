@@ -39,6 +39,9 @@ import (
 	fakeosb "github.com/anynines/klutchio/clients/a9s-open-service-broker/fake"
 	apisv1 "github.com/anynines/klutchio/provider-anynines/apis/v1"
 	a9stest "github.com/anynines/klutchio/provider-anynines/internal/controller/test"
+	credhelp "github.com/anynines/klutchio/provider-anynines/internal/controller/utils"
+	bmpkg "github.com/anynines/klutchio/provider-anynines/pkg/client/backupmanager"
+	osbpkg "github.com/anynines/klutchio/provider-anynines/pkg/client/osb"
 )
 
 func TestMain(m *testing.M) {
@@ -59,6 +62,17 @@ func failureReaction(message string) *fakeosb.CheckAvailabilityReaction {
 	return &reaction
 }
 
+// unavailableBackupManager is a backup manager client whose availability
+// check fails.
+type unavailableBackupManager struct {
+	bmclient.Client
+	message string
+}
+
+func (c unavailableBackupManager) CheckAvailability(context.Context, string) error {
+	return errors.New(c.message)
+}
+
 func TestReconcile(t *testing.T) {
 	t0 := time.Now().Truncate(time.Second)
 
@@ -68,10 +82,85 @@ func TestReconcile(t *testing.T) {
 		secret                    *corev1.Secret
 		expectedHealth            *apisv1.ProviderConfigHealth
 		checkAvailabilityReaction *fakeosb.CheckAvailabilityReaction
-		expectedCreds             []string
-		expectedEvents            []string
-		expectedClientUsed        string
+		// negotiateReaction defaults to negotiating the latest API version.
+		negotiateReaction  *fakeosb.NegotiateAPIVersionReaction
+		expectedAPIVersion string
+		expectedCreds      []string
+		expectedEvents     []string
+		expectedClientUsed string
+		// unavailableURLs fail the availability check with the given
+		// message, regardless of checkAvailabilityReaction.
+		unavailableURLs        map[string]string
+		expectedActiveEndpoint string
+		expectedEndpoints      []apisv1.EndpointHealth
+		expectedFailedURLs     []string
 	}{
+		"unavailable endpoint fails over": {
+			now: t0,
+			pc: a9stest.ProviderConfig(a9stest.Name[apisv1.ProviderConfig]("test-provider"),
+				a9stest.WithProviderConfigSpec("https://a.test.com",
+					apisv1.ServiceTypeServiceBroker,
+					a9stest.SecretRef("test-secret", "test", "username"),
+					a9stest.SecretRef("test-secret", "test", "password"),
+					xpv1.CredentialsSourceSecret),
+				a9stest.WithFailoverURLs("https://b.test.com", "https://c.test.com"),
+			),
+			secret: a9stest.Secret(a9stest.Name[corev1.Secret]("test-secret"),
+				a9stest.Namespace[corev1.Secret]("test"),
+				a9stest.WithKey("username", "test"),
+				a9stest.WithKey("password", "secure-test-password"),
+			),
+			expectedHealth: a9stest.NewProviderConfigHealth(
+				a9stest.HealthLastCheckTime(t0),
+				a9stest.HealthLastStatus(true),
+				a9stest.HealthLastMessage("Available at failover endpoint https://b.test.com"),
+			),
+			checkAvailabilityReaction: successReaction(),
+			unavailableURLs:           map[string]string{"https://a.test.com": "connection refused"},
+			expectedActiveEndpoint:    "https://b.test.com",
+			expectedEndpoints: []apisv1.EndpointHealth{
+				{URL: "https://a.test.com", Healthy: false, Message: "connection refused"},
+				{URL: "https://b.test.com", Healthy: true, Message: "Available"},
+				{URL: "https://c.test.com", Healthy: true, Message: "Available"},
+			},
+			expectedFailedURLs: []string{"https://a.test.com"},
+			expectedEvents:     []string{"Normal CheckSuccess ProviderConfig is now healthy"},
+			expectedClientUsed: "osb",
+		},
+
+		"all endpoints unavailable": {
+			now: t0,
+			pc: a9stest.ProviderConfig(a9stest.Name[apisv1.ProviderConfig]("test-provider"),
+				a9stest.WithProviderConfigSpec("https://a.test.com",
+					apisv1.ServiceTypeBackupManager,
+					a9stest.SecretRef("test-secret", "test", "username"),
+					a9stest.SecretRef("test-secret", "test", "password"),
+					xpv1.CredentialsSourceSecret),
+				a9stest.WithFailoverURLs("https://b.test.com"),
+			),
+			secret: a9stest.Secret(a9stest.Name[corev1.Secret]("test-secret"),
+				a9stest.Namespace[corev1.Secret]("test"),
+				a9stest.WithKey("username", "test"),
+				a9stest.WithKey("password", "secure-test-password"),
+			),
+			expectedHealth: a9stest.NewProviderConfigHealth(
+				a9stest.HealthLastCheckTime(t0),
+				a9stest.HealthLastStatus(false),
+				a9stest.HealthLastMessage("No endpoint is available: https://a.test.com: connection refused; https://b.test.com: bad gateway"),
+			),
+			unavailableURLs: map[string]string{
+				"https://a.test.com": "connection refused",
+				"https://b.test.com": "bad gateway",
+			},
+			expectedEndpoints: []apisv1.EndpointHealth{
+				{URL: "https://a.test.com", Healthy: false, Message: "connection refused"},
+				{URL: "https://b.test.com", Healthy: false, Message: "bad gateway"},
+			},
+			expectedFailedURLs: []string{"https://a.test.com", "https://b.test.com"},
+			expectedEvents:     []string{"Warning CheckFailure Health check failed: No endpoint is available: https://a.test.com: connection refused; https://b.test.com: bad gateway"},
+			expectedClientUsed: "backupmanager",
+		},
+
 		"new config performs check initially": {
 			now: t0,
 			pc: a9stest.ProviderConfig(a9stest.Name[apisv1.ProviderConfig]("test-provider"),
@@ -97,6 +186,58 @@ func TestReconcile(t *testing.T) {
 			expectedClientUsed:        "osb",
 		},
 
+		"negotiated api version is recorded": {
+			now: t0,
+			pc: a9stest.ProviderConfig(a9stest.Name[apisv1.ProviderConfig]("test-provider"),
+				a9stest.WithProviderConfigSpec("test.com",
+					apisv1.ServiceTypeServiceBroker,
+					a9stest.SecretRef("test-secret", "test", "username"),
+					a9stest.SecretRef("test-secret", "test", "password"),
+					xpv1.CredentialsSourceSecret),
+			),
+			secret: a9stest.Secret(a9stest.Name[corev1.Secret]("test-secret"),
+				a9stest.Namespace[corev1.Secret]("test"),
+				a9stest.WithKey("username", "test"),
+				a9stest.WithKey("password", "secure-test-password"),
+			),
+			expectedHealth: a9stest.NewProviderConfigHealth(
+				a9stest.HealthLastCheckTime(t0),
+				a9stest.HealthLastStatus(true),
+				a9stest.HealthLastMessage("Available"),
+			),
+			checkAvailabilityReaction: successReaction(),
+			negotiateReaction:         &fakeosb.NegotiateAPIVersionReaction{Response: osbclient.Version2_13()},
+			expectedAPIVersion:        "2.13",
+			expectedEvents:            []string{"Normal CheckSuccess ProviderConfig is now healthy"},
+			expectedClientUsed:        "osb",
+		},
+
+		"failed api version negotiation fails the check": {
+			now: t0,
+			pc: a9stest.ProviderConfig(a9stest.Name[apisv1.ProviderConfig]("test-provider"),
+				a9stest.WithProviderConfigSpec("test.com",
+					apisv1.ServiceTypeServiceBroker,
+					a9stest.SecretRef("test-secret", "test", "username"),
+					a9stest.SecretRef("test-secret", "test", "password"),
+					xpv1.CredentialsSourceSecret),
+			),
+			secret: a9stest.Secret(a9stest.Name[corev1.Secret]("test-secret"),
+				a9stest.Namespace[corev1.Secret]("test"),
+				a9stest.WithKey("username", "test"),
+				a9stest.WithKey("password", "secure-test-password"),
+			),
+			expectedHealth: a9stest.NewProviderConfigHealth(
+				a9stest.HealthLastCheckTime(t0),
+				a9stest.HealthLastStatus(false),
+				a9stest.HealthLastMessage("Negotiating API version: broker does not support any API version up to 2.14"),
+			),
+			checkAvailabilityReaction: successReaction(),
+			negotiateReaction: &fakeosb.NegotiateAPIVersionReaction{
+				Error: osbclient.APIVersionNegotiationError{MaxVersion: osbclient.Version2_14()},
+			},
+			expectedEvents: []string{"Warning CheckFailure Health check failed: Negotiating API version: broker does not support any API version up to 2.14"},
+		},
+
 		"successful check is not retried after half the successCheckInterval": {
 			now: t0.Add(successCheckInterval / 2),
 			pc: a9stest.ProviderConfig(a9stest.Name[apisv1.ProviderConfig]("test-provider"),
@@ -329,9 +470,16 @@ func TestReconcile(t *testing.T) {
 			t.Parallel()
 
 			fakeBM := fakebm.NewFakeClient(nil)
+			osbEndpointHealth := osbclient.NewEndpointHealth(time.Minute)
+			backupManagerEndpointHealth := bmclient.NewEndpointHealth(time.Minute)
 
+			negotiateReaction := tc.negotiateReaction
+			if negotiateReaction == nil {
+				negotiateReaction = &fakeosb.NegotiateAPIVersionReaction{Response: osbclient.LatestAPIVersion()}
+			}
 			fakeOSB := fakeosb.NewFakeClient(fakeosb.FakeClientConfiguration{
-				CheckAvailabilityReaction: tc.checkAvailabilityReaction,
+				CheckAvailabilityReaction:   tc.checkAvailabilityReaction,
+				NegotiateAPIVersionReaction: negotiateReaction,
 			})
 
 			creds := []string{}
@@ -350,19 +498,42 @@ func TestReconcile(t *testing.T) {
 
 				nowFn: func() time.Time { return tc.now },
 
-				newOsbServiceFn: func(username, password []byte, url string, insecureSkipVerify bool, caBundle []byte, overrideServerName string) (osbclient.Client, error) {
+				newOsbServiceFn: func(url string, opts ...osbpkg.Option) (osbclient.Client, error) {
+					cfg := &osbclient.ClientConfiguration{}
+					for _, opt := range opts {
+						opt(cfg)
+					}
+					username := []byte(cfg.AuthConfig.BasicAuthConfig.Username)
+					password := []byte(cfg.AuthConfig.BasicAuthConfig.Password)
 					clientUsed = "osb"
 					creds = append(creds, string(username), string(password))
+					if message, ok := tc.unavailableURLs[url]; ok {
+						return fakeosb.NewFakeClient(fakeosb.FakeClientConfiguration{
+							CheckAvailabilityReaction: failureReaction(message),
+						}), nil
+					}
 					return fakeOSB, nil
 				},
 
-				newBackupManagerFn: func(username, password []byte, url string, insecureSkipVerify bool, caBundle []byte, overrideServerName string) (bmclient.Client, error) {
+				newBackupManagerFn: func(url string, opts ...bmpkg.Option) (bmclient.Client, error) {
+					cfg := &bmclient.ClientConfiguration{}
+					for _, opt := range opts {
+						opt(cfg)
+					}
+					username := cfg.AuthConfig.BasicAuthConfig.Username
+					password := cfg.AuthConfig.BasicAuthConfig.Password
 					clientUsed = "backupmanager"
 					creds = append(creds, string(username), string(password))
+					if message, ok := tc.unavailableURLs[url]; ok {
+						return unavailableBackupManager{Client: fakeBM, message: message}, nil
+					}
 					return fakeBM, nil
 				},
 
 				recorder: eventRecorder,
+
+				osbEndpointHealth:           osbEndpointHealth,
+				backupManagerEndpointHealth: backupManagerEndpointHealth,
 			}
 
 			_, err := r.Reconcile(context.Background(), reconcile.Request{
@@ -382,6 +553,29 @@ func TestReconcile(t *testing.T) {
 				t.Fatalf("Expected health to be %+v, but got %+v", tc.expectedHealth, reloaded.Status.Health)
 			}
 
+			if tc.expectedAPIVersion != "" && reloaded.Status.APIVersion != tc.expectedAPIVersion {
+				t.Fatalf("Expected API version %q, but got %q", tc.expectedAPIVersion, reloaded.Status.APIVersion)
+			}
+
+			if tc.expectedEndpoints != nil && reloaded.Status.ActiveEndpoint != tc.expectedActiveEndpoint {
+				t.Fatalf("Expected active endpoint %q, but got %q", tc.expectedActiveEndpoint, reloaded.Status.ActiveEndpoint)
+			}
+
+			if tc.expectedEndpoints != nil && !reflect.DeepEqual(reloaded.Status.Endpoints, tc.expectedEndpoints) {
+				t.Fatalf("Expected endpoints to be %+v, but got %+v", tc.expectedEndpoints, reloaded.Status.Endpoints)
+			}
+
+			if tc.expectedFailedURLs != nil {
+				health := osbEndpointHealth.Order
+				if tc.pc.Spec.ServiceType == apisv1.ServiceTypeBackupManager {
+					health = backupManagerEndpointHealth.Order
+				}
+				order := health(tc.pc.Spec.Endpoints())
+				if failed := order[len(order)-len(tc.expectedFailedURLs):]; !reflect.DeepEqual(failed, tc.expectedFailedURLs) {
+					t.Fatalf("Expected endpoints %v to be recorded as failed, but the order is %v", tc.expectedFailedURLs, order)
+				}
+			}
+
 			if tc.expectedCreds != nil && !reflect.DeepEqual(creds, tc.expectedCreds) {
 				t.Fatalf("Expected credentials to be %+v, but got %+v", tc.expectedCreds, creds)
 			}
@@ -411,3 +605,55 @@ func TestReconcile(t *testing.T) {
 		})
 	}
 }
+
+func TestChecksUseTheClientsOfTheProviderConfig(t *testing.T) {
+	pc := &apisv1.ProviderConfig{}
+	pc.Name = "rate-limited"
+	pc.Spec.FailoverURLs = []string{"https://failover.example.com"}
+	pc.Spec.RateLimit = &apisv1.RateLimit{RequestsPerSecond: 5}
+	pc.Status.APIVersion = "2.13"
+	credentials := credhelp.Credentials{Username: []byte("admin"), Password: []byte("secret")}
+
+	var osbCfg osbclient.ClientConfiguration
+	var bmCfg bmclient.ClientConfiguration
+	r := reconciler{
+		newOsbServiceFn: func(url string, opts ...osbpkg.Option) (osbclient.Client, error) {
+			for _, opt := range opts {
+				opt(&osbCfg)
+			}
+			return fakeosb.NewFakeClient(fakeosb.FakeClientConfiguration{
+				CheckAvailabilityReaction:   successReaction(),
+				NegotiateAPIVersionReaction: &fakeosb.NegotiateAPIVersionReaction{Response: osbclient.LatestAPIVersion()},
+			}), nil
+		},
+		newBackupManagerFn: func(url string, opts ...bmpkg.Option) (bmclient.Client, error) {
+			for _, opt := range opts {
+				opt(&bmCfg)
+			}
+			return fakebm.NewFakeClient(nil), nil
+		},
+	}
+
+	if check := r.performOsbCheck(context.Background(), pc, credentials, "https://broker.example.com"); !check.healthy {
+		t.Fatalf("OSB check failed: %s", check.message)
+	}
+	if osbCfg.RateLimiter == nil {
+		t.Error("want the OSB check to use the rate limiter of the ProviderConfig")
+	}
+	if len(osbCfg.FailoverURLs) != 0 {
+		t.Errorf("want the OSB check to use no failover URLs, got %v", osbCfg.FailoverURLs)
+	}
+	if osbCfg.APIVersion != osbclient.LatestAPIVersion() {
+		t.Errorf("want the OSB check to negotiate from API version %v, got %v", osbclient.LatestAPIVersion(), osbCfg.APIVersion)
+	}
+
+	if check := r.performBackupManagerCheck(context.Background(), pc, credentials, "https://backup-manager.example.com"); !check.healthy {
+		t.Fatalf("backup manager check failed: %s", check.message)
+	}
+	if bmCfg.RateLimiter == nil {
+		t.Error("want the backup manager check to use the rate limiter of the ProviderConfig")
+	}
+	if len(bmCfg.FailoverURLs) != 0 {
+		t.Errorf("want the backup manager check to use no failover URLs, got %v", bmCfg.FailoverURLs)
+	}
+}
@@ -42,6 +42,7 @@ import (
 	util "github.com/anynines/klutchio/provider-anynines/internal/controller/utils"
 	bkpclient "github.com/anynines/klutchio/provider-anynines/pkg/client/backupmanager"
 	"github.com/anynines/klutchio/provider-anynines/pkg/constants"
+	"github.com/anynines/klutchio/provider-anynines/pkg/tracing"
 	utilerr "github.com/anynines/klutchio/provider-anynines/pkg/utilerr"
 )
 
@@ -89,10 +90,13 @@ func Setup(mgr ctrl.Manager, o controller.Options) error {
 	r := managed.NewReconciler(mgr,
 		resource.ManagedKind(v1.RestoreGroupVersionKind),
 		managed.WithExternalConnecter(&utilerr.ConnectDecorator{
-			Connector: &connector{
-				kube:         mgr.GetClient(),
-				usage:        resource.NewProviderConfigUsageTracker(mgr.GetClient(), &apisv1.ProviderConfigUsage{}),
-				newServiceFn: bkpclient.NewBackupManagerServiceWithTLS},
+			Connector: tracing.ConnectDecorator{
+				Connector: &connector{
+					kube:         mgr.GetClient(),
+					usage:        resource.NewProviderConfigUsageTracker(mgr.GetClient(), &apisv1.ProviderConfigUsage{}),
+					newServiceFn: bkpclient.NewBackupManagerServiceWithOptions},
+				Kind: v1.RestoreKind,
+			},
 			Logger: log,
 		}),
 		managed.WithLogger(log),
@@ -111,7 +115,7 @@ func Setup(mgr ctrl.Manager, o controller.Options) error {
 type connector struct {
 	kube         k8sclient.Client
 	usage        resource.Tracker
-	newServiceFn func(username, password []byte, url string, insecureSkipVerify bool, caBundle []byte, overrideServerName string) (a9sbackupmanager.Client, error)
+	newServiceFn func(url string, opts ...bkpclient.Option) (a9sbackupmanager.Client, error)
 }
 
 // Connect typically produces an ExternalClient by:
@@ -138,8 +142,16 @@ func (c *connector) Connect(ctx context.Context, mg resource.Managed) (managed.E
 	if err != nil {
 		return nil, err
 	}
+	if err := credentials.AssertBasicAuth(); err != nil {
+		return nil, err
+	}
 
-	svc, err := c.newServiceFn(credentials.Username, credentials.Password, pc.Spec.Url, credentials.InsecureSkipVerify, credentials.CABundle, credentials.OverrideServerName)
+	svc, err := c.newServiceFn(pc.Spec.Url,
+		bkpclient.WithBasicAuth(credentials.Username, credentials.Password),
+		bkpclient.WithTLS(credentials.InsecureSkipVerify, credentials.CABundle, credentials.OverrideServerName),
+		bkpclient.ForProviderConfig(pc),
+		bkpclient.WithLogger(ctrl.LoggerFrom(ctx)),
+	)
 	if err != nil {
 		return nil, fmt.Errorf("%s: %w", errNewClient, err)
 	}
@@ -188,7 +200,7 @@ func (c *external) Observe(ctx context.Context, mg resource.Managed) (managed.Ex
 		rst.Status.AtProvider.RestoreID = &rstID
 	}
 
-	getRestoreResponse, err := c.service.GetRestore(&a9sbackupmanager.GetRestoreRequest{
+	getRestoreResponse, err := c.service.GetRestore(ctx, &a9sbackupmanager.GetRestoreRequest{
 		InstanceID: rst.Status.AtProvider.InstanceID,
 		RestoreID:  strconv.Itoa(*rst.Status.AtProvider.RestoreID),
 	})
@@ -230,10 +242,10 @@ func checkForDeletion(rst *v1.Restore) (bool, error) {
 	}
 
 	var returnState error = nil
-	switch state := rst.Status.AtProvider.State; state {
-	case v1.StatusQueued:
+	switch a9sbackupmanager.RestoreStatus(rst.Status.AtProvider.State) {
+	case a9sbackupmanager.RestoreStatusQueued:
 		returnState = errRestoreQueued
-	case v1.StatusRunning:
+	case a9sbackupmanager.RestoreStatusRunning:
 		returnState = errRestoreRunning
 	}
 	return true, returnState
@@ -241,16 +253,16 @@ func checkForDeletion(rst *v1.Restore) (bool, error) {
 
 func setConditions(rst *v1.Restore) {
 	var conditionValue (xpv1.Condition)
-	switch rst.Status.AtProvider.State {
-	case v1.StatusQueued:
+	switch a9sbackupmanager.RestoreStatus(rst.Status.AtProvider.State) {
+	case a9sbackupmanager.RestoreStatusQueued:
 		conditionValue = xpv1.Creating()
-	case v1.StatusRunning:
+	case a9sbackupmanager.RestoreStatusRunning:
 		conditionValue = xpv1.Creating()
-	case v1.StatusDone:
+	case a9sbackupmanager.RestoreStatusDone:
 		conditionValue = xpv1.Available().WithMessage("Restore completed successfully")
-	case v1.StatusFailed:
+	case a9sbackupmanager.RestoreStatusFailed:
 		conditionValue = xpv1.Unavailable().WithMessage("Restore has failed")
-	case v1.StatusDeleted:
+	case a9sbackupmanager.RestoreStatusDeleted:
 		conditionValue = xpv1.Unavailable().WithMessage("Restore has been deleted")
 	default:
 		conditionValue = xpv1.ReconcileError(
@@ -266,7 +278,7 @@ func (c *external) Create(ctx context.Context, mg resource.Managed) (managed.Ext
 		return managed.ExternalCreation{}, errors.New(errNotRestore)
 	}
 
-	response, err := c.service.CreateRestore(&a9sbackupmanager.CreateRestoreRequest{
+	response, err := c.service.CreateRestore(ctx, &a9sbackupmanager.CreateRestoreRequest{
 		InstanceID: rst.Status.AtProvider.InstanceID,
 		BackupID:   strconv.Itoa(*rst.Status.AtProvider.BackupID),
 	})
@@ -314,10 +326,10 @@ func (c *external) Delete(ctx context.Context, mg resource.Managed) (managed.Ext
 		return managed.ExternalDelete{}, errors.New(errNotRestore)
 	}
 
-	switch state := rst.Status.AtProvider.State; state {
-	case v1.StatusQueued:
+	switch a9sbackupmanager.RestoreStatus(rst.Status.AtProvider.State) {
+	case a9sbackupmanager.RestoreStatusQueued:
 		return managed.ExternalDelete{}, errRestoreQueued
-	case v1.StatusRunning:
+	case a9sbackupmanager.RestoreStatusRunning:
 		return managed.ExternalDelete{}, errRestoreRunning
 	default:
 		return managed.ExternalDelete{}, nil
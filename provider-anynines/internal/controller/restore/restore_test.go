@@ -18,9 +18,11 @@ package restore
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"net/http"
 	"testing"
+	"time"
 
 	cmnv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
 	"github.com/crossplane/crossplane-runtime/pkg/meta"
@@ -36,6 +38,7 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 
 	a9sbackupmanager "github.com/anynines/klutchio/clients/a9s-backup-manager"
+	"github.com/anynines/klutchio/clients/a9s-backup-manager/bmtest"
 	fakebkpmgr "github.com/anynines/klutchio/clients/a9s-backup-manager/fake"
 	bkpv1 "github.com/anynines/klutchio/provider-anynines/apis/backup/v1"
 	v1 "github.com/anynines/klutchio/provider-anynines/apis/restore/v1"
@@ -87,8 +90,8 @@ func withStatus(state, triggeredAt, finishedAt string, conditions []cmnv1.Condit
 	return func(rst *v1.Restore) {
 		rst.Status.AtProvider.RestoreID = ptr.To[int](2)
 		rst.Status.AtProvider.State = state
-		rst.Status.AtProvider.TriggeredAt = triggeredAt
-		rst.Status.AtProvider.FinishedAt = finishedAt
+		rst.Status.AtProvider.TriggeredAt = &metav1.Time{Time: timestamp(triggeredAt)}
+		rst.Status.AtProvider.FinishedAt = &metav1.Time{Time: timestamp(finishedAt)}
 		rst.Status.ConditionedStatus.Conditions = conditions
 		rst.Status.AtProvider.InstanceID = "40a5148f-dba2-41f2-b1b7-0ca90e1501c5"
 		rst.Status.AtProvider.BackupID = ptr.To[int](29)
@@ -120,14 +123,23 @@ func newRestoreResponse(status, triggeredAt, finishedAt string) *a9sbackupmanage
 	rstResponse := &a9sbackupmanager.GetRestoreResponse{
 		BackupID:    ptr.To[int](1),
 		RestoreID:   ptr.To[int](2),
-		Status:      status,
-		TriggeredAt: triggeredAt,
-		FinishedAt:  finishedAt,
+		Status:      a9sbackupmanager.RestoreStatus(status),
+		TriggeredAt: timestamp(triggeredAt),
+		FinishedAt:  timestamp(finishedAt),
 	}
 
 	return rstResponse
 }
 
+// timestamp parses a timestamp in the format of the a9s Backup Manager.
+func timestamp(value string) time.Time {
+	t, err := time.Parse(time.RFC3339Nano, value)
+	if err != nil {
+		panic(err)
+	}
+	return t
+}
+
 func newBackup(modifiers ...BackupOption) *bkpv1.Backup {
 	backup := &bkpv1.Backup{
 		ObjectMeta: metav1.ObjectMeta{
@@ -566,8 +578,8 @@ func TestObserve(t *testing.T) {
 							InstanceID:   "40a5148f-dba2-41f2-b1b7-0ca90e1501c5",
 							BackupID:     ptr.To[int](29),
 							SizeInBytes:  10,
-							TriggeredAt:  "2023-05-01T01:30:00.742Z",
-							FinishedAt:   "2023-05-01T01:30:28.300Z",
+							TriggeredAt:  &metav1.Time{Time: timestamp("2023-05-01T01:30:00.742Z")},
+							FinishedAt:   &metav1.Time{Time: timestamp("2023-05-01T01:30:28.300Z")},
 							Downloadable: true,
 						}),
 				),
@@ -596,8 +608,8 @@ func TestObserve(t *testing.T) {
 						InstanceID:   "40a5148f-dba2-41f2-b1b7-0ca90e1501c5",
 						BackupID:     ptr.To[int](29),
 						SizeInBytes:  10,
-						TriggeredAt:  "2023-05-01T01:30:00.742Z",
-						FinishedAt:   "2023-05-01T01:30:28.300Z",
+						TriggeredAt:  &metav1.Time{Time: timestamp("2023-05-01T01:30:00.742Z")},
+						FinishedAt:   &metav1.Time{Time: timestamp("2023-05-01T01:30:28.300Z")},
 						Downloadable: true,
 					},
 				)),
@@ -614,8 +626,8 @@ func TestObserve(t *testing.T) {
 								InstanceID:   "40a5148f-dba2-41f2-b1b7-0ca90e1501c5",
 								BackupID:     ptr.To[int](29),
 								SizeInBytes:  10,
-								TriggeredAt:  "2023-05-01T01:30:00.742Z",
-								FinishedAt:   "2023-05-01T01:30:28.300Z",
+								TriggeredAt:  &metav1.Time{Time: timestamp("2023-05-01T01:30:00.742Z")},
+								FinishedAt:   &metav1.Time{Time: timestamp("2023-05-01T01:30:28.300Z")},
 								Downloadable: true,
 							},
 						),
@@ -991,3 +1003,105 @@ func TestDelete(t *testing.T) {
 		})
 	}
 }
+
+func TestBackupManagerLifecycle(t *testing.T) {
+	t.Parallel()
+
+	const instanceID = "40a5148f-dba2-41f2-b1b7-0ca90e1501c5"
+
+	cases := map[string]struct {
+		fail          bool
+		wantState     string
+		wantCondition cmnv1.Condition
+	}{
+		"successRestoreIsDone": {
+			wantState:     v1.StatusDone,
+			wantCondition: cmnv1.Available().WithMessage("Restore completed successfully"),
+		},
+		"successRestoreHasFailed": {
+			fail:          true,
+			wantState:     v1.StatusFailed,
+			wantCondition: cmnv1.Unavailable().WithMessage("Restore has failed"),
+		},
+	}
+
+	for name, tc := range cases {
+		tc := tc
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			ctx := context.Background()
+			manager := bmtest.NewBackupManager(bmtest.Options{PollsPerState: 1})
+			defer manager.Close()
+			manager.AddInstance(instanceID)
+
+			klient, err := a9sbackupmanager.NewClient(manager.ClientConfiguration())
+			if err != nil {
+				t.Fatalf("cannot create client: %v", err)
+			}
+
+			// The backup is observed until it is done, so that it can be
+			// restored.
+			backup, err := klient.CreateBackup(ctx, &a9sbackupmanager.CreateBackupRequest{InstanceID: instanceID})
+			if err != nil {
+				t.Fatalf("CreateBackup(...): unexpected error: %v", err)
+			}
+			for range 3 {
+				if _, err := klient.GetBackup(ctx, &a9sbackupmanager.GetBackupRequest{
+					InstanceID: instanceID,
+					BackupID:   fmt.Sprint(*backup.BackupID),
+				}); err != nil {
+					t.Fatalf("GetBackup(...): unexpected error: %v", err)
+				}
+			}
+			if tc.fail {
+				manager.FailNextRestore()
+			}
+
+			e := &external{service: klient}
+			mr := newRestore(initializeRestoreStatus(instanceID, *backup.BackupID))
+
+			observe := func(step string) managed.ExternalObservation {
+				t.Helper()
+				observation, err := e.Observe(ctx, mr)
+				if err != nil {
+					t.Fatalf("%s: Observe(...): unexpected error: %v", step, err)
+				}
+				return observation
+			}
+
+			if observation := observe("before create"); observation.ResourceExists {
+				t.Fatalf("before create: expected the restore not to exist")
+			}
+			if _, err := e.Create(ctx, mr); err != nil {
+				t.Fatalf("Create(...): unexpected error: %v", err)
+			}
+
+			for _, state := range []string{v1.StatusQueued, v1.StatusRunning} {
+				if observation := observe("while " + state); !observation.ResourceExists || mr.Status.AtProvider.State != state {
+					t.Errorf("while %s: unexpected observation %+v, state %q", state, observation, mr.Status.AtProvider.State)
+				}
+				if diff := cmp.Diff(cmnv1.Creating(), mr.GetCondition(cmnv1.TypeReady), test.EquateConditions()); diff != "" {
+					t.Errorf("while %s: -want condition, +got condition:\n%s", state, diff)
+				}
+			}
+
+			// The restore cannot be deleted while it is running.
+			if _, err := e.Delete(ctx, mr); !errors.Is(err, errRestoreRunning) {
+				t.Errorf("Delete(...) while running: expected error %v, got %v", errRestoreRunning, err)
+			}
+
+			observe("when finished")
+			if mr.Status.AtProvider.State != tc.wantState {
+				t.Errorf("when finished: expected state %q, got %q", tc.wantState, mr.Status.AtProvider.State)
+			}
+			if diff := cmp.Diff(tc.wantCondition, mr.GetCondition(cmnv1.TypeReady), test.EquateConditions()); diff != "" {
+				t.Errorf("when finished: -want condition, +got condition:\n%s", diff)
+			}
+			if _, err := e.Delete(ctx, mr); err != nil {
+				t.Errorf("Delete(...) when finished: unexpected error: %v", err)
+			}
+		})
+	}
+}
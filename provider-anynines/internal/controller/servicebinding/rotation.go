@@ -0,0 +1,194 @@
+/*
+Copyright 2024 Klutch Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package servicebinding
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	osbclient "github.com/anynines/klutchio/clients/a9s-open-service-broker"
+	"github.com/crossplane/crossplane-runtime/pkg/meta"
+	"github.com/crossplane/crossplane-runtime/pkg/reconciler/managed"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	v1 "github.com/anynines/klutchio/provider-anynines/apis/servicebinding/v1"
+	utilerr "github.com/anynines/klutchio/provider-anynines/pkg/utilerr"
+)
+
+// defaultRotationGracePeriod is the time the predecessor of a rotated binding
+// is kept if the rotation policy does not configure a grace period.
+const defaultRotationGracePeriod = time.Hour
+
+var (
+	errRotateServiceBinding = utilerr.FromStr("failed to rotate the credentials of ServiceBinding")
+	errUnbindPredecessor    = utilerr.FromStr("failed to unbind the predecessor of ServiceBinding")
+	errRecordSuccessor      = utilerr.FromStr("failed to record the rotated binding of ServiceBinding")
+
+	errAsyncSuccessor = errors.New("the service broker created the rotated binding asynchronously, although it was requested synchronously")
+)
+
+// rotationPending returns whether Update has to be called to rotate the
+// credentials of the binding or to unbind the predecessor of the last
+// rotation. A rotation is only started once the predecessor of the previous
+// one is unbound, so that there are at most two bindings at a time.
+func (c *external) rotationPending(sb *v1.ServiceBinding, metadata *osbclient.BindingMetadata) bool {
+	if predecessorBindingID(sb) != "" {
+		unbindTime := sb.Status.AtProvider.PredecessorUnbindTime
+		return unbindTime == nil || !c.now().Before(unbindTime.Time)
+	}
+
+	if trigger := sb.Annotations[AnnotationKeyRotateCredentials]; trigger != "" && trigger != sb.Status.AtProvider.RotationTrigger {
+		return true
+	}
+
+	policy := sb.Spec.ForProvider.Rotation
+	if policy == nil {
+		return false
+	}
+	if policy.Interval != nil && policy.Interval.Duration > 0 {
+		lastRotation := sb.CreationTimestamp.Time
+		if sb.Status.AtProvider.LastRotationTime != nil {
+			lastRotation = sb.Status.AtProvider.LastRotationTime.Time
+		}
+		if !c.now().Before(lastRotation.Add(policy.Interval.Duration)) {
+			return true
+		}
+	}
+	if metadata != nil && metadata.RenewBefore != "" {
+		renewBefore, err := time.Parse(time.RFC3339, metadata.RenewBefore)
+		if err == nil && !c.now().Before(renewBefore) {
+			return true
+		}
+	}
+	return false
+}
+
+// rotate creates a successor of the current binding and returns its
+// credentials, which replace the credentials in the connection secret. The
+// successor is created synchronously, so that its credentials can be
+// published right away. Brokers that support binding rotation are told which
+// binding the successor replaces. Rotations are made by the provider, not on
+// behalf of a user, so their requests carry no originating identity.
+//
+// The ID of the successor is persisted as the external name, and the ID of
+// the predecessor in an annotation, before rotate returns, because the
+// managed reconciler saves the status after Update with a single status
+// update, and a lost status must orphan neither binding.
+// The successor is unbound again if its credentials cannot be published or
+// its ID cannot be persisted, so that retries do not leak bindings.
+func (c *external) rotate(ctx context.Context, sb *v1.ServiceBinding) (managed.ExternalUpdate, error) {
+	predecessor := sb.CurrentBindingID()
+	bindReq := &osbclient.BindRequest{
+		BindingID:  c.newBindingID(),
+		InstanceID: sb.Status.AtProvider.InstanceID,
+		ServiceID:  sb.Status.AtProvider.ServiceID,
+		PlanID:     sb.Status.AtProvider.PlanID,
+	}
+	if c.service.Capabilities().BindingRotation {
+		bindReq.PredecessorBindingID = &predecessor
+	}
+
+	resp, err := c.service.Bind(ctx, bindReq)
+	if err != nil {
+		return managed.ExternalUpdate{}, errRotateServiceBinding.WithCause(err)
+	}
+	if resp.Async {
+		return managed.ExternalUpdate{}, errRotateServiceBinding.WithCause(
+			errors.Join(errAsyncSuccessor, c.unbindSuccessor(ctx, sb, bindReq.BindingID)))
+	}
+	cd, err := generateConnectionDetails(resp)
+	if err != nil {
+		return managed.ExternalUpdate{}, errRotateServiceBinding.WithCause(
+			errors.Join(err, c.unbindSuccessor(ctx, sb, bindReq.BindingID)))
+	}
+
+	externalName := meta.GetExternalName(sb)
+	meta.SetExternalName(sb, bindReq.BindingID)
+	meta.AddAnnotations(sb, map[string]string{AnnotationKeyPredecessorBindingID: predecessor})
+	if err := c.annotations.UpdateCriticalAnnotations(ctx, sb); err != nil {
+		meta.SetExternalName(sb, externalName)
+		meta.RemoveAnnotations(sb, AnnotationKeyPredecessorBindingID)
+		return managed.ExternalUpdate{}, errRecordSuccessor.WithCause(
+			errors.Join(err, c.unbindSuccessor(ctx, sb, bindReq.BindingID)))
+	}
+
+	now := c.now()
+	gracePeriod := defaultRotationGracePeriod
+	if policy := sb.Spec.ForProvider.Rotation; policy != nil && policy.GracePeriod != nil {
+		gracePeriod = policy.GracePeriod.Duration
+	}
+	sb.Status.AtProvider.PredecessorBindingID = predecessor
+	sb.Status.AtProvider.PredecessorUnbindTime = &metav1.Time{Time: now.Add(gracePeriod)}
+	sb.Status.AtProvider.LastRotationTime = &metav1.Time{Time: now}
+	sb.Status.AtProvider.RotationTrigger = sb.Annotations[AnnotationKeyRotateCredentials]
+
+	return managed.ExternalUpdate{ConnectionDetails: cd}, nil
+}
+
+// unbindSuccessor deletes a successor created by rotate that cannot be used.
+func (c *external) unbindSuccessor(ctx context.Context, sb *v1.ServiceBinding, bindingID string) error {
+	_, err := c.service.Unbind(ctx, &osbclient.UnbindRequest{
+		BindingID:         bindingID,
+		InstanceID:        sb.Status.AtProvider.InstanceID,
+		AcceptsIncomplete: c.acceptsIncomplete(sb),
+		ServiceID:         sb.Status.AtProvider.ServiceID,
+		PlanID:            sb.Status.AtProvider.PlanID,
+	})
+	return err
+}
+
+// predecessorBindingID returns the ID of the binding replaced by the last
+// rotation, or an empty string if it is unbound. The ID is taken from the
+// annotation if the status update that followed the rotation was lost; the
+// predecessor is then unbound without waiting for the grace period, as its
+// unbind time was lost as well.
+func predecessorBindingID(sb *v1.ServiceBinding) string {
+	if id := sb.Status.AtProvider.PredecessorBindingID; id != "" {
+		return id
+	}
+	return sb.GetAnnotations()[AnnotationKeyPredecessorBindingID]
+}
+
+// unbindPredecessor deletes the binding replaced by the last rotation and
+// removes its annotation. A predecessor that is already gone counts as
+// unbound, so that a lost status or annotation update is simply retried.
+// The status is cleared only after the annotation update, which reloads the
+// ServiceBinding on conflicts.
+func (c *external) unbindPredecessor(ctx context.Context, sb *v1.ServiceBinding) error {
+	_, err := c.service.Unbind(ctx, &osbclient.UnbindRequest{
+		BindingID:         predecessorBindingID(sb),
+		InstanceID:        sb.Status.AtProvider.InstanceID,
+		AcceptsIncomplete: c.acceptsIncomplete(sb),
+		ServiceID:         sb.Status.AtProvider.ServiceID,
+		PlanID:            sb.Status.AtProvider.PlanID,
+	})
+	if err != nil && !osbclient.IsGoneError(err) {
+		return errUnbindPredecessor.WithCause(err)
+	}
+
+	if _, ok := sb.GetAnnotations()[AnnotationKeyPredecessorBindingID]; ok {
+		meta.RemoveAnnotations(sb, AnnotationKeyPredecessorBindingID)
+		if err := c.annotations.UpdateCriticalAnnotations(ctx, sb); err != nil {
+			return errUnbindPredecessor.WithCause(err)
+		}
+	}
+
+	sb.Status.AtProvider.PredecessorBindingID = ""
+	sb.Status.AtProvider.PredecessorUnbindTime = nil
+	return nil
+}
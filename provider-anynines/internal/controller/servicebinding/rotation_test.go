@@ -0,0 +1,585 @@
+/*
+Copyright 2024 Klutch Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package servicebinding
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/crossplane/crossplane-runtime/pkg/meta"
+	"github.com/crossplane/crossplane-runtime/pkg/reconciler/managed"
+	"github.com/google/go-cmp/cmp"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	osbclient "github.com/anynines/klutchio/clients/a9s-open-service-broker"
+	fakeosb "github.com/anynines/klutchio/clients/a9s-open-service-broker/fake"
+	v1 "github.com/anynines/klutchio/provider-anynines/apis/servicebinding/v1"
+)
+
+const successorBindingID = "0d7bd6f3-8f5a-4d0c-9a43-5f1f3e0b7c21"
+
+var rotationNow = time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+
+func withRotationPolicy(policy *v1.RotationPolicy) func(*v1.ServiceBinding) {
+	return func(sb *v1.ServiceBinding) {
+		sb.Spec.ForProvider.Rotation = policy
+	}
+}
+
+func withRotateAnnotation(value string) func(*v1.ServiceBinding) {
+	return func(sb *v1.ServiceBinding) {
+		meta.AddAnnotations(sb, map[string]string{
+			"anynines.crossplane.io/rotate-credentials": value,
+		})
+	}
+}
+
+func withCreationTimestamp(t time.Time) func(*v1.ServiceBinding) {
+	return func(sb *v1.ServiceBinding) {
+		sb.CreationTimestamp = metav1.NewTime(t)
+	}
+}
+
+func withExternalName(name string) func(*v1.ServiceBinding) {
+	return func(sb *v1.ServiceBinding) {
+		meta.SetExternalName(sb, name)
+	}
+}
+
+func withPredecessorAnnotation(bindingID string) func(*v1.ServiceBinding) {
+	return func(sb *v1.ServiceBinding) {
+		meta.AddAnnotations(sb, map[string]string{
+			"anynines.crossplane.io/predecessor-binding-id": bindingID,
+		})
+	}
+}
+
+func withRotationStatus(mutate func(*v1.ServiceBindingObservation)) func(*v1.ServiceBinding) {
+	return func(sb *v1.ServiceBinding) {
+		mutate(&sb.Status.AtProvider)
+	}
+}
+
+func TestRotationPending(t *testing.T) {
+	t.Parallel()
+
+	cases := map[string]struct {
+		sb       *v1.ServiceBinding
+		metadata *osbclient.BindingMetadata
+		expected bool
+	}{
+		"no_rotation_policy": {
+			sb:       serviceBinding("postgresql", withCreationTimestamp(rotationNow.Add(-24*time.Hour))),
+			expected: false,
+		},
+		"annotation_set": {
+			sb:       serviceBinding("postgresql", withRotateAnnotation("2026-10-16")),
+			expected: true,
+		},
+		"annotation_already_handled": {
+			sb: serviceBinding("postgresql",
+				withRotateAnnotation("2026-10-16"),
+				withRotationStatus(func(o *v1.ServiceBindingObservation) { o.RotationTrigger = "2026-10-16" }),
+			),
+			expected: false,
+		},
+		"interval_elapsed_since_creation": {
+			sb: serviceBinding("postgresql",
+				withCreationTimestamp(rotationNow.Add(-2*time.Hour)),
+				withRotationPolicy(&v1.RotationPolicy{Interval: &metav1.Duration{Duration: time.Hour}}),
+			),
+			expected: true,
+		},
+		"interval_not_elapsed_since_last_rotation": {
+			sb: serviceBinding("postgresql",
+				withCreationTimestamp(rotationNow.Add(-2*time.Hour)),
+				withRotationPolicy(&v1.RotationPolicy{Interval: &metav1.Duration{Duration: time.Hour}}),
+				withRotationStatus(func(o *v1.ServiceBindingObservation) {
+					o.LastRotationTime = &metav1.Time{Time: rotationNow.Add(-30 * time.Minute)}
+				}),
+			),
+			expected: false,
+		},
+		"broker_asks_for_renewal": {
+			sb: serviceBinding("postgresql",
+				withCreationTimestamp(rotationNow.Add(-2*time.Hour)),
+				withRotationPolicy(&v1.RotationPolicy{}),
+			),
+			metadata: &osbclient.BindingMetadata{RenewBefore: "2026-10-16T11:00:00Z"},
+			expected: true,
+		},
+		"broker_renewal_ignored_without_policy": {
+			sb:       serviceBinding("postgresql", withCreationTimestamp(rotationNow.Add(-2*time.Hour))),
+			metadata: &osbclient.BindingMetadata{RenewBefore: "2026-10-16T11:00:00Z"},
+			expected: false,
+		},
+		"predecessor_within_grace_period": {
+			sb: serviceBinding("postgresql",
+				withRotateAnnotation("again"),
+				withRotationStatus(func(o *v1.ServiceBindingObservation) {
+					o.PredecessorBindingID = "1a6a6b3e-254e-11ee-be56-0242ac120002"
+					o.PredecessorUnbindTime = &metav1.Time{Time: rotationNow.Add(time.Minute)}
+				}),
+			),
+			expected: false,
+		},
+		"predecessor_grace_period_over": {
+			sb: serviceBinding("postgresql",
+				withRotationStatus(func(o *v1.ServiceBindingObservation) {
+					o.PredecessorBindingID = "1a6a6b3e-254e-11ee-be56-0242ac120002"
+					o.PredecessorUnbindTime = &metav1.Time{Time: rotationNow.Add(-time.Minute)}
+				}),
+			),
+			expected: true,
+		},
+		"predecessor_recorded_only_in_annotation_is_due": {
+			sb: serviceBinding("postgresql",
+				withPredecessorAnnotation("1a6a6b3e-254e-11ee-be56-0242ac120002"),
+			),
+			expected: true,
+		},
+	}
+
+	for name, tc := range cases {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			e := &external{now: func() time.Time { return rotationNow }}
+			if got := e.rotationPending(tc.sb, tc.metadata); got != tc.expected {
+				t.Errorf("rotationPending(...): want %v, got %v", tc.expected, got)
+			}
+		})
+	}
+}
+
+func TestUpdateRotatesCredentials(t *testing.T) {
+	t.Parallel()
+
+	credentials := map[string]interface{}{"username": "rotated", "password": "new-secret"}
+
+	cases := map[string]struct {
+		sb                    *v1.ServiceBinding
+		apiVersion            *osbclient.APIVersion
+		bindReaction          *BindReaction
+		unbindReaction        *UnbindReaction
+		expectedBindReq       *osbclient.BindRequest
+		expectedUnbindReq     *osbclient.UnbindRequest
+		annotationsErr        error
+		expectedUpdate        managed.ExternalUpdate
+		expectedExternalName  string
+		expectedPredecessor   string
+		expectedObservation   v1.ServiceBindingObservation
+		expectedErrorContains string
+	}{
+		"successor_with_predecessor_binding_id": {
+			sb: serviceBinding("postgresql",
+				withServiceBindingParameters(defaultBindingParameters),
+				withRotationPolicy(&v1.RotationPolicy{GracePeriod: &metav1.Duration{Duration: 10 * time.Minute}}),
+				withRotateAnnotation("now"),
+				withExternalName("test-sb-asty"),
+				initializeSBStatus("instance-id", "plan-id", "service-id", nil),
+			),
+			apiVersion:   ptr.To(osbclient.Version2_17()),
+			bindReaction: &BindReaction{Response: &osbclient.BindResponse{Credentials: credentials}},
+			expectedBindReq: &osbclient.BindRequest{
+				BindingID:            successorBindingID,
+				InstanceID:           "instance-id",
+				ServiceID:            "service-id",
+				PlanID:               "plan-id",
+				PredecessorBindingID: ptr.To("1a6a6b3e-254e-11ee-be56-0242ac120002"),
+			},
+			expectedUpdate: managed.ExternalUpdate{ConnectionDetails: managed.ConnectionDetails{
+				"username": []byte("rotated"),
+				"password": []byte("new-secret"),
+			}},
+			expectedExternalName: successorBindingID,
+			expectedPredecessor:  "1a6a6b3e-254e-11ee-be56-0242ac120002",
+			expectedObservation: v1.ServiceBindingObservation{
+				InstanceID:            "instance-id",
+				PlanID:                "plan-id",
+				ServiceID:             "service-id",
+				PredecessorBindingID:  "1a6a6b3e-254e-11ee-be56-0242ac120002",
+				PredecessorUnbindTime: &metav1.Time{Time: rotationNow.Add(10 * time.Minute)},
+				LastRotationTime:      &metav1.Time{Time: rotationNow},
+				RotationTrigger:       "now",
+			},
+		},
+		"successor_without_broker_support": {
+			sb: serviceBinding("postgresql",
+				withServiceBindingParameters(defaultBindingParameters),
+				initializeSBStatus("instance-id", "plan-id", "service-id", nil),
+				withExternalName("previous-successor"),
+			),
+			bindReaction: &BindReaction{Response: &osbclient.BindResponse{Credentials: credentials}},
+			expectedBindReq: &osbclient.BindRequest{
+				BindingID:  successorBindingID,
+				InstanceID: "instance-id",
+				ServiceID:  "service-id",
+				PlanID:     "plan-id",
+			},
+			expectedUpdate: managed.ExternalUpdate{ConnectionDetails: managed.ConnectionDetails{
+				"username": []byte("rotated"),
+				"password": []byte("new-secret"),
+			}},
+			expectedExternalName: successorBindingID,
+			expectedPredecessor:  "previous-successor",
+			expectedObservation: v1.ServiceBindingObservation{
+				InstanceID:            "instance-id",
+				PlanID:                "plan-id",
+				ServiceID:             "service-id",
+				PredecessorBindingID:  "previous-successor",
+				PredecessorUnbindTime: &metav1.Time{Time: rotationNow.Add(time.Hour)},
+				LastRotationTime:      &metav1.Time{Time: rotationNow},
+			},
+		},
+		"bind_error_keeps_current_binding": {
+			sb: serviceBinding("postgresql",
+				withServiceBindingParameters(defaultBindingParameters),
+				initializeSBStatus("instance-id", "plan-id", "service-id", nil),
+				withExternalName("test-sb-asty"),
+			),
+			bindReaction: &BindReaction{Error: errors.New("broker unavailable")},
+			expectedBindReq: &osbclient.BindRequest{
+				BindingID:  successorBindingID,
+				InstanceID: "instance-id",
+				ServiceID:  "service-id",
+				PlanID:     "plan-id",
+			},
+			expectedExternalName: "test-sb-asty",
+			expectedObservation: v1.ServiceBindingObservation{
+				InstanceID: "instance-id",
+				PlanID:     "plan-id",
+				ServiceID:  "service-id",
+			},
+			expectedErrorContains: "broker unavailable",
+		},
+		"successor_is_unbound_if_it_cannot_be_recorded": {
+			sb: serviceBinding("postgresql",
+				withServiceBindingParameters(defaultBindingParameters),
+				initializeSBStatus("instance-id", "plan-id", "service-id", nil),
+				withExternalName("test-sb-asty"),
+			),
+			bindReaction:   &BindReaction{Response: &osbclient.BindResponse{Credentials: credentials}},
+			unbindReaction: &UnbindReaction{Response: &osbclient.UnbindResponse{}},
+			annotationsErr: errors.New("conflict"),
+			expectedBindReq: &osbclient.BindRequest{
+				BindingID:  successorBindingID,
+				InstanceID: "instance-id",
+				ServiceID:  "service-id",
+				PlanID:     "plan-id",
+			},
+			expectedUnbindReq: &osbclient.UnbindRequest{
+				BindingID:  successorBindingID,
+				InstanceID: "instance-id",
+				ServiceID:  "service-id",
+				PlanID:     "plan-id",
+			},
+			expectedExternalName: "test-sb-asty",
+			expectedObservation: v1.ServiceBindingObservation{
+				InstanceID: "instance-id",
+				PlanID:     "plan-id",
+				ServiceID:  "service-id",
+			},
+			expectedErrorContains: "conflict",
+		},
+		"successor_is_unbound_if_it_has_no_credentials": {
+			sb: serviceBinding("postgresql",
+				withServiceBindingParameters(defaultBindingParameters),
+				initializeSBStatus("instance-id", "plan-id", "service-id", nil),
+				withExternalName("test-sb-asty"),
+			),
+			bindReaction:   &BindReaction{Response: &osbclient.BindResponse{}},
+			unbindReaction: &UnbindReaction{Response: &osbclient.UnbindResponse{}},
+			expectedBindReq: &osbclient.BindRequest{
+				BindingID:  successorBindingID,
+				InstanceID: "instance-id",
+				ServiceID:  "service-id",
+				PlanID:     "plan-id",
+			},
+			expectedUnbindReq: &osbclient.UnbindRequest{
+				BindingID:  successorBindingID,
+				InstanceID: "instance-id",
+				ServiceID:  "service-id",
+				PlanID:     "plan-id",
+			},
+			expectedExternalName: "test-sb-asty",
+			expectedObservation: v1.ServiceBindingObservation{
+				InstanceID: "instance-id",
+				PlanID:     "plan-id",
+				ServiceID:  "service-id",
+			},
+			expectedErrorContains: "no credentials",
+		},
+		"successor_is_unbound_if_it_is_created_asynchronously": {
+			sb: serviceBinding("postgresql",
+				withServiceBindingParameters(defaultBindingParameters),
+				initializeSBStatus("instance-id", "plan-id", "service-id", nil),
+				withExternalName("test-sb-asty"),
+			),
+			bindReaction:   &BindReaction{Response: &osbclient.BindResponse{Async: true}},
+			unbindReaction: &UnbindReaction{Response: &osbclient.UnbindResponse{}},
+			expectedBindReq: &osbclient.BindRequest{
+				BindingID:  successorBindingID,
+				InstanceID: "instance-id",
+				ServiceID:  "service-id",
+				PlanID:     "plan-id",
+			},
+			expectedUnbindReq: &osbclient.UnbindRequest{
+				BindingID:  successorBindingID,
+				InstanceID: "instance-id",
+				ServiceID:  "service-id",
+				PlanID:     "plan-id",
+			},
+			expectedExternalName: "test-sb-asty",
+			expectedObservation: v1.ServiceBindingObservation{
+				InstanceID: "instance-id",
+				PlanID:     "plan-id",
+				ServiceID:  "service-id",
+			},
+			expectedErrorContains: "asynchronously",
+		},
+		"predecessor_is_unbound": {
+			sb: serviceBinding("postgresql",
+				withServiceBindingParameters(defaultBindingParameters),
+				initializeSBStatus("instance-id", "plan-id", "service-id", nil),
+				withExternalName(successorBindingID),
+				withPredecessorAnnotation("1a6a6b3e-254e-11ee-be56-0242ac120002"),
+				withRotationStatus(func(o *v1.ServiceBindingObservation) {
+					o.PredecessorBindingID = "1a6a6b3e-254e-11ee-be56-0242ac120002"
+					o.PredecessorUnbindTime = &metav1.Time{Time: rotationNow.Add(-time.Minute)}
+					o.LastRotationTime = &metav1.Time{Time: rotationNow.Add(-time.Hour)}
+				}),
+			),
+			unbindReaction: &UnbindReaction{Response: &osbclient.UnbindResponse{}},
+			expectedUnbindReq: &osbclient.UnbindRequest{
+				BindingID:  "1a6a6b3e-254e-11ee-be56-0242ac120002",
+				InstanceID: "instance-id",
+				ServiceID:  "service-id",
+				PlanID:     "plan-id",
+			},
+			expectedExternalName: successorBindingID,
+			expectedObservation: v1.ServiceBindingObservation{
+				InstanceID:       "instance-id",
+				PlanID:           "plan-id",
+				ServiceID:        "service-id",
+				LastRotationTime: &metav1.Time{Time: rotationNow.Add(-time.Hour)},
+			},
+		},
+		"predecessor_is_unbound_after_status_loss": {
+			sb: serviceBinding("postgresql",
+				withServiceBindingParameters(defaultBindingParameters),
+				initializeSBStatus("instance-id", "plan-id", "service-id", nil),
+				withExternalName(successorBindingID),
+				withPredecessorAnnotation("1a6a6b3e-254e-11ee-be56-0242ac120002"),
+			),
+			unbindReaction: &UnbindReaction{Response: &osbclient.UnbindResponse{}},
+			expectedUnbindReq: &osbclient.UnbindRequest{
+				BindingID:  "1a6a6b3e-254e-11ee-be56-0242ac120002",
+				InstanceID: "instance-id",
+				ServiceID:  "service-id",
+				PlanID:     "plan-id",
+			},
+			expectedExternalName: successorBindingID,
+			expectedObservation: v1.ServiceBindingObservation{
+				InstanceID: "instance-id",
+				PlanID:     "plan-id",
+				ServiceID:  "service-id",
+			},
+		},
+		"gone_predecessor_counts_as_unbound": {
+			sb: serviceBinding("postgresql",
+				withServiceBindingParameters(defaultBindingParameters),
+				initializeSBStatus("instance-id", "plan-id", "service-id", nil),
+				withExternalName(successorBindingID),
+				withPredecessorAnnotation("1a6a6b3e-254e-11ee-be56-0242ac120002"),
+			),
+			unbindReaction: &UnbindReaction{Error: osbclient.HTTPStatusCodeError{StatusCode: http.StatusGone}},
+			expectedUnbindReq: &osbclient.UnbindRequest{
+				BindingID:  "1a6a6b3e-254e-11ee-be56-0242ac120002",
+				InstanceID: "instance-id",
+				ServiceID:  "service-id",
+				PlanID:     "plan-id",
+			},
+			expectedExternalName: successorBindingID,
+			expectedObservation: v1.ServiceBindingObservation{
+				InstanceID: "instance-id",
+				PlanID:     "plan-id",
+				ServiceID:  "service-id",
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			config := fakeosb.FakeClientConfiguration{APIVersion: tc.apiVersion}
+			if tc.bindReaction != nil {
+				config.BindReaction = tc.bindReaction
+			}
+			if tc.unbindReaction != nil {
+				config.UnbindReaction = tc.unbindReaction
+			}
+			e := &external{
+				service: fakeosb.NewFakeClient(config),
+				annotations: managed.CriticalAnnotationUpdateFn(func(context.Context, client.Object) error {
+					return tc.annotationsErr
+				}),
+				now:          func() time.Time { return rotationNow },
+				newBindingID: func() string { return successorBindingID },
+			}
+
+			got, err := e.Update(context.TODO(), tc.sb)
+			if tc.expectedErrorContains != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.expectedErrorContains) {
+					t.Errorf("Update(...): expected error containing %q, got %v", tc.expectedErrorContains, err)
+				}
+			} else if err != nil {
+				t.Errorf("Update(...): unexpected error: %v", err)
+			}
+
+			if diff := cmp.Diff(tc.expectedUpdate, got); diff != "" {
+				t.Errorf("Update(...): -want, +got:\n%s", diff)
+			}
+			if tc.bindReaction != nil {
+				if diff := cmp.Diff(tc.expectedBindReq, tc.bindReaction.getLastBindRequest()); diff != "" {
+					t.Errorf("BindRequest: -want, +got:\n%s", diff)
+				}
+			}
+			if tc.unbindReaction != nil {
+				if diff := cmp.Diff(tc.expectedUnbindReq, tc.unbindReaction.getLastUnbindRequest()); diff != "" {
+					t.Errorf("UnbindRequest: -want, +got:\n%s", diff)
+				}
+			}
+			if got := meta.GetExternalName(tc.sb); got != tc.expectedExternalName {
+				t.Errorf("external name: want %q, got %q", tc.expectedExternalName, got)
+			}
+			if got := tc.sb.GetAnnotations()[AnnotationKeyPredecessorBindingID]; got != tc.expectedPredecessor {
+				t.Errorf("predecessor annotation: want %q, got %q", tc.expectedPredecessor, got)
+			}
+			if diff := cmp.Diff(tc.expectedObservation, tc.sb.Status.AtProvider); diff != "" {
+				t.Errorf("status.atProvider: -want, +got:\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestDeleteUnbindsPredecessor(t *testing.T) {
+	t.Parallel()
+
+	sb := serviceBinding("postgresql",
+		withServiceBindingParameters(defaultBindingParameters),
+		initializeSBStatus("instance-id", "plan-id", "service-id", nil),
+		withExternalName(successorBindingID),
+		withRotationStatus(func(o *v1.ServiceBindingObservation) {
+			o.PredecessorBindingID = "1a6a6b3e-254e-11ee-be56-0242ac120002"
+			o.PredecessorUnbindTime = &metav1.Time{Time: rotationNow.Add(time.Hour)}
+		}),
+	)
+
+	fakeOSB := fakeosb.NewFakeClient(fakeosb.FakeClientConfiguration{
+		UnbindReaction: &UnbindReaction{Response: &osbclient.UnbindResponse{}},
+	})
+	e := &external{service: fakeOSB}
+
+	if _, err := e.Delete(context.TODO(), sb); err != nil {
+		t.Fatalf("Delete(...): unexpected error: %v", err)
+	}
+
+	var unbound []string
+	for _, action := range fakeOSB.Actions() {
+		if action.Type == fakeosb.Unbind {
+			unbound = append(unbound, action.Request.(*osbclient.UnbindRequest).BindingID)
+		}
+	}
+	if diff := cmp.Diff([]string{"1a6a6b3e-254e-11ee-be56-0242ac120002", successorBindingID}, unbound); diff != "" {
+		t.Errorf("unbound bindings: -want, +got:\n%s", diff)
+	}
+}
+
+func TestDeleteUnbindsBothBindingsAfterStatusLoss(t *testing.T) {
+	t.Parallel()
+
+	sb := serviceBinding("postgresql",
+		withServiceBindingParameters(defaultBindingParameters),
+		withExternalName("test-sb-asty"),
+		initializeSBStatus("instance-id", "plan-id", "service-id", nil),
+	)
+	kube := fake.NewClientBuilder().WithScheme(newRotationScheme(t)).WithObjects(sb.DeepCopy()).Build()
+
+	fakeOSB := fakeosb.NewFakeClient(fakeosb.FakeClientConfiguration{
+		BindReaction:   &BindReaction{Response: &osbclient.BindResponse{Credentials: map[string]interface{}{"password": "new-secret"}}},
+		UnbindReaction: &UnbindReaction{Response: &osbclient.UnbindResponse{}},
+	})
+	e := &external{
+		service:      fakeOSB,
+		annotations:  managed.NewRetryingCriticalAnnotationUpdater(kube),
+		now:          func() time.Time { return rotationNow },
+		newBindingID: func() string { return successorBindingID },
+	}
+
+	rotating := &v1.ServiceBinding{}
+	if err := kube.Get(context.TODO(), client.ObjectKeyFromObject(sb), rotating); err != nil {
+		t.Fatalf("Get(...): unexpected error: %v", err)
+	}
+	if _, err := e.Update(context.TODO(), rotating); err != nil {
+		t.Fatalf("Update(...): unexpected error: %v", err)
+	}
+
+	// The status update that follows Update in the managed reconciler is
+	// lost, so the stored ServiceBinding has no record of the rotation in its
+	// status, only in its annotations.
+	stored := &v1.ServiceBinding{}
+	if err := kube.Get(context.TODO(), client.ObjectKeyFromObject(sb), stored); err != nil {
+		t.Fatalf("Get(...): unexpected error: %v", err)
+	}
+	stored.Status = sb.Status
+
+	if _, err := e.Delete(context.TODO(), stored); err != nil {
+		t.Fatalf("Delete(...): unexpected error: %v", err)
+	}
+
+	var unbound []string
+	for _, action := range fakeOSB.Actions() {
+		if action.Type == fakeosb.Unbind {
+			unbound = append(unbound, action.Request.(*osbclient.UnbindRequest).BindingID)
+		}
+	}
+	if diff := cmp.Diff([]string{string(sb.UID), successorBindingID}, unbound); diff != "" {
+		t.Errorf("unbound bindings: -want, +got:\n%s", diff)
+	}
+}
+
+func newRotationScheme(t *testing.T) *runtime.Scheme {
+	t.Helper()
+
+	s := runtime.NewScheme()
+	if err := v1.SchemeBuilder.AddToScheme(s); err != nil {
+		t.Fatalf("cannot add ServiceBinding to scheme: %v", err)
+	}
+	return s
+}
@@ -21,8 +21,10 @@ import (
 	"fmt"
 	"net/url"
 	"strings"
+	"time"
 
 	osbclient "github.com/anynines/klutchio/clients/a9s-open-service-broker"
+	"github.com/google/uuid"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/types"
 	ctrl "sigs.k8s.io/controller-runtime"
@@ -44,6 +46,8 @@ import (
 	util "github.com/anynines/klutchio/provider-anynines/internal/controller/utils"
 	client "github.com/anynines/klutchio/provider-anynines/pkg/client/osb"
 	"github.com/anynines/klutchio/provider-anynines/pkg/constants"
+	"github.com/anynines/klutchio/provider-anynines/pkg/originatingidentity"
+	"github.com/anynines/klutchio/provider-anynines/pkg/tracing"
 	utilerr "github.com/anynines/klutchio/provider-anynines/pkg/utilerr"
 	utils "github.com/anynines/klutchio/provider-anynines/pkg/utils"
 )
@@ -53,6 +57,15 @@ const (
 	// created or not.
 	AnnotationKeyServiceBindingCreated = "anynines.crossplane.io/servicebinding-created"
 
+	// AnnotationKeyRotateCredentials triggers a rotation of the credentials
+	// of a servicebinding whenever its value changes.
+	AnnotationKeyRotateCredentials = "anynines.crossplane.io/rotate-credentials"
+
+	// AnnotationKeyPredecessorBindingID records the binding replaced by the
+	// last rotation of the credentials of a servicebinding until it is
+	// unbound.
+	AnnotationKeyPredecessorBindingID = "anynines.crossplane.io/predecessor-binding-id"
+
 	serviceBindingStatusCreated  = "Created"
 	serviceBindingStatusDeleting = "Deleting"
 )
@@ -98,9 +111,10 @@ func Setup(mgr ctrl.Manager, o controller.Options) error {
 // A connector is expected to produce an ExternalClient when its Connect method
 // is called.
 type connector struct {
+	logger       logging.Logger
 	kube         k8sclient.Client
 	usage        resource.Tracker
-	newServiceFn func(username, password []byte, url string, insecureSkipVerify bool, caBundle []byte, overrideServerName string) (osbclient.Client, error)
+	newServiceFn func(url string, opts ...client.Option) (osbclient.Client, error)
 }
 
 // Connect typically produces an ExternalClient by:
@@ -128,20 +142,32 @@ func (c *connector) Connect(ctx context.Context, mg resource.Managed) (managed.E
 		return nil, err
 	}
 
-	svc, err := c.newServiceFn(credentials.Username, credentials.Password, pc.Spec.Url, credentials.InsecureSkipVerify, credentials.CABundle, credentials.OverrideServerName)
+	svc, err := c.newServiceFn(pc.Spec.Url,
+		client.WithBasicAuth(credentials.Username, credentials.Password),
+		client.WithOAuth2(credentials.OAuth2),
+		client.WithTLS(credentials.InsecureSkipVerify, credentials.CABundle, credentials.OverrideServerName),
+		client.ForProviderConfig(pc),
+		client.WithLogger(ctrl.LoggerFrom(ctx)),
+	)
 	if err != nil {
 		return nil, fmt.Errorf("%s: %w", errNewClient, err)
 	}
 
 	return &external{
-		service: svc,
-		kube:    c.kube,
+		logger:       c.logger,
+		service:      svc,
+		kube:         c.kube,
+		annotations:  managed.NewRetryingCriticalAnnotationUpdater(c.kube),
+		now:          time.Now,
+		newBindingID: uuid.NewString,
 	}, nil
 }
 
 // An ExternalClient observes, then either creates, updates, or deletes an
 // external resource to ensure it reflects the managed resource's desired state.
 type external struct {
+	logger logging.Logger
+
 	// A 'client' used to connect to the external resource API. In practice this
 	// would be something like an AWS SDK client.
 	service osbclient.Client
@@ -149,6 +175,14 @@ type external struct {
 	// we need a k8s client in the external struct in order to retrieve the MRs
 	// for Service Instances in order to resolve the Instance names into instance IDs
 	kube k8sclient.Client
+
+	// annotations persists the external name of a binding whose credentials
+	// are rotated, as the managed reconciler only persists it after Create.
+	annotations managed.CriticalAnnotationUpdater
+
+	// now and newBindingID are used to rotate the credentials of bindings.
+	now          func() time.Time
+	newBindingID func() string
 }
 
 func (c *external) Observe(ctx context.Context, mg resource.Managed) (managed.ExternalObservation, error) {
@@ -172,9 +206,9 @@ func (c *external) Observe(ctx context.Context, mg resource.Managed) (managed.Ex
 	isDeleting := sb.DeletionTimestamp != nil
 
 	// Get binding
-	bindResponse, err := c.service.GetBinding(&osbclient.GetBindingRequest{
+	bindResponse, err := c.service.GetBinding(ctx, &osbclient.GetBindingRequest{
 		InstanceID: sb.Status.AtProvider.InstanceID,
-		BindingID:  string(sb.UID),
+		BindingID:  sb.CurrentBindingID(),
 	})
 	if err != nil && isDeleting {
 		// Resource does not exist and is marked for deletion
@@ -184,6 +218,7 @@ func (c *external) Observe(ctx context.Context, mg resource.Managed) (managed.Ex
 	}
 
 	exists := false
+	upToDate := true
 	if bindResponse != nil && bindResponse.Credentials != nil {
 		exists = true
 
@@ -191,6 +226,7 @@ func (c *external) Observe(ctx context.Context, mg resource.Managed) (managed.Ex
 		if !isDeleting {
 			sb.Status.SetConditions(xpv1.Available())
 			sb.Status.AtProvider.State = serviceBindingStatusCreated
+			upToDate = !c.rotationPending(sb, bindResponse.Metadata)
 		}
 	}
 
@@ -202,8 +238,9 @@ func (c *external) Observe(ctx context.Context, mg resource.Managed) (managed.Ex
 
 		// Return false when the external resource exists, but it not up to date
 		// with the desired managed resource state. This lets the managed
-		// resource reconciler know that it needs to call Update.
-		ResourceUpToDate: true,
+		// resource reconciler know that it needs to call Update, which
+		// rotates the credentials of the binding.
+		ResourceUpToDate: upToDate,
 
 		// Return any details that may be required to connect to the external
 		// resource. These will be stored as the connection secret.
@@ -220,14 +257,15 @@ func (c *external) Create(ctx context.Context, mg resource.Managed) (managed.Ext
 	bindReq := &osbclient.BindRequest{
 		// Using the serviceBinding UID provided by Kubernetes as the BindingID
 		// may result in collisions.
-		BindingID:         string(sb.UID),
-		InstanceID:        sb.Status.AtProvider.InstanceID,
-		AcceptsIncomplete: sb.Spec.ForProvider.AcceptsIncomplete,
-		ServiceID:         sb.Status.AtProvider.ServiceID,
-		PlanID:            sb.Status.AtProvider.PlanID,
+		BindingID:           string(sb.UID),
+		InstanceID:          sb.Status.AtProvider.InstanceID,
+		AcceptsIncomplete:   c.acceptsIncomplete(sb),
+		ServiceID:           sb.Status.AtProvider.ServiceID,
+		PlanID:              sb.Status.AtProvider.PlanID,
+		OriginatingIdentity: originatingIdentity(sb),
 	}
 
-	resp, err := c.service.Bind(bindReq)
+	resp, err := c.service.Bind(ctx, bindReq)
 	if err != nil {
 		return managed.ExternalCreation{}, err
 	}
@@ -235,6 +273,8 @@ func (c *external) Create(ctx context.Context, mg resource.Managed) (managed.Ext
 	meta.AddAnnotations(sb, map[string]string{
 		AnnotationKeyServiceBindingCreated: "true",
 	})
+	// The managed reconciler persists the external name after Create.
+	meta.SetExternalName(sb, bindReq.BindingID)
 
 	cd, err := generateConnectionDetails(resp)
 	return managed.ExternalCreation{ConnectionDetails: cd}, err
@@ -315,14 +355,19 @@ func (c *external) initializeServiceBindingStatus(ctx context.Context, sb *v1.Se
 	return errServiceBindingIsUnset
 }
 
+// Update rotates the credentials of the binding. Other changes of service
+// bindings are not supported.
 func (c *external) Update(ctx context.Context, mg resource.Managed) (managed.ExternalUpdate, error) {
-	fmt.Println("Updating Service Bindings is not supported!")
+	sb, err := getServiceBindingFromResource(mg)
+	if err != nil {
+		return managed.ExternalUpdate{}, err
+	}
 
-	return managed.ExternalUpdate{
-		// Optionally return any details that may be required to connect to the
-		// external resource. These will be stored as the connection secret.
-		ConnectionDetails: managed.ConnectionDetails{},
-	}, nil
+	if predecessorBindingID(sb) != "" {
+		return managed.ExternalUpdate{}, c.unbindPredecessor(ctx, sb)
+	}
+
+	return c.rotate(ctx, sb)
 }
 
 func (c *external) Delete(ctx context.Context, mg resource.Managed) (managed.ExternalDelete, error) {
@@ -333,16 +378,22 @@ func (c *external) Delete(ctx context.Context, mg resource.Managed) (managed.Ext
 
 	sb.Status.SetConditions(xpv1.Deleting())
 
+	if predecessorBindingID(sb) != "" {
+		if err := c.unbindPredecessor(ctx, sb); err != nil {
+			return managed.ExternalDelete{}, err
+		}
+	}
+
 	deleteReq := &osbclient.UnbindRequest{
-		BindingID:         string(sb.UID),
+		BindingID:         sb.CurrentBindingID(),
 		InstanceID:        sb.Status.AtProvider.InstanceID,
-		AcceptsIncomplete: sb.Spec.ForProvider.AcceptsIncomplete,
+		AcceptsIncomplete: c.acceptsIncomplete(sb),
 		ServiceID:         sb.Status.AtProvider.ServiceID,
 		PlanID:            sb.Status.AtProvider.PlanID,
 	}
 
 	// TODO: handle response from client
-	_, err = c.service.Unbind(deleteReq)
+	_, err = c.service.Unbind(ctx, deleteReq)
 	if err != nil {
 		return managed.ExternalDelete{}, errDeleteServiceBinding.WithCause(err)
 	}
@@ -350,6 +401,23 @@ func (c *external) Delete(ctx context.Context, mg resource.Managed) (managed.Ext
 	return managed.ExternalDelete{}, nil
 }
 
+// originatingIdentity returns the identity of the user bind requests for the
+// binding are made on behalf of, as recorded by the originating identity
+// webhook. The spec is not trusted, as anyone who may change the binding may
+// set it. Unbind requests carry no identity, as the recorded user is the last
+// one who changed the binding, not the one who deleted it.
+func originatingIdentity(sb *v1.ServiceBinding) *osbclient.OriginatingIdentity {
+	return originatingidentity.FromObject(sb)
+}
+
+// acceptsIncomplete returns whether bind and unbind requests for the binding
+// accept asynchronous operations. Brokers that do not support asynchronous
+// bindings reject requests that accept them, so they are only accepted if the
+// negotiated API version supports them.
+func (c *external) acceptsIncomplete(sb *v1.ServiceBinding) bool {
+	return sb.Spec.ForProvider.AcceptsIncomplete && c.service.Capabilities().AsyncBindings
+}
+
 func (c *external) Disconnect(ctx context.Context) error {
 	// Unimplemented, required by newer versions of crossplane-runtime
 	return nil
@@ -576,13 +644,17 @@ func (c external) initializeInstanceFields(ctx context.Context, sb *v1.ServiceBi
 
 func getExternalConnector(mgr ctrl.Manager, log logging.Logger) utilerr.ConnectDecorator {
 	connec := &connector{
+		logger:       log,
 		kube:         mgr.GetClient(),
 		usage:        resource.NewProviderConfigUsageTracker(mgr.GetClient(), &apisv1.ProviderConfigUsage{}),
-		newServiceFn: client.NewOsbServiceWithTLS,
+		newServiceFn: client.NewOsbServiceWithOptions,
 	}
 	logConnec := &utilerr.ConnectDecorator{
-		Connector: connec,
-		Logger:    log,
+		Connector: tracing.ConnectDecorator{
+			Connector: connec,
+			Kind:      v1.ServiceBindingKind,
+		},
+		Logger: log,
 	}
 	return *logConnec
 }
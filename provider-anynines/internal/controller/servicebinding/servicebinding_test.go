@@ -37,6 +37,7 @@ import (
 	dsv1 "github.com/anynines/klutchio/provider-anynines/apis/serviceinstance/v1"
 	apisv1 "github.com/anynines/klutchio/provider-anynines/apis/v1"
 	a9stest "github.com/anynines/klutchio/provider-anynines/internal/controller/test"
+	osbpkg "github.com/anynines/klutchio/provider-anynines/pkg/client/osb"
 	utilerr "github.com/anynines/klutchio/provider-anynines/pkg/utilerr"
 	"github.com/crossplane/crossplane-runtime/pkg/meta"
 	"github.com/crossplane/crossplane-runtime/pkg/reconciler/managed"
@@ -231,6 +232,51 @@ func TestObserve(t *testing.T) {
 				Error: nil,
 			},
 		},
+		"sb_exists_and_rotation_is_requested": {
+			sb: serviceBinding("postgresql",
+				withServiceBindingParameters(defaultBindingParameters),
+				afterBindingCreation(),
+				withRotateAnnotation("2026-10-16"),
+				initializeSBStatus(
+					"6e2c036c-254f-11ee-be56-0242ac120002",
+					"63d05ec8-254e-11ee-be56-0242ac120002",
+					"76c0089e-254e-11ee-be56-0242ac120002",
+					[]connectionDetails{
+						{hostURL: "test.URL.com", port: "5432", label: "SQL"},
+					},
+				),
+				withAtProvider("Created"),
+			),
+			expectedExternalObservation: managed.ExternalObservation{
+				ResourceExists:          true,
+				ResourceUpToDate:        false,
+				ResourceLateInitialized: false,
+				ConnectionDetails:       managed.ConnectionDetails{},
+			},
+			expectedServiceBinding: serviceBinding("postgresql",
+				withServiceBindingParameters(defaultBindingParameters),
+				afterBindingCreation(),
+				withRotateAnnotation("2026-10-16"),
+				initializeSBStatus(
+					"6e2c036c-254f-11ee-be56-0242ac120002",
+					"63d05ec8-254e-11ee-be56-0242ac120002",
+					"76c0089e-254e-11ee-be56-0242ac120002",
+					[]connectionDetails{
+						{hostURL: "test.URL.com", port: "5432", label: "SQL"},
+					},
+				),
+				withConditions(xpv1.Available()),
+				withAtProvider("Created"),
+			),
+			getBindingReaction: &GetBindReaction{
+				Response: &osbclient.GetBindingResponse{
+					Credentials: map[string]interface{}{
+						"username": "admin",
+						"password": "else",
+					},
+				},
+			},
+		},
 		"sb_is_being_deleted": {
 			sb: serviceBinding("postgresql",
 				withServiceBindingParameters(defaultBindingParameters),
@@ -1427,7 +1473,13 @@ func TestConnect(t *testing.T) {
 		kube: fakeK8s,
 		usage: resource.NewProviderConfigUsageTracker(fakeK8s,
 			&apisv1.ProviderConfigUsage{}),
-		newServiceFn: func(username, password []byte, url string, insecureSkipVerify bool, caBundle []byte, overrideServerName string) (osbclient.Client, error) {
+		newServiceFn: func(url string, opts ...osbpkg.Option) (osbclient.Client, error) {
+			cfg := &osbclient.ClientConfiguration{}
+			for _, opt := range opts {
+				opt(cfg)
+			}
+			username := []byte(cfg.AuthConfig.BasicAuthConfig.Username)
+			password := []byte(cfg.AuthConfig.BasicAuthConfig.Password)
 			gotPassword = password
 			gotUsername = username
 			gotURL = url
@@ -2084,6 +2136,40 @@ func TestCreate(t *testing.T) {
 				err: utilerr.ErrInternal,
 			},
 		},
+		"sb_created_without_sending_parameters": {
+			args: args{
+				sb: serviceBinding("postgresql",
+					withServiceBindingParameters(&v1.ServiceBindingParameters{
+						InstanceName: "postgres-1",
+						Parameters:   map[string]string{"role": "readonly"},
+					}),
+					initializeSBStatus(
+						"6e2c036c-254f-11ee-be56-0242ac120002",
+						"63d05ec8-254e-11ee-be56-0242ac120002",
+						"76c0089e-254e-11ee-be56-0242ac120002",
+						nil,
+					),
+				),
+				bindReaction: &BindReaction{
+					Response: &osbclient.BindResponse{
+						Credentials: map[string]interface{}{"username": "a9s-brk-usr"},
+					},
+				},
+			},
+			want: want{
+				bindRequest: &osbclient.BindRequest{
+					BindingID:  "1a6a6b3e-254e-11ee-be56-0242ac120002",
+					InstanceID: "6e2c036c-254f-11ee-be56-0242ac120002",
+					ServiceID:  "76c0089e-254e-11ee-be56-0242ac120002",
+					PlanID:     "63d05ec8-254e-11ee-be56-0242ac120002",
+				},
+				externalCreation: managed.ExternalCreation{
+					ConnectionDetails: managed.ConnectionDetails{
+						"username": []byte("a9s-brk-usr"),
+					},
+				},
+			},
+		},
 		"fails_not_a_service_binding": {
 			args: args{
 				sb: &dsv1.ServiceInstance{},
@@ -2109,6 +2195,7 @@ func TestCreate(t *testing.T) {
 			external := utilerr.Decorator{
 				Logger: a9stest.TestLogger(t),
 				ExternalClient: &external{
+					logger:  a9stest.TestLogger(t),
 					service: fakeOSB,
 				},
 			}
@@ -2204,6 +2291,52 @@ func TestDeleteHappyPath(t *testing.T) {
 				PlanID:            "63d05ec8-254e-11ee-be56-0242ac120002",
 			},
 		},
+		"unbind_without_recorded_originating_identity": {
+			serviceBinding: serviceBinding("postgresql",
+				withServiceBindingParameters(defaultBindingParameters),
+				afterBindingCreation(),
+				withOriginatingIdentityAnnotation(`{"username":"alice","uid":"a1"}`),
+				initializeSBStatus(
+					"6e2c036c-254f-11ee-be56-0242ac120002",
+					"63d05ec8-254e-11ee-be56-0242ac120002",
+					"76c0089e-254e-11ee-be56-0242ac120002",
+					nil,
+				),
+			),
+			unbindResponse: &osbclient.UnbindResponse{},
+			expectedUnbindReq: &osbclient.UnbindRequest{
+				BindingID:         "1a6a6b3e-254e-11ee-be56-0242ac120002",
+				InstanceID:        "6e2c036c-254f-11ee-be56-0242ac120002",
+				AcceptsIncomplete: false,
+				ServiceID:         "76c0089e-254e-11ee-be56-0242ac120002",
+				PlanID:            "63d05ec8-254e-11ee-be56-0242ac120002",
+			},
+		},
+		"unbind_without_originating_identity_of_spec": {
+			serviceBinding: serviceBinding("postgresql",
+				withServiceBindingParameters(&v1.ServiceBindingParameters{
+					OriginatingIdentity: &v1.OriginatingIdentity{
+						Platform: "kubernetes",
+						Value:    `{"username":"bob","uid":"b2"}`,
+					},
+				}),
+				afterBindingCreation(),
+				initializeSBStatus(
+					"6e2c036c-254f-11ee-be56-0242ac120002",
+					"63d05ec8-254e-11ee-be56-0242ac120002",
+					"76c0089e-254e-11ee-be56-0242ac120002",
+					nil,
+				),
+			),
+			unbindResponse: &osbclient.UnbindResponse{},
+			expectedUnbindReq: &osbclient.UnbindRequest{
+				BindingID:         "1a6a6b3e-254e-11ee-be56-0242ac120002",
+				InstanceID:        "6e2c036c-254f-11ee-be56-0242ac120002",
+				AcceptsIncomplete: false,
+				ServiceID:         "76c0089e-254e-11ee-be56-0242ac120002",
+				PlanID:            "63d05ec8-254e-11ee-be56-0242ac120002",
+			},
+		},
 	}
 
 	for name, testCase := range testCases {
@@ -2392,6 +2525,14 @@ func afterBindingCreation() func(*v1.ServiceBinding) {
 	}
 }
 
+func withOriginatingIdentityAnnotation(value string) func(*v1.ServiceBinding) {
+	return func(sb *v1.ServiceBinding) {
+		meta.AddAnnotations(sb, map[string]string{
+			"anynines.crossplane.io/originating-identity": value,
+		})
+	}
+}
+
 func addConnectionDetails(conD []connectionDetails, sb *v1.ServiceBinding) {
 	for i := 0; i < len(conD); i++ {
 		sb.AddConnectionDetailsWithLabel(conD[i].hostURL, conD[i].port, conD[i].label)
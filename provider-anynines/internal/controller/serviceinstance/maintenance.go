@@ -0,0 +1,107 @@
+/*
+Copyright 2024 Klutch Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package serviceinstance
+
+import (
+	"fmt"
+
+	osbclient "github.com/anynines/klutchio/clients/a9s-open-service-broker"
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	v1 "github.com/anynines/klutchio/provider-anynines/apis/serviceinstance/v1"
+)
+
+// TypeUpgradeAvailable indicates whether the plan of a service instance has a
+// newer maintenance info version than the instance is deployed with.
+const TypeUpgradeAvailable xpv1.ConditionType = "UpgradeAvailable"
+
+// Reasons an upgrade is or is not available for a service instance.
+const (
+	ReasonMaintenanceInfoOutdated xpv1.ConditionReason = "MaintenanceInfoOutdated"
+	ReasonMaintenanceInfoCurrent  xpv1.ConditionReason = "MaintenanceInfoCurrent"
+)
+
+// UpgradeAvailable returns a condition that indicates the instance can be
+// upgraded to the given maintenance info of its plan.
+func UpgradeAvailable(current *v1.MaintenanceInfo, upgrade *osbclient.MaintenanceInfo) xpv1.Condition {
+	message := fmt.Sprintf("maintenance info version %s is available, instance has version %s", upgrade.Version, current.Version)
+	if upgrade.Description != "" {
+		message += ": " + upgrade.Description
+	}
+	return xpv1.Condition{
+		Type:               TypeUpgradeAvailable,
+		Status:             corev1.ConditionTrue,
+		LastTransitionTime: metav1.Now(),
+		Reason:             ReasonMaintenanceInfoOutdated,
+		Message:            message,
+	}
+}
+
+// NoUpgradeAvailable returns a condition that indicates the instance is
+// deployed with the maintenance info of its plan.
+func NoUpgradeAvailable() xpv1.Condition {
+	return xpv1.Condition{
+		Type:               TypeUpgradeAvailable,
+		Status:             corev1.ConditionFalse,
+		LastTransitionTime: metav1.Now(),
+		Reason:             ReasonMaintenanceInfoCurrent,
+	}
+}
+
+// availableUpgrade returns the maintenance info of the plan if the instance
+// is on that plan and deployed with a different maintenance info version, and
+// nil otherwise. Instances the broker reports no maintenance info for are
+// never considered outdated, so that brokers without maintenance info support
+// are not sent the same upgrade over and over again.
+func availableUpgrade(dsi *v1.ServiceInstance, plan *osbclient.Plan) *osbclient.MaintenanceInfo {
+	current := dsi.Status.AtProvider.MaintenanceInfo
+	if plan.MaintenanceInfo == nil || current == nil || plan.ID != dsi.Status.AtProvider.PlanID {
+		return nil
+	}
+	if plan.MaintenanceInfo.Version == current.Version {
+		return nil
+	}
+	return plan.MaintenanceInfo
+}
+
+// checkUpgrade records in the UpgradeAvailable condition of the instance
+// whether an upgrade to the maintenance info of the plan is available. The
+// condition is left alone if the plan or the instance has no maintenance
+// info, or if the instance is changing plans.
+func checkUpgrade(dsi *v1.ServiceInstance, plan *osbclient.Plan) {
+	current := dsi.Status.AtProvider.MaintenanceInfo
+	if plan.MaintenanceInfo == nil || current == nil || plan.ID != dsi.Status.AtProvider.PlanID {
+		return
+	}
+
+	if upgrade := availableUpgrade(dsi, plan); upgrade != nil {
+		dsi.Status.SetConditions(UpgradeAvailable(current, upgrade))
+		return
+	}
+	dsi.Status.SetConditions(NoUpgradeAvailable())
+}
+
+// autoUpgrade returns the upgrade to perform on the instance, if one is
+// available and the instance opted in to automatic upgrades.
+func autoUpgrade(dsi *v1.ServiceInstance, plan *osbclient.Plan) *osbclient.MaintenanceInfo {
+	if dsi.Spec.ForProvider.AutoUpgrade == nil || !*dsi.Spec.ForProvider.AutoUpgrade {
+		return nil
+	}
+	return availableUpgrade(dsi, plan)
+}
@@ -42,6 +42,8 @@ import (
 	anynines "github.com/anynines/klutchio/provider-anynines/pkg/client"
 	client "github.com/anynines/klutchio/provider-anynines/pkg/client/osb"
 	"github.com/anynines/klutchio/provider-anynines/pkg/client/serviceinstance"
+	"github.com/anynines/klutchio/provider-anynines/pkg/originatingidentity"
+	"github.com/anynines/klutchio/provider-anynines/pkg/tracing"
 	utilerr "github.com/anynines/klutchio/provider-anynines/pkg/utilerr"
 )
 
@@ -55,18 +57,24 @@ func Setup(mgr ctrl.Manager, o controller.Options) error {
 	cps := util.GetConnectionPublisher(mgr, o)
 
 	log := o.Logger.WithValues("controller", name)
+	pollDelays := util.NewPollDelays()
 
 	r := managed.NewReconciler(mgr,
 		resource.ManagedKind(v1.ServiceInstanceGroupVersionKind),
 		managed.WithExternalConnecter(utilerr.ConnectDecorator{
-			Connector: &connector{
-				logger:       log,
-				kube:         mgr.GetClient(),
-				usage:        resource.NewProviderConfigUsageTracker(mgr.GetClient(), &apisv1.ProviderConfigUsage{}),
-				newServiceFn: client.NewOsbServiceWithTLS,
+			Connector: tracing.ConnectDecorator{
+				Connector: &connector{
+					logger:       log,
+					kube:         mgr.GetClient(),
+					usage:        resource.NewProviderConfigUsageTracker(mgr.GetClient(), &apisv1.ProviderConfigUsage{}),
+					newServiceFn: client.NewOsbServiceWithOptions,
+					pollDelays:   pollDelays,
+				},
+				Kind: v1.ServiceInstanceKind,
 			},
 			Logger: log,
 		}),
+		managed.WithPollIntervalHook(pollDelays.PollIntervalHook),
 		managed.WithLogger(log),
 		managed.WithRecorder(event.NewAPIRecorder(mgr.GetEventRecorderFor(name))),
 		managed.WithConnectionPublishers(cps...))
@@ -84,7 +92,8 @@ type connector struct {
 	logger       logging.Logger
 	kube         k8sclient.Client
 	usage        resource.Tracker
-	newServiceFn func(username, password []byte, url string, insecureSkipVerify bool, caBundle []byte, overrideServerName string) (osbclient.Client, error)
+	newServiceFn func(url string, opts ...client.Option) (osbclient.Client, error)
+	pollDelays   *util.PollDelays
 }
 
 // Connect typically produces an ExternalClient by:
@@ -112,27 +121,35 @@ func (c *connector) Connect(ctx context.Context, mg resource.Managed) (managed.E
 		return nil, err
 	}
 
-	svc, err := c.newServiceFn(credentials.Username, credentials.Password, pc.Spec.Url, credentials.InsecureSkipVerify, credentials.CABundle, credentials.OverrideServerName)
+	svc, err := c.newServiceFn(pc.Spec.Url,
+		client.WithBasicAuth(credentials.Username, credentials.Password),
+		client.WithOAuth2(credentials.OAuth2),
+		client.WithTLS(credentials.InsecureSkipVerify, credentials.CABundle, credentials.OverrideServerName),
+		client.ForProviderConfig(pc),
+		client.WithLogger(ctrl.LoggerFrom(ctx)),
+	)
 	if err != nil {
 		return nil, fmt.Errorf("%s: %w", errNewClient, err)
 	}
 
 	return &external{
-		logger: c.logger,
-		osb:    svc,
+		logger:     c.logger,
+		osb:        svc,
+		pollDelays: c.pollDelays,
 	}, nil
 }
 
 // An ExternalClient observes, then either creates, updates, or deletes an
 // external resource to ensure it reflects the managed resource's desired state.
 type external struct {
-	logger logging.Logger
-	osb    osbclient.Client
+	logger     logging.Logger
+	osb        osbclient.Client
+	pollDelays *util.PollDelays
 }
 
 // Observe makes observation about the external resource.
 func (c *external) Observe(ctx context.Context, mg resource.Managed) (managed.ExternalObservation, error) {
-	dsi, err := c.getAndVerifyServiceInstance(mg)
+	dsi, err := c.getAndVerifyServiceInstance(ctx, mg)
 	if err != nil {
 		return managed.ExternalObservation{}, err
 	}
@@ -142,32 +159,33 @@ func (c *external) Observe(ctx context.Context, mg resource.Managed) (managed.Ex
 	// broker, and its response contains only service and plan IDs, not names. We only have names in
 	// dsi's spec. So here we resolve the desired service and plan names into their IDs (by querying
 	// the catalog of the service broker), so that we can perform the comparison.
-	_, desiredPlanID, err := c.getServiceAndPlanIDs(*dsi.Spec.ForProvider.ServiceName, *dsi.Spec.ForProvider.PlanName)
+	_, desiredPlan, err := c.getServiceAndPlan(ctx, *dsi.Spec.ForProvider.ServiceName, *dsi.Spec.ForProvider.PlanName)
 	if err != nil {
 		return managed.ExternalObservation{}, err
 	}
 
-	instance, parameters, err := c.getInstanceAndUpdateObservation(dsi)
+	instance, parameters, err := c.getInstanceAndUpdateObservation(ctx, dsi)
 	if err != nil {
 		return managed.ExternalObservation{}, err
 	} else if instance == nil {
 		return managed.ExternalObservation{}, nil
 	}
 
-	err = c.processPendingOperation(dsi)
+	err = c.processPendingOperation(ctx, dsi)
 	if err != nil {
 		return managed.ExternalObservation{}, err
 	}
 
 	// Set the conditions that indicate whether the instance is ready and synched
 	setCrossplaneConditions(dsi)
+	checkUpgrade(dsi, &desiredPlan)
 
 	return managed.ExternalObservation{
 		// Return false when the external resource does not exist. This lets
 		// the managed resource reconciler know that it needs to call Create to
 		// (re)create the resource, or that it has successfully been deleted.
 		ResourceExists:   true,
-		ResourceUpToDate: c.isResourceUpToDate(dsi, instance, desiredPlanID, parameters),
+		ResourceUpToDate: c.isResourceUpToDate(dsi, instance, &desiredPlan, parameters),
 	}, nil
 }
 
@@ -183,11 +201,11 @@ func assertServiceAndPlanNamesAreSet(dsi *v1.ServiceInstance) error {
 	return nil
 }
 
-func genAndCheckUID(osb osbclient.Client, maxAttempts int) (string, error) {
+func genAndCheckUID(ctx context.Context, osb osbclient.Client, maxAttempts int) (string, error) {
 	var err error
 	for i := 0; i < maxAttempts; i++ {
 		uid := anynines.GenUID()
-		_, err = osb.GetInstance(&osbclient.GetInstanceRequest{InstanceID: uid})
+		_, err = osb.GetInstance(ctx, &osbclient.GetInstanceRequest{InstanceID: uid})
 		if client.IsNotFound(err) {
 			return uid, nil
 		}
@@ -198,17 +216,17 @@ func genAndCheckUID(osb osbclient.Client, maxAttempts int) (string, error) {
 	return "", errInstanceIDNotUnique.WithCause(err)
 }
 
-// This function returns the IDs in the same order that it takes the names with Service first and then Plan
-func (c *external) getServiceAndPlanIDs(servicePrefix, planName string) (string, string, error) {
-	service, err := c.getServiceFromCatalog(servicePrefix)
+// getServiceAndPlan resolves the service and plan names into their catalog entries.
+func (c *external) getServiceAndPlan(ctx context.Context, servicePrefix, planName string) (osbclient.Service, osbclient.Plan, error) {
+	service, err := c.getServiceFromCatalog(ctx, servicePrefix)
 	if err != nil {
-		return "", "", err
+		return osbclient.Service{}, osbclient.Plan{}, err
 	}
 	plan, err := getPlanFromService(planName, service)
 	if err != nil {
-		return "", "", err
+		return osbclient.Service{}, osbclient.Plan{}, err
 	}
-	return service.ID, plan.ID, nil
+	return service, plan, nil
 }
 
 func setCrossplaneConditions(dsi *v1.ServiceInstance) {
@@ -242,7 +260,7 @@ func (c *external) Create(ctx context.Context, mg resource.Managed) (managed.Ext
 		return managed.ExternalCreation{}, errNotServiceInstance
 	}
 
-	serviceID, planID, err := c.getServiceAndPlanIDs(*dsi.Spec.ForProvider.ServiceName, *dsi.Spec.ForProvider.PlanName)
+	service, plan, err := c.getServiceAndPlan(ctx, *dsi.Spec.ForProvider.ServiceName, *dsi.Spec.ForProvider.PlanName)
 	if err != nil {
 		return managed.ExternalCreation{}, err
 	}
@@ -252,14 +270,14 @@ func (c *external) Create(ctx context.Context, mg resource.Managed) (managed.Ext
 		return managed.ExternalCreation{}, err
 	}
 
-	response, err := c.osb.ProvisionInstance(&osbclient.ProvisionRequest{
+	request := &osbclient.ProvisionRequest{
 		// We use the Kubernetes resource UID to ensure that each managed resource is associated
 		// with only one service instance throughout its lifecycle. The Instance UID need not be
 		// provided in the managed resource on creation.
 		InstanceID:        dsi.Status.AtProvider.InstanceID,
 		AcceptsIncomplete: *dsi.Spec.ForProvider.AcceptsIncomplete,
-		ServiceID:         serviceID,
-		PlanID:            planID,
+		ServiceID:         service.ID,
+		PlanID:            plan.ID,
 		OrganizationGUID:  *dsi.Spec.ForProvider.OrganizationGUID,
 		SpaceGUID:         *dsi.Spec.ForProvider.SpaceGUID,
 		Parameters:        params,
@@ -267,21 +285,28 @@ func (c *external) Create(ctx context.Context, mg resource.Managed) (managed.Ext
 			osbclient.VarOrganizationKey: *dsi.Spec.ForProvider.OrganizationGUID,
 			osbclient.VarSpaceKey:        *dsi.Spec.ForProvider.SpaceGUID,
 		},
-	})
+		MaintenanceInfo:     plan.MaintenanceInfo,
+		OriginatingIdentity: originatingIdentity(dsi),
+	}
+	if err := util.CheckParameters(dsi, c.logger, &plan, osbclient.ValidateProvisionRequest(&plan, request)); err != nil {
+		return managed.ExternalCreation{}, fmt.Errorf("cannot create ServiceInstance: %w", err)
+	}
+
+	response, err := c.osb.ProvisionInstance(ctx, request)
 	if err != nil {
 		return managed.ExternalCreation{}, fmt.Errorf("cannot create ServiceInstance: %w", utilerr.HandleHttpError(err))
 	}
 
 	if response.Async {
-		c.logAsyncAction(*response.OperationKey, dsi)
+		c.logAsyncAction(response.OperationKey, dsi)
 	}
 
 	return managed.ExternalCreation{}, nil
 }
 
 // getServiceFromCatalog gets a service from catalog using a specified prefix
-func (c *external) getServiceFromCatalog(servicePrefix string) (osbclient.Service, error) {
-	catalog, err := c.osb.GetCatalog()
+func (c *external) getServiceFromCatalog(ctx context.Context, servicePrefix string) (osbclient.Service, error) {
+	catalog, err := c.osb.GetCatalog(ctx)
 	if err != nil {
 		return osbclient.Service{}, fmt.Errorf("cannot get service broker catalog: %w", err)
 	}
@@ -321,7 +346,7 @@ func (c *external) Update(ctx context.Context, mg resource.Managed) (managed.Ext
 		return managed.ExternalUpdate{}, errNotServiceInstance
 	}
 
-	desiredServiceID, desiredPlanID, err := c.getServiceAndPlanIDs(*dsi.Spec.ForProvider.ServiceName, *dsi.Spec.ForProvider.PlanName)
+	desiredService, desiredPlan, err := c.getServiceAndPlan(ctx, *dsi.Spec.ForProvider.ServiceName, *dsi.Spec.ForProvider.PlanName)
 	if err != nil {
 		return managed.ExternalUpdate{}, fmt.Errorf("%s: %w", errUpdateServiceInstance, err)
 	}
@@ -331,17 +356,27 @@ func (c *external) Update(ctx context.Context, mg resource.Managed) (managed.Ext
 		return managed.ExternalUpdate{}, fmt.Errorf("%s: %w", errUpdateServiceInstance, err)
 	}
 
-	response, err := c.osb.UpdateInstance(&osbclient.UpdateInstanceRequest{
+	request := &osbclient.UpdateInstanceRequest{
 		InstanceID:        dsi.Status.AtProvider.InstanceID,
 		AcceptsIncomplete: *dsi.Spec.ForProvider.AcceptsIncomplete,
-		ServiceID:         desiredServiceID,
-		PlanID:            &desiredPlanID,
+		ServiceID:         desiredService.ID,
+		PlanID:            &desiredPlan.ID,
 		Parameters:        parameterUpdate,
 		Context: map[string]interface{}{
 			osbclient.VarOrganizationKey: *dsi.Spec.ForProvider.OrganizationGUID,
 			osbclient.VarSpaceKey:        *dsi.Spec.ForProvider.SpaceGUID,
 		},
-	})
+		MaintenanceInfo:     autoUpgrade(dsi, &desiredPlan),
+		OriginatingIdentity: originatingIdentity(dsi),
+	}
+	if request.MaintenanceInfo != nil {
+		c.logger.Debug("Upgrading instance", "maintenanceInfoVersion", request.MaintenanceInfo.Version)
+	}
+	if err := c.checkUpdateParameters(dsi, &desiredPlan, request); err != nil {
+		return managed.ExternalUpdate{}, fmt.Errorf("%s: %w", errUpdateServiceInstance, err)
+	}
+
+	response, err := c.osb.UpdateInstance(ctx, request)
 	if err != nil {
 		return managed.ExternalUpdate{}, fmt.Errorf(
 			"%s: %w", errUpdateServiceInstance,
@@ -349,7 +384,7 @@ func (c *external) Update(ctx context.Context, mg resource.Managed) (managed.Ext
 	}
 
 	if response.Async {
-		c.logAsyncAction(*response.OperationKey, dsi)
+		c.logAsyncAction(response.OperationKey, dsi)
 	}
 
 	return managed.ExternalUpdate{}, nil
@@ -363,7 +398,7 @@ func (c *external) Delete(ctx context.Context, mg resource.Managed) (managed.Ext
 
 	dsi.SetConditions(xpv1.Deleting())
 
-	response, err := c.osb.DeprovisionInstance(&osbclient.DeprovisionRequest{
+	response, err := c.osb.DeprovisionInstance(ctx, &osbclient.DeprovisionRequest{
 		InstanceID:        dsi.Status.AtProvider.InstanceID,
 		AcceptsIncomplete: *dsi.Spec.ForProvider.AcceptsIncomplete,
 		ServiceID:         dsi.Status.AtProvider.ServiceID,
@@ -374,19 +409,29 @@ func (c *external) Delete(ctx context.Context, mg resource.Managed) (managed.Ext
 	}
 
 	if response.Async {
-		c.logAsyncAction(*response.OperationKey, dsi)
+		c.logAsyncAction(response.OperationKey, dsi)
 	}
 
 	return managed.ExternalDelete{}, nil
 }
 
+// originatingIdentity returns the identity of the user provision and update
+// requests for the instance are made on behalf of, as recorded by the
+// originating identity webhook. The spec is not trusted, as anyone who may
+// change the instance may set it. Deprovision requests carry no identity, as
+// the recorded user is the last one who changed the instance, not the one who
+// deleted it.
+func originatingIdentity(dsi *v1.ServiceInstance) *osbclient.OriginatingIdentity {
+	return originatingidentity.FromObject(dsi)
+}
+
 func (c *external) Disconnect(ctx context.Context) error {
 	// Unimplemented, required by newer versions of crossplane-runtime
 	return nil
 }
 
-func (c *external) setUidWithError(dsi *v1.ServiceInstance) error {
-	uid, err := genAndCheckUID(c.osb, maxRetryAttempts)
+func (c *external) setUidWithError(ctx context.Context, dsi *v1.ServiceInstance) error {
+	uid, err := genAndCheckUID(ctx, c.osb, maxRetryAttempts)
 	if err != nil {
 		return err
 	}
@@ -413,13 +458,35 @@ func (c *external) updateParameters(dsi *v1.ServiceInstance) (map[string]interfa
 	return parameterUpdate, nil
 }
 
-func (c *external) logAsyncAction(opKey osbclient.OperationKey, dsi *v1.ServiceInstance) {
-	operationKey := string(opKey)
+// checkUpdateParameters validates the parameters of an update against the
+// update schema of the plan. The update only carries the parameters that
+// changed, while the schema describes all of them, so the desired parameters
+// are validated instead. Updates without parameters are not validated.
+func (c *external) checkUpdateParameters(dsi *v1.ServiceInstance, plan *osbclient.Plan, request *osbclient.UpdateInstanceRequest) error {
+	validationRequest := *request
+	if len(request.Parameters) > 0 {
+		desiredParams, err := serviceinstance.KubernetesParamsToServiceBroker(dsi.Spec.ForProvider.Parameters)
+		if err != nil {
+			return err
+		}
+		validationRequest.Parameters = desiredParams
+	}
+	return util.CheckParameters(dsi, c.logger, plan, osbclient.ValidateUpdateInstanceRequest(plan, &validationRequest))
+}
+
+// logAsyncAction records the operation of an asynchronous request as pending.
+// Brokers may omit the operation, in which case the last operation of the
+// instance is polled without operation key.
+func (c *external) logAsyncAction(opKey *osbclient.OperationKey, dsi *v1.ServiceInstance) {
+	operationKey := ""
+	if opKey != nil {
+		operationKey = string(*opKey)
+	}
 	dsi.Status.PendingOperation = &operationKey
 	c.logger.Debug("Asynchronous operation now pending", "operationKey", operationKey)
 }
 
-func (c *external) getAndVerifyServiceInstance(mg resource.Managed) (*v1.ServiceInstance, error) {
+func (c *external) getAndVerifyServiceInstance(ctx context.Context, mg resource.Managed) (*v1.ServiceInstance, error) {
 	dsi, ok := mg.(*v1.ServiceInstance)
 	if !ok {
 		return nil, errNotServiceInstance
@@ -431,7 +498,7 @@ func (c *external) getAndVerifyServiceInstance(mg resource.Managed) (*v1.Service
 	// Without this error-induced early return, the updated status wouldn't be persisted, causing a
 	// failure in the Reconciler's Create method and an endless reconciliation loop.
 	if dsi.Status.AtProvider.InstanceID == "" {
-		return nil, c.setUidWithError(dsi)
+		return nil, c.setUidWithError(ctx, dsi)
 	}
 
 	err := assertServiceAndPlanNamesAreSet(dsi)
@@ -441,10 +508,10 @@ func (c *external) getAndVerifyServiceInstance(mg resource.Managed) (*v1.Service
 	return dsi, nil
 }
 
-func (c *external) getInstanceAndUpdateObservation(dsi *v1.ServiceInstance) (*osbclient.GetInstanceResponse, map[string]apiextv1.JSON, error) {
+func (c *external) getInstanceAndUpdateObservation(ctx context.Context, dsi *v1.ServiceInstance) (*osbclient.GetInstanceResponse, map[string]apiextv1.JSON, error) {
 	// TODO: Remove this method in favor of c.osb.GetServiceInstance, when the response is compatible with what
 	// we get as a response from c.osb.GetInstance
-	instance, err := c.osb.GetInstance(&osbclient.GetInstanceRequest{InstanceID: dsi.Status.AtProvider.InstanceID})
+	instance, err := c.osb.GetInstance(ctx, &osbclient.GetInstanceRequest{InstanceID: dsi.Status.AtProvider.InstanceID})
 	if err != nil && client.IsNotFound(err) {
 		return nil, nil, nil
 	} else if err != nil {
@@ -452,7 +519,7 @@ func (c *external) getInstanceAndUpdateObservation(dsi *v1.ServiceInstance) (*os
 	}
 
 	// TODO: This method is called with combination with c.osb.GetInstance, as GetInstance doesn't return parameters.
-	serviceInstance, err := c.osb.GetServiceInstance(&osbclient.GetInstanceRequest{InstanceID: dsi.Status.AtProvider.InstanceID})
+	serviceInstance, err := c.osb.GetServiceInstance(ctx, &osbclient.GetInstanceRequest{InstanceID: dsi.Status.AtProvider.InstanceID})
 	if err != nil && client.IsNotFound(err) {
 		return nil, nil, nil
 	} else if err != nil {
@@ -468,6 +535,7 @@ func (c *external) getInstanceAndUpdateObservation(dsi *v1.ServiceInstance) (*os
 
 	// Update the status
 	dsi.Status.AtProvider = serviceinstance.GenerateObservation(*instance, params)
+	dsi.Status.AtProvider.MaintenanceInfo = serviceinstance.GenerateMaintenanceInfo(serviceInstance.MaintenanceInfo)
 	if err != nil {
 		return nil, nil, fmt.Errorf("%s: %w", errGetServiceInstance, err)
 	}
@@ -482,9 +550,9 @@ func (c *external) getInstanceAndUpdateObservation(dsi *v1.ServiceInstance) (*os
 	return instance, params, nil
 }
 
-func (c *external) processPendingOperation(dsi *v1.ServiceInstance) error {
+func (c *external) processPendingOperation(ctx context.Context, dsi *v1.ServiceInstance) error {
 	if dsi.Status.PendingOperation != nil {
-		response, err := c.osb.GetOperation(&osbclient.GetOperationRequest{
+		response, err := c.osb.GetOperation(ctx, &osbclient.GetOperationRequest{
 			OperationKey: osbclient.OperationKey(*dsi.Status.PendingOperation),
 			InstanceID:   dsi.Status.AtProvider.InstanceID,
 		})
@@ -499,6 +567,10 @@ func (c *external) processPendingOperation(dsi *v1.ServiceInstance) error {
 			"operationKey", *dsi.Status.PendingOperation,
 			"state", response.State)
 
+		// Requeue when the broker asked us to poll again, rather than after the
+		// fixed poll interval.
+		c.pollDelays.Set(dsi, response.PollDelay)
+
 		if response.IsDone() {
 			dsi.Status.PendingOperation = nil
 		} else if failed, err := response.IsFailure(); failed {
@@ -514,7 +586,7 @@ func (c *external) processPendingOperation(dsi *v1.ServiceInstance) error {
 	return nil
 }
 
-func (c *external) isResourceUpToDate(dsi *v1.ServiceInstance, instance *osbclient.GetInstanceResponse, desiredPlanID string, parameters map[string]apiextv1.JSON) bool {
+func (c *external) isResourceUpToDate(dsi *v1.ServiceInstance, instance *osbclient.GetInstanceResponse, desiredPlan *osbclient.Plan, parameters map[string]apiextv1.JSON) bool {
 	if dsi.Status.PendingOperation == nil {
 		specMatchesObserved, diff := serviceinstance.SpecMatchesObservedState(dsi.Spec.ForProvider, *instance, parameters)
 		if !specMatchesObserved {
@@ -526,7 +598,12 @@ func (c *external) isResourceUpToDate(dsi *v1.ServiceInstance, instance *osbclie
 		} else {
 			// Since ServiceID and PlanID are part of Status instead of Spec we need
 			// to separately check whether they are up to date as well.
-			return desiredPlanID == dsi.Status.AtProvider.PlanID
+			if desiredPlan.ID != dsi.Status.AtProvider.PlanID {
+				return false
+			}
+			// An instance that opted in to automatic upgrades is outdated
+			// while its plan has a newer maintenance info version.
+			return autoUpgrade(dsi, desiredPlan) == nil
 		}
 	}
 
@@ -22,7 +22,9 @@ import (
 	"errors"
 	"net/http"
 	"os"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/google/go-cmp/cmp"
 	corev1 "k8s.io/api/core/v1"
@@ -39,10 +41,13 @@ import (
 
 	osbclient "github.com/anynines/klutchio/clients/a9s-open-service-broker"
 	fakeosb "github.com/anynines/klutchio/clients/a9s-open-service-broker/fake"
+	"github.com/anynines/klutchio/clients/a9s-open-service-broker/osbtest"
+	"github.com/anynines/klutchio/clients/a9s-open-service-broker/recorder"
 	sbv1 "github.com/anynines/klutchio/provider-anynines/apis/servicebinding/v1"
 	v1 "github.com/anynines/klutchio/provider-anynines/apis/serviceinstance/v1"
 	apisv1 "github.com/anynines/klutchio/provider-anynines/apis/v1"
 	a9stest "github.com/anynines/klutchio/provider-anynines/internal/controller/test"
+	util "github.com/anynines/klutchio/provider-anynines/internal/controller/utils"
 	client "github.com/anynines/klutchio/provider-anynines/pkg/client/osb"
 	utilerr "github.com/anynines/klutchio/provider-anynines/pkg/utilerr"
 
@@ -79,6 +84,68 @@ var defaultCatalogResponse = osbclient.CatalogResponse{
 	},
 }
 
+// schemaCatalogResponse returns the default catalog with a parameters schema
+// on the postgresql-single-small plan.
+func schemaCatalogResponse() *osbclient.CatalogResponse {
+	schema := &osbclient.InputParametersSchema{
+		Parameters: map[string]interface{}{
+			"$schema": "http://json-schema.org/draft-04/schema#",
+			"type":    "object",
+			"properties": map[string]interface{}{
+				"max_connections": map[string]interface{}{"type": "integer", "minimum": 1},
+			},
+		},
+	}
+
+	catalog := defaultCatalogResponse
+	service := catalog.Services[0]
+	service.Plans = append([]osbclient.Plan(nil), service.Plans...)
+	service.Plans[0].Schemas = &osbclient.Schemas{
+		ServiceInstance: &osbclient.ServiceInstanceSchema{Create: schema, Update: schema},
+	}
+	catalog.Services = []osbclient.Service{service}
+	return &catalog
+}
+
+// requiredSchemaCatalogResponse returns the default catalog with a parameters
+// schema that requires max_connections and maintenance info version 1.1.0 on
+// the postgresql-single-small plan.
+func requiredSchemaCatalogResponse() *osbclient.CatalogResponse {
+	schema := &osbclient.InputParametersSchema{
+		Parameters: map[string]interface{}{
+			"$schema":  "http://json-schema.org/draft-04/schema#",
+			"type":     "object",
+			"required": []interface{}{"max_connections"},
+			"properties": map[string]interface{}{
+				"max_connections": map[string]interface{}{"type": "integer", "minimum": 1},
+			},
+		},
+	}
+
+	catalog := *maintenanceInfoCatalogResponse()
+	service := catalog.Services[0]
+	service.Plans = append([]osbclient.Plan(nil), service.Plans...)
+	service.Plans[0].Schemas = &osbclient.Schemas{
+		ServiceInstance: &osbclient.ServiceInstanceSchema{Create: schema, Update: schema},
+	}
+	catalog.Services = []osbclient.Service{service}
+	return &catalog
+}
+
+// maintenanceInfoCatalogResponse returns the default catalog with maintenance
+// info version 1.1.0 on the postgresql-single-small plan.
+func maintenanceInfoCatalogResponse() *osbclient.CatalogResponse {
+	catalog := defaultCatalogResponse
+	service := catalog.Services[0]
+	service.Plans = append([]osbclient.Plan(nil), service.Plans...)
+	service.Plans[0].MaintenanceInfo = &osbclient.MaintenanceInfo{
+		Version:     "1.1.0",
+		Description: "PostgreSQL minor version upgrade",
+	}
+	catalog.Services = []osbclient.Service{service}
+	return &catalog
+}
+
 // Unlike many Kubernetes projects Crossplane does not use third party testing
 // libraries, per the common Go test review comments. Crossplane encourages the
 // use of table driven unit tests. The tests of the crossplane-runtime project
@@ -141,6 +208,12 @@ func withServiceInstanceResponseIntParameter(key string, value int) serviceInsta
 	}
 }
 
+func withServiceInstanceResponseMaintenanceInfo(version string) serviceInstanceResponseOption {
+	return func(gir *osbclient.GetServiceInstanceResponse) {
+		gir.MaintenanceInfo = &osbclient.MaintenanceInfo{Version: version}
+	}
+}
+
 func newInstanceResponse(opts ...instanceResponseOption) *osbclient.GetInstanceResponse {
 	// Set defaults
 	ir := &osbclient.GetInstanceResponse{
@@ -531,6 +604,91 @@ func TestObserve(t *testing.T) {
 				),
 			},
 		},
+		"upgradeAvailable": {
+			args: args{
+				getInstanceReaction:        &fakeosb.GetInstanceReaction{Response: newInstanceResponse(withInstanceResponseState("provisioned"))},
+				getServiceInstanceReaction: &fakeosb.GetServiceInstanceReaction{Response: newServiceInstanceResponse(withServiceInstanceResponseMaintenanceInfo("1.0.0"))},
+				catalogReaction:            &fakeosb.CatalogReaction{Response: maintenanceInfoCatalogResponse()},
+				mr: newServiceInstance(
+					withStatusInstanceID("40a5148f-dba2-41f2-b1b7-0ca90e1501c5"),
+				),
+			},
+			want: want{
+				observation: managed.ExternalObservation{
+					ResourceExists:   true,
+					ResourceUpToDate: true,
+				},
+				mr: newServiceInstance(
+					withStatusInstanceID("40a5148f-dba2-41f2-b1b7-0ca90e1501c5"),
+					withServiceID("0f3f9e21-f960-41f4-b787-b2b47b567996"),
+					withPlanID("40a5148f-dba2-41f2-b1b7-0ca90e1501c5"),
+					withState("provisioned"),
+					withStatusMaintenanceInfo("1.0.0"),
+					withCondition(xpv1.Available()),
+					withCondition(UpgradeAvailable(
+						&v1.MaintenanceInfo{Version: "1.0.0"},
+						maintenanceInfoCatalogResponse().Services[0].Plans[0].MaintenanceInfo,
+					)),
+				),
+			},
+		},
+		"upgradeAvailableWithAutoUpgrade": {
+			args: args{
+				getInstanceReaction:        &fakeosb.GetInstanceReaction{Response: newInstanceResponse(withInstanceResponseState("provisioned"))},
+				getServiceInstanceReaction: &fakeosb.GetServiceInstanceReaction{Response: newServiceInstanceResponse(withServiceInstanceResponseMaintenanceInfo("1.0.0"))},
+				catalogReaction:            &fakeosb.CatalogReaction{Response: maintenanceInfoCatalogResponse()},
+				mr: newServiceInstance(
+					withStatusInstanceID("40a5148f-dba2-41f2-b1b7-0ca90e1501c5"),
+					withAutoUpgrade(),
+				),
+			},
+			want: want{
+				observation: managed.ExternalObservation{
+					ResourceExists:   true,
+					ResourceUpToDate: false,
+				},
+				mr: newServiceInstance(
+					withStatusInstanceID("40a5148f-dba2-41f2-b1b7-0ca90e1501c5"),
+					withAutoUpgrade(),
+					withServiceID("0f3f9e21-f960-41f4-b787-b2b47b567996"),
+					withPlanID("40a5148f-dba2-41f2-b1b7-0ca90e1501c5"),
+					withState("provisioned"),
+					withStatusMaintenanceInfo("1.0.0"),
+					withCondition(xpv1.Available()),
+					withCondition(UpgradeAvailable(
+						&v1.MaintenanceInfo{Version: "1.0.0"},
+						maintenanceInfoCatalogResponse().Services[0].Plans[0].MaintenanceInfo,
+					)),
+				),
+			},
+		},
+		"noUpgradeAvailable": {
+			args: args{
+				getInstanceReaction:        &fakeosb.GetInstanceReaction{Response: newInstanceResponse(withInstanceResponseState("provisioned"))},
+				getServiceInstanceReaction: &fakeosb.GetServiceInstanceReaction{Response: newServiceInstanceResponse(withServiceInstanceResponseMaintenanceInfo("1.1.0"))},
+				catalogReaction:            &fakeosb.CatalogReaction{Response: maintenanceInfoCatalogResponse()},
+				mr: newServiceInstance(
+					withStatusInstanceID("40a5148f-dba2-41f2-b1b7-0ca90e1501c5"),
+					withAutoUpgrade(),
+				),
+			},
+			want: want{
+				observation: managed.ExternalObservation{
+					ResourceExists:   true,
+					ResourceUpToDate: true,
+				},
+				mr: newServiceInstance(
+					withStatusInstanceID("40a5148f-dba2-41f2-b1b7-0ca90e1501c5"),
+					withAutoUpgrade(),
+					withServiceID("0f3f9e21-f960-41f4-b787-b2b47b567996"),
+					withPlanID("40a5148f-dba2-41f2-b1b7-0ca90e1501c5"),
+					withState("provisioned"),
+					withStatusMaintenanceInfo("1.1.0"),
+					withCondition(xpv1.Available()),
+					withCondition(NoUpgradeAvailable()),
+				),
+			},
+		},
 		"pendingOperationIsPending": {
 			args: args{
 				getInstanceReaction:        &fakeosb.GetInstanceReaction{Response: newInstanceResponse(withInstanceResponseState("unknown"))},
@@ -1062,6 +1220,140 @@ func TestCreate(t *testing.T) {
 					}},
 			},
 		},
+		"successInstanceIsProvisionedOnBehalfOfRecordedUser": {
+			args: args{
+				provisionReaction: &fakeosb.ProvisionReaction{
+					Response: &osbclient.ProvisionResponse{},
+				},
+				mr: newServiceInstance(
+					withStatusInstanceID("40a5148f-dba2-41f2-b1b7-0ca90e1501c5"),
+					withAnnotation("anynines.crossplane.io/originating-identity",
+						`{"username":"alice","uid":"a1"}`),
+				),
+			},
+			want: want{
+				mr: newServiceInstance(
+					withStatusInstanceID("40a5148f-dba2-41f2-b1b7-0ca90e1501c5"),
+					withAnnotation("anynines.crossplane.io/originating-identity",
+						`{"username":"alice","uid":"a1"}`),
+				),
+				actions: []fakeosb.Action{
+					{Type: "GetCatalog"},
+					{
+						Type: "ProvisionInstance",
+						Request: &osbclient.ProvisionRequest{
+							InstanceID:        "40a5148f-dba2-41f2-b1b7-0ca90e1501c5",
+							AcceptsIncomplete: true,
+							ServiceID:         "0f3f9e21-f960-41f4-b787-b2b47b567996",
+							PlanID:            "40a5148f-dba2-41f2-b1b7-0ca90e1501c5",
+							OrganizationGUID:  "a1612e60-3042-4bf2-bd7c-fa600a4f66b9",
+							SpaceGUID:         "009dbe05-925d-4f2a-ac0d-8d44dd723a11",
+							Context: map[string]interface{}{
+								osbclient.VarOrganizationKey: "a1612e60-3042-4bf2-bd7c-fa600a4f66b9",
+								osbclient.VarSpaceKey:        "009dbe05-925d-4f2a-ac0d-8d44dd723a11",
+							},
+							OriginatingIdentity: &osbclient.OriginatingIdentity{
+								Platform: "kubernetes",
+								Value:    `{"username":"alice","uid":"a1"}`,
+							},
+						},
+					}},
+			},
+		},
+		"successOriginatingIdentityOfSpecIsNotSent": {
+			args: args{
+				provisionReaction: &fakeosb.ProvisionReaction{
+					Response: &osbclient.ProvisionResponse{},
+				},
+				mr: newServiceInstance(
+					withStatusInstanceID("40a5148f-dba2-41f2-b1b7-0ca90e1501c5"),
+					withSpecOriginatingIdentity("kubernetes", `{"username":"admin"}`),
+				),
+			},
+			want: want{
+				mr: newServiceInstance(
+					withStatusInstanceID("40a5148f-dba2-41f2-b1b7-0ca90e1501c5"),
+					withSpecOriginatingIdentity("kubernetes", `{"username":"admin"}`),
+				),
+				actions: []fakeosb.Action{
+					{Type: "GetCatalog"},
+					{
+						Type: "ProvisionInstance",
+						Request: &osbclient.ProvisionRequest{
+							InstanceID:        "40a5148f-dba2-41f2-b1b7-0ca90e1501c5",
+							AcceptsIncomplete: true,
+							ServiceID:         "0f3f9e21-f960-41f4-b787-b2b47b567996",
+							PlanID:            "40a5148f-dba2-41f2-b1b7-0ca90e1501c5",
+							OrganizationGUID:  "a1612e60-3042-4bf2-bd7c-fa600a4f66b9",
+							SpaceGUID:         "009dbe05-925d-4f2a-ac0d-8d44dd723a11",
+							Context: map[string]interface{}{
+								osbclient.VarOrganizationKey: "a1612e60-3042-4bf2-bd7c-fa600a4f66b9",
+								osbclient.VarSpaceKey:        "009dbe05-925d-4f2a-ac0d-8d44dd723a11",
+							},
+						},
+					}},
+			},
+		},
+		"errParametersViolateSchema": {
+			args: args{
+				catalogReaction: &fakeosb.CatalogReaction{
+					Response: schemaCatalogResponse(),
+				},
+				mr: newServiceInstance(
+					withStatusInstanceID("40a5148f-dba2-41f2-b1b7-0ca90e1501c5"),
+					withStringParameter("max_connections", "many"),
+				),
+			},
+			want: want{
+				err: utilerr.PlainUserErr("parameters do not match the schema of the plan: max_connections: got string, want integer"),
+				mr: newServiceInstance(
+					withStatusInstanceID("40a5148f-dba2-41f2-b1b7-0ca90e1501c5"),
+					withStringParameter("max_connections", "many"),
+					withCondition(util.ParametersInvalid([]osbclient.ParameterViolation{
+						{Field: "max_connections", Message: "got string, want integer"},
+					})),
+				),
+				// The instance must not be provisioned with invalid parameters.
+				actions: []fakeosb.Action{{Type: "GetCatalog"}},
+			},
+		},
+		"successAsyncProvisionWithoutOperationKey": {
+			args: args{
+				provisionReaction: &fakeosb.ProvisionReaction{
+					Response: &osbclient.ProvisionResponse{Async: true},
+				},
+				mr: newServiceInstance(
+					withStatusInstanceID("40a5148f-dba2-41f2-b1b7-0ca90e1501c5"),
+				),
+			},
+			want: want{
+				mr: newServiceInstance(
+					withStatusInstanceID("40a5148f-dba2-41f2-b1b7-0ca90e1501c5"),
+					withPendingOperation(""),
+				),
+			},
+		},
+		"successParametersMatchSchema": {
+			args: args{
+				provisionReaction: &fakeosb.ProvisionReaction{
+					Response: &osbclient.ProvisionResponse{},
+				},
+				catalogReaction: &fakeosb.CatalogReaction{
+					Response: schemaCatalogResponse(),
+				},
+				mr: newServiceInstance(
+					withStatusInstanceID("40a5148f-dba2-41f2-b1b7-0ca90e1501c5"),
+					withIntParameter("max_connections", 100),
+				),
+			},
+			want: want{
+				mr: newServiceInstance(
+					withStatusInstanceID("40a5148f-dba2-41f2-b1b7-0ca90e1501c5"),
+					withIntParameter("max_connections", 100),
+					withCondition(util.ParametersValid()),
+				),
+			},
+		},
 	}
 
 	for name, tc := range cases {
@@ -1170,6 +1462,164 @@ func TestUpdate(t *testing.T) {
 				},
 			},
 		},
+		"successAutoUpgrade": {
+			args: args{
+				updateInstanceReaction: fakeosb.UpdateInstanceReaction{
+					Response: &osbclient.UpdateInstanceResponse{},
+				},
+				catalogReaction: &fakeosb.CatalogReaction{Response: maintenanceInfoCatalogResponse()},
+				mr: newServiceInstance(
+					withStatusInstanceID("40a5148f-dba2-41f2-b1b7-0ca90e1501c5"),
+					withServiceID("0f3f9e21-f960-41f4-b787-b2b47b567996"),
+					withPlanID("40a5148f-dba2-41f2-b1b7-0ca90e1501c5"),
+					withStatusMaintenanceInfo("1.0.0"),
+					withAutoUpgrade(),
+				),
+			},
+			want: want{
+				actions: []fakeosb.Action{
+					{Type: "GetCatalog"},
+					{
+						Type: "UpdateInstance",
+						Request: &osbclient.UpdateInstanceRequest{
+							InstanceID:        "40a5148f-dba2-41f2-b1b7-0ca90e1501c5",
+							AcceptsIncomplete: true,
+							ServiceID:         "0f3f9e21-f960-41f4-b787-b2b47b567996",
+							PlanID:            ptr.To[string]("40a5148f-dba2-41f2-b1b7-0ca90e1501c5"),
+							Context: map[string]interface{}{
+								osbclient.VarOrganizationKey: "a1612e60-3042-4bf2-bd7c-fa600a4f66b9",
+								osbclient.VarSpaceKey:        "009dbe05-925d-4f2a-ac0d-8d44dd723a11",
+							},
+							MaintenanceInfo: maintenanceInfoCatalogResponse().Services[0].Plans[0].MaintenanceInfo,
+						},
+					},
+				},
+			},
+		},
+		"successAutoUpgradeWithRequiredParameters": {
+			reason: "An upgrade without parameters must not be validated against a schema with required parameters",
+			args: args{
+				updateInstanceReaction: fakeosb.UpdateInstanceReaction{
+					Response: &osbclient.UpdateInstanceResponse{},
+				},
+				catalogReaction: &fakeosb.CatalogReaction{Response: requiredSchemaCatalogResponse()},
+				mr: newServiceInstance(
+					withStatusInstanceID("40a5148f-dba2-41f2-b1b7-0ca90e1501c5"),
+					withServiceID("0f3f9e21-f960-41f4-b787-b2b47b567996"),
+					withPlanID("40a5148f-dba2-41f2-b1b7-0ca90e1501c5"),
+					withStatusMaintenanceInfo("1.0.0"),
+					withAutoUpgrade(),
+					withIntParameter("max_connections", 100),
+					withStatusIntParameter("max_connections", 100),
+				),
+			},
+			want: want{
+				actions: []fakeosb.Action{
+					{Type: "GetCatalog"},
+					{
+						Type: "UpdateInstance",
+						Request: &osbclient.UpdateInstanceRequest{
+							InstanceID:        "40a5148f-dba2-41f2-b1b7-0ca90e1501c5",
+							AcceptsIncomplete: true,
+							ServiceID:         "0f3f9e21-f960-41f4-b787-b2b47b567996",
+							PlanID:            ptr.To[string]("40a5148f-dba2-41f2-b1b7-0ca90e1501c5"),
+							Context: map[string]interface{}{
+								osbclient.VarOrganizationKey: "a1612e60-3042-4bf2-bd7c-fa600a4f66b9",
+								osbclient.VarSpaceKey:        "009dbe05-925d-4f2a-ac0d-8d44dd723a11",
+							},
+							MaintenanceInfo: requiredSchemaCatalogResponse().Services[0].Plans[0].MaintenanceInfo,
+						},
+					},
+				},
+			},
+		},
+		"successPartialUpdateWithRequiredParameters": {
+			reason: "A partial update must be validated with the desired parameters, not only the changed ones",
+			args: args{
+				updateInstanceReaction: fakeosb.UpdateInstanceReaction{
+					Response: &osbclient.UpdateInstanceResponse{},
+				},
+				catalogReaction: &fakeosb.CatalogReaction{Response: requiredSchemaCatalogResponse()},
+				mr: newServiceInstance(
+					withStatusInstanceID("40a5148f-dba2-41f2-b1b7-0ca90e1501c5"),
+					withServiceID("0f3f9e21-f960-41f4-b787-b2b47b567996"),
+					withPlanID("40a5148f-dba2-41f2-b1b7-0ca90e1501c5"),
+					withIntParameter("max_connections", 100),
+					withStringParameter("synchronous_commit", "local"),
+					withStatusIntParameter("max_connections", 100),
+				),
+			},
+			want: want{
+				actions: []fakeosb.Action{
+					{Type: "GetCatalog"},
+					{
+						Type: "UpdateInstance",
+						Request: &osbclient.UpdateInstanceRequest{
+							InstanceID:        "40a5148f-dba2-41f2-b1b7-0ca90e1501c5",
+							AcceptsIncomplete: true,
+							ServiceID:         "0f3f9e21-f960-41f4-b787-b2b47b567996",
+							PlanID:            ptr.To[string]("40a5148f-dba2-41f2-b1b7-0ca90e1501c5"),
+							Parameters: map[string]interface{}{
+								"synchronous_commit": "local",
+							},
+							Context: map[string]interface{}{
+								osbclient.VarOrganizationKey: "a1612e60-3042-4bf2-bd7c-fa600a4f66b9",
+								osbclient.VarSpaceKey:        "009dbe05-925d-4f2a-ac0d-8d44dd723a11",
+							},
+						},
+					},
+				},
+			},
+		},
+		"errUpdateRemovesRequiredParameter": {
+			reason: "Removing a required parameter must be rejected before the update is sent",
+			args: args{
+				catalogReaction: &fakeosb.CatalogReaction{Response: requiredSchemaCatalogResponse()},
+				mr: newServiceInstance(
+					withStatusInstanceID("40a5148f-dba2-41f2-b1b7-0ca90e1501c5"),
+					withServiceID("0f3f9e21-f960-41f4-b787-b2b47b567996"),
+					withPlanID("40a5148f-dba2-41f2-b1b7-0ca90e1501c5"),
+					withStringParameter("synchronous_commit", "local"),
+					withStatusIntParameter("max_connections", 100),
+				),
+			},
+			want: want{
+				err:     utilerr.PlainUserErr("parameters do not match the schema of the plan: max_connections: is required"),
+				actions: []fakeosb.Action{{Type: "GetCatalog"}},
+			},
+		},
+		"successUpgradeNotSentWithoutAutoUpgrade": {
+			args: args{
+				updateInstanceReaction: fakeosb.UpdateInstanceReaction{
+					Response: &osbclient.UpdateInstanceResponse{},
+				},
+				catalogReaction: &fakeosb.CatalogReaction{Response: maintenanceInfoCatalogResponse()},
+				mr: newServiceInstance(
+					withStatusInstanceID("40a5148f-dba2-41f2-b1b7-0ca90e1501c5"),
+					withServiceID("0f3f9e21-f960-41f4-b787-b2b47b567996"),
+					withPlanID("40a5148f-dba2-41f2-b1b7-0ca90e1501c5"),
+					withStatusMaintenanceInfo("1.0.0"),
+				),
+			},
+			want: want{
+				actions: []fakeosb.Action{
+					{Type: "GetCatalog"},
+					{
+						Type: "UpdateInstance",
+						Request: &osbclient.UpdateInstanceRequest{
+							InstanceID:        "40a5148f-dba2-41f2-b1b7-0ca90e1501c5",
+							AcceptsIncomplete: true,
+							ServiceID:         "0f3f9e21-f960-41f4-b787-b2b47b567996",
+							PlanID:            ptr.To[string]("40a5148f-dba2-41f2-b1b7-0ca90e1501c5"),
+							Context: map[string]interface{}{
+								osbclient.VarOrganizationKey: "a1612e60-3042-4bf2-bd7c-fa600a4f66b9",
+								osbclient.VarSpaceKey:        "009dbe05-925d-4f2a-ac0d-8d44dd723a11",
+							},
+						},
+					},
+				},
+			},
+		},
 		"successUpdateWithParameters": {
 			args: args{
 				updateInstanceReaction: fakeosb.UpdateInstanceReaction{
@@ -1448,6 +1898,25 @@ func TestUpdate(t *testing.T) {
 			},
 		},
 
+		"errParametersViolateSchema": {
+			reason: "Should not update the instance with parameters that violate the schema of the plan",
+			args: args{
+				catalogReaction: &fakeosb.CatalogReaction{
+					Response: schemaCatalogResponse(),
+				},
+				mr: newServiceInstance(
+					withStatusInstanceID("40a5148f-dba2-41f2-b1b7-0ca90e1501c5"),
+					withServiceID("0f3f9e21-f960-41f4-b787-b2b47b567996"),
+					withPlanID("40a5148f-dba2-41f2-b1b7-0ca90e1501c5"),
+					withIntParameter("max_connections", 0),
+				),
+			},
+			want: want{
+				err:     utilerr.PlainUserErr("parameters do not match the schema of the plan: max_connections: minimum: got 0, want 1"),
+				actions: []fakeosb.Action{{Type: "GetCatalog"}},
+			},
+		},
+
 		// TODO: Add test cases for PlanID and ServiceID not in catalog
 
 	}
@@ -1635,7 +2104,13 @@ func TestConnect(t *testing.T) {
 			kube: fakeK8s,
 			usage: resource.NewProviderConfigUsageTracker(fakeK8s,
 				&apisv1.ProviderConfigUsage{}),
-			newServiceFn: func(username, password []byte, url string, insecureSkipVerify bool, caBundle []byte, overrideServerName string) (osbclient.Client, error) {
+			newServiceFn: func(url string, opts ...client.Option) (osbclient.Client, error) {
+				cfg := &osbclient.ClientConfiguration{}
+				for _, opt := range opts {
+					opt(cfg)
+				}
+				username := []byte(cfg.AuthConfig.BasicAuthConfig.Username)
+				password := []byte(cfg.AuthConfig.BasicAuthConfig.Password)
 				gotPassword = password
 				gotUsername = username
 				gotURL = url
@@ -1774,12 +2249,30 @@ func withStatusIntParameter(key string, value int) serviceInstanceOption {
 	}
 }
 
+func withStatusMaintenanceInfo(version string) serviceInstanceOption {
+	return func(pg *v1.ServiceInstance) {
+		pg.Status.AtProvider.MaintenanceInfo = &v1.MaintenanceInfo{Version: version}
+	}
+}
+
+func withAutoUpgrade() serviceInstanceOption {
+	return func(pg *v1.ServiceInstance) {
+		pg.Spec.ForProvider.AutoUpgrade = ptr.To(true)
+	}
+}
+
 func withAnnotation(key, value string) serviceInstanceOption {
 	return func(pg *v1.ServiceInstance) {
 		meta.AddAnnotations(pg, map[string]string{key: value})
 	}
 }
 
+func withSpecOriginatingIdentity(platform, value string) serviceInstanceOption {
+	return func(pg *v1.ServiceInstance) {
+		pg.Spec.ForProvider.OriginatingIdentity = &v1.OriginatingIdentity{Platform: &platform, Value: &value}
+	}
+}
+
 func withState(state string) serviceInstanceOption {
 	return func(pg *v1.ServiceInstance) {
 		pg.Status.AtProvider.State = state
@@ -1836,7 +2329,7 @@ func TestGenAndCheckUID(t *testing.T) {
 			GetInstanceReaction: &fakeosb.GetInstanceReaction{Error: notFound},
 		})
 
-		uid, err := genAndCheckUID(osb, 1)
+		uid, err := genAndCheckUID(context.Background(), osb, 1)
 		if err != nil {
 			t.Fatalf("expected no error, got: %v", err)
 		}
@@ -1853,7 +2346,7 @@ func TestGenAndCheckUID(t *testing.T) {
 			GetInstanceReaction: &fakeosb.GetInstanceReaction{Error: cause},
 		})
 
-		_, err := genAndCheckUID(osb, 3)
+		_, err := genAndCheckUID(context.Background(), osb, 3)
 		if err == nil {
 			t.Fatal("expected an error, got nil")
 		}
@@ -1872,7 +2365,7 @@ func TestGenAndCheckUID(t *testing.T) {
 			},
 		})
 
-		_, err := genAndCheckUID(osb, 3)
+		_, err := genAndCheckUID(context.Background(), osb, 3)
 		if err == nil {
 			t.Fatal("expected an error, got nil")
 		}
@@ -1885,3 +2378,198 @@ func TestGenAndCheckUID(t *testing.T) {
 		}
 	})
 }
+
+func TestLifecycleAgainstBrokerStandIn(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	broker := osbtest.NewBroker(osbtest.Options{
+		Catalog:        &defaultCatalogResponse,
+		Username:       "admin",
+		Password:       "secret",
+		Async:          true,
+		PollsUntilDone: 1,
+		RetryAfter:     10 * time.Second,
+	})
+	defer broker.Close()
+
+	osb, err := osbclient.NewClient(broker.ClientConfiguration())
+	if err != nil {
+		t.Fatalf("cannot create client: %v", err)
+	}
+	pollDelays := util.NewPollDelays()
+	e := &external{
+		logger:     a9stest.TestLogger(t),
+		osb:        osb,
+		pollDelays: pollDelays,
+	}
+
+	mr := newServiceInstance(
+		withStatusInstanceID("40a5148f-dba2-41f2-b1b7-0ca90e1501c5"),
+		withIntParameter("max_connections", 100),
+	)
+	mr.SetUID("b7d0a3c2-6f1e-4c8a-9d2b-5e4f3a2b1c0d")
+
+	observe := func(step string) managed.ExternalObservation {
+		t.Helper()
+		observation, err := e.Observe(ctx, mr)
+		if err != nil {
+			t.Fatalf("%s: Observe(...): unexpected error: %v", step, err)
+		}
+		return observation
+	}
+
+	if observation := observe("before create"); observation.ResourceExists {
+		t.Fatalf("before create: expected the instance not to exist")
+	}
+
+	if _, err := e.Create(ctx, mr); err != nil {
+		t.Fatalf("Create(...): unexpected error: %v", err)
+	}
+	if mr.Status.PendingOperation == nil {
+		t.Fatalf("Create(...): expected a pending operation")
+	}
+
+	observation := observe("while provisioning")
+	if !observation.ResourceExists || mr.Status.PendingOperation == nil {
+		t.Errorf("while provisioning: unexpected observation %+v, pending operation %v", observation, mr.Status.PendingOperation)
+	}
+	if diff := cmp.Diff(xpv1.Unavailable(), mr.GetCondition(xpv1.TypeReady), test.EquateConditions()); diff != "" {
+		t.Errorf("while provisioning: -want condition, +got condition:\n%s", diff)
+	}
+	if got := pollDelays.PollIntervalHook(mr, time.Minute); got != 10*time.Second {
+		t.Errorf("while provisioning: expected the broker suggested poll delay of 10s, got %v", got)
+	}
+
+	// The operation finishes while it is polled, after the instance itself
+	// has been observed. The next observation sees the provisioned instance.
+	observe("when provisioning finishes")
+	observation = observe("after provisioning")
+	if !observation.ResourceExists || !observation.ResourceUpToDate || mr.Status.PendingOperation != nil {
+		t.Errorf("after provisioning: unexpected observation %+v, pending operation %v", observation, mr.Status.PendingOperation)
+	}
+	if diff := cmp.Diff(xpv1.Available(), mr.GetCondition(xpv1.TypeReady), test.EquateConditions()); diff != "" {
+		t.Errorf("after provisioning: -want condition, +got condition:\n%s", diff)
+	}
+
+	if _, err := e.Delete(ctx, mr); err != nil {
+		t.Fatalf("Delete(...): unexpected error: %v", err)
+	}
+	observe("while deprovisioning")
+	observe("when deprovisioning finishes")
+	if observation := observe("after deprovisioning"); observation.ResourceExists {
+		t.Errorf("after deprovisioning: expected the instance not to exist")
+	}
+}
+
+// TestReplayFailedPlanUpdate replays a plan update that the broker accepts but
+// then fails. The reconcile sequence was recorded against the broker stand-in
+// with the recorder package, cassettes captured against an a9s environment
+// are replayed the same way.
+func TestReplayFailedPlanUpdate(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	rec, err := recorder.New("testdata/failed-plan-update.json", recorder.ModeReplay)
+	if err != nil {
+		t.Fatalf("cannot load cassette: %v", err)
+	}
+	config := osbclient.DefaultClientConfiguration()
+	config.URL = "http://broker.invalid"
+	config.WrapTransport = rec.Transport
+	osb, err := osbclient.NewClient(config)
+	if err != nil {
+		t.Fatalf("cannot create client: %v", err)
+	}
+	e := &external{
+		logger:     a9stest.TestLogger(t),
+		osb:        osb,
+		pollDelays: util.NewPollDelays(),
+	}
+
+	mr := newServiceInstance(
+		withStatusInstanceID("40a5148f-dba2-41f2-b1b7-0ca90e1501c5"),
+		withIntParameter("max_connections", 100),
+		withPlanName("postgresql-single-big"),
+	)
+
+	observation, err := e.Observe(ctx, mr)
+	if err != nil {
+		t.Fatalf("before update: Observe(...): unexpected error: %v", err)
+	}
+	if !observation.ResourceExists || observation.ResourceUpToDate {
+		t.Errorf("before update: expected an existing instance that is not up to date, got %+v", observation)
+	}
+
+	if _, err := e.Update(ctx, mr); err != nil {
+		t.Fatalf("Update(...): unexpected error: %v", err)
+	}
+	if mr.Status.PendingOperation == nil || *mr.Status.PendingOperation != "osbtest-3" {
+		t.Errorf("Update(...): expected pending operation %q, got %v", "osbtest-3", mr.Status.PendingOperation)
+	}
+
+	if _, err := e.Observe(ctx, mr); err == nil || !strings.Contains(err.Error(), errOperationFailed) {
+		t.Errorf("when the update fails: expected error %q, got %v", errOperationFailed, err)
+	}
+	if mr.Status.PendingOperation != nil {
+		t.Errorf("when the update fails: expected the failed operation to be cleared, got %v", *mr.Status.PendingOperation)
+	}
+
+	// The instance is still on the old plan, so the update is retried.
+	observation, err = e.Observe(ctx, mr)
+	if err != nil {
+		t.Fatalf("after the failed update: Observe(...): unexpected error: %v", err)
+	}
+	if !observation.ResourceExists || observation.ResourceUpToDate {
+		t.Errorf("after the failed update: expected an existing instance that is not up to date, got %+v", observation)
+	}
+
+	if n := rec.Unplayed(); n != 0 {
+		t.Errorf("expected all recorded interactions to be replayed, %d are left", n)
+	}
+}
+
+// TestFailedProvisionAgainstStatefulClient runs a provision that the broker
+// accepts but then fails against the stateful fake client.
+func TestFailedProvisionAgainstStatefulClient(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	osb := fakeosb.NewStatefulClient(&defaultCatalogResponse)
+	osb.Async = true
+	osb.PollsUntilDone = 1
+	osb.FailNextOperation()
+
+	e := &external{
+		logger:     a9stest.TestLogger(t),
+		osb:        osb,
+		pollDelays: util.NewPollDelays(),
+	}
+
+	mr := newServiceInstance(withStatusInstanceID("40a5148f-dba2-41f2-b1b7-0ca90e1501c5"))
+	mr.SetUID("b7d0a3c2-6f1e-4c8a-9d2b-5e4f3a2b1c0d")
+
+	if _, err := e.Create(ctx, mr); err != nil {
+		t.Fatalf("Create(...): unexpected error: %v", err)
+	}
+
+	observation, err := e.Observe(ctx, mr)
+	if err != nil || !observation.ResourceExists || mr.Status.PendingOperation == nil {
+		t.Errorf("while provisioning: unexpected observation %+v, error %v, pending operation %v", observation, err, mr.Status.PendingOperation)
+	}
+
+	if _, err := e.Observe(ctx, mr); err == nil {
+		t.Errorf("when provisioning fails: expected the failed operation to be reported")
+	}
+
+	observation, err = e.Observe(ctx, mr)
+	if err != nil || !observation.ResourceExists || mr.Status.PendingOperation != nil {
+		t.Errorf("after provisioning failed: unexpected observation %+v, error %v, pending operation %v", observation, err, mr.Status.PendingOperation)
+	}
+	if diff := cmp.Diff(xpv1.Unavailable(), mr.GetCondition(xpv1.TypeReady), test.EquateConditions()); diff != "" {
+		t.Errorf("after provisioning failed: -want condition, +got condition:\n%s", diff)
+	}
+	if instance, _ := osb.Instance(mr.Status.AtProvider.InstanceID); instance.State != osbtest.StateFailed {
+		t.Errorf("after provisioning failed: expected the broker to report a failed instance, got %q", instance.State)
+	}
+}
@@ -66,6 +66,12 @@ func WithProviderConfigSpec(url string,
 	}
 }
 
+func WithFailoverURLs(urls ...string) func(*apisv1.ProviderConfig) {
+	return func(pc *apisv1.ProviderConfig) {
+		pc.Spec.FailoverURLs = urls
+	}
+}
+
 func WithProviderConfigHealth(health *apisv1.ProviderConfigHealth) func(*apisv1.ProviderConfig) {
 	return func(pc *apisv1.ProviderConfig) {
 		pc.Status.Health = *health
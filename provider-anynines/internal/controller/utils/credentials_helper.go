@@ -18,7 +18,9 @@ package utils
 
 import (
 	"context"
+	"strings"
 
+	osbclient "github.com/anynines/klutchio/clients/a9s-open-service-broker"
 	apisv1 "github.com/anynines/klutchio/provider-anynines/apis/v1"
 	utilerr "github.com/anynines/klutchio/provider-anynines/pkg/utilerr"
 	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
@@ -28,32 +30,60 @@ import (
 )
 
 var (
-	errGetCreds = utilerr.FromStr("cannot get credentials")
+	errGetCreds           = utilerr.FromStr("cannot get credentials")
+	errOAuth2NotSupported = utilerr.PlainUserErr("OAuth2 credentials are only supported for service brokers")
+	errNoCredentials      = utilerr.PlainUserErr("ProviderConfig credentials must configure either oauth2 or a username and password")
+	errAmbiguousCreds     = utilerr.PlainUserErr("ProviderConfig credentials must configure either oauth2 or a username and password, not both")
+	errOAuth2TokenURL     = utilerr.PlainUserErr("ProviderConfig oauth2 credentials must configure a tokenURL")
 )
 
 type Credentials struct {
 	Username           []byte
 	Password           []byte
-	CABundle           []byte // TLS CA certificate(s)
+	OAuth2             *osbclient.OAuth2Config // Replaces Username and Password if set
+	CABundle           []byte                  // TLS CA certificate(s)
 	InsecureSkipVerify bool
 	OverrideServerName string // Override server name for TLS certificate verification
 }
 
-func GetCredentialsFromProvider(ctx context.Context, pc *apisv1.ProviderConfig, kube k8sclient.Client) (Credentials, error) {
-	username := pc.Spec.ProviderCredentials.Username
-	password := pc.Spec.ProviderCredentials.Password
-
-	usernameData, err := resource.CommonCredentialExtractor(ctx, pc.Spec.ProviderCredentials.Source, kube, username)
-	if err != nil {
-		return Credentials{}, errGetCreds.WithCause(err)
+// AssertBasicAuth returns an error if the credentials are OAuth2 client
+// credentials, for services that only support basic auth.
+func (c Credentials) AssertBasicAuth() error {
+	if c.OAuth2 != nil {
+		return errOAuth2NotSupported
 	}
+	return nil
+}
 
-	passwordData, err := resource.CommonCredentialExtractor(ctx, pc.Spec.ProviderCredentials.Source, kube, password)
-	if err != nil {
-		return Credentials{}, errGetCreds.WithCause(err)
+func GetCredentialsFromProvider(ctx context.Context, pc *apisv1.ProviderConfig, kube k8sclient.Client) (Credentials, error) {
+	if err := validateProviderCredentials(pc.Spec.ProviderCredentials); err != nil {
+		return Credentials{}, err
 	}
 
-	creds := Credentials{usernameData, passwordData, nil, false, ""}
+	var creds Credentials
+	if oauth2 := pc.Spec.ProviderCredentials.OAuth2; oauth2 != nil {
+		config, err := getOAuth2Config(ctx, pc.Spec.ProviderCredentials.Source, oauth2, kube)
+		if err != nil {
+			return Credentials{}, err
+		}
+		creds.OAuth2 = config
+	} else {
+		username := pc.Spec.ProviderCredentials.Username
+		password := pc.Spec.ProviderCredentials.Password
+
+		usernameData, err := resource.CommonCredentialExtractor(ctx, pc.Spec.ProviderCredentials.Source, kube, username)
+		if err != nil {
+			return Credentials{}, errGetCreds.WithCause(err)
+		}
+
+		passwordData, err := resource.CommonCredentialExtractor(ctx, pc.Spec.ProviderCredentials.Source, kube, password)
+		if err != nil {
+			return Credentials{}, errGetCreds.WithCause(err)
+		}
+
+		creds.Username = usernameData
+		creds.Password = passwordData
+	}
 
 	// Extract TLS configuration if provided
 	if pc.Spec.TLS != nil {
@@ -73,6 +103,52 @@ func GetCredentialsFromProvider(ctx context.Context, pc *apisv1.ProviderConfig,
 	return creds, nil
 }
 
+// validateProviderCredentials returns an error unless the credentials
+// configure exactly one of OAuth2 and basic auth. Credentials with source
+// None configure neither.
+func validateProviderCredentials(pc apisv1.ProviderCredentials) error {
+	if pc.Source == xpv1.CredentialsSourceNone {
+		return nil
+	}
+
+	basicAuth := selectorConfigured(pc.Username) || selectorConfigured(pc.Password)
+	switch {
+	case pc.OAuth2 != nil && basicAuth:
+		return errAmbiguousCreds
+	case pc.OAuth2 != nil && pc.OAuth2.TokenURL == "":
+		return errOAuth2TokenURL
+	case pc.OAuth2 == nil && (!selectorConfigured(pc.Username) || !selectorConfigured(pc.Password)):
+		return errNoCredentials
+	}
+	return nil
+}
+
+// selectorConfigured returns whether a credential selector selects anything.
+func selectorConfigured(s xpv1.CommonCredentialSelectors) bool {
+	return s.SecretRef != nil || s.Env != nil || s.Fs != nil
+}
+
+// getOAuth2Config extracts the OAuth2 client credentials referenced by a
+// ProviderConfig.
+func getOAuth2Config(ctx context.Context, source xpv1.CredentialsSource, oauth2 *apisv1.OAuth2Credentials, kube k8sclient.Client) (*osbclient.OAuth2Config, error) {
+	clientID, err := resource.CommonCredentialExtractor(ctx, source, kube, oauth2.ClientID)
+	if err != nil {
+		return nil, errGetCreds.WithCause(err)
+	}
+
+	clientSecret, err := resource.CommonCredentialExtractor(ctx, source, kube, oauth2.ClientSecret)
+	if err != nil {
+		return nil, errGetCreds.WithCause(err)
+	}
+
+	return &osbclient.OAuth2Config{
+		TokenURL:     oauth2.TokenURL,
+		ClientID:     strings.TrimSpace(string(clientID)),
+		ClientSecret: strings.TrimSpace(string(clientSecret)),
+		Scopes:       oauth2.Scopes,
+	}, nil
+}
+
 // extractSecretData retrieves the value of a key from a Kubernetes secret
 func extractSecretData(ctx context.Context, kube k8sclient.Client, ref *xpv1.SecretKeySelector) ([]byte, error) {
 	if ref == nil || ref.Name == "" {
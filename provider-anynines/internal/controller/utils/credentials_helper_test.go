@@ -22,6 +22,8 @@ import (
 
 	apisv1 "github.com/anynines/klutchio/provider-anynines/apis/v1"
 	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+	"github.com/google/go-cmp/cmp"
 	v1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
@@ -290,6 +292,129 @@ func TestGetCredentialsFromProviderWithDefaultCAKey(t *testing.T) {
 	}
 }
 
+// TestGetCredentialsFromProviderWithOAuth2 tests extraction of OAuth2 client credentials
+func TestGetCredentialsFromProviderWithOAuth2(t *testing.T) {
+	ctx := context.Background()
+
+	scheme := runtime.NewScheme()
+	_ = apisv1.AddToScheme(scheme)
+	_ = v1.AddToScheme(scheme)
+
+	oauth2Secret := newTestSecret("oauth2", "default", "clientID", []byte("klutch\n"))
+	oauth2Secret.Data["clientSecret"] = []byte("s3cr3t")
+
+	kube := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithObjects(oauth2Secret).
+		Build()
+
+	pc := newTestProviderConfig("test-pc-oauth2")
+	pc.Spec.ProviderCredentials.Username = xpv1.CommonCredentialSelectors{}
+	pc.Spec.ProviderCredentials.Password = xpv1.CommonCredentialSelectors{}
+	pc.Spec.ProviderCredentials.OAuth2 = &apisv1.OAuth2Credentials{
+		TokenURL: "https://uaa.example.com/oauth/token",
+		Scopes:   []string{"broker.read", "broker.write"},
+		ClientID: xpv1.CommonCredentialSelectors{
+			SecretRef: &xpv1.SecretKeySelector{
+				SecretReference: xpv1.SecretReference{Namespace: "default", Name: "oauth2"},
+				Key:             "clientID",
+			},
+		},
+		ClientSecret: xpv1.CommonCredentialSelectors{
+			SecretRef: &xpv1.SecretKeySelector{
+				SecretReference: xpv1.SecretReference{Namespace: "default", Name: "oauth2"},
+				Key:             "clientSecret",
+			},
+		},
+	}
+
+	creds, err := GetCredentialsFromProvider(ctx, pc, kube)
+	if err != nil {
+		t.Fatalf("failed to get OAuth2 credentials: %v", err)
+	}
+
+	if creds.Username != nil || creds.Password != nil {
+		t.Errorf("expected no basic auth credentials, got %q and %q", creds.Username, creds.Password)
+	}
+
+	if creds.OAuth2 == nil {
+		t.Fatal("expected OAuth2 config to be set")
+	}
+
+	if creds.OAuth2.TokenURL != "https://uaa.example.com/oauth/token" {
+		t.Errorf("expected token URL to be copied, got %s", creds.OAuth2.TokenURL)
+	}
+
+	if creds.OAuth2.ClientID != "klutch" || creds.OAuth2.ClientSecret != "s3cr3t" {
+		t.Errorf("expected client credentials 'klutch' and 's3cr3t', got %q and %q", creds.OAuth2.ClientID, creds.OAuth2.ClientSecret)
+	}
+
+	if len(creds.OAuth2.Scopes) != 2 {
+		t.Errorf("expected 2 scopes, got %v", creds.OAuth2.Scopes)
+	}
+
+	if err := creds.AssertBasicAuth(); err == nil {
+		t.Error("expected OAuth2 credentials to be rejected where basic auth is required")
+	}
+}
+
+func TestValidateProviderCredentials(t *testing.T) {
+	basicAuth := newTestProviderConfig("test-pc").Spec.ProviderCredentials
+	oauth2 := &apisv1.OAuth2Credentials{
+		TokenURL: "https://uaa.example.com/oauth/token",
+		ClientID: xpv1.CommonCredentialSelectors{
+			Env: &xpv1.EnvSelector{Name: "CLIENT_ID"},
+		},
+	}
+
+	cases := map[string]struct {
+		credentials apisv1.ProviderCredentials
+		want        error
+	}{
+		"BasicAuth": {
+			credentials: basicAuth,
+		},
+		"OAuth2": {
+			credentials: apisv1.ProviderCredentials{Source: xpv1.CredentialsSourceSecret, OAuth2: oauth2},
+		},
+		"SourceNone": {
+			credentials: apisv1.ProviderCredentials{Source: xpv1.CredentialsSourceNone},
+		},
+		"Neither": {
+			credentials: apisv1.ProviderCredentials{Source: xpv1.CredentialsSourceSecret},
+			want:        errNoCredentials,
+		},
+		"UsernameWithoutPassword": {
+			credentials: apisv1.ProviderCredentials{Source: xpv1.CredentialsSourceSecret, Username: basicAuth.Username},
+			want:        errNoCredentials,
+		},
+		"Both": {
+			credentials: apisv1.ProviderCredentials{
+				Source:   xpv1.CredentialsSourceSecret,
+				Username: basicAuth.Username,
+				Password: basicAuth.Password,
+				OAuth2:   oauth2,
+			},
+			want: errAmbiguousCreds,
+		},
+		"OAuth2WithoutTokenURL": {
+			credentials: apisv1.ProviderCredentials{
+				Source: xpv1.CredentialsSourceSecret,
+				OAuth2: &apisv1.OAuth2Credentials{ClientID: oauth2.ClientID},
+			},
+			want: errOAuth2TokenURL,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			if diff := cmp.Diff(tc.want, validateProviderCredentials(tc.credentials), test.EquateErrors()); diff != "" {
+				t.Errorf("validateProviderCredentials(...): -want error, +got error:\n%s", diff)
+			}
+		})
+	}
+}
+
 // TestCredentialsStructure tests that Credentials struct has all required fields
 func TestCredentialsStructure(t *testing.T) {
 	creds := Credentials{
@@ -0,0 +1,93 @@
+/*
+Copyright 2024 Klutch Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"strings"
+
+	osbclient "github.com/anynines/klutchio/clients/a9s-open-service-broker"
+	utilerr "github.com/anynines/klutchio/provider-anynines/pkg/utilerr"
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	"github.com/crossplane/crossplane-runtime/pkg/logging"
+	"github.com/crossplane/crossplane-runtime/pkg/resource"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// TypeParametersValid indicates whether the parameters of a managed resource
+// match the JSON schema the service broker publishes for its plan.
+const TypeParametersValid xpv1.ConditionType = "ParametersValid"
+
+// Reasons a managed resource's parameters are or are not valid.
+const (
+	ReasonSchemaSatisfied xpv1.ConditionReason = "SchemaSatisfied"
+	ReasonSchemaViolated  xpv1.ConditionReason = "SchemaViolated"
+)
+
+// ParametersValid returns a condition that indicates the parameters match the
+// schema of the plan.
+func ParametersValid() xpv1.Condition {
+	return xpv1.Condition{
+		Type:               TypeParametersValid,
+		Status:             corev1.ConditionTrue,
+		LastTransitionTime: metav1.Now(),
+		Reason:             ReasonSchemaSatisfied,
+	}
+}
+
+// ParametersInvalid returns a condition that lists every parameter that does
+// not match the schema of the plan, one per line.
+func ParametersInvalid(violations []osbclient.ParameterViolation) xpv1.Condition {
+	lines := make([]string, len(violations))
+	for i, violation := range violations {
+		lines[i] = violation.String()
+	}
+	return xpv1.Condition{
+		Type:               TypeParametersValid,
+		Status:             corev1.ConditionFalse,
+		LastTransitionTime: metav1.Now(),
+		Reason:             ReasonSchemaViolated,
+		Message:            strings.Join(lines, "\n"),
+	}
+}
+
+// CheckParameters records the result of validating the parameters of a broker
+// request against the schemas of the given plan in the ParametersValid
+// condition of the managed resource. The condition is left alone for plans
+// without schemas. CheckParameters returns an error if the parameters violate
+// the schema, in which case the request must not be sent. An invalid schema is
+// logged and otherwise ignored, so that a broken catalog leaves the broker to
+// validate the parameters.
+func CheckParameters(mg resource.Managed, logger logging.Logger, plan *osbclient.Plan, validationErr error) error {
+	if plan.Schemas == nil {
+		return nil
+	}
+
+	if validationErr == nil {
+		mg.SetConditions(ParametersValid())
+		return nil
+	}
+
+	violations, ok := osbclient.IsParameterValidationError(validationErr)
+	if !ok {
+		logger.Info("Cannot validate parameters against the schema of the plan", "error", validationErr)
+		return nil
+	}
+
+	mg.SetConditions(ParametersInvalid(violations.Violations))
+	return utilerr.PlainUserErr(violations.Error())
+}
@@ -0,0 +1,85 @@
+/*
+Copyright 2024 Klutch Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"errors"
+	"testing"
+
+	osbclient "github.com/anynines/klutchio/clients/a9s-open-service-broker"
+	utilerr "github.com/anynines/klutchio/provider-anynines/pkg/utilerr"
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	"github.com/crossplane/crossplane-runtime/pkg/logging"
+	"github.com/crossplane/crossplane-runtime/pkg/resource/fake"
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestCheckParameters(t *testing.T) {
+	planWithSchemas := &osbclient.Plan{Schemas: &osbclient.Schemas{}}
+	violations := osbclient.ParameterValidationError{Violations: []osbclient.ParameterViolation{
+		{Field: "max_connections", Message: "is required"},
+		{Field: "backup.retention", Message: "value must be one of '7d', '30d'"},
+	}}
+
+	cases := map[string]struct {
+		plan          *osbclient.Plan
+		validationErr error
+		wantErr       error
+		wantCondition xpv1.Condition
+	}{
+		"PlanWithoutSchemas": {
+			plan:          &osbclient.Plan{},
+			validationErr: violations,
+			wantCondition: xpv1.Condition{Type: TypeParametersValid, Status: "Unknown"},
+		},
+		"Valid": {
+			plan:          planWithSchemas,
+			wantCondition: ParametersValid(),
+		},
+		"Invalid": {
+			plan:          planWithSchemas,
+			validationErr: violations,
+			wantErr:       utilerr.PlainUserErr(violations.Error()),
+			wantCondition: xpv1.Condition{
+				Type:    TypeParametersValid,
+				Status:  "False",
+				Reason:  ReasonSchemaViolated,
+				Message: "max_connections: is required\nbackup.retention: value must be one of '7d', '30d'",
+			},
+		},
+		"InvalidSchema": {
+			plan:          planWithSchemas,
+			validationErr: errors.New("invalid parameters schema"),
+			wantCondition: xpv1.Condition{Type: TypeParametersValid, Status: "Unknown"},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			mg := &fake.Managed{}
+
+			err := CheckParameters(mg, logging.NewNopLogger(), tc.plan, tc.validationErr)
+			if diff := cmp.Diff(tc.wantErr, err, test.EquateErrors()); diff != "" {
+				t.Errorf("CheckParameters(...): -want error, +got error:\n%s", diff)
+			}
+			if diff := cmp.Diff(tc.wantCondition, mg.GetCondition(TypeParametersValid), test.EquateConditions()); diff != "" {
+				t.Errorf("CheckParameters(...): -want condition, +got condition:\n%s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,76 @@
+/*
+Copyright 2024 Klutch Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"sync"
+	"time"
+
+	"github.com/crossplane/crossplane-runtime/pkg/resource"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// PollDelays remembers, per managed resource, how long a service broker asked
+// us to wait before polling a pending operation again. Its PollIntervalHook
+// makes the managed reconciler requeue the resource after exactly that delay
+// instead of the fixed poll interval.
+type PollDelays struct {
+	mu     sync.Mutex
+	delays map[types.UID]time.Duration
+}
+
+// NewPollDelays returns an empty PollDelays.
+func NewPollDelays() *PollDelays {
+	return &PollDelays{delays: map[types.UID]time.Duration{}}
+}
+
+// Set records the delay the broker suggested for the given managed resource.
+// A nil delay forgets any previously recorded delay. Set is a no-op on a nil
+// PollDelays.
+func (p *PollDelays) Set(mg resource.Managed, delay *time.Duration) {
+	if p == nil {
+		return
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if delay == nil || *delay <= 0 {
+		delete(p.delays, mg.GetUID())
+		return
+	}
+	p.delays[mg.GetUID()] = *delay
+}
+
+// PollIntervalHook implements managed.PollIntervalHook. It returns the delay
+// recorded for the managed resource, if any, and the given poll interval
+// otherwise. A recorded delay is used only once.
+func (p *PollDelays) PollIntervalHook(mg resource.Managed, pollInterval time.Duration) time.Duration {
+	if p == nil {
+		return pollInterval
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	delay, ok := p.delays[mg.GetUID()]
+	if !ok {
+		return pollInterval
+	}
+	delete(p.delays, mg.GetUID())
+	return delay
+}
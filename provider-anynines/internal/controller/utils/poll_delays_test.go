@@ -0,0 +1,64 @@
+/*
+Copyright 2024 Klutch Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"testing"
+	"time"
+
+	"github.com/crossplane/crossplane-runtime/pkg/resource/fake"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/ptr"
+)
+
+func TestPollDelays(t *testing.T) {
+	const pollInterval = time.Minute
+
+	mg := &fake.Managed{}
+	mg.SetUID(types.UID("9b0d6c3a-2b8c-4d62-a0f8-6c1d2f7b8c11"))
+	other := &fake.Managed{}
+	other.SetUID(types.UID("0f3f9e21-f960-41f4-b787-b2b47b567996"))
+
+	delays := NewPollDelays()
+
+	if got := delays.PollIntervalHook(mg, pollInterval); got != pollInterval {
+		t.Errorf("without a recorded delay: expected %v, got %v", pollInterval, got)
+	}
+
+	delays.Set(mg, ptr.To(5*time.Second))
+	if got := delays.PollIntervalHook(other, pollInterval); got != pollInterval {
+		t.Errorf("delay of another resource: expected %v, got %v", pollInterval, got)
+	}
+	if got := delays.PollIntervalHook(mg, pollInterval); got != 5*time.Second {
+		t.Errorf("recorded delay: expected %v, got %v", 5*time.Second, got)
+	}
+	if got := delays.PollIntervalHook(mg, pollInterval); got != pollInterval {
+		t.Errorf("recorded delay is used once: expected %v, got %v", pollInterval, got)
+	}
+
+	delays.Set(mg, ptr.To(5*time.Second))
+	delays.Set(mg, nil)
+	if got := delays.PollIntervalHook(mg, pollInterval); got != pollInterval {
+		t.Errorf("forgotten delay: expected %v, got %v", pollInterval, got)
+	}
+
+	var nilDelays *PollDelays
+	nilDelays.Set(mg, ptr.To(5*time.Second))
+	if got := nilDelays.PollIntervalHook(mg, pollInterval); got != pollInterval {
+		t.Errorf("nil PollDelays: expected %v, got %v", pollInterval, got)
+	}
+}
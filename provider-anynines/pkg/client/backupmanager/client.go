@@ -18,7 +18,9 @@ package client
 
 import (
 	"errors"
-	"strings"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	bkpmgrclient "github.com/anynines/klutchio/clients/a9s-backup-manager"
 	v1 "github.com/anynines/klutchio/provider-anynines/apis/backup/v1"
@@ -29,6 +31,11 @@ const (
 	InstanceNotFound = "InstanceNotFound"
 )
 
+// EndpointHealth is shared by all backup manager clients created by this
+// package and by the ProviderConfig health checks, so that every client sends
+// its requests to the endpoints that were found healthy last.
+var EndpointHealth = bkpmgrclient.NewEndpointHealth(time.Minute)
+
 // NewBackupManagerService is the default backup manager service factory that creates a client
 // with the provided credentials. It maintains backward compatibility with the existing API.
 // For advanced TLS configuration, use NewBackupManagerServiceWithTLS.
@@ -46,18 +53,26 @@ func NewBackupManagerService(username, password []byte, url string) (bkpmgrclien
 // insecureSkipVerify: if true, skips TLS certificate verification (useful for self-signed certs in development)
 // caBundle: PEM-encoded CA certificate(s) for TLS verification
 // overrideServerName: if set, overrides the server name used for certificate verification
+// For further configuration, use NewBackupManagerServiceWithOptions.
 func NewBackupManagerServiceWithTLS(username, password []byte, url string, insecureSkipVerify bool, caBundle []byte, overrideServerName string) (bkpmgrclient.Client, error) {
+	return NewBackupManagerServiceWithOptions(url,
+		WithBasicAuth(username, password),
+		WithTLS(insecureSkipVerify, caBundle, overrideServerName),
+	)
+}
+
+// NewBackupManagerServiceWithOptions creates a backup manager client for the
+// backup manager at the given URL, configured by the given options. Without
+// WithBasicAuth the client sends empty basic auth credentials.
+func NewBackupManagerServiceWithOptions(url string, opts ...Option) (bkpmgrclient.Client, error) {
 	cfg := bkpmgrclient.DefaultClientConfiguration()
 	cfg.Name = "BackupManagerClient"
 	cfg.URL = url
-	cfg.InsecureSkipVerify = insecureSkipVerify
-	cfg.CABundle = caBundle
-	cfg.OverrideServerName = overrideServerName
-	cfg.AuthConfig = &bkpmgrclient.AuthConfig{
-		BasicAuthConfig: &bkpmgrclient.BasicAuthConfig{
-			Username: strings.TrimSpace(string(username)),
-			Password: strings.TrimSpace(string(password)),
-		},
+	cfg.Metrics = Metrics
+	cfg.EndpointHealth = EndpointHealth
+	WithBasicAuth(nil, nil)(cfg)
+	for _, opt := range opts {
+		opt(cfg)
 	}
 
 	return bkpmgrclient.NewClient(cfg)
@@ -66,9 +81,9 @@ func NewBackupManagerServiceWithTLS(username, password []byte, url string, insec
 func GenerateBackupRestoreObservation(in bkpmgrclient.GetRestoreResponse, rst rstv1.Restore) rstv1.RestoreObservation {
 	return rstv1.RestoreObservation{
 		RestoreID:   in.RestoreID,
-		State:       in.Status,
-		TriggeredAt: in.TriggeredAt,
-		FinishedAt:  in.FinishedAt,
+		State:       string(in.Status),
+		TriggeredAt: metaTime(in.TriggeredAt),
+		FinishedAt:  metaTime(in.FinishedAt),
 		InstanceID:  rst.Status.AtProvider.InstanceID,
 		BackupID:    rst.Status.AtProvider.BackupID,
 	}
@@ -104,10 +119,19 @@ func GenerateObservation(in bkpmgrclient.GetBackupResponse, bkp v1.Backup) v1.Ba
 	return v1.BackupObservation{
 		BackupID:     in.BackupID,
 		SizeInBytes:  uint64(in.Size),
-		Status:       in.Status,
-		TriggeredAt:  in.TriggeredAt,
-		FinishedAt:   in.FinishedAt,
+		Status:       string(in.Status),
+		TriggeredAt:  metaTime(in.TriggeredAt),
+		FinishedAt:   metaTime(in.FinishedAt),
 		Downloadable: in.Downloadable,
 		InstanceID:   bkp.Status.AtProvider.InstanceID,
 	}
 }
+
+// metaTime converts a timestamp of the backup manager to the type used in
+// observations. Unset timestamps are returned as nil.
+func metaTime(t time.Time) *metav1.Time {
+	if t.IsZero() {
+		return nil
+	}
+	return &metav1.Time{Time: t}
+}
@@ -0,0 +1,108 @@
+/*
+Copyright 2024 Klutch Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"strings"
+
+	bkpmgrclient "github.com/anynines/klutchio/clients/a9s-backup-manager"
+	apisv1 "github.com/anynines/klutchio/provider-anynines/apis/v1"
+	"github.com/go-logr/logr"
+)
+
+// Option configures a backup manager client created by
+// NewBackupManagerServiceWithOptions.
+type Option func(*bkpmgrclient.ClientConfiguration)
+
+// WithBasicAuth makes the client authenticate with the given username and
+// password.
+func WithBasicAuth(username, password []byte) Option {
+	return func(cfg *bkpmgrclient.ClientConfiguration) {
+		cfg.AuthConfig = &bkpmgrclient.AuthConfig{
+			BasicAuthConfig: &bkpmgrclient.BasicAuthConfig{
+				Username: strings.TrimSpace(string(username)),
+				Password: strings.TrimSpace(string(password)),
+			},
+		}
+	}
+}
+
+// WithTLS configures the verification of the certificate of the backup
+// manager.
+// insecureSkipVerify: if true, skips TLS certificate verification (useful for self-signed certs in development)
+// caBundle: PEM-encoded CA certificate(s) for TLS verification
+// overrideServerName: if set, overrides the server name used for certificate verification
+func WithTLS(insecureSkipVerify bool, caBundle []byte, overrideServerName string) Option {
+	return func(cfg *bkpmgrclient.ClientConfiguration) {
+		cfg.InsecureSkipVerify = insecureSkipVerify
+		cfg.CABundle = caBundle
+		cfg.OverrideServerName = overrideServerName
+	}
+}
+
+// ForProviderConfig configures the client as specified by the given
+// ProviderConfig: its metrics are labeled with the name of the
+// ProviderConfig, and it uses the failover URLs and the rate limit of the
+// ProviderConfig.
+func ForProviderConfig(pc *apisv1.ProviderConfig) Option {
+	return func(cfg *bkpmgrclient.ClientConfiguration) {
+		for _, opt := range []Option{
+			WithProviderConfig(pc.Name),
+			WithFailoverURLs(pc.Spec.FailoverURLs),
+			WithRateLimit(pc.Name, pc.Spec.RateLimit),
+		} {
+			opt(cfg)
+		}
+	}
+}
+
+// WithProviderConfig labels the metrics of the client with the name of the
+// ProviderConfig it is created for.
+func WithProviderConfig(name string) Option {
+	return func(cfg *bkpmgrclient.ClientConfiguration) {
+		cfg.ProviderConfig = name
+	}
+}
+
+// WithLogger makes the client log to the given logger. Requests and responses
+// are logged, with credentials redacted, if its verbosity is at least 1.
+func WithLogger(log logr.Logger) Option {
+	return func(cfg *bkpmgrclient.ClientConfiguration) {
+		cfg.Logger = log
+		cfg.Verbose = log.V(1).Enabled()
+	}
+}
+
+// WithFailoverURLs makes the client fail over to the given further endpoints
+// of the backup manager, in order, if an endpoint is unreachable.
+func WithFailoverURLs(urls []string) Option {
+	return func(cfg *bkpmgrclient.ClientConfiguration) {
+		cfg.FailoverURLs = urls
+	}
+}
+
+// WithRateLimit limits the requests of the client to the rate limit of the
+// ProviderConfig it is created for. All clients created for the same
+// ProviderConfig share a rate limiter, which is replaced when the limit
+// changes. A backup download holds one of the MaxInFlight requests of the
+// limit until it is closed, which may take as long as the export of the
+// backup.
+func WithRateLimit(providerConfig string, limit *apisv1.RateLimit) Option {
+	return func(cfg *bkpmgrclient.ClientConfiguration) {
+		cfg.RateLimiter = rateLimiter(providerConfig, limit)
+	}
+}
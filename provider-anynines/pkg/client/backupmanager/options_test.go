@@ -0,0 +1,63 @@
+/*
+Copyright 2024 Klutch Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	bkpmgrclient "github.com/anynines/klutchio/clients/a9s-backup-manager"
+	apisv1 "github.com/anynines/klutchio/provider-anynines/apis/v1"
+)
+
+func TestOptions(t *testing.T) {
+	pc := &apisv1.ProviderConfig{
+		ObjectMeta: metav1.ObjectMeta{Name: "options-pc"},
+		Spec: apisv1.ProviderConfigSpec{
+			FailoverURLs: []string{"https://failover.example.com"},
+			RateLimit:    &apisv1.RateLimit{RequestsPerSecond: 5},
+		},
+	}
+
+	cfg := &bkpmgrclient.ClientConfiguration{}
+	for _, opt := range []Option{
+		WithBasicAuth([]byte(" admin\n"), []byte("secret\n")),
+		WithTLS(true, []byte("ca"), "backup-manager.example.com"),
+		ForProviderConfig(pc),
+	} {
+		opt(cfg)
+	}
+
+	want := bkpmgrclient.BasicAuthConfig{Username: "admin", Password: "secret"}
+	if diff := cmp.Diff(want, *cfg.AuthConfig.BasicAuthConfig); diff != "" {
+		t.Errorf("basic auth: -want, +got:\n%s", diff)
+	}
+	if !cfg.InsecureSkipVerify || string(cfg.CABundle) != "ca" || cfg.OverrideServerName != "backup-manager.example.com" {
+		t.Errorf("want the TLS configuration to be set, got %v, %q, %q", cfg.InsecureSkipVerify, cfg.CABundle, cfg.OverrideServerName)
+	}
+	if cfg.ProviderConfig != "options-pc" {
+		t.Errorf("want ProviderConfig %q, got %q", "options-pc", cfg.ProviderConfig)
+	}
+	if diff := cmp.Diff(pc.Spec.FailoverURLs, cfg.FailoverURLs); diff != "" {
+		t.Errorf("failover URLs: -want, +got:\n%s", diff)
+	}
+	if cfg.RateLimiter != rateLimiter("options-pc", pc.Spec.RateLimit) {
+		t.Error("want the rate limiter of the ProviderConfig")
+	}
+}
@@ -0,0 +1,43 @@
+/*
+Copyright 2024 Klutch Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	bkpmgrclient "github.com/anynines/klutchio/clients/a9s-backup-manager"
+	apisv1 "github.com/anynines/klutchio/provider-anynines/apis/v1"
+	"github.com/anynines/klutchio/provider-anynines/pkg/client/ratelimit"
+)
+
+// rateLimiters are the rate limiters of the backup managers, keyed by the
+// name of their ProviderConfig.
+var rateLimiters = ratelimit.NewRegistry(func(limit apisv1.RateLimit) *bkpmgrclient.RateLimiter {
+	return bkpmgrclient.NewRateLimiter(bkpmgrclient.RateLimit{
+		QPS:         float64(limit.RequestsPerSecond),
+		Burst:       int(limit.Burst),
+		MaxInFlight: int(limit.MaxInFlight),
+	})
+})
+
+func rateLimiter(providerConfig string, limit *apisv1.RateLimit) *bkpmgrclient.RateLimiter {
+	return rateLimiters.Get(providerConfig, limit)
+}
+
+// ForgetRateLimit drops the rate limiter shared by the clients of the given
+// ProviderConfig. It is called when the ProviderConfig is deleted.
+func ForgetRateLimit(providerConfig string) {
+	rateLimiters.Forget(providerConfig)
+}
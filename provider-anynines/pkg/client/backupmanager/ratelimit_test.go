@@ -0,0 +1,55 @@
+/*
+Copyright 2024 Klutch Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"testing"
+
+	apisv1 "github.com/anynines/klutchio/provider-anynines/apis/v1"
+)
+
+func TestRateLimiter(t *testing.T) {
+	limit := &apisv1.RateLimit{RequestsPerSecond: 5, MaxInFlight: 2}
+
+	first := rateLimiter("pc", limit)
+	if first == nil {
+		t.Fatal("want a rate limiter for a rate limit")
+	}
+	if got := rateLimiter("pc", limit.DeepCopy()); got != first {
+		t.Error("want clients of the same ProviderConfig to share the rate limiter")
+	}
+	if got := rateLimiter("other", limit); got == first {
+		t.Error("want clients of other ProviderConfigs to use their own rate limiter")
+	}
+
+	changed := rateLimiter("pc", &apisv1.RateLimit{RequestsPerSecond: 10})
+	if changed == first {
+		t.Error("want the rate limiter to be replaced when the limit changes")
+	}
+	if got := changed.Limit().QPS; got != 10 {
+		t.Errorf("want QPS 10, got %v", got)
+	}
+
+	ForgetRateLimit("pc")
+	if got := rateLimiter("pc", &apisv1.RateLimit{RequestsPerSecond: 10}); got == changed {
+		t.Error("want a new rate limiter after the ProviderConfig is forgotten")
+	}
+
+	if got := rateLimiter("pc", nil); got != nil {
+		t.Error("want no rate limiter without rate limit")
+	}
+}
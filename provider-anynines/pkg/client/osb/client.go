@@ -18,7 +18,7 @@ package client
 
 import (
 	"errors"
-	"strings"
+	"time"
 
 	osbclient "github.com/anynines/klutchio/clients/a9s-open-service-broker"
 )
@@ -27,6 +27,21 @@ const (
 	InstanceNotFound = "InstanceNotFound"
 )
 
+// CatalogCache is shared by all service broker clients created by this
+// package. Controllers create a new client on every reconcile, so that the
+// catalog would otherwise be downloaded again by every reconcile.
+var CatalogCache = osbclient.NewCatalogCache(15 * time.Second)
+
+// EndpointHealth is shared by all service broker clients created by this
+// package and by the ProviderConfig health checks, so that every client sends
+// its requests to the endpoints that were found healthy last.
+var EndpointHealth = osbclient.NewEndpointHealth(time.Minute)
+
+// TokenCache is shared by all service broker clients created by this package,
+// so that clients for the same OAuth2 client do not fetch a new access token
+// on every reconcile.
+var TokenCache = osbclient.NewTokenCache()
+
 // NewOsbService is the default OSB service factory that creates a client
 // with the provided credentials. It maintains backward compatibility with the existing API.
 // username: username for basic auth
@@ -44,18 +59,28 @@ func NewOsbService(username, password []byte, url string) (osbclient.Client, err
 // insecureSkipVerify: if true, skips TLS certificate verification (useful for self-signed certs in development)
 // caBundle: PEM-encoded CA certificate(s) for TLS verification
 // overrideServerName: if set, overrides the server name used for certificate verification
+// For further configuration, use NewOsbServiceWithOptions.
 func NewOsbServiceWithTLS(username, password []byte, url string, insecureSkipVerify bool, caBundle []byte, overrideServerName string) (osbclient.Client, error) {
+	return NewOsbServiceWithOptions(url,
+		WithBasicAuth(username, password),
+		WithTLS(insecureSkipVerify, caBundle, overrideServerName),
+	)
+}
+
+// NewOsbServiceWithOptions creates an OSB client for the broker at the given
+// URL, configured by the given options. Without an authentication option the
+// client sends empty basic auth credentials.
+func NewOsbServiceWithOptions(url string, opts ...Option) (osbclient.Client, error) {
 	cfg := osbclient.DefaultClientConfiguration()
 	cfg.Name = "OSBClient"
 	cfg.URL = url
-	cfg.Insecure = insecureSkipVerify
-	cfg.OverrideServerName = overrideServerName
-	cfg.CAData = caBundle
-	cfg.AuthConfig = &osbclient.AuthConfig{
-		BasicAuthConfig: &osbclient.BasicAuthConfig{
-			Username: strings.TrimSpace(string(username)),
-			Password: strings.TrimSpace(string(password)),
-		},
+	cfg.Metrics = Metrics
+	cfg.CatalogCache = CatalogCache
+	cfg.EndpointHealth = EndpointHealth
+	cfg.TokenCache = TokenCache
+	WithBasicAuth(nil, nil)(cfg)
+	for _, opt := range opts {
+		opt(cfg)
 	}
 
 	return osbclient.NewClient(cfg)
@@ -0,0 +1,31 @@
+/*
+Copyright 2024 Klutch Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	osbclient "github.com/anynines/klutchio/clients/a9s-open-service-broker"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+// Metrics records the requests of all service broker clients created by this
+// package. It is registered on the controller-runtime metrics registry and
+// therefore exposed on the metrics endpoint of the provider.
+var Metrics = osbclient.NewMetrics()
+
+func init() {
+	metrics.Registry.MustRegister(Metrics)
+}
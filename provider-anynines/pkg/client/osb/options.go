@@ -0,0 +1,142 @@
+/*
+Copyright 2024 Klutch Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"strings"
+
+	osbclient "github.com/anynines/klutchio/clients/a9s-open-service-broker"
+	apisv1 "github.com/anynines/klutchio/provider-anynines/apis/v1"
+	"github.com/go-logr/logr"
+)
+
+// Option configures a service broker client created by
+// NewOsbServiceWithOptions.
+type Option func(*osbclient.ClientConfiguration)
+
+// WithBasicAuth makes the client authenticate with the given username and
+// password.
+func WithBasicAuth(username, password []byte) Option {
+	return func(cfg *osbclient.ClientConfiguration) {
+		cfg.AuthConfig = &osbclient.AuthConfig{
+			BasicAuthConfig: &osbclient.BasicAuthConfig{
+				Username: strings.TrimSpace(string(username)),
+				Password: strings.TrimSpace(string(password)),
+			},
+		}
+	}
+}
+
+// WithOAuth2 makes the client authenticate with the given OAuth2 client
+// credentials instead of basic auth. A nil config is ignored.
+func WithOAuth2(config *osbclient.OAuth2Config) Option {
+	return func(cfg *osbclient.ClientConfiguration) {
+		if config != nil {
+			cfg.AuthConfig = &osbclient.AuthConfig{OAuth2Config: config}
+		}
+	}
+}
+
+// WithTLS configures the verification of the certificate of the broker.
+// insecureSkipVerify: if true, skips TLS certificate verification (useful for self-signed certs in development)
+// caBundle: PEM-encoded CA certificate(s) for TLS verification
+// overrideServerName: if set, overrides the server name used for certificate verification
+func WithTLS(insecureSkipVerify bool, caBundle []byte, overrideServerName string) Option {
+	return func(cfg *osbclient.ClientConfiguration) {
+		cfg.Insecure = insecureSkipVerify
+		cfg.CAData = caBundle
+		cfg.OverrideServerName = overrideServerName
+	}
+}
+
+// ForProviderConfig configures the client as specified by the given
+// ProviderConfig: its metrics are labeled with the name of the
+// ProviderConfig, and it uses the negotiated API version, the failover URLs,
+// the rate limit and the response validation of the ProviderConfig.
+func ForProviderConfig(pc *apisv1.ProviderConfig) Option {
+	return func(cfg *osbclient.ClientConfiguration) {
+		for _, opt := range []Option{
+			WithProviderConfig(pc.Name),
+			WithAPIVersion(pc.Status.APIVersion),
+			WithFailoverURLs(pc.Spec.FailoverURLs),
+			WithRateLimit(pc.Name, pc.Spec.RateLimit),
+			WithResponseValidation(pc.Spec.ResponseValidation),
+		} {
+			opt(cfg)
+		}
+	}
+}
+
+// WithProviderConfig labels the metrics of the client with the name of the
+// ProviderConfig it is created for.
+func WithProviderConfig(name string) Option {
+	return func(cfg *osbclient.ClientConfiguration) {
+		cfg.ProviderConfig = name
+	}
+}
+
+// WithLogger makes the client log to the given logger. Requests and responses
+// are logged, with credentials redacted, if its verbosity is at least 1.
+func WithLogger(log logr.Logger) Option {
+	return func(cfg *osbclient.ClientConfiguration) {
+		cfg.Logger = log
+		cfg.Verbose = log.V(1).Enabled()
+	}
+}
+
+// WithAPIVersion makes the client use the given API version, typically the
+// one negotiated with the broker and recorded in the status of the
+// ProviderConfig. Empty and unknown versions are ignored.
+func WithAPIVersion(version string) Option {
+	return func(cfg *osbclient.ClientConfiguration) {
+		if parsed, err := osbclient.ParseAPIVersion(version); err == nil {
+			cfg.APIVersion = parsed
+		}
+	}
+}
+
+// WithResponseValidation makes the client validate the responses of the
+// broker in the given mode, Log or Strict. Other modes disable the
+// validation.
+func WithResponseValidation(mode string) Option {
+	return func(cfg *osbclient.ClientConfiguration) {
+		switch osbclient.ResponseValidation(mode) {
+		case osbclient.ResponseValidationLog, osbclient.ResponseValidationStrict:
+			cfg.ResponseValidation = osbclient.ResponseValidation(mode)
+		default:
+			cfg.ResponseValidation = osbclient.ResponseValidationDisabled
+		}
+	}
+}
+
+// WithFailoverURLs makes the client fail over to the given further endpoints
+// of the broker, in order, if an endpoint is unreachable.
+func WithFailoverURLs(urls []string) Option {
+	return func(cfg *osbclient.ClientConfiguration) {
+		cfg.FailoverURLs = urls
+	}
+}
+
+// WithRateLimit limits the requests of the client to the rate limit of the
+// ProviderConfig it is created for. All clients created for the same
+// ProviderConfig share a rate limiter, which is replaced when the limit
+// changes.
+func WithRateLimit(providerConfig string, limit *apisv1.RateLimit) Option {
+	return func(cfg *osbclient.ClientConfiguration) {
+		cfg.RateLimiter = rateLimiter(providerConfig, limit)
+	}
+}
@@ -0,0 +1,77 @@
+/*
+Copyright 2024 Klutch Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package ratelimit shares the rate limiters of the service broker and backup
+// manager clients between all clients created for the same ProviderConfig.
+package ratelimit
+
+import (
+	"sync"
+
+	apisv1 "github.com/anynines/klutchio/provider-anynines/apis/v1"
+)
+
+// Registry holds a rate limiter of type L per ProviderConfig.
+type Registry[L any] struct {
+	newLimiter func(apisv1.RateLimit) L
+
+	mu       sync.Mutex
+	limiters map[string]entry[L]
+}
+
+type entry[L any] struct {
+	limit   apisv1.RateLimit
+	limiter L
+}
+
+// NewRegistry returns a Registry that creates its rate limiters with
+// newLimiter.
+func NewRegistry[L any](newLimiter func(apisv1.RateLimit) L) *Registry[L] {
+	return &Registry[L]{
+		newLimiter: newLimiter,
+		limiters:   map[string]entry[L]{},
+	}
+}
+
+// Get returns the rate limiter of the given ProviderConfig. The rate limiter
+// is replaced when the limit changes. Without limit, Get forgets the rate
+// limiter of the ProviderConfig and returns the zero value of L.
+func (r *Registry[L]) Get(providerConfig string, limit *apisv1.RateLimit) L {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if limit == nil {
+		delete(r.limiters, providerConfig)
+		var none L
+		return none
+	}
+
+	if e, ok := r.limiters[providerConfig]; ok && e.limit == *limit {
+		return e.limiter
+	}
+	e := entry[L]{limit: *limit, limiter: r.newLimiter(*limit)}
+	r.limiters[providerConfig] = e
+	return e.limiter
+}
+
+// Forget drops the rate limiter of the given ProviderConfig, e.g. when the
+// ProviderConfig is deleted. Clients that still use it are not affected.
+func (r *Registry[L]) Forget(providerConfig string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	delete(r.limiters, providerConfig)
+}
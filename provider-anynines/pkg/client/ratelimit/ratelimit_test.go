@@ -0,0 +1,60 @@
+/*
+Copyright 2024 Klutch Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ratelimit
+
+import (
+	"testing"
+
+	apisv1 "github.com/anynines/klutchio/provider-anynines/apis/v1"
+)
+
+func TestRegistry(t *testing.T) {
+	created := 0
+	registry := NewRegistry(func(limit apisv1.RateLimit) *apisv1.RateLimit {
+		created++
+		return &limit
+	})
+	limit := &apisv1.RateLimit{RequestsPerSecond: 5, MaxInFlight: 2}
+
+	first := registry.Get("pc", limit)
+	if first == nil || *first != *limit {
+		t.Fatalf("want a rate limiter for %v, got %v", limit, first)
+	}
+	if got := registry.Get("pc", limit.DeepCopy()); got != first {
+		t.Error("want the rate limiter to be shared while the limit is unchanged")
+	}
+	if got := registry.Get("other", limit); got == first {
+		t.Error("want every ProviderConfig to have its own rate limiter")
+	}
+	if got := registry.Get("pc", &apisv1.RateLimit{RequestsPerSecond: 10}); got == first {
+		t.Error("want the rate limiter to be replaced when the limit changes")
+	}
+
+	registry.Forget("other")
+	if _, ok := registry.limiters["other"]; ok {
+		t.Error("want Forget to drop the rate limiter")
+	}
+	if got := registry.Get("pc", nil); got != nil {
+		t.Errorf("want no rate limiter without rate limit, got %v", got)
+	}
+	if len(registry.limiters) != 0 {
+		t.Errorf("want no rate limiters left, got %v", registry.limiters)
+	}
+	if created != 3 {
+		t.Errorf("want 3 rate limiters to be created, got %d", created)
+	}
+}
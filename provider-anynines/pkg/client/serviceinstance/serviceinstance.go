@@ -88,9 +88,21 @@ func GenerateObservation(in osbclient.GetInstanceResponse, params map[string]api
 	}
 }
 
+// GenerateMaintenanceInfo converts the maintenance info a Service Broker reports
+// for an instance into the format used by our internal ServiceInstance API.
+func GenerateMaintenanceInfo(in *osbclient.MaintenanceInfo) *v1.MaintenanceInfo {
+	if in == nil {
+		return nil
+	}
+	return &v1.MaintenanceInfo{
+		Version:     in.Version,
+		Description: in.Description,
+	}
+}
+
 // SpecMatchesObservedState checks whether current state is up-to-date compared to the given set of parameters.
 func SpecMatchesObservedState(spec v1.ServiceInstanceParameters, in osbclient.GetInstanceResponse, parameters map[string]apiextv1.JSON) (bool, string) {
-	// We pre-fill these values for the ServiceName and the PlanName into the struct because they
+	// We pre-fill these values for the ServiceName, the PlanName and AutoUpgrade into the struct because they
 	// are not part of the observation response we get from the Service broker and therefore these
 	// field would be nil in the variable "observed". This would in turn lead the provider to assume
 	// that the k8s object and the service instance at the provider are out of sync when in reality
@@ -98,6 +110,7 @@ func SpecMatchesObservedState(spec v1.ServiceInstanceParameters, in osbclient.Ge
 	observed := &v1.ServiceInstanceParameters{
 		ServiceName: spec.ServiceName,
 		PlanName:    spec.PlanName,
+		AutoUpgrade: spec.AutoUpgrade,
 	}
 
 	LateInitialize(observed, in.Context, parameters)
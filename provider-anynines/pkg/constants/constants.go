@@ -20,9 +20,10 @@ const (
 	LabelKeyClaimName      = "crossplane.io/claim-name"
 	LabelKeyClaimNamespace = "crossplane.io/claim-namespace"
 
-	AnnotationKeyInstanceID   = "anynines.crossplane.io/instance-id"
-	AnnotationKeyExternalName = "crossplane.io/external-name"
-	AnnotationKeyPlanID       = "anynines.crossplane.io/plan-id"
-	AnnotationKeyRestoreID    = "anynines.crossplane.io/restore-id"
-	AnnotationKeyServiceID    = "anynines.crossplane.io/service-id"
+	AnnotationKeyInstanceID          = "anynines.crossplane.io/instance-id"
+	AnnotationKeyExternalName        = "crossplane.io/external-name"
+	AnnotationKeyOriginatingIdentity = "anynines.crossplane.io/originating-identity"
+	AnnotationKeyPlanID              = "anynines.crossplane.io/plan-id"
+	AnnotationKeyRestoreID           = "anynines.crossplane.io/restore-id"
+	AnnotationKeyServiceID           = "anynines.crossplane.io/service-id"
 )
@@ -0,0 +1,84 @@
+/*
+Copyright 2024 Klutch Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package originatingidentity records the Kubernetes users who request
+// changes of resources, so that the requests the provider makes to service
+// brokers on their behalf carry their originating identity.
+package originatingidentity
+
+import (
+	"encoding/json"
+
+	authenticationv1 "k8s.io/api/authentication/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	osbclient "github.com/anynines/klutchio/clients/a9s-open-service-broker"
+	"github.com/anynines/klutchio/provider-anynines/pkg/constants"
+)
+
+// User is a Kubernetes user as described by the value of the originating
+// identity of the kubernetes platform in the Open Service Broker API.
+type User struct {
+	Username string              `json:"username"`
+	UID      string              `json:"uid"`
+	Groups   []string            `json:"groups,omitempty"`
+	Extra    map[string][]string `json:"extra,omitempty"`
+}
+
+// FromUserInfo returns the user of an admission request.
+func FromUserInfo(info authenticationv1.UserInfo) User {
+	user := User{
+		Username: info.Username,
+		UID:      info.UID,
+		Groups:   info.Groups,
+	}
+	if len(info.Extra) > 0 {
+		user.Extra = make(map[string][]string, len(info.Extra))
+		for key, values := range info.Extra {
+			user.Extra[key] = values
+		}
+	}
+	return user
+}
+
+// Annotation returns the value of the originating identity annotation that
+// records the user.
+func (u User) Annotation() (string, error) {
+	value, err := json.Marshal(u)
+	if err != nil {
+		return "", err
+	}
+	return string(value), nil
+}
+
+// FromObject returns the originating identity recorded in the annotation of
+// obj. It returns nil if obj has no annotation or the annotation does not
+// describe a user.
+func FromObject(obj metav1.Object) *osbclient.OriginatingIdentity {
+	value := obj.GetAnnotations()[constants.AnnotationKeyOriginatingIdentity]
+	if value == "" {
+		return nil
+	}
+
+	var user User
+	if err := json.Unmarshal([]byte(value), &user); err != nil || user.Username == "" {
+		return nil
+	}
+	return &osbclient.OriginatingIdentity{
+		Platform: osbclient.PlatformKubernetes,
+		Value:    value,
+	}
+}
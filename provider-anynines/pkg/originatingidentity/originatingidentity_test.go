@@ -0,0 +1,54 @@
+/*
+Copyright 2024 Klutch Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package originatingidentity
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	osbclient "github.com/anynines/klutchio/clients/a9s-open-service-broker"
+)
+
+func TestFromObject(t *testing.T) {
+	cases := map[string]struct {
+		annotations map[string]string
+		want        *osbclient.OriginatingIdentity
+	}{
+		"NoAnnotation": {},
+		"User": {
+			annotations: map[string]string{"anynines.crossplane.io/originating-identity": alice},
+			want:        &osbclient.OriginatingIdentity{Platform: "kubernetes", Value: alice},
+		},
+		"InvalidJSON": {
+			annotations: map[string]string{"anynines.crossplane.io/originating-identity": "alice"},
+		},
+		"NoUsername": {
+			annotations: map[string]string{"anynines.crossplane.io/originating-identity": `{"uid":"a1"}`},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got := FromObject(&metav1.ObjectMeta{Annotations: tc.annotations})
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("FromObject(...): -want, +got:\n%s", diff)
+			}
+		})
+	}
+}
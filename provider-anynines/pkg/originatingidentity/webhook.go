@@ -0,0 +1,122 @@
+/*
+Copyright 2024 Klutch Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package originatingidentity
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"strings"
+
+	admissionv1 "k8s.io/api/admission/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	"github.com/anynines/klutchio/provider-anynines/pkg/constants"
+)
+
+// WebhookPath is the path the webhook is served at.
+const WebhookPath = "/mutate-originating-identity"
+
+const errForgedAnnotation = "the " + constants.AnnotationKeyOriginatingIdentity + " annotation is recorded by the provider and cannot be set by users"
+
+// Webhook is a mutating admission webhook that records the user who creates a
+// resource or changes its spec in the originating identity annotation of the
+// resource. Other changes do not replace the recorded user. Requests of users
+// that set or change the annotation themselves are rejected.
+//
+// Service accounts of the trusted namespace, such as those of Crossplane and
+// the provider, are not recorded and may change the annotation, so that
+// Crossplane propagates the annotation of a claim to its composite and managed
+// resources.
+type Webhook struct {
+	// TrustedNamespace is the namespace of the trusted service accounts.
+	TrustedNamespace string
+}
+
+var _ admission.Handler = &Webhook{}
+
+// Handle records the user of create and update requests.
+func (w *Webhook) Handle(_ context.Context, req admission.Request) admission.Response {
+	if req.Operation != admissionv1.Create && req.Operation != admissionv1.Update {
+		return admission.Allowed("")
+	}
+	if w.trusted(req.UserInfo.Username) {
+		return admission.Allowed("trusted service account")
+	}
+
+	obj := &unstructured.Unstructured{}
+	if err := json.Unmarshal(req.Object.Raw, &obj.Object); err != nil {
+		return admission.Errored(http.StatusBadRequest, err)
+	}
+
+	var value string
+	forged := obj.GetAnnotations()[constants.AnnotationKeyOriginatingIdentity]
+	if req.Operation == admissionv1.Create {
+		if forged != "" {
+			return admission.Denied(errForgedAnnotation)
+		}
+		user, err := FromUserInfo(req.UserInfo).Annotation()
+		if err != nil {
+			return admission.Errored(http.StatusInternalServerError, err)
+		}
+		value = user
+	} else {
+		old := &unstructured.Unstructured{}
+		if err := json.Unmarshal(req.OldObject.Raw, &old.Object); err != nil {
+			return admission.Errored(http.StatusBadRequest, err)
+		}
+		value = old.GetAnnotations()[constants.AnnotationKeyOriginatingIdentity]
+		if forged != value {
+			return admission.Denied(errForgedAnnotation)
+		}
+		if !reflect.DeepEqual(obj.Object["spec"], old.Object["spec"]) {
+			user, err := FromUserInfo(req.UserInfo).Annotation()
+			if err != nil {
+				return admission.Errored(http.StatusInternalServerError, err)
+			}
+			value = user
+		}
+	}
+
+	annotations := obj.GetAnnotations()
+	if annotations[constants.AnnotationKeyOriginatingIdentity] == value {
+		return admission.Allowed("")
+	}
+	if value == "" {
+		delete(annotations, constants.AnnotationKeyOriginatingIdentity)
+	} else {
+		if annotations == nil {
+			annotations = map[string]string{}
+		}
+		annotations[constants.AnnotationKeyOriginatingIdentity] = value
+	}
+	obj.SetAnnotations(annotations)
+
+	mutated, err := json.Marshal(obj.Object)
+	if err != nil {
+		return admission.Errored(http.StatusInternalServerError, err)
+	}
+	return admission.PatchResponseFromRaw(req.Object.Raw, mutated)
+}
+
+// trusted returns whether username is a service account of the trusted
+// namespace.
+func (w *Webhook) trusted(username string) bool {
+	return w.TrustedNamespace != "" && strings.HasPrefix(username, "system:serviceaccount:"+w.TrustedNamespace+":")
+}
@@ -0,0 +1,184 @@
+/*
+Copyright 2024 Klutch Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package originatingidentity
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	jsonpatch "gomodules.xyz/jsonpatch/v2"
+	admissionv1 "k8s.io/api/admission/v1"
+	authenticationv1 "k8s.io/api/authentication/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+)
+
+const (
+	alice = `{"username":"alice","uid":"a1","groups":["dev"]}`
+	bob   = `{"username":"bob","uid":"b2"}`
+)
+
+func claim(spec string, annotation string) runtime.RawExtension {
+	obj := map[string]interface{}{
+		"apiVersion": "anynines.com/v1",
+		"kind":       "PostgresqlInstance",
+		"metadata":   map[string]interface{}{"name": "example", "namespace": "default"},
+		"spec":       map[string]interface{}{"plan": spec},
+	}
+	if annotation != "" {
+		obj["metadata"].(map[string]interface{})["annotations"] = map[string]interface{}{
+			"anynines.crossplane.io/originating-identity": annotation,
+		}
+	}
+	raw, _ := json.Marshal(obj)
+	return runtime.RawExtension{Raw: raw}
+}
+
+func TestWebhookHandle(t *testing.T) {
+	type args struct {
+		operation admissionv1.Operation
+		user      authenticationv1.UserInfo
+		object    runtime.RawExtension
+		oldObject runtime.RawExtension
+	}
+	type want struct {
+		allowed bool
+		patches []jsonpatch.JsonPatchOperation
+	}
+
+	aliceInfo := authenticationv1.UserInfo{Username: "alice", UID: "a1", Groups: []string{"dev"}}
+	bobInfo := authenticationv1.UserInfo{Username: "bob", UID: "b2"}
+	addAnnotation := func(value string) []jsonpatch.JsonPatchOperation {
+		return []jsonpatch.JsonPatchOperation{{
+			Operation: "add",
+			Path:      "/metadata/annotations",
+			Value:     map[string]interface{}{"anynines.crossplane.io/originating-identity": value},
+		}}
+	}
+	replaceAnnotation := func(value string) []jsonpatch.JsonPatchOperation {
+		return []jsonpatch.JsonPatchOperation{{
+			Operation: "replace",
+			Path:      "/metadata/annotations/anynines.crossplane.io~1originating-identity",
+			Value:     value,
+		}}
+	}
+
+	cases := map[string]struct {
+		args args
+		want want
+	}{
+		"CreateRecordsUser": {
+			args: args{
+				operation: admissionv1.Create,
+				user:      aliceInfo,
+				object:    claim("small", ""),
+			},
+			want: want{allowed: true, patches: addAnnotation(alice)},
+		},
+		"CreateRejectsForgedAnnotation": {
+			args: args{
+				operation: admissionv1.Create,
+				user:      bobInfo,
+				object:    claim("small", alice),
+			},
+			want: want{allowed: false},
+		},
+		"SpecUpdateRecordsUser": {
+			args: args{
+				operation: admissionv1.Update,
+				user:      bobInfo,
+				object:    claim("medium", alice),
+				oldObject: claim("small", alice),
+			},
+			want: want{allowed: true, patches: replaceAnnotation(bob)},
+		},
+		"MetadataUpdateKeepsUser": {
+			args: args{
+				operation: admissionv1.Update,
+				user:      bobInfo,
+				object:    claim("small", alice),
+				oldObject: claim("small", alice),
+			},
+			want: want{allowed: true},
+		},
+		"UpdateRejectsForgedAnnotation": {
+			args: args{
+				operation: admissionv1.Update,
+				user:      bobInfo,
+				object:    claim("medium", `{"username":"admin"}`),
+				oldObject: claim("medium", alice),
+			},
+			want: want{allowed: false},
+		},
+		"UpdateRejectsRemovedAnnotation": {
+			args: args{
+				operation: admissionv1.Update,
+				user:      bobInfo,
+				object:    claim("medium", ""),
+				oldObject: claim("small", alice),
+			},
+			want: want{allowed: false},
+		},
+		"TrustedServiceAccountIsNotRecorded": {
+			args: args{
+				operation: admissionv1.Create,
+				user:      authenticationv1.UserInfo{Username: "system:serviceaccount:crossplane-system:crossplane"},
+				object:    claim("small", alice),
+			},
+			want: want{allowed: true},
+		},
+		"OtherServiceAccountIsRecorded": {
+			args: args{
+				operation: admissionv1.Create,
+				user:      authenticationv1.UserInfo{Username: "system:serviceaccount:default:ci", UID: "c3"},
+				object:    claim("small", ""),
+			},
+			want: want{allowed: true, patches: addAnnotation(`{"username":"system:serviceaccount:default:ci","uid":"c3"}`)},
+		},
+		"DeleteIsAllowed": {
+			args: args{
+				operation: admissionv1.Delete,
+				user:      aliceInfo,
+				oldObject: claim("small", alice),
+			},
+			want: want{allowed: true},
+		},
+	}
+
+	w := &Webhook{TrustedNamespace: "crossplane-system"}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got := w.Handle(context.Background(), admission.Request{AdmissionRequest: admissionv1.AdmissionRequest{
+				Operation: tc.args.operation,
+				UserInfo:  tc.args.user,
+				Object:    tc.args.object,
+				OldObject: tc.args.oldObject,
+			}})
+
+			if got.Allowed != tc.want.allowed {
+				t.Errorf("Handle(...): want allowed %t, got %t (%v)", tc.want.allowed, got.Allowed, got.Result)
+			}
+			if diff := cmp.Diff(tc.want.patches, got.Patches, cmpopts.EquateEmpty()); diff != "" {
+				t.Errorf("Handle(...): -want patches, +got patches:\n%s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,134 @@
+/*
+Copyright 2024 Klutch Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tracing
+
+import (
+	"context"
+
+	"github.com/crossplane/crossplane-runtime/pkg/meta"
+	"github.com/crossplane/crossplane-runtime/pkg/reconciler/managed"
+	"github.com/crossplane/crossplane-runtime/pkg/resource"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// tracerName is the name of the tracer that creates the spans of managed
+// resource controllers.
+const tracerName = "github.com/anynines/klutchio/provider-anynines/pkg/tracing"
+
+// Attributes set on the spans of managed resource operations.
+const (
+	attributeKind         = attribute.Key("crossplane.managed.kind")
+	attributeName         = attribute.Key("crossplane.managed.name")
+	attributeExternalName = attribute.Key("crossplane.managed.external_name")
+	attributeExists       = attribute.Key("crossplane.observation.resource_exists")
+	attributeUpToDate     = attribute.Key("crossplane.observation.resource_up_to_date")
+)
+
+var _ managed.ExternalClient = &Decorator{}
+var _ managed.ExternalConnecter = &ConnectDecorator{}
+
+// ConnectDecorator wraps the ExternalClients of a connector in a Decorator.
+type ConnectDecorator struct {
+	Connector managed.ExternalConnecter
+	// Kind is the kind of the managed resources, used to name the spans.
+	Kind string
+}
+
+func (cd ConnectDecorator) Connect(ctx context.Context, res resource.Managed) (managed.ExternalClient, error) {
+	c, err := cd.Connector.Connect(ctx, res)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Decorator{
+		ExternalClient: c,
+		Kind:           cd.Kind,
+	}, nil
+}
+
+// Decorator creates a span around each operation of an existing
+// ExternalClient. Requests the ExternalClient sends to a service broker or
+// backup manager with the context it is given become children of the span.
+type Decorator struct {
+	ExternalClient managed.ExternalClient
+	Kind           string
+}
+
+func (cl Decorator) Create(ctx context.Context, res resource.Managed) (managed.ExternalCreation, error) {
+	ctx, span := cl.start(ctx, "Create", res)
+	defer span.End()
+
+	r, err := cl.ExternalClient.Create(ctx, res)
+	return r, recordError(span, err)
+}
+
+func (cl Decorator) Delete(ctx context.Context, res resource.Managed) (managed.ExternalDelete, error) {
+	ctx, span := cl.start(ctx, "Delete", res)
+	defer span.End()
+
+	r, err := cl.ExternalClient.Delete(ctx, res)
+	return r, recordError(span, err)
+}
+
+func (cl Decorator) Observe(ctx context.Context, res resource.Managed) (managed.ExternalObservation, error) {
+	ctx, span := cl.start(ctx, "Observe", res)
+	defer span.End()
+
+	r, err := cl.ExternalClient.Observe(ctx, res)
+	if err == nil {
+		span.SetAttributes(
+			attributeExists.Bool(r.ResourceExists),
+			attributeUpToDate.Bool(r.ResourceUpToDate),
+		)
+	}
+	return r, recordError(span, err)
+}
+
+func (cl Decorator) Update(ctx context.Context, res resource.Managed) (managed.ExternalUpdate, error) {
+	ctx, span := cl.start(ctx, "Update", res)
+	defer span.End()
+
+	r, err := cl.ExternalClient.Update(ctx, res)
+	return r, recordError(span, err)
+}
+
+func (cl Decorator) Disconnect(ctx context.Context) error {
+	return cl.ExternalClient.Disconnect(ctx)
+}
+
+func (cl Decorator) start(ctx context.Context, operation string, res resource.Managed) (context.Context, trace.Span) {
+	attributes := []attribute.KeyValue{attributeKind.String(cl.Kind)}
+	if res != nil {
+		attributes = append(attributes,
+			attributeName.String(res.GetName()),
+			attributeExternalName.String(meta.GetExternalName(res)),
+		)
+	}
+
+	return otel.Tracer(tracerName).Start(ctx, cl.Kind+"."+operation, trace.WithAttributes(attributes...))
+}
+
+func recordError(span trace.Span, err error) error {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	return err
+}
@@ -0,0 +1,85 @@
+/*
+Copyright 2024 Klutch Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tracing_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/crossplane/crossplane-runtime/pkg/reconciler/managed"
+	"github.com/crossplane/crossplane-runtime/pkg/resource"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/anynines/klutchio/provider-anynines/pkg/tracing"
+)
+
+func TestDecorator(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	defer otel.SetTracerProvider(otel.GetTracerProvider())
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+
+	internal := errors.New("frobber is kalooning")
+	var brokerSpan trace.SpanContext
+
+	dec := tracing.Decorator{
+		ExternalClient: managed.ExternalClientFns{
+			ObserveFn: func(ctx context.Context, mg resource.Managed) (managed.ExternalObservation, error) {
+				brokerSpan = trace.SpanContextFromContext(ctx)
+				return managed.ExternalObservation{ResourceExists: true}, nil
+			},
+			DeleteFn: func(ctx context.Context, mg resource.Managed) (managed.ExternalDelete, error) {
+				return managed.ExternalDelete{}, internal
+			},
+		},
+		Kind: "ServiceInstance",
+	}
+
+	if _, err := dec.Observe(context.Background(), nil); err != nil {
+		t.Fatal("expected nil error", err)
+	}
+	if _, err := dec.Delete(context.Background(), nil); !errors.Is(err, internal) {
+		t.Fatalf("expected error %v, got %v", internal, err)
+	}
+
+	spans := recorder.Ended()
+	if len(spans) != 2 {
+		t.Fatalf("expected 2 spans, got %d", len(spans))
+	}
+
+	observe, del := spans[0], spans[1]
+	if observe.Name() != "ServiceInstance.Observe" {
+		t.Errorf("expected span name %q, got %q", "ServiceInstance.Observe", observe.Name())
+	}
+	if observe.SpanContext().SpanID() != brokerSpan.SpanID() {
+		t.Errorf("expected the wrapped client to be called with the context of the span")
+	}
+	if observe.Status().Code == codes.Error {
+		t.Errorf("expected span of successful operation not to fail")
+	}
+
+	if del.Name() != "ServiceInstance.Delete" {
+		t.Errorf("expected span name %q, got %q", "ServiceInstance.Delete", del.Name())
+	}
+	if del.Status().Code != codes.Error {
+		t.Errorf("expected span status %v, got %v", codes.Error, del.Status().Code)
+	}
+}